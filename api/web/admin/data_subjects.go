@@ -0,0 +1,205 @@
+package admin
+
+import (
+	"errors"
+	"main-api/internal/domain/partners"
+	"main-api/internal/pkg/validator"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/google/uuid"
+)
+
+type (
+	// DataSubjectRequestData takes the CPF in the body, so it isn't written to
+	// the access logs along with the URL.
+	DataSubjectRequestData struct {
+		Cpf string `json:"cpf" validate:"required,cpf"`
+	}
+
+	DataSubjectExportResponseData struct {
+		Cpf         string                            `json:"cpf"`
+		Customers   []DataSubjectCustomerResponseData `json:"customers"`
+		Policies    []DataSubjectPolicyResponseData   `json:"policies"`
+		AuditEvents []AuditEventResponseData          `json:"audit_events"`
+	}
+
+	DataSubjectCustomerResponseData struct {
+		ID          string     `json:"id"`
+		PartnerID   string     `json:"partner_id"`
+		Name        string     `json:"name"`
+		Sex         string     `json:"sex"`
+		DateOfBirth string     `json:"date_of_birth"`
+		Cpf         string     `json:"cpf"`
+		Email       string     `json:"email,omitempty"`
+		Phone       string     `json:"phone,omitempty"`
+		CreatedAt   time.Time  `json:"created_at"`
+		UpdatedAt   time.Time  `json:"updated_at"`
+		DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+	}
+
+	DataSubjectPolicyResponseData struct {
+		ID            string                               `json:"id"`
+		PartnerID     string                               `json:"partner_id"`
+		CustomerID    string                               `json:"customer_id,omitempty"`
+		ProviderID    uuid.UUID                            `json:"provider_id"`
+		QuotationID   uuid.UUID                            `json:"quotation_id"`
+		Name          string                               `json:"name"`
+		Sex           string                               `json:"sex"`
+		DateOfBirth   string                               `json:"date_of_birth"`
+		Cpf           string                               `json:"cpf"`
+		Email         string                               `json:"email,omitempty"`
+		Phone         string                               `json:"phone,omitempty"`
+		Beneficiaries []DataSubjectBeneficiaryResponseData `json:"beneficiaries,omitempty"`
+		Dependents    []DataSubjectDependentResponseData   `json:"dependents,omitempty"`
+		Status        string                               `json:"status"`
+	}
+
+	DataSubjectBeneficiaryResponseData struct {
+		Name         string  `json:"name"`
+		Relationship string  `json:"relationship,omitempty"`
+		Percentage   float64 `json:"percentage"`
+	}
+
+	DataSubjectDependentResponseData struct {
+		Name         string `json:"name"`
+		Sex          string `json:"sex"`
+		DateOfBirth  string `json:"date_of_birth"`
+		Relationship string `json:"relationship"`
+	}
+
+	DataSubjectErasureResponseData struct {
+		Pseudonym         string `json:"pseudonym"`
+		Customers         int64  `json:"customers"`
+		Policies          int64  `json:"policies"`
+		WebhookDeliveries int64  `json:"webhook_deliveries"`
+	}
+)
+
+// ExportDataSubject answers an LGPD access request with the personal data
+// kept about the holder with the CPF.
+func (h *HTTPHandler) ExportDataSubject(c *fiber.Ctx) error {
+	cpf, err := parseDataSubjectCpf(c)
+	if err != nil {
+		return err
+	}
+
+	export, err := h.service.ExportDataSubject(c.Context(), cpf)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(toDataSubjectExportResponseData(export))
+}
+
+// EraseDataSubject answers an LGPD deletion request by pseudonymizing the
+// personal data kept about the holder with the CPF.
+func (h *HTTPHandler) EraseDataSubject(c *fiber.Ctx) error {
+	cpf, err := parseDataSubjectCpf(c)
+	if err != nil {
+		return err
+	}
+
+	result, err := h.service.EraseDataSubject(c.Context(), cpf)
+	if errors.Is(err, partners.ErrPolicyCacheNotInvalidated) {
+		// The data was erased, so the erasure mustn't be retried.
+		log.Errorf("[DATA SUBJECTS] erasure %q: %v", result.Pseudonym, err)
+	} else if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(DataSubjectErasureResponseData{
+		Pseudonym:         result.Pseudonym,
+		Customers:         result.Customers,
+		Policies:          result.Policies,
+		WebhookDeliveries: result.WebhookDeliveries,
+	})
+}
+
+func parseDataSubjectCpf(c *fiber.Ctx) (string, error) {
+	bodyData := new(DataSubjectRequestData)
+	if err := c.BodyParser(bodyData); err != nil {
+		return "", fiber.NewError(fiber.StatusUnprocessableEntity, err.Error())
+	}
+
+	if err := validator.BodyData(bodyData); err != nil {
+		return "", err
+	}
+
+	return validator.NormalizeCPF(bodyData.Cpf), nil
+}
+
+func toDataSubjectExportResponseData(export *partners.DataSubjectExport) DataSubjectExportResponseData {
+	response := DataSubjectExportResponseData{
+		Cpf:         export.Cpf,
+		Customers:   make([]DataSubjectCustomerResponseData, len(export.Customers)),
+		Policies:    make([]DataSubjectPolicyResponseData, len(export.Policies)),
+		AuditEvents: make([]AuditEventResponseData, len(export.AuditEvents)),
+	}
+
+	for index, customer := range export.Customers {
+		response.Customers[index] = DataSubjectCustomerResponseData{
+			ID:          customer.ID,
+			PartnerID:   customer.PartnerID,
+			Name:        customer.Name,
+			Sex:         string(customer.Sex),
+			DateOfBirth: customer.DateOfBirth,
+			Cpf:         customer.Cpf,
+			Email:       customer.Email,
+			Phone:       customer.Phone,
+			CreatedAt:   customer.CreatedAt,
+			UpdatedAt:   customer.UpdatedAt,
+		}
+
+		if !customer.DeletedAt.IsZero() {
+			response.Customers[index].DeletedAt = &customer.DeletedAt
+		}
+	}
+
+	for index, policy := range export.Policies {
+		response.Policies[index] = toDataSubjectPolicyResponseData(policy)
+	}
+
+	for index, event := range export.AuditEvents {
+		response.AuditEvents[index] = toAuditEventResponseData(event)
+	}
+
+	return response
+}
+
+func toDataSubjectPolicyResponseData(policy partners.PolicyEntity) DataSubjectPolicyResponseData {
+	response := DataSubjectPolicyResponseData{
+		ID:          policy.ID,
+		PartnerID:   policy.PartnerID,
+		CustomerID:  policy.CustomerID,
+		ProviderID:  policy.ProviderID,
+		QuotationID: policy.QuotationID,
+		Name:        policy.Name,
+		Sex:         string(policy.Sex),
+		DateOfBirth: policy.DateOfBirth,
+		Cpf:         policy.Cpf,
+		Email:       policy.Email,
+		Phone:       policy.Phone,
+		Status:      string(policy.Status),
+	}
+
+	for _, beneficiary := range policy.Beneficiaries {
+		response.Beneficiaries = append(response.Beneficiaries, DataSubjectBeneficiaryResponseData{
+			Name:         beneficiary.Name,
+			Relationship: string(beneficiary.Relationship),
+			Percentage:   beneficiary.Percentage,
+		})
+	}
+
+	for _, dependent := range policy.Dependents {
+		response.Dependents = append(response.Dependents, DataSubjectDependentResponseData{
+			Name:         dependent.Name,
+			Sex:          string(dependent.Sex),
+			DateOfBirth:  dependent.DateOfBirth,
+			Relationship: string(dependent.Relationship),
+		})
+	}
+
+	return response
+}
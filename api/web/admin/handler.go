@@ -0,0 +1,229 @@
+package admin
+
+import (
+	"encoding/json"
+	"main-api/api/web/middlewares"
+	"main-api/internal/domain/partners"
+	"main-api/internal/infra/scheduler"
+	"main-api/internal/pkg/validator"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp/expvarhandler"
+)
+
+type (
+	HTTPHandler struct {
+		scheduler *scheduler.Scheduler
+		service   partners.Service
+	}
+
+	HTTPHandlerParams struct {
+		App       *fiber.App
+		Scheduler *scheduler.Scheduler
+		Service   partners.Service
+		// AdminTokens are the admins' bearer tokens by their names, which the
+		// audit log records.
+		AdminTokens map[string]string
+	}
+
+	JobStatusResponseData struct {
+		Name         string           `json:"name"`
+		Interval     string           `json:"interval"`
+		At           string           `json:"at,omitempty"`
+		LastRunAt    *time.Time       `json:"last_run_at,omitempty"`
+		LastDuration string           `json:"last_duration,omitempty"`
+		LastError    string           `json:"last_error,omitempty"`
+		Counts       map[string]int64 `json:"counts,omitempty"`
+		RanBy        string           `json:"ran_by,omitempty"`
+		Runs         int64            `json:"runs"`
+	}
+
+	ListAuditEventsQuery struct {
+		PartnerID  string `query:"partner_id" validate:"omitempty,mongodb"`
+		EntityType string `query:"entity_type" validate:"omitempty,oneof=partner quote policy customer webhook webhook_delivery"`
+		EntityID   string `query:"entity_id" validate:"omitempty,max=255"`
+		Action     string `query:"action" validate:"omitempty,max=255"`
+		ActorID    string `query:"actor_id" validate:"omitempty,max=255"`
+		From       string `query:"from" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+		To         string `query:"to" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+		After      int64  `query:"after" validate:"omitempty,min=0"`
+		Limit      int    `query:"limit" validate:"omitempty,min=1,max=500"`
+	}
+
+	AuditEventResponseData struct {
+		ID           string          `json:"id"`
+		Sequence     int64           `json:"sequence"`
+		ActorType    string          `json:"actor_type"`
+		ActorID      string          `json:"actor_id,omitempty"`
+		Action       string          `json:"action"`
+		EntityType   string          `json:"entity_type"`
+		EntityID     string          `json:"entity_id"`
+		PartnerID    string          `json:"partner_id"`
+		Changes      json.RawMessage `json:"changes"`
+		ChangesHash  string          `json:"changes_hash"`
+		RequestID    string          `json:"request_id,omitempty"`
+		SourceIP     string          `json:"source_ip,omitempty"`
+		OccurredAt   time.Time       `json:"occurred_at"`
+		PreviousHash string          `json:"previous_hash"`
+		Hash         string          `json:"hash"`
+	}
+
+	AuditVerificationResponseData struct {
+		Valid    bool   `json:"valid"`
+		Checked  int64  `json:"checked"`
+		BrokenAt int64  `json:"broken_at,omitempty"`
+		Reason   string `json:"reason,omitempty"`
+	}
+)
+
+const defaultAuditEventsLimit = 100
+
+func NewHTTPHandler(params HTTPHandlerParams) {
+	httpHandler := HTTPHandler{
+		scheduler: params.Scheduler,
+		service:   params.Service,
+	}
+
+	params.App.Route("/admin", func(r fiber.Router) {
+		r.Use(middlewares.AdminTokens(params.AdminTokens))
+		r.Use(middlewares.Audit(partners.AuditActorAdmin, middlewares.AdminName))
+		r.Get("/jobs", httpHandler.ListJobs)
+		r.Get("/debug/vars", httpHandler.DebugVars)
+		r.Put("/partners/:partner_id", httpHandler.UpdatePartner)
+		r.Put("/partners/:partner_id/limits", httpHandler.UpdatePartnerLimits)
+		r.Post("/partners/:partner_id/suspend", httpHandler.SuspendPartner)
+		r.Post("/partners/:partner_id/unsuspend", httpHandler.UnsuspendPartner)
+		r.Get("/audit-events", httpHandler.ListAuditEvents)
+		r.Get("/audit-events/verify", httpHandler.VerifyAuditLog)
+		r.Post("/data-subjects/export", httpHandler.ExportDataSubject)
+		r.Post("/data-subjects/erase", httpHandler.EraseDataSubject)
+	})
+}
+
+func (h *HTTPHandler) ListJobs(c *fiber.Ctx) error {
+	statuses, err := h.scheduler.Status(c.Context())
+	if err != nil {
+		return err
+	}
+
+	response := make([]JobStatusResponseData, len(statuses))
+	for i, status := range statuses {
+		response[i] = toJobStatusResponseData(status)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+// DebugVars serves the expvar variables, such as the hit rates of the cache.
+// They include the command line and memory stats of the process, so they are
+// kept behind the admin tokens.
+func (h *HTTPHandler) DebugVars(c *fiber.Ctx) error {
+	expvarhandler.ExpvarHandler(c.Context())
+
+	return nil
+}
+
+// ListAuditEvents pages through the audit log in the order the changes were
+// made. The sequence of the last event is the after of the next page.
+func (h *HTTPHandler) ListAuditEvents(c *fiber.Ctx) error {
+	queryData := new(ListAuditEventsQuery)
+	if err := c.QueryParser(queryData); err != nil {
+		return fiber.NewError(fiber.StatusUnprocessableEntity, err.Error())
+	}
+
+	if err := validator.BodyData(queryData); err != nil {
+		return err
+	}
+
+	filter := partners.AuditEventFilter{
+		PartnerID:     queryData.PartnerID,
+		EntityType:    queryData.EntityType,
+		EntityID:      queryData.EntityID,
+		Action:        partners.AuditActionEnum(queryData.Action),
+		ActorID:       queryData.ActorID,
+		AfterSequence: queryData.After,
+		Limit:         queryData.Limit,
+	}
+
+	if filter.Limit == 0 {
+		filter.Limit = defaultAuditEventsLimit
+	}
+
+	// The dates were validated along with the query.
+	filter.From, _ = parseOptionalTime(queryData.From)
+	filter.To, _ = parseOptionalTime(queryData.To)
+
+	events, err := h.service.ListAuditEvents(c.Context(), filter)
+	if err != nil {
+		return err
+	}
+
+	response := make([]AuditEventResponseData, len(events))
+	for i, event := range events {
+		response[i] = toAuditEventResponseData(event)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+func (h *HTTPHandler) VerifyAuditLog(c *fiber.Ctx) error {
+	result, err := h.service.VerifyAuditLog(c.Context())
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(AuditVerificationResponseData{
+		Valid:    result.Valid,
+		Checked:  result.Checked,
+		BrokenAt: result.BrokenAt,
+		Reason:   result.Reason,
+	})
+}
+
+func parseOptionalTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	return time.Parse(time.RFC3339, value)
+}
+
+func toAuditEventResponseData(event partners.AuditEventEntity) AuditEventResponseData {
+	return AuditEventResponseData{
+		ID:           event.ID,
+		Sequence:     event.Sequence,
+		ActorType:    string(event.Actor.Type),
+		ActorID:      event.Actor.ID,
+		Action:       string(event.Action),
+		EntityType:   event.EntityType,
+		EntityID:     event.EntityID,
+		PartnerID:    event.PartnerID,
+		Changes:      json.RawMessage(event.Changes),
+		ChangesHash:  event.ChangesHash,
+		RequestID:    event.RequestID,
+		SourceIP:     event.SourceIP,
+		OccurredAt:   event.OccurredAt,
+		PreviousHash: event.PreviousHash,
+		Hash:         event.Hash,
+	}
+}
+
+func toJobStatusResponseData(status scheduler.JobStatus) JobStatusResponseData {
+	data := JobStatusResponseData{
+		Name:      status.Name,
+		Interval:  status.Interval.String(),
+		At:        status.At,
+		LastError: status.LastError,
+		Counts:    status.Counts,
+		RanBy:     status.RanBy,
+		Runs:      status.Runs,
+	}
+
+	if !status.LastRunAt.IsZero() {
+		data.LastRunAt = &status.LastRunAt
+		data.LastDuration = status.LastDuration.String()
+	}
+
+	return data
+}
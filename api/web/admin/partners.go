@@ -0,0 +1,118 @@
+package admin
+
+import (
+	"main-api/internal/domain/partners"
+	"main-api/internal/pkg/i18n"
+	"main-api/internal/pkg/validator"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type (
+	UpdatePartnerRequestData struct {
+		Name     string `json:"name" validate:"required,min=3,max=255"`
+		Language string `json:"language" validate:"omitempty,oneof=pt-BR en"`
+	}
+
+	// PartnerLimitsData overrides the default limits of a partner. A limit left
+	// out falls back to the default.
+	PartnerLimitsData struct {
+		RequestsPerWindow int `json:"requests_per_window,omitempty" validate:"omitempty,min=1"`
+		DailyQuotes       int `json:"daily_quotes,omitempty" validate:"omitempty,min=1"`
+	}
+
+	PartnerResponseData struct {
+		ID        string            `json:"id"`
+		Name      string            `json:"name"`
+		Cnpj      string            `json:"cnpj"`
+		Language  string            `json:"language"`
+		Limits    PartnerLimitsData `json:"limits"`
+		Suspended bool              `json:"suspended"`
+		CreatedAt time.Time         `json:"created_at"`
+		UpdatedAt time.Time         `json:"updated_at"`
+	}
+)
+
+func (h *HTTPHandler) UpdatePartner(c *fiber.Ctx) error {
+	bodyData := new(UpdatePartnerRequestData)
+	if err := c.BodyParser(bodyData); err != nil {
+		return fiber.NewError(fiber.StatusUnprocessableEntity, err.Error())
+	}
+
+	if err := validator.BodyData(bodyData); err != nil {
+		return err
+	}
+
+	if bodyData.Language == "" {
+		bodyData.Language = string(i18n.PartnerDefaultLanguage)
+	}
+
+	partner, err := h.service.UpdatePartner(c.Context(), &partners.PartnerEntity{
+		ID:       c.Params("partner_id"),
+		Name:     bodyData.Name,
+		Language: bodyData.Language,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(toPartnerResponseData(partner))
+}
+
+func (h *HTTPHandler) UpdatePartnerLimits(c *fiber.Ctx) error {
+	bodyData := new(PartnerLimitsData)
+	if err := c.BodyParser(bodyData); err != nil {
+		return fiber.NewError(fiber.StatusUnprocessableEntity, err.Error())
+	}
+
+	if err := validator.BodyData(bodyData); err != nil {
+		return err
+	}
+
+	partner, err := h.service.UpdatePartnerLimits(c.Context(), c.Params("partner_id"), partners.PartnerLimitsEntity{
+		RequestsPerWindow: bodyData.RequestsPerWindow,
+		DailyQuotes:       bodyData.DailyQuotes,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(toPartnerResponseData(partner))
+}
+
+func (h *HTTPHandler) SuspendPartner(c *fiber.Ctx) error {
+	partner, err := h.service.SuspendPartner(c.Context(), c.Params("partner_id"))
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(toPartnerResponseData(partner))
+}
+
+// UnsuspendPartner lets a suspended partner work again. The cached partner is
+// dropped along with the change, so every replica sees it right away.
+func (h *HTTPHandler) UnsuspendPartner(c *fiber.Ctx) error {
+	partner, err := h.service.UnsuspendPartner(c.Context(), c.Params("partner_id"))
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(toPartnerResponseData(partner))
+}
+
+func toPartnerResponseData(partner *partners.PartnerEntity) PartnerResponseData {
+	return PartnerResponseData{
+		ID:       partner.ID,
+		Name:     partner.Name,
+		Cnpj:     partner.Cnpj,
+		Language: partner.Language,
+		Limits: PartnerLimitsData{
+			RequestsPerWindow: partner.Limits.RequestsPerWindow,
+			DailyQuotes:       partner.Limits.DailyQuotes,
+		},
+		Suspended: partner.Suspended,
+		CreatedAt: partner.CreatedAt,
+		UpdatedAt: partner.UpdatedAt,
+	}
+}
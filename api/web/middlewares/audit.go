@@ -0,0 +1,34 @@
+package middlewares
+
+import (
+	"main-api/internal/domain/partners"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Audit puts in the context of the request who is making it, for the audit
+// log of the changes it makes. actorID, when not nil, tells which actor of the
+// type it is. It records the ID set by the requestid middleware, so it must be
+// registered after it.
+func Audit(actorType partners.AuditActorTypeEnum, actorID func(c *fiber.Ctx) string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		actor := partners.AuditActor{Type: actorType}
+		if actorID != nil {
+			actor.ID = actorID(c)
+		}
+
+		c.Context().SetUserValue(partners.AuditRequestContextKey, partners.AuditRequest{
+			Actor:     actor,
+			RequestID: c.GetRespHeader(fiber.HeaderXRequestID),
+			SourceIP:  c.IP(),
+		})
+
+		return c.Next()
+	}
+}
+
+// PartnerActorID identifies the partner by the partner_id of the route, so it
+// only works on the routes that have it.
+func PartnerActorID(c *fiber.Ctx) string {
+	return c.Params(partnerIDParam)
+}
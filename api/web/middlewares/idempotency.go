@@ -0,0 +1,179 @@
+package middlewares
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"main-api/internal/domain/partners"
+	"main-api/internal/infra/cache"
+	"main-api/internal/pkg/i18n"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+type (
+	// idempotencyRecord is what is kept under an idempotency key: the
+	// fingerprint of the first request and, once it finishes, its response.
+	idempotencyRecord struct {
+		Fingerprint string `json:"fingerprint"`
+		Completed   bool   `json:"completed"`
+		Status      int    `json:"status,omitempty"`
+		ContentType string `json:"content_type,omitempty"`
+		Body        []byte `json:"body,omitempty"`
+	}
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotentReplayedHeader  = "Idempotent-Replayed"
+	idempotencyKeyPrefix      = "idempotency:"
+	idempotencyKeyMaxLength   = 255
+	idempotencyRetryAfterSecs = 1
+
+	// DefaultIdempotencyLockTTL is how long a request holds its key when the
+	// caller doesn't say how long its requests may take.
+	DefaultIdempotencyLockTTL = time.Minute
+)
+
+var (
+	ErrInvalidIdempotencyKey = i18n.NewError(fiber.StatusBadRequest, "invalid_idempotency_key", "idempotency key must have between 1 and 255 characters")
+	ErrIdempotencyKeyReused  = i18n.NewError(fiber.StatusConflict, "idempotency_key_reused", "idempotency key was already used with a different request")
+	ErrRequestInProgress     = i18n.NewError(fiber.StatusConflict, "idempotency_request_in_progress", "a request with this idempotency key is still in progress")
+)
+
+// Idempotency replays the response of a request retried with the same
+// Idempotency-Key header instead of running it again. Keys are scoped by method
+// and path, so the same key can be used with different partners.
+//
+// A request holds its key for lockTTL while it runs, which must outlast the
+// slowest request, provider calls included; otherwise a retry could run it
+// twice. When the request fails before calling the insurance provider the key
+// is released and the retry runs again. Once the provider was called the
+// failure is kept like any other response, since the provider may have done
+// its part, and the client needs a new key to try again.
+func Idempotency(store cache.CacheStore, ttl, lockTTL time.Duration) fiber.Handler {
+	if lockTTL <= 0 {
+		lockTTL = DefaultIdempotencyLockTTL
+	}
+
+	return func(c *fiber.Ctx) error {
+		idempotencyKey := utils.CopyString(c.Get(IdempotencyKeyHeader))
+		if idempotencyKey == "" {
+			return c.Next()
+		}
+
+		if len(idempotencyKey) > idempotencyKeyMaxLength {
+			return ErrInvalidIdempotencyKey
+		}
+
+		key := idempotencyKeyPrefix + c.Method() + ":" + c.Path() + ":" + idempotencyKey
+		fingerprint := requestFingerprint(c.Body())
+
+		inFlight, err := json.Marshal(idempotencyRecord{Fingerprint: fingerprint})
+		if err != nil {
+			return err
+		}
+
+		acquired, err := store.SetNX(c.Context(), key, inFlight, lockTTL)
+		if err != nil {
+			return err
+		}
+
+		if !acquired {
+			return replay(c, store, key, fingerprint)
+		}
+
+		providerCalls := &partners.ProviderCalls{}
+		c.Context().SetUserValue(partners.ProviderCallsContextKey, providerCalls)
+
+		if err := c.Next(); err != nil {
+			if !providerCalls.Called() {
+				release(c, store, key)
+
+				return err
+			}
+
+			// The error is rendered here, so its response can be kept.
+			if err := c.App().Config().ErrorHandler(c, err); err != nil {
+				release(c, store, key)
+
+				return err
+			}
+		}
+
+		if c.Response().StatusCode() >= fiber.StatusInternalServerError && !providerCalls.Called() {
+			release(c, store, key)
+
+			return nil
+		}
+
+		completed, err := json.Marshal(idempotencyRecord{
+			Fingerprint: fingerprint,
+			Completed:   true,
+			Status:      c.Response().StatusCode(),
+			ContentType: string(c.Response().Header.ContentType()),
+			Body:        utils.CopyBytes(c.Response().Body()),
+		})
+		if err != nil {
+			return err
+		}
+
+		if err := store.Set(c.Context(), key, completed, ttl); err != nil {
+			log.Errorf("[IDEMPOTENCY] failed to store the response of key %q: %v", key, err)
+		}
+
+		return nil
+	}
+}
+
+func replay(c *fiber.Ctx, store cache.CacheStore, key, fingerprint string) error {
+	value, err := store.Get(c.Context(), key)
+	if errors.Is(err, cache.ErrCacheMiss) {
+		// The first request released the key between both calls; the client
+		// can retry right away.
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(idempotencyRetryAfterSecs))
+
+		return ErrRequestInProgress
+	}
+
+	if err != nil {
+		return err
+	}
+
+	var record idempotencyRecord
+	if err := json.Unmarshal([]byte(value), &record); err != nil {
+		return err
+	}
+
+	if record.Fingerprint != fingerprint {
+		return ErrIdempotencyKeyReused
+	}
+
+	if !record.Completed {
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(idempotencyRetryAfterSecs))
+
+		return ErrRequestInProgress
+	}
+
+	c.Set(IdempotentReplayedHeader, "true")
+	c.Set(fiber.HeaderContentType, record.ContentType)
+
+	return c.Status(record.Status).Send(record.Body)
+}
+
+func release(c *fiber.Ctx, store cache.CacheStore, key string) {
+	if err := store.Delete(c.Context(), key); err != nil {
+		log.Errorf("[IDEMPOTENCY] failed to release key %q: %v", key, err)
+	}
+}
+
+func requestFingerprint(body []byte) string {
+	sum := sha256.Sum256(body)
+
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,41 @@
+package middlewares
+
+import (
+	"crypto/hmac"
+	"main-api/internal/infra/http/webhook"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	ProviderSignatureHeader = "X-Provider-Signature"
+	ProviderTimestampHeader = "X-Provider-Timestamp"
+)
+
+// ProviderSignature only lets through the requests signed by the insurance
+// provider with the shared secret, in the same scheme as our webhooks. Requests
+// whose timestamp is further than tolerance from now are rejected, so captured
+// requests can't be replayed later. Every request is rejected when no secret
+// is configured.
+func ProviderSignature(secret string, tolerance time.Duration) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		timestamp, err := strconv.ParseInt(c.Get(ProviderTimestampHeader), 10, 64)
+		if secret == "" || err != nil {
+			return fiber.ErrUnauthorized
+		}
+
+		age := time.Since(time.Unix(timestamp, 0))
+		if age > tolerance || age < -tolerance {
+			return fiber.ErrUnauthorized
+		}
+
+		expected := webhook.Sign(secret, timestamp, c.Body())
+		if !hmac.Equal([]byte(c.Get(ProviderSignatureHeader)), []byte(expected)) {
+			return fiber.ErrUnauthorized
+		}
+
+		return c.Next()
+	}
+}
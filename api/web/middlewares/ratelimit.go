@@ -0,0 +1,120 @@
+package middlewares
+
+import (
+	"main-api/internal/infra/ratelimit"
+	"main-api/internal/pkg/i18n"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+)
+
+type (
+	// Limits are the request limits of a partner. Non-positive values disable
+	// the limit.
+	Limits struct {
+		RequestsPerWindow int
+		DailyQuotes       int
+	}
+
+	// LimitsFunc resolves the limits of the partner in the route.
+	LimitsFunc func(c *fiber.Ctx) Limits
+)
+
+const (
+	RateLimitLimitHeader     = "X-RateLimit-Limit"
+	RateLimitRemainingHeader = "X-RateLimit-Remaining"
+	RateLimitResetHeader     = "X-RateLimit-Reset"
+
+	partnerIDParam = "partner_id"
+)
+
+var (
+	ErrRateLimitExceeded  = i18n.NewError(fiber.StatusTooManyRequests, "rate_limit_exceeded", "rate limit exceeded, try again later")
+	ErrDailyQuotaExceeded = i18n.NewError(fiber.StatusTooManyRequests, "daily_quota_exceeded", "daily quota exceeded, try again tomorrow")
+)
+
+// RateLimit limits the requests of each partner to each route within a sliding
+// window. It must be registered on the routes themselves, after the partner_id
+// param is matched. When Redis is unavailable requests are let through, so an
+// outage of the limiter doesn't take the API down with it.
+func RateLimit(limiter *ratelimit.SlidingWindow, limits LimitsFunc) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		partnerID := c.Params(partnerIDParam)
+
+		partnerLimits := limits(c)
+		if partnerLimits.RequestsPerWindow <= 0 {
+			return c.Next()
+		}
+
+		key := "ratelimit:" + partnerID + ":" + c.Method() + ":" + c.Route().Path
+
+		result, err := limiter.Allow(c.Context(), key, partnerLimits.RequestsPerWindow)
+		if err != nil {
+			log.Errorf("[RATE LIMIT] failed to check key %q: %v", key, err)
+
+			return c.Next()
+		}
+
+		setRateLimitHeaders(c, result)
+
+		if !result.Allowed {
+			return ErrRateLimitExceeded
+		}
+
+		return c.Next()
+	}
+}
+
+// DailyQuota limits how many requests each partner makes to a route per day,
+// such as quotations, which spend the provider quota. Requests that fail are
+// given back to the partner.
+func DailyQuota(quota *ratelimit.DailyQuota, resource string, limits LimitsFunc) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		partnerID := c.Params(partnerIDParam)
+
+		partnerLimits := limits(c)
+		if partnerLimits.DailyQuotes <= 0 {
+			return c.Next()
+		}
+
+		key := "quota:" + resource + ":" + partnerID
+		now := time.Now()
+
+		result, err := quota.Consume(c.Context(), key, partnerLimits.DailyQuotes, now)
+		if err != nil {
+			log.Errorf("[RATE LIMIT] failed to consume quota %q: %v", key, err)
+
+			return c.Next()
+		}
+
+		if !result.Allowed {
+			setRateLimitHeaders(c, result)
+
+			return ErrDailyQuotaExceeded
+		}
+
+		err = c.Next()
+		if err != nil || c.Response().StatusCode() >= fiber.StatusBadRequest {
+			if refundErr := quota.Refund(c.Context(), key, now); refundErr != nil {
+				log.Errorf("[RATE LIMIT] failed to refund quota %q: %v", key, refundErr)
+			}
+		}
+
+		return err
+	}
+}
+
+func setRateLimitHeaders(c *fiber.Ctx, result ratelimit.Result) {
+	resetSeconds := strconv.Itoa(int(math.Ceil(result.ResetAfter.Seconds())))
+
+	c.Set(RateLimitLimitHeader, strconv.Itoa(result.Limit))
+	c.Set(RateLimitRemainingHeader, strconv.Itoa(result.Remaining))
+	c.Set(RateLimitResetHeader, resetSeconds)
+
+	if !result.Allowed {
+		c.Set(fiber.HeaderRetryAfter, resetSeconds)
+	}
+}
@@ -0,0 +1,147 @@
+package partners
+
+import (
+	"main-api/internal/domain/partners"
+	"main-api/internal/pkg/validator"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type (
+	CustomerRequestData struct {
+		Name        string `json:"name" validate:"required,min=3,max=255"`
+		Sex         string `json:"sex" validate:"required,oneof=m M f F n N"`
+		DateOfBirth string `json:"date_of_birth" validate:"required,datetime=2006-01-02"`
+		Cpf         string `json:"cpf" validate:"required,cpf"`
+		Email       string `json:"email" validate:"omitempty,email"`
+		Phone       string `json:"phone" validate:"omitempty,e164"`
+	}
+
+	CustomerResponseData struct {
+		ID          string    `json:"id"`
+		Name        string    `json:"name"`
+		Sex         string    `json:"sex"`
+		DateOfBirth string    `json:"date_of_birth"`
+		Cpf         string    `json:"cpf"`
+		Email       string    `json:"email,omitempty"`
+		Phone       string    `json:"phone,omitempty"`
+		CreatedAt   time.Time `json:"created_at"`
+		UpdatedAt   time.Time `json:"updated_at"`
+	}
+)
+
+func (h HTTPHandler) CreateCustomer(c *fiber.Ctx) error {
+	bodyData := new(CustomerRequestData)
+	if err := c.BodyParser(bodyData); err != nil {
+		return fiber.NewError(fiber.StatusUnprocessableEntity, err.Error())
+	}
+
+	if err := validator.BodyData(bodyData); err != nil {
+		return err
+	}
+
+	customer, err := h.service.CreateCustomer(c.Context(), partners.NewCustomerEntity(
+		c.Params("partner_id"),
+		bodyData.Name,
+		bodyData.Sex,
+		bodyData.DateOfBirth,
+		validator.NormalizeCPF(bodyData.Cpf),
+		bodyData.Email,
+		bodyData.Phone,
+	))
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(toCustomerResponseData(customer))
+}
+
+func (h HTTPHandler) ListCustomers(c *fiber.Ctx) error {
+	customers, err := h.service.ListCustomers(c.Context(), c.Params("partner_id"))
+	if err != nil {
+		return err
+	}
+
+	response := make([]CustomerResponseData, len(customers))
+	for index := range customers {
+		response[index] = toCustomerResponseData(&customers[index])
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+func (h HTTPHandler) GetCustomer(c *fiber.Ctx) error {
+	customer, err := h.service.GetCustomer(c.Context(), c.Params("partner_id"), c.Params("customer_id"))
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(toCustomerResponseData(customer))
+}
+
+func (h HTTPHandler) UpdateCustomer(c *fiber.Ctx) error {
+	bodyData := new(CustomerRequestData)
+	if err := c.BodyParser(bodyData); err != nil {
+		return fiber.NewError(fiber.StatusUnprocessableEntity, err.Error())
+	}
+
+	if err := validator.BodyData(bodyData); err != nil {
+		return err
+	}
+
+	customer := partners.NewCustomerEntity(
+		c.Params("partner_id"),
+		bodyData.Name,
+		bodyData.Sex,
+		bodyData.DateOfBirth,
+		validator.NormalizeCPF(bodyData.Cpf),
+		bodyData.Email,
+		bodyData.Phone,
+	)
+	customer.ID = c.Params("customer_id")
+
+	result, err := h.service.UpdateCustomer(c.Context(), customer)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(toCustomerResponseData(result))
+}
+
+func (h HTTPHandler) DeleteCustomer(c *fiber.Ctx) error {
+	err := h.service.DeleteCustomer(c.Context(), c.Params("partner_id"), c.Params("customer_id"))
+	if err != nil {
+		return err
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h HTTPHandler) ListCustomerPolicies(c *fiber.Ctx) error {
+	policies, err := h.service.ListCustomerPolicies(c.Context(), c.Params("partner_id"), c.Params("customer_id"))
+	if err != nil {
+		return err
+	}
+
+	response := make([]CreatePolicyResponseData, len(policies))
+	for index := range policies {
+		response[index] = toPolicyResponseData(&policies[index])
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+func toCustomerResponseData(customer *partners.CustomerEntity) CustomerResponseData {
+	return CustomerResponseData{
+		ID:          customer.ID,
+		Name:        customer.Name,
+		Sex:         string(customer.Sex),
+		DateOfBirth: customer.DateOfBirth,
+		Cpf:         customer.Cpf,
+		Email:       customer.Email,
+		Phone:       customer.Phone,
+		CreatedAt:   customer.CreatedAt,
+		UpdatedAt:   customer.UpdatedAt,
+	}
+}
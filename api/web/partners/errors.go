@@ -1,28 +1,77 @@
 package partners
 
 import (
+	"errors"
 	"main-api/internal/domain/partners"
+	"main-api/internal/infra/http/insurance"
+	"main-api/internal/infra/ratelimit"
+	"main-api/internal/pkg/i18n"
+	"main-api/internal/pkg/validator"
+	"strconv"
 
 	"github.com/gofiber/fiber/v2"
 )
 
 var (
-	ErrosMapped = map[error]*fiber.Error{
-		partners.ErrPartnerAlreadyExists: fiber.NewError(
-			fiber.StatusConflict,
-			partners.ErrPartnerAlreadyExists.Error(),
-		),
-		partners.ErrPartnerNotFound: fiber.NewError(
-			fiber.StatusNotFound,
-			partners.ErrPartnerNotFound.Error(),
-		),
+	// ErrosMapped translates the domain errors to their HTTP status and the
+	// i18n catalog code of their message.
+	ErrosMapped = map[error]*i18n.Error{
+		partners.ErrPartnerAlreadyExists:         i18n.NewError(fiber.StatusConflict, "partner_already_exists", partners.ErrPartnerAlreadyExists.Error()),
+		partners.ErrPartnerNotFound:              i18n.NewError(fiber.StatusNotFound, "partner_not_found", partners.ErrPartnerNotFound.Error()),
+		partners.ErrPartnerSuspended:             i18n.NewError(fiber.StatusForbidden, "partner_suspended", partners.ErrPartnerSuspended.Error()),
+		partners.ErrPolicyNotFound:               i18n.NewError(fiber.StatusNotFound, "policy_not_found", partners.ErrPolicyNotFound.Error()),
+		partners.ErrQuoteNotFound:                i18n.NewError(fiber.StatusNotFound, "quote_not_found", partners.ErrQuoteNotFound.Error()),
+		partners.ErrCustomerAlreadyExists:        i18n.NewError(fiber.StatusConflict, "customer_already_exists", partners.ErrCustomerAlreadyExists.Error()),
+		partners.ErrCustomerNotFound:             i18n.NewError(fiber.StatusNotFound, "customer_not_found", partners.ErrCustomerNotFound.Error()),
+		partners.ErrDataSubjectNotFound:          i18n.NewError(fiber.StatusNotFound, "data_subject_not_found", partners.ErrDataSubjectNotFound.Error()),
+		partners.ErrWebhookNotFound:              i18n.NewError(fiber.StatusNotFound, "webhook_not_found", partners.ErrWebhookNotFound.Error()),
+		partners.ErrWebhookDeliveryNotFound:      i18n.NewError(fiber.StatusNotFound, "webhook_delivery_not_found", partners.ErrWebhookDeliveryNotFound.Error()),
+		partners.ErrInvalidWebhookEventType:      i18n.NewError(fiber.StatusBadRequest, "invalid_webhook_event_type", partners.ErrInvalidWebhookEventType.Error()),
+		partners.ErrInvalidWebhookURL:            i18n.NewError(fiber.StatusBadRequest, "invalid_webhook_url", partners.ErrInvalidWebhookURL.Error()),
+		partners.ErrBeneficiariesPercentageSum:   i18n.NewError(fiber.StatusBadRequest, "beneficiaries_percentage_sum", partners.ErrBeneficiariesPercentageSum.Error()),
+		partners.ErrInvalidBeneficiaryPercentage: i18n.NewError(fiber.StatusBadRequest, "invalid_beneficiary_percentage", partners.ErrInvalidBeneficiaryPercentage.Error()),
+		partners.ErrInvalidRelationship:          i18n.NewError(fiber.StatusBadRequest, "invalid_relationship", partners.ErrInvalidRelationship.Error()),
+		partners.ErrInvalidDependentRelationship: i18n.NewError(fiber.StatusBadRequest, "invalid_dependent_relationship", partners.ErrInvalidDependentRelationship.Error()),
 	}
+
+	errProviderUnavailable = i18n.NewError(fiber.StatusBadGateway, "provider_unavailable", "the insurance provider is unavailable, try again later")
+	errProviderThrottled   = i18n.NewError(fiber.StatusServiceUnavailable, "provider_rate_limited", "the insurance provider is busy, try again later")
 )
 
-func HandlerCorrectlyErrorsStatus(status error) *fiber.Error {
-	if err, ok := ErrosMapped[status]; ok {
-		return err
+// ErrorHandler renders every error returned by the handlers as a problem
+// response, after translating the domain errors.
+func ErrorHandler(c *fiber.Ctx, err error) error {
+	var throttledErr *ratelimit.ThrottledError
+	if errors.As(err, &throttledErr) {
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(throttledErr.RetryAfterSeconds()))
+	}
+
+	return validator.ErrorHandler(c, HandlerCorrectlyErrorsStatus(err))
+}
+
+// HandlerCorrectlyErrorsStatus translates domain errors to errors the problem
+// handler knows how to render. Other errors are returned unchanged, so fiber
+// errors keep their status and anything unexpected becomes a 500.
+func HandlerCorrectlyErrorsStatus(err error) error {
+	for domainErr, httpErr := range ErrosMapped {
+		if errors.Is(err, domainErr) {
+			return httpErr
+		}
+	}
+
+	var throttledErr *ratelimit.ThrottledError
+	if errors.As(err, &throttledErr) {
+		return errProviderThrottled
+	}
+
+	if errors.Is(err, insurance.ErrProviderUnavailable) {
+		return errProviderUnavailable
+	}
+
+	var fieldErr *partners.FieldError
+	if errors.As(err, &fieldErr) {
+		return validator.NewFieldError(fieldErr.Field, fieldErr.Code, fieldErr.Message)
 	}
 
-	return fiber.NewError(fiber.StatusInternalServerError, "internal server error")
+	return err
 }
@@ -1,11 +1,18 @@
 package partners
 
 import (
+	"errors"
+	"main-api/api/web/middlewares"
 	"main-api/internal/domain/partners"
+	"main-api/internal/infra/cache"
+	"main-api/internal/infra/ratelimit"
+	"main-api/internal/pkg/i18n"
 	"main-api/internal/pkg/validator"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
 	"github.com/google/uuid"
 )
 
@@ -14,16 +21,42 @@ type (
 		service partners.Service
 	}
 
+	HTTPHandlerParams struct {
+		App            *fiber.App
+		Service        partners.Service
+		CacheStore     cache.CacheStore
+		IdempotencyTTL time.Duration
+		// IdempotencyLockTTL must outlast the slowest request, provider
+		// calls included.
+		IdempotencyLockTTL time.Duration
+		RateLimiter        *ratelimit.SlidingWindow
+		QuoteQuota         *ratelimit.DailyQuota
+		// DefaultLimits apply to the partners that don't override them.
+		DefaultLimits middlewares.Limits
+	}
+
+	PartnerLimitsData struct {
+		RequestsPerWindow int `json:"requests_per_window,omitempty"`
+		DailyQuotes       int `json:"daily_quotes,omitempty"`
+	}
+
+	// CreatePartnerRequestData has no limits: only admins set them, with
+	// PUT /admin/partners/:partner_id/limits.
 	CreatePartnerRequestData struct {
-		Name string `json:"name" validate:"required,min=3,max=255"`
-		Cnpj string `json:"cnpj" validate:"required,min=14,max=14"`
+		Name     string `json:"name" validate:"required,min=3,max=255"`
+		Cnpj     string `json:"cnpj" validate:"required,min=14,max=14"`
+		Language string `json:"language" validate:"omitempty,oneof=pt-BR en"`
 	}
 
 	CreatePartnerResponseData struct {
-		ID        string    `json:"id"`
-		Name      string    `json:"name"`
-		Cnpj      string    `json:"cnpj"`
-		CreatedAt time.Time `json:"created_at"`
+		ID        string            `json:"id"`
+		Name      string            `json:"name"`
+		Cnpj      string            `json:"cnpj"`
+		Language  string            `json:"language"`
+		Limits    PartnerLimitsData `json:"limits"`
+		Suspended bool              `json:"suspended"`
+		CreatedAt time.Time         `json:"created_at"`
+		UpdatedAt time.Time         `json:"updated_at"`
 	}
 
 	CreateQuoteData struct {
@@ -40,63 +73,118 @@ type (
 		CreatedAt time.Time `json:"created_at"`
 	}
 
+	BeneficiaryData struct {
+		Name         string  `json:"name" validate:"required,min=3,max=255"`
+		Relationship string  `json:"relationship,omitempty" validate:"omitempty,oneof=spouse child parent sibling other"`
+		Percentage   float64 `json:"percentage" validate:"required,gt=0,lte=100"`
+	}
+
+	DependentData struct {
+		Name         string `json:"name" validate:"required,min=3,max=255"`
+		Sex          string `json:"sex" validate:"required,oneof=m M f F n N"`
+		DateOfBirth  string `json:"date_of_birth" validate:"required,datetime=2006-01-02"`
+		Relationship string `json:"relationship" validate:"required,oneof=spouse child parent"`
+	}
+
 	CreatePolicyData struct {
-		QuotationID uuid.UUID `json:"quotation_id" validate:"required"`
-		Name        string    `json:"name" validate:"required,min=3,max=255"`
-		Sex         string    `json:"sex" validate:"required,oneof=m M f F n N"`
-		DateOfBirth string    `json:"date_of_birth" validate:"required"`
+		QuotationID   uuid.UUID         `json:"quotation_id" validate:"required"`
+		CustomerID    string            `json:"customer_id" validate:"omitempty,mongodb"`
+		Name          string            `json:"name" validate:"required_without=CustomerID,excluded_with=CustomerID,omitempty,min=3,max=255"`
+		Sex           string            `json:"sex" validate:"required_without=CustomerID,excluded_with=CustomerID,omitempty,oneof=m M f F n N"`
+		DateOfBirth   string            `json:"date_of_birth" validate:"required_without=CustomerID,excluded_with=CustomerID,omitempty,datetime=2006-01-02"`
+		Cpf           string            `json:"cpf" validate:"required_without=CustomerID,excluded_with=CustomerID,omitempty,cpf"`
+		Email         string            `json:"email" validate:"excluded_with=CustomerID,omitempty,email"`
+		Phone         string            `json:"phone" validate:"excluded_with=CustomerID,omitempty,e164"`
+		Beneficiaries []BeneficiaryData `json:"beneficiaries" validate:"omitempty,dive"`
+		Dependents    []DependentData   `json:"dependents" validate:"omitempty,dive"`
 	}
 
 	CreatePolicyResponseData struct {
-		ID          string    `json:"id"`
-		Sex         string    `json:"sex"`
-		Name        string    `json:"name"`
-		QuotationID uuid.UUID `json:"quotation_id"`
-		DateOfBirth string    `json:"date_of_birth"`
+		ID            string            `json:"id"`
+		Sex           string            `json:"sex"`
+		Name          string            `json:"name"`
+		QuotationID   uuid.UUID         `json:"quotation_id"`
+		DateOfBirth   string            `json:"date_of_birth"`
+		CustomerID    string            `json:"customer_id,omitempty"`
+		Cpf           string            `json:"cpf"`
+		Email         string            `json:"email,omitempty"`
+		Phone         string            `json:"phone,omitempty"`
+		Beneficiaries []BeneficiaryData `json:"beneficiaries,omitempty"`
+		Dependents    []DependentData   `json:"dependents,omitempty"`
+		Status        string            `json:"status,omitempty"`
+	}
+
+	ListPoliciesQuery struct {
+		Cpf string `query:"cpf" validate:"required,cpf"`
 	}
 )
 
-func NewHTTPHandler(app *fiber.App, service partners.Service) {
+// StaleDataHeader flags responses built from cached provider data because the
+// provider couldn't be reached.
+const StaleDataHeader = "X-Data-Stale"
+
+func NewHTTPHandler(params HTTPHandlerParams) {
 	httpHandler := HTTPHandler{
-		service: service,
+		service: params.Service,
 	}
 
-	app.Route("/partners", func(r fiber.Router) {
-		r.Post("/", httpHandler.CreatePartner)
-		r.Post("/:partner_id/quotes", httpHandler.CreateQuote)
-		r.Post("/:partner_id/policies", httpHandler.CreatePolicy)
-		r.Get("/:partner_id/policies/:policy_id", httpHandler.GetPolicy)
+	idempotency := middlewares.Idempotency(params.CacheStore, params.IdempotencyTTL, params.IdempotencyLockTTL)
+	limits := httpHandler.partnerLimits(params.DefaultLimits)
+	rateLimit := middlewares.RateLimit(params.RateLimiter, limits)
+	// quoteQuota goes after idempotency, so replayed quotes don't count.
+	quoteQuota := middlewares.DailyQuota(params.QuoteQuota, "quotes", limits)
+
+	params.App.Route("/partners", func(r fiber.Router) {
+		r.Use("/:partner_id", middlewares.Audit(partners.AuditActorPartner, middlewares.PartnerActorID), httpHandler.loadPartner)
+		// Partners are created outside of /:partner_id, and are the actor of
+		// their own creation.
+		r.Post("/", middlewares.Audit(partners.AuditActorPartner, nil), idempotency, httpHandler.CreatePartner)
+		r.Post("/:partner_id/quotes", rateLimit, idempotency, quoteQuota, httpHandler.CreateQuote)
+		r.Post("/:partner_id/policies", rateLimit, idempotency, httpHandler.CreatePolicy)
+		r.Get("/:partner_id/policies", rateLimit, httpHandler.ListPolicies)
+		r.Get("/:partner_id/policies/:policy_id", rateLimit, httpHandler.GetPolicy)
+		r.Post("/:partner_id/customers", rateLimit, idempotency, httpHandler.CreateCustomer)
+		r.Get("/:partner_id/customers", rateLimit, httpHandler.ListCustomers)
+		r.Get("/:partner_id/customers/:customer_id", rateLimit, httpHandler.GetCustomer)
+		r.Put("/:partner_id/customers/:customer_id", rateLimit, httpHandler.UpdateCustomer)
+		r.Delete("/:partner_id/customers/:customer_id", rateLimit, httpHandler.DeleteCustomer)
+		r.Get("/:partner_id/customers/:customer_id/policies", rateLimit, httpHandler.ListCustomerPolicies)
+		r.Post("/:partner_id/webhooks", rateLimit, httpHandler.RegisterWebhook)
+		r.Get("/:partner_id/webhooks", rateLimit, httpHandler.ListWebhooks)
+		r.Post("/:partner_id/webhooks/secret/rotate", rateLimit, httpHandler.RotateWebhookSecret)
+		r.Get("/:partner_id/webhooks/deliveries", rateLimit, httpHandler.ListWebhookDeliveries)
+		r.Post("/:partner_id/webhooks/deliveries/:delivery_id/redeliver", rateLimit, httpHandler.RedeliverWebhook)
+		r.Delete("/:partner_id/webhooks/:webhook_id", rateLimit, httpHandler.DeleteWebhook)
 	})
 }
 
 func (h *HTTPHandler) CreatePartner(c *fiber.Ctx) error {
 	bodyData := new(CreatePartnerRequestData)
 	if err := c.BodyParser(bodyData); err != nil {
-		return c.Status(fiber.StatusUnprocessableEntity).JSON(err)
+		return fiber.NewError(fiber.StatusUnprocessableEntity, err.Error())
 	}
 
 	if err := validator.BodyData(bodyData); err != nil {
 		return err
 	}
 
-	partnerEntity := partners.NewEntity(bodyData.Name, bodyData.Cnpj)
+	if bodyData.Language == "" {
+		bodyData.Language = string(i18n.PartnerDefaultLanguage)
+	}
+
+	partnerEntity := partners.NewEntity(bodyData.Name, bodyData.Cnpj, bodyData.Language)
 	partner, err := h.service.CreatePartner(c.Context(), partnerEntity)
 	if err != nil {
 		return err
 	}
 
-	return c.Status(fiber.StatusCreated).JSON(CreatePartnerResponseData{
-		ID:        partner.ID,
-		Name:      partner.Name,
-		Cnpj:      partner.Cnpj,
-		CreatedAt: partner.CreatedAt,
-	})
+	return c.Status(fiber.StatusCreated).JSON(toPartnerResponseData(partner))
 }
 
 func (h *HTTPHandler) CreateQuote(c *fiber.Ctx) error {
 	bodyData := new(CreateQuoteData)
 	if err := c.BodyParser(bodyData); err != nil {
-		return c.Status(fiber.StatusUnprocessableEntity).JSON(err)
+		return fiber.NewError(fiber.StatusUnprocessableEntity, err.Error())
 	}
 
 	if err := validator.BodyData(bodyData); err != nil {
@@ -125,7 +213,7 @@ func (h *HTTPHandler) CreateQuote(c *fiber.Ctx) error {
 func (h HTTPHandler) CreatePolicy(c *fiber.Ctx) error {
 	bodyData := new(CreatePolicyData)
 	if err := c.BodyParser(bodyData); err != nil {
-		return c.Status(fiber.StatusUnprocessableEntity).JSON(err)
+		return fiber.NewError(fiber.StatusUnprocessableEntity, err.Error())
 	}
 
 	if err := validator.BodyData(bodyData); err != nil {
@@ -133,23 +221,28 @@ func (h HTTPHandler) CreatePolicy(c *fiber.Ctx) error {
 	}
 
 	result, err := h.service.CreatePolicy(c.Context(), &partners.PolicyEntity{
-		QuotationID: bodyData.QuotationID,
-		Sex:         partners.SexEnum(bodyData.Sex),
-		Name:        bodyData.Name,
-		DateOfBirth: bodyData.DateOfBirth,
-		PartnerID:   c.Params("partner_id"),
+		QuotationID:   bodyData.QuotationID,
+		Sex:           partners.SexEnum(bodyData.Sex),
+		Name:          bodyData.Name,
+		DateOfBirth:   bodyData.DateOfBirth,
+		Cpf:           validator.NormalizeCPF(bodyData.Cpf),
+		Email:         bodyData.Email,
+		Phone:         bodyData.Phone,
+		PartnerID:     c.Params("partner_id"),
+		CustomerID:    bodyData.CustomerID,
+		Beneficiaries: toBeneficiariesEntity(bodyData.Beneficiaries),
+		Dependents:    toDependentsEntity(bodyData.Dependents),
 	})
+	if errors.Is(err, partners.ErrPolicyIssuanceUnresolved) {
+		// The partner only gets the cause; the recovery job resolves the rest.
+		log.Errorf("[POLICY ISSUANCE] %v", err)
+	}
+
 	if err != nil {
 		return err
 	}
 
-	return c.Status(fiber.StatusOK).JSON(CreatePolicyResponseData{
-		ID:          result.ID,
-		Sex:         string(result.Sex),
-		Name:        result.Name,
-		QuotationID: result.QuotationID,
-		DateOfBirth: result.DateOfBirth,
-	})
+	return c.Status(fiber.StatusOK).JSON(toPolicyResponseData(result))
 }
 
 func (h HTTPHandler) GetPolicy(c *fiber.Ctx) error {
@@ -158,11 +251,123 @@ func (h HTTPHandler) GetPolicy(c *fiber.Ctx) error {
 		return err
 	}
 
-	return c.Status(fiber.StatusOK).JSON(CreatePolicyResponseData{
-		ID:          policy.ID,
-		Sex:         string(policy.Sex),
-		Name:        policy.Name,
-		QuotationID: policy.QuotationID,
-		DateOfBirth: policy.DateOfBirth,
-	})
+	if policy.Stale {
+		c.Set(StaleDataHeader, "true")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(toPolicyResponseData(policy))
+}
+
+func (h HTTPHandler) ListPolicies(c *fiber.Ctx) error {
+	queryData := new(ListPoliciesQuery)
+	if err := c.QueryParser(queryData); err != nil {
+		return fiber.NewError(fiber.StatusUnprocessableEntity, err.Error())
+	}
+
+	if err := validator.BodyData(queryData); err != nil {
+		return err
+	}
+
+	policies, err := h.service.ListPoliciesByCpf(
+		c.Context(),
+		c.Params("partner_id"),
+		validator.NormalizeCPF(queryData.Cpf),
+	)
+	if err != nil {
+		return err
+	}
+
+	response := make([]CreatePolicyResponseData, len(policies))
+	for index := range policies {
+		response[index] = toPolicyResponseData(&policies[index])
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+func toPartnerResponseData(partner *partners.PartnerEntity) CreatePartnerResponseData {
+	return CreatePartnerResponseData{
+		ID:       partner.ID,
+		Name:     partner.Name,
+		Cnpj:     partner.Cnpj,
+		Language: partner.Language,
+		Limits: PartnerLimitsData{
+			RequestsPerWindow: partner.Limits.RequestsPerWindow,
+			DailyQuotes:       partner.Limits.DailyQuotes,
+		},
+		Suspended: partner.Suspended,
+		CreatedAt: partner.CreatedAt,
+		UpdatedAt: partner.UpdatedAt,
+	}
+}
+
+func toPolicyResponseData(policy *partners.PolicyEntity) CreatePolicyResponseData {
+	return CreatePolicyResponseData{
+		ID:            policy.ID,
+		Sex:           string(policy.Sex),
+		Name:          policy.Name,
+		QuotationID:   policy.QuotationID,
+		DateOfBirth:   policy.DateOfBirth,
+		CustomerID:    policy.CustomerID,
+		Cpf:           policy.Cpf,
+		Email:         policy.Email,
+		Phone:         policy.Phone,
+		Beneficiaries: toBeneficiariesData(policy.Beneficiaries),
+		Dependents:    toDependentsData(policy.Dependents),
+		Status:        string(policy.Status),
+	}
+}
+
+func toBeneficiariesEntity(beneficiaries []BeneficiaryData) []partners.BeneficiaryEntity {
+	result := make([]partners.BeneficiaryEntity, len(beneficiaries))
+	for index, beneficiary := range beneficiaries {
+		result[index] = partners.BeneficiaryEntity{
+			Name:         beneficiary.Name,
+			Relationship: partners.RelationshipEnum(beneficiary.Relationship),
+			Percentage:   beneficiary.Percentage,
+		}
+	}
+
+	return result
+}
+
+func toDependentsEntity(dependents []DependentData) []partners.DependentEntity {
+	result := make([]partners.DependentEntity, len(dependents))
+	for index, dependent := range dependents {
+		result[index] = partners.DependentEntity{
+			Name:         dependent.Name,
+			Sex:          partners.SexEnum(strings.ToUpper(dependent.Sex)),
+			DateOfBirth:  dependent.DateOfBirth,
+			Relationship: partners.RelationshipEnum(dependent.Relationship),
+		}
+	}
+
+	return result
+}
+
+func toBeneficiariesData(beneficiaries []partners.BeneficiaryEntity) []BeneficiaryData {
+	result := make([]BeneficiaryData, len(beneficiaries))
+	for index, beneficiary := range beneficiaries {
+		result[index] = BeneficiaryData{
+			Name:         beneficiary.Name,
+			Relationship: string(beneficiary.Relationship),
+			Percentage:   beneficiary.Percentage,
+		}
+	}
+
+	return result
+}
+
+func toDependentsData(dependents []partners.DependentEntity) []DependentData {
+	result := make([]DependentData, len(dependents))
+	for index, dependent := range dependents {
+		result[index] = DependentData{
+			Name:         dependent.Name,
+			Sex:          string(dependent.Sex),
+			DateOfBirth:  dependent.DateOfBirth,
+			Relationship: string(dependent.Relationship),
+		}
+	}
+
+	return result
 }
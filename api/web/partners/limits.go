@@ -0,0 +1,54 @@
+package partners
+
+import (
+	"errors"
+	"main-api/api/web/middlewares"
+	"main-api/internal/domain/partners"
+	"main-api/internal/pkg/i18n"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const partnerLocal = "partner"
+
+// loadPartner loads the partner in the route once per request, for its limits
+// and the language of the errors. Unknown partners are let through; the
+// handler answers them with a not found.
+func (h *HTTPHandler) loadPartner(c *fiber.Ctx) error {
+	partner, err := h.service.GetPartner(c.Context(), c.Params("partner_id"))
+	if errors.Is(err, partners.ErrPartnerNotFound) {
+		return c.Next()
+	}
+
+	if err != nil {
+		return err
+	}
+
+	c.Locals(partnerLocal, partner)
+	i18n.SetPartnerLanguage(c, i18n.Language(partner.Language))
+
+	return c.Next()
+}
+
+// partnerLimits resolves the limits of the partner loaded by the request,
+// falling back to the defaults for the limits it doesn't override. Unknown
+// partners get the defaults too.
+func (h *HTTPHandler) partnerLimits(defaults middlewares.Limits) middlewares.LimitsFunc {
+	return func(c *fiber.Ctx) middlewares.Limits {
+		partner, ok := c.Locals(partnerLocal).(*partners.PartnerEntity)
+		if !ok {
+			return defaults
+		}
+
+		limits := defaults
+		if partner.Limits.RequestsPerWindow > 0 {
+			limits.RequestsPerWindow = partner.Limits.RequestsPerWindow
+		}
+
+		if partner.Limits.DailyQuotes > 0 {
+			limits.DailyQuotes = partner.Limits.DailyQuotes
+		}
+
+		return limits
+	}
+}
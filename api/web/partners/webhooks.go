@@ -0,0 +1,191 @@
+package partners
+
+import (
+	"encoding/json"
+	"main-api/internal/domain/partners"
+	"main-api/internal/pkg/validator"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type (
+	WebhookRequestData struct {
+		URL        string   `json:"url" validate:"required,url"`
+		EventTypes []string `json:"event_types" validate:"required,min=1,dive,oneof=partner.created quote.created policy.issued policy.status_changed"`
+	}
+
+	WebhookResponseData struct {
+		ID         string    `json:"id"`
+		URL        string    `json:"url"`
+		EventTypes []string  `json:"event_types"`
+		CreatedAt  time.Time `json:"created_at"`
+		// Secret is only sent in the response to the partner's first webhook.
+		Secret string `json:"secret,omitempty"`
+	}
+
+	WebhookSecretResponseData struct {
+		Secret string `json:"secret"`
+	}
+
+	ListWebhookDeliveriesQuery struct {
+		Status string `query:"status" validate:"omitempty,oneof=pending delivered dead"`
+	}
+
+	WebhookDeliveryResponseData struct {
+		ID             string          `json:"id"`
+		WebhookID      string          `json:"webhook_id"`
+		EventID        string          `json:"event_id"`
+		EventType      string          `json:"event_type"`
+		URL            string          `json:"url"`
+		Payload        json.RawMessage `json:"payload"`
+		Status         string          `json:"status"`
+		Attempts       int             `json:"attempts"`
+		LastStatusCode int             `json:"last_status_code,omitempty"`
+		LastError      string          `json:"last_error,omitempty"`
+		NextAttemptAt  *time.Time      `json:"next_attempt_at,omitempty"`
+		RetryUntil     time.Time       `json:"retry_until"`
+		CreatedAt      time.Time       `json:"created_at"`
+		DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+	}
+)
+
+func (h HTTPHandler) RegisterWebhook(c *fiber.Ctx) error {
+	bodyData := new(WebhookRequestData)
+	if err := c.BodyParser(bodyData); err != nil {
+		return fiber.NewError(fiber.StatusUnprocessableEntity, err.Error())
+	}
+
+	if err := validator.BodyData(bodyData); err != nil {
+		return err
+	}
+
+	eventTypes := make([]partners.EventType, len(bodyData.EventTypes))
+	for index, eventType := range bodyData.EventTypes {
+		eventTypes[index] = partners.EventType(eventType)
+	}
+
+	webhook, secret, err := h.service.RegisterWebhook(c.Context(), partners.NewWebhookEntity(
+		c.Params("partner_id"),
+		bodyData.URL,
+		eventTypes,
+	))
+	if err != nil {
+		return err
+	}
+
+	response := toWebhookResponseData(webhook)
+	response.Secret = secret
+
+	return c.Status(fiber.StatusCreated).JSON(response)
+}
+
+func (h HTTPHandler) ListWebhooks(c *fiber.Ctx) error {
+	webhooks, err := h.service.ListWebhooks(c.Context(), c.Params("partner_id"))
+	if err != nil {
+		return err
+	}
+
+	response := make([]WebhookResponseData, len(webhooks))
+	for index := range webhooks {
+		response[index] = toWebhookResponseData(&webhooks[index])
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+func (h HTTPHandler) DeleteWebhook(c *fiber.Ctx) error {
+	err := h.service.DeleteWebhook(c.Context(), c.Params("partner_id"), c.Params("webhook_id"))
+	if err != nil {
+		return err
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h HTTPHandler) RotateWebhookSecret(c *fiber.Ctx) error {
+	secret, err := h.service.RotateWebhookSecret(c.Context(), c.Params("partner_id"))
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(WebhookSecretResponseData{Secret: secret})
+}
+
+func (h HTTPHandler) ListWebhookDeliveries(c *fiber.Ctx) error {
+	queryData := new(ListWebhookDeliveriesQuery)
+	if err := c.QueryParser(queryData); err != nil {
+		return fiber.NewError(fiber.StatusUnprocessableEntity, err.Error())
+	}
+
+	if err := validator.BodyData(queryData); err != nil {
+		return err
+	}
+
+	deliveries, err := h.service.ListWebhookDeliveries(
+		c.Context(),
+		c.Params("partner_id"),
+		partners.WebhookDeliveryStatusEnum(queryData.Status),
+	)
+	if err != nil {
+		return err
+	}
+
+	response := make([]WebhookDeliveryResponseData, len(deliveries))
+	for index := range deliveries {
+		response[index] = toWebhookDeliveryResponseData(&deliveries[index])
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+func (h HTTPHandler) RedeliverWebhook(c *fiber.Ctx) error {
+	delivery, err := h.service.RedeliverWebhook(c.Context(), c.Params("partner_id"), c.Params("delivery_id"))
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(toWebhookDeliveryResponseData(delivery))
+}
+
+func toWebhookResponseData(webhook *partners.WebhookEntity) WebhookResponseData {
+	eventTypes := make([]string, len(webhook.EventTypes))
+	for index, eventType := range webhook.EventTypes {
+		eventTypes[index] = string(eventType)
+	}
+
+	return WebhookResponseData{
+		ID:         webhook.ID,
+		URL:        webhook.URL,
+		EventTypes: eventTypes,
+		CreatedAt:  webhook.CreatedAt,
+	}
+}
+
+func toWebhookDeliveryResponseData(delivery *partners.WebhookDeliveryEntity) WebhookDeliveryResponseData {
+	response := WebhookDeliveryResponseData{
+		ID:             delivery.ID,
+		WebhookID:      delivery.WebhookID,
+		EventID:        delivery.EventID,
+		EventType:      string(delivery.EventType),
+		URL:            delivery.URL,
+		Payload:        delivery.Body,
+		Status:         string(delivery.Status),
+		Attempts:       delivery.Attempts,
+		LastStatusCode: delivery.LastStatusCode,
+		LastError:      delivery.LastError,
+		RetryUntil:     delivery.RetryUntil,
+		CreatedAt:      delivery.CreatedAt,
+	}
+
+	// Only pending deliveries have a next attempt.
+	if delivery.Status == partners.WebhookDeliveryPending {
+		response.NextAttemptAt = &delivery.NextAttemptAt
+	}
+
+	if !delivery.DeliveredAt.IsZero() {
+		response.DeliveredAt = &delivery.DeliveredAt
+	}
+
+	return response
+}
@@ -0,0 +1,86 @@
+package provider
+
+import (
+	"errors"
+	"main-api/api/web/middlewares"
+	"main-api/internal/domain/partners"
+	"main-api/internal/pkg/validator"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/google/uuid"
+)
+
+type (
+	HTTPHandler struct {
+		service partners.Service
+	}
+
+	HTTPHandlerParams struct {
+		App     *fiber.App
+		Service partners.Service
+		// Secret is shared with the provider to sign its requests.
+		Secret string
+	}
+
+	PolicyEventRequestData struct {
+		ID         string    `json:"id" validate:"required,max=255"`
+		Type       string    `json:"type" validate:"required,eq=policy.status_changed"`
+		PolicyID   uuid.UUID `json:"policy_id" validate:"required"`
+		Status     string    `json:"status" validate:"required,oneof=active suspended cancelled expired"`
+		OccurredAt time.Time `json:"occurred_at" validate:"required"`
+	}
+
+	PolicyEventResponseData struct {
+		Result string `json:"result"`
+	}
+)
+
+// signatureTolerance is how far the timestamp of a signed request may be from
+// our clock.
+const signatureTolerance = 5 * time.Minute
+
+func NewHTTPHandler(params HTTPHandlerParams) {
+	httpHandler := HTTPHandler{
+		service: params.Service,
+	}
+
+	params.App.Route("/provider", func(r fiber.Router) {
+		r.Use(middlewares.ProviderSignature(params.Secret, signatureTolerance))
+		r.Use(middlewares.Audit(partners.AuditActorProvider, nil))
+		r.Post("/events/policies", httpHandler.ReceivePolicyEvent)
+	})
+}
+
+// ReceivePolicyEvent answers 200 to every event it handled, including the ones
+// already received, so the provider stops redelivering them.
+func (h *HTTPHandler) ReceivePolicyEvent(c *fiber.Ctx) error {
+	bodyData := new(PolicyEventRequestData)
+	if err := c.BodyParser(bodyData); err != nil {
+		return fiber.NewError(fiber.StatusUnprocessableEntity, err.Error())
+	}
+
+	if err := validator.BodyData(bodyData); err != nil {
+		return err
+	}
+
+	result, err := h.service.HandleProviderPolicyEvent(c.Context(), partners.ProviderPolicyEvent{
+		ID:         bodyData.ID,
+		PolicyID:   bodyData.PolicyID,
+		Status:     partners.PolicyStatusEnum(bodyData.Status),
+		OccurredAt: bodyData.OccurredAt,
+	})
+	if errors.Is(err, partners.ErrPolicyCacheNotInvalidated) {
+		// The event was applied, so the provider mustn't redeliver it.
+		log.Errorf("[PROVIDER EVENTS] event %q: %v", bodyData.ID, err)
+	} else if err != nil {
+		return err
+	}
+
+	if result == partners.ProviderEventIgnored {
+		log.Warnf("[PROVIDER EVENTS] event %q is about unknown policy %q", bodyData.ID, bodyData.PolicyID)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(PolicyEventResponseData{Result: string(result)})
+}
@@ -0,0 +1,401 @@
+package admin_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"main-api/api/web/admin"
+	partnersHandler "main-api/api/web/partners"
+	"main-api/internal/domain/partners"
+	"main-api/internal/infra/cache"
+	"main-api/internal/infra/scheduler"
+	"main-api/internal/pkg/validator"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+const adminToken = "admin-token"
+
+// adminTokens name the admin of adminToken, as the audit log records it.
+var adminTokens = map[string]string{"ops-admin": adminToken}
+
+func TestListJobs(t *testing.T) {
+	testRedis := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: testRedis.Addr()})
+
+	jobs := scheduler.New(scheduler.NewRedisLocker(redisClient), cache.NewRedisCacheAdapter(redisClient))
+	jobs.Register(scheduler.Job{
+		Name:     "expire-quotes",
+		Interval: time.Hour,
+		Run: func(ctx context.Context) (scheduler.Counts, error) {
+			return scheduler.Counts{"expired": 2}, nil
+		},
+	})
+
+	ctx, cancel := context.WithTimeout(t.Context(), 20*time.Millisecond)
+	defer cancel()
+
+	jobs.Run(ctx)
+	jobs.Register(scheduler.Job{Name: "never-ran", Interval: time.Minute})
+
+	app := fiber.New(fiber.Config{ErrorHandler: validator.ErrorHandler})
+	admin.NewHTTPHandler(admin.HTTPHandlerParams{
+		App:         app,
+		Scheduler:   jobs,
+		AdminTokens: adminTokens,
+	})
+
+	t.Run("Should list the status of the jobs", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/admin/jobs", nil)
+		req.Header.Set(fiber.HeaderAuthorization, "Bearer "+adminToken)
+
+		resp, err := app.Test(req, -1)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		defer resp.Body.Close()
+
+		var response []admin.JobStatusResponseData
+		err = json.NewDecoder(resp.Body).Decode(&response)
+
+		assert.NoError(t, err)
+		assert.Len(t, response, 2)
+		assert.Equal(t, "expire-quotes", response[0].Name)
+		assert.Equal(t, "1h0m0s", response[0].Interval)
+		assert.Equal(t, map[string]int64{"expired": 2}, response[0].Counts)
+		assert.Equal(t, int64(1), response[0].Runs)
+		assert.NotNil(t, response[0].LastRunAt)
+		assert.Equal(t, admin.JobStatusResponseData{Name: "never-ran", Interval: "1m0s"}, response[1])
+	})
+
+	t.Run("Not should list the jobs without the admin token", func(t *testing.T) {
+		for _, authorization := range []string{"", "Bearer wrong-token", adminToken} {
+			req := httptest.NewRequest(http.MethodGet, "/admin/jobs", nil)
+			req.Header.Set(fiber.HeaderAuthorization, authorization)
+
+			resp, err := app.Test(req, -1)
+
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+		}
+	})
+
+	t.Run("Not should list the jobs when no admin token is configured", func(t *testing.T) {
+		app := fiber.New(fiber.Config{ErrorHandler: validator.ErrorHandler})
+		admin.NewHTTPHandler(admin.HTTPHandlerParams{App: app, Scheduler: jobs})
+
+		req := httptest.NewRequest(http.MethodGet, "/admin/jobs", nil)
+		req.Header.Set(fiber.HeaderAuthorization, "Bearer ")
+
+		resp, err := app.Test(req, -1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+}
+
+func TestDebugVars(t *testing.T) {
+	app := fiber.New(fiber.Config{ErrorHandler: validator.ErrorHandler})
+	admin.NewHTTPHandler(admin.HTTPHandlerParams{App: app, AdminTokens: adminTokens})
+
+	t.Run("Should serve the expvar variables to the admins", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/admin/debug/vars", nil)
+		req.Header.Set(fiber.HeaderAuthorization, "Bearer "+adminToken)
+
+		resp, err := app.Test(req, -1)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		defer resp.Body.Close()
+
+		var vars map[string]json.RawMessage
+		err = json.NewDecoder(resp.Body).Decode(&vars)
+
+		assert.NoError(t, err)
+		assert.Contains(t, vars, "memstats")
+	})
+
+	t.Run("Not should serve the expvar variables without the admin token", func(t *testing.T) {
+		for _, path := range []string{"/admin/debug/vars", "/debug/vars"} {
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, path, nil), -1)
+
+			assert.NoError(t, err)
+			assert.NotEqual(t, http.StatusOK, resp.StatusCode, path)
+		}
+	})
+}
+
+// auditService answers the audit queries of the admin handler.
+type auditService struct {
+	partners.Service
+	filter partners.AuditEventFilter
+	events []partners.AuditEventEntity
+}
+
+func (s *auditService) ListAuditEvents(_ context.Context, filter partners.AuditEventFilter) ([]partners.AuditEventEntity, error) {
+	s.filter = filter
+
+	return s.events, nil
+}
+
+func (s *auditService) VerifyAuditLog(context.Context) (partners.AuditVerification, error) {
+	return partners.AuditVerification{Checked: int64(len(s.events)), Valid: true}, nil
+}
+
+func TestAuditEvents(t *testing.T) {
+	occurredAt := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	service := &auditService{
+		events: []partners.AuditEventEntity{{
+			ID:          "67e1ae8c7e7a8b2b0f1e4b40",
+			Sequence:    7,
+			Actor:       partners.AuditActor{Type: partners.AuditActorPartner, ID: "67e1ae8c7e7a8b2b0f1e4b11"},
+			Action:      partners.AuditCustomerUpdated,
+			EntityType:  "customer",
+			EntityID:    "67e1ae8c7e7a8b2b0f1e4b41",
+			PartnerID:   "67e1ae8c7e7a8b2b0f1e4b11",
+			Changes:     []byte(`{"email":{"before":"old@test.com","after":"new@test.com"}}`),
+			ChangesHash: "changes-hash",
+			RequestID:   "request-01",
+			SourceIP:    "10.0.0.1",
+			OccurredAt:  occurredAt,
+			Hash:        "hash",
+		}},
+	}
+
+	app := fiber.New(fiber.Config{ErrorHandler: validator.ErrorHandler})
+	admin.NewHTTPHandler(admin.HTTPHandlerParams{
+		App:         app,
+		Service:     service,
+		AdminTokens: adminTokens,
+	})
+
+	t.Run("Should list the audit events matching the filters", func(t *testing.T) {
+		req := httptest.NewRequest(
+			http.MethodGet,
+			"/admin/audit-events?partner_id=67e1ae8c7e7a8b2b0f1e4b11&entity_type=customer&from=2026-10-01T00:00:00Z&after=6",
+			nil,
+		)
+		req.Header.Set(fiber.HeaderAuthorization, "Bearer "+adminToken)
+
+		resp, err := app.Test(req, -1)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		defer resp.Body.Close()
+
+		var response []admin.AuditEventResponseData
+		err = json.NewDecoder(resp.Body).Decode(&response)
+
+		assert.NoError(t, err)
+		assert.Equal(t, partners.AuditEventFilter{
+			PartnerID:     "67e1ae8c7e7a8b2b0f1e4b11",
+			EntityType:    "customer",
+			From:          time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+			AfterSequence: 6,
+			Limit:         100,
+		}, service.filter)
+		assert.Len(t, response, 1)
+		assert.Equal(t, int64(7), response[0].Sequence)
+		assert.Equal(t, "partner", response[0].ActorType)
+		assert.Equal(t, "customer.updated", response[0].Action)
+		assert.JSONEq(t, `{"email":{"before":"old@test.com","after":"new@test.com"}}`, string(response[0].Changes))
+	})
+
+	t.Run("Not should list the audit events with an invalid filter", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/admin/audit-events?from=yesterday", nil)
+		req.Header.Set(fiber.HeaderAuthorization, "Bearer "+adminToken)
+
+		resp, err := app.Test(req, -1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("Should verify the audit log", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/admin/audit-events/verify", nil)
+		req.Header.Set(fiber.HeaderAuthorization, "Bearer "+adminToken)
+
+		resp, err := app.Test(req, -1)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		defer resp.Body.Close()
+
+		var response admin.AuditVerificationResponseData
+		err = json.NewDecoder(resp.Body).Decode(&response)
+
+		assert.NoError(t, err)
+		assert.Equal(t, admin.AuditVerificationResponseData{Valid: true, Checked: 1}, response)
+	})
+}
+
+// dataSubjectService answers the data subject requests of the admin handler
+// for a single holder.
+type dataSubjectService struct {
+	partners.Service
+	cpf string
+	// erasureErr is returned along with the erasure.
+	erasureErr error
+}
+
+func (s *dataSubjectService) ExportDataSubject(_ context.Context, cpf string) (*partners.DataSubjectExport, error) {
+	if cpf != s.cpf {
+		return nil, partners.ErrDataSubjectNotFound
+	}
+
+	return &partners.DataSubjectExport{
+		Cpf: cpf,
+		Customers: []partners.CustomerEntity{{
+			ID:        "67e1ae8c7e7a8b2b0f1e4b41",
+			PartnerID: "67e1ae8c7e7a8b2b0f1e4b11",
+			Name:      "customer-test",
+			Cpf:       cpf,
+			DeletedAt: time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC),
+		}},
+		Policies: []partners.PolicyEntity{{
+			ID:            "67e1ae8c7e7a8b2b0f1e4b42",
+			PartnerID:     "67e1ae8c7e7a8b2b0f1e4b11",
+			Name:          "customer-test",
+			Cpf:           cpf,
+			Status:        partners.PolicyStatusActive,
+			Beneficiaries: []partners.BeneficiaryEntity{{Name: "beneficiary-test", Percentage: 100}},
+		}},
+	}, nil
+}
+
+func (s *dataSubjectService) EraseDataSubject(_ context.Context, cpf string) (partners.DataSubjectErasure, error) {
+	if cpf != s.cpf {
+		return partners.DataSubjectErasure{}, partners.ErrDataSubjectNotFound
+	}
+
+	return partners.DataSubjectErasure{Pseudonym: "erased-test", Customers: 1, Policies: 1}, s.erasureErr
+}
+
+func TestDataSubjects(t *testing.T) {
+	app := fiber.New(fiber.Config{ErrorHandler: partnersHandler.ErrorHandler})
+	service := &dataSubjectService{cpf: "52998224725"}
+	admin.NewHTTPHandler(admin.HTTPHandlerParams{
+		App:         app,
+		Service:     service,
+		AdminTokens: adminTokens,
+	})
+
+	newRequest := func(path, cpf string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{"cpf": "`+cpf+`"}`))
+		req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+		req.Header.Set(fiber.HeaderAuthorization, "Bearer "+adminToken)
+
+		return req
+	}
+
+	t.Run("Should export the personal data of the holder", func(t *testing.T) {
+		resp, err := app.Test(newRequest("/admin/data-subjects/export", "529.982.247-25"), -1)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		defer resp.Body.Close()
+
+		var response admin.DataSubjectExportResponseData
+		err = json.NewDecoder(resp.Body).Decode(&response)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "52998224725", response.Cpf)
+		assert.Len(t, response.Customers, 1)
+		assert.NotNil(t, response.Customers[0].DeletedAt)
+		assert.Len(t, response.Policies, 1)
+		assert.Equal(t, "beneficiary-test", response.Policies[0].Beneficiaries[0].Name)
+		assert.Empty(t, response.AuditEvents)
+	})
+
+	t.Run("Should erase the personal data of the holder", func(t *testing.T) {
+		resp, err := app.Test(newRequest("/admin/data-subjects/erase", "52998224725"), -1)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		defer resp.Body.Close()
+
+		var response admin.DataSubjectErasureResponseData
+		err = json.NewDecoder(resp.Body).Decode(&response)
+
+		assert.NoError(t, err)
+		assert.Equal(t, admin.DataSubjectErasureResponseData{Pseudonym: "erased-test", Customers: 1, Policies: 1}, response)
+	})
+
+	t.Run("Should answer the erasure when the provider's policy isn't invalidated", func(t *testing.T) {
+		service.erasureErr = fmt.Errorf("%w: policy %q: %w", partners.ErrPolicyCacheNotInvalidated, "provider-01", assert.AnError)
+		defer func() { service.erasureErr = nil }()
+
+		resp, err := app.Test(newRequest("/admin/data-subjects/erase", "52998224725"), -1)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		defer resp.Body.Close()
+
+		var response admin.DataSubjectErasureResponseData
+		err = json.NewDecoder(resp.Body).Decode(&response)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "erased-test", response.Pseudonym)
+	})
+
+	t.Run("Not should answer for a cpf without personal data", func(t *testing.T) {
+		resp, err := app.Test(newRequest("/admin/data-subjects/erase", "11144477735"), -1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("Not should answer for an invalid cpf", func(t *testing.T) {
+		resp, err := app.Test(newRequest("/admin/data-subjects/export", "12345678900"), -1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+// actorService records who suspended a partner.
+type actorService struct {
+	partners.Service
+	actor partners.AuditActor
+}
+
+func (s *actorService) SuspendPartner(ctx context.Context, partnerID string) (*partners.PartnerEntity, error) {
+	s.actor = partners.AuditRequestFromContext(ctx).Actor
+
+	return &partners.PartnerEntity{ID: partnerID, Suspended: true}, nil
+}
+
+func TestAdminActor(t *testing.T) {
+	service := &actorService{}
+
+	app := fiber.New(fiber.Config{ErrorHandler: validator.ErrorHandler})
+	admin.NewHTTPHandler(admin.HTTPHandlerParams{
+		App:     app,
+		Service: service,
+		AdminTokens: map[string]string{
+			"ops-admin":     adminToken,
+			"support-admin": "support-token",
+		},
+	})
+
+	t.Run("Should record the admin whose token made the change", func(t *testing.T) {
+		for name, token := range map[string]string{"ops-admin": adminToken, "support-admin": "support-token"} {
+			req := httptest.NewRequest(http.MethodPost, "/admin/partners/67e1ae8c7e7a8b2b0f1e4b11/suspend", nil)
+			req.Header.Set(fiber.HeaderAuthorization, "Bearer "+token)
+
+			resp, err := app.Test(req, -1)
+
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.Equal(t, partners.AuditActor{Type: partners.AuditActorAdmin, ID: name}, service.actor)
+		}
+	})
+}
@@ -18,7 +18,8 @@ type (
 )
 
 func createMongodBContainer(ctx context.Context) (*MongoContainer, error) {
-	mongoDBContainer, err := mongodb.Run(ctx, "mongo:6")
+	// Transactions, used to write the outbox, need a replica set.
+	mongoDBContainer, err := mongodb.Run(ctx, "mongo:6", mongodb.WithReplicaSet("rs0"))
 	if err != nil {
 		panic("Could not start mongo container: " + err.Error())
 	}
@@ -60,7 +61,9 @@ func ConnectionToDB(ctx context.Context, databaseName string) (*mongo.Client, fu
 func clearAllDataBase(ctx context.Context, db *mongo.Client, databaseName string) {
 	fmt.Println("Cleaning database...")
 
-	collections := []string{"partners", "quotes", "policies"}
+	collections := []string{"partners", "quotes", "policies", "customers", "outbox",
+		"webhooks", "webhook_secrets", "webhook_deliveries", "provider_events", "reconciliation_reports",
+		"policy_issuances", "audit_events", "audit_chain", "data_keys"}
 
 	for _, collection := range collections {
 		_, err := db.Database(databaseName).Collection(collection).DeleteMany(ctx, bson.M{})
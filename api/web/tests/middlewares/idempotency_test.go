@@ -0,0 +1,146 @@
+package middlewares_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"main-api/api/web/middlewares"
+	"main-api/internal/domain/partners"
+	"main-api/internal/infra/cache"
+	"main-api/internal/pkg/validator"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdempotency(t *testing.T) {
+	testRedis := miniredis.RunT(t)
+	store := cache.NewRedisCacheAdapter(redis.NewClient(&redis.Options{Addr: testRedis.Addr()}))
+
+	var calls atomic.Int32
+	release := make(chan struct{})
+
+	app := fiber.New(fiber.Config{ErrorHandler: validator.ErrorHandler})
+	app.Post("/policies", middlewares.Idempotency(store, time.Hour, time.Minute), func(c *fiber.Ctx) error {
+		return c.Status(fiber.StatusCreated).JSON(fiber.Map{"call": calls.Add(1)})
+	})
+	app.Post("/failures", middlewares.Idempotency(store, time.Hour, time.Minute), func(c *fiber.Ctx) error {
+		calls.Add(1)
+
+		return fiber.NewError(fiber.StatusBadGateway, "provider unavailable")
+	})
+	app.Post("/provider-failures", middlewares.Idempotency(store, time.Hour, time.Minute), func(c *fiber.Ctx) error {
+		calls.Add(1)
+		partners.MarkProviderCalled(c.Context())
+
+		return fiber.NewError(fiber.StatusBadGateway, "provider unavailable")
+	})
+	app.Post("/slow", middlewares.Idempotency(store, time.Hour, 5*time.Minute), func(c *fiber.Ctx) error {
+		<-release
+
+		return c.SendStatus(fiber.StatusCreated)
+	})
+
+	doRequest := func(t *testing.T, path, key, body string) (*http.Response, string) {
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewBufferString(body))
+		req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+		if key != "" {
+			req.Header.Set(middlewares.IdempotencyKeyHeader, key)
+		}
+
+		resp, err := app.Test(req, -1)
+		assert.NoError(t, err)
+
+		defer resp.Body.Close()
+
+		responseBody, _ := io.ReadAll(resp.Body)
+
+		return resp, string(responseBody)
+	}
+
+	t.Run("Should replay the stored response when the request is retried", func(t *testing.T) {
+		calls.Store(0)
+
+		firstResp, firstBody := doRequest(t, "/policies", "key-01", `{"name":"test"}`)
+		retryResp, retryBody := doRequest(t, "/policies", "key-01", `{"name":"test"}`)
+
+		assert.Equal(t, http.StatusCreated, firstResp.StatusCode)
+		assert.Equal(t, http.StatusCreated, retryResp.StatusCode)
+		assert.Equal(t, firstBody, retryBody)
+		assert.Equal(t, "true", retryResp.Header.Get(middlewares.IdempotentReplayedHeader))
+		assert.Equal(t, fiber.MIMEApplicationJSON, retryResp.Header.Get(fiber.HeaderContentType))
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("Should run every request without an idempotency key", func(t *testing.T) {
+		calls.Store(0)
+
+		doRequest(t, "/policies", "", `{"name":"test"}`)
+		doRequest(t, "/policies", "", `{"name":"test"}`)
+
+		assert.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("Not should accept a key reused with a different body", func(t *testing.T) {
+		doRequest(t, "/policies", "key-02", `{"name":"test"}`)
+		resp, body := doRequest(t, "/policies", "key-02", `{"name":"other"}`)
+
+		var problem validator.ProblemDetails
+		assert.NoError(t, json.Unmarshal([]byte(body), &problem))
+
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+		assert.Equal(t, "idempotency key was already used with a different request", problem.Detail)
+	})
+
+	t.Run("Should release the key when the request fails before calling the provider", func(t *testing.T) {
+		calls.Store(0)
+
+		firstResp, _ := doRequest(t, "/failures", "key-03", `{}`)
+		retryResp, _ := doRequest(t, "/failures", "key-03", `{}`)
+
+		assert.Equal(t, http.StatusBadGateway, firstResp.StatusCode)
+		assert.Equal(t, http.StatusBadGateway, retryResp.StatusCode)
+		assert.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("Should replay the failure of a request that called the provider", func(t *testing.T) {
+		calls.Store(0)
+
+		firstResp, firstBody := doRequest(t, "/provider-failures", "key-05", `{}`)
+		retryResp, retryBody := doRequest(t, "/provider-failures", "key-05", `{}`)
+
+		assert.Equal(t, http.StatusBadGateway, firstResp.StatusCode)
+		assert.Equal(t, http.StatusBadGateway, retryResp.StatusCode)
+		assert.Equal(t, firstBody, retryBody)
+		assert.Equal(t, "true", retryResp.Header.Get(middlewares.IdempotentReplayedHeader))
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("Not should run a retry while the first request is in flight", func(t *testing.T) {
+		done := make(chan *http.Response)
+		go func() {
+			resp, _ := doRequest(t, "/slow", "key-04", `{}`)
+			done <- resp
+		}()
+
+		assert.Eventually(t, func() bool {
+			return testRedis.Exists("idempotency:POST:/slow:key-04")
+		}, time.Second, 10*time.Millisecond)
+		assert.Equal(t, 5*time.Minute, testRedis.TTL("idempotency:POST:/slow:key-04"))
+
+		resp, _ := doRequest(t, "/slow", "key-04", `{}`)
+
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+		assert.Equal(t, "1", resp.Header.Get(fiber.HeaderRetryAfter))
+
+		close(release)
+		assert.Equal(t, http.StatusCreated, (<-done).StatusCode)
+	})
+}
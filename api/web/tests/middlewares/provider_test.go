@@ -0,0 +1,86 @@
+package middlewares_test
+
+import (
+	"bytes"
+	"main-api/api/web/middlewares"
+	"main-api/internal/infra/http/webhook"
+	"main-api/internal/pkg/validator"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProviderSignature(t *testing.T) {
+	const secret = "provider-secret"
+
+	newApp := func(secret string) *fiber.App {
+		app := fiber.New(fiber.Config{ErrorHandler: validator.ErrorHandler})
+		app.Post("/events", middlewares.ProviderSignature(secret, 5*time.Minute), func(c *fiber.Ctx) error {
+			return c.SendStatus(fiber.StatusOK)
+		})
+
+		return app
+	}
+
+	signedRequest := func(secret string, timestamp int64, body []byte) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/events", bytes.NewReader(body))
+		req.Header.Set(middlewares.ProviderTimestampHeader, strconv.FormatInt(timestamp, 10))
+		req.Header.Set(middlewares.ProviderSignatureHeader, webhook.Sign(secret, timestamp, body))
+
+		return req
+	}
+
+	body := []byte(`{"id":"event-01"}`)
+
+	t.Run("Should let through a request signed with the secret", func(t *testing.T) {
+		resp, err := newApp(secret).Test(signedRequest(secret, time.Now().Unix(), body), -1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("Not should let through a request signed with another secret", func(t *testing.T) {
+		resp, err := newApp(secret).Test(signedRequest("other-secret", time.Now().Unix(), body), -1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("Not should let through a request whose body was changed", func(t *testing.T) {
+		req := signedRequest(secret, time.Now().Unix(), body)
+		req.Body = httptest.NewRequest(http.MethodPost, "/", bytes.NewReader([]byte(`{"id":"event-02"}`))).Body
+
+		resp, err := newApp(secret).Test(req, -1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("Not should let through a replay of an old request", func(t *testing.T) {
+		resp, err := newApp(secret).Test(signedRequest(secret, time.Now().Add(-10*time.Minute).Unix(), body), -1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("Not should let through an unsigned request", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/events", bytes.NewReader(body))
+
+		resp, err := newApp(secret).Test(req, -1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("Not should let through any request when no secret is configured", func(t *testing.T) {
+		resp, err := newApp("").Test(signedRequest("", time.Now().Unix(), body), -1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+}
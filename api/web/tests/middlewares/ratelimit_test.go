@@ -0,0 +1,96 @@
+package middlewares_test
+
+import (
+	"main-api/api/web/middlewares"
+	"main-api/internal/infra/ratelimit"
+	"main-api/internal/pkg/validator"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimit(t *testing.T) {
+	testRedis := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: testRedis.Addr()})
+
+	limits := func(c *fiber.Ctx) middlewares.Limits {
+		if c.Params("partner_id") == "unlimited" {
+			return middlewares.Limits{}
+		}
+
+		return middlewares.Limits{RequestsPerWindow: 2, DailyQuotes: 1}
+	}
+
+	rateLimit := middlewares.RateLimit(ratelimit.NewSlidingWindow(redisClient, time.Minute), limits)
+	quoteQuota := middlewares.DailyQuota(ratelimit.NewDailyQuota(redisClient, ratelimit.QuotaLocation), "quotes", limits)
+
+	app := fiber.New(fiber.Config{ErrorHandler: validator.ErrorHandler})
+	app.Get("/partners/:partner_id/policies", rateLimit, func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+	app.Get("/partners/:partner_id/customers", rateLimit, func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+	app.Post("/partners/:partner_id/quotes", quoteQuota, func(c *fiber.Ctx) error {
+		if c.Query("fail") != "" {
+			return fiber.NewError(fiber.StatusBadRequest, "invalid quote")
+		}
+
+		return c.SendStatus(fiber.StatusCreated)
+	})
+
+	doRequest := func(t *testing.T, method, path string) *http.Response {
+		resp, err := app.Test(httptest.NewRequest(method, path, nil), -1)
+		assert.NoError(t, err)
+
+		defer resp.Body.Close()
+
+		return resp
+	}
+
+	t.Run("Should limit the requests of a partner to a route", func(t *testing.T) {
+		first := doRequest(t, http.MethodGet, "/partners/partner-01/policies")
+
+		assert.Equal(t, http.StatusOK, first.StatusCode)
+		assert.Equal(t, "2", first.Header.Get(middlewares.RateLimitLimitHeader))
+		assert.Equal(t, "1", first.Header.Get(middlewares.RateLimitRemainingHeader))
+
+		doRequest(t, http.MethodGet, "/partners/partner-01/policies")
+		limited := doRequest(t, http.MethodGet, "/partners/partner-01/policies")
+
+		assert.Equal(t, http.StatusTooManyRequests, limited.StatusCode)
+		assert.Equal(t, "0", limited.Header.Get(middlewares.RateLimitRemainingHeader))
+		assert.NotEmpty(t, limited.Header.Get(fiber.HeaderRetryAfter))
+	})
+
+	t.Run("Should count routes and partners apart", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, doRequest(t, http.MethodGet, "/partners/partner-01/customers").StatusCode)
+		assert.Equal(t, http.StatusOK, doRequest(t, http.MethodGet, "/partners/partner-02/policies").StatusCode)
+	})
+
+	t.Run("Not should limit partners without limits", func(t *testing.T) {
+		for range 5 {
+			resp := doRequest(t, http.MethodGet, "/partners/unlimited/policies")
+
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.Empty(t, resp.Header.Get(middlewares.RateLimitLimitHeader))
+		}
+	})
+
+	t.Run("Should limit the daily quotations and refund the failed ones", func(t *testing.T) {
+		failed := doRequest(t, http.MethodPost, "/partners/partner-03/quotes?fail=true")
+		created := doRequest(t, http.MethodPost, "/partners/partner-03/quotes")
+		limited := doRequest(t, http.MethodPost, "/partners/partner-03/quotes")
+
+		assert.Equal(t, http.StatusBadRequest, failed.StatusCode)
+		assert.Equal(t, http.StatusCreated, created.StatusCode)
+		assert.Equal(t, http.StatusTooManyRequests, limited.StatusCode)
+		assert.NotEmpty(t, limited.Header.Get(fiber.HeaderRetryAfter))
+	})
+}
@@ -0,0 +1,99 @@
+package partners_test
+
+import (
+	"bytes"
+	"encoding/json"
+	partnersHandler "main-api/api/web/partners"
+	partnerDomain "main-api/internal/domain/partners"
+	auditRepo "main-api/internal/infra/repository/audit"
+	"net/http"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestAuditLog(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx, server, cleanUp, clearAllDataBase := testContext(ctrl)
+	defer cleanUp()
+
+	t.Run("Should audit the changes made through the API in a verifiable chain", func(t *testing.T) {
+		defer clearAllDataBase()
+
+		jsonData, err := json.Marshal(map[string]interface{}{
+			"name": "180 Seguros",
+			"cnpj": "12345678901234",
+		})
+		assert.NoError(t, err)
+
+		req, _ := http.NewRequest(http.MethodPost, PartnerPath, bytes.NewReader(jsonData))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(fiber.HeaderXRequestID, "request-01")
+
+		resp, err := server.Test(req, -1)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		var partner partnersHandler.CreatePartnerResponseData
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&partner))
+		resp.Body.Close()
+
+		jsonData, err = json.Marshal(map[string]interface{}{"name": "181 Seguros"})
+		assert.NoError(t, err)
+
+		req, _ = http.NewRequest(http.MethodPut, AdminPartnerPath+partner.ID, bytes.NewReader(jsonData))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+
+		resp, err = server.Test(req, -1)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		resp.Body.Close()
+
+		events, err := helpers.Service.ListAuditEvents(ctx, partnerDomain.AuditEventFilter{PartnerID: partner.ID, Limit: 10})
+
+		assert.NoError(t, err)
+		assert.Len(t, events, 2)
+		assert.Equal(t, partnerDomain.AuditPartnerCreated, events[0].Action)
+		assert.Equal(t, "request-01", events[0].RequestID)
+		assert.Equal(t, partnerDomain.AuditActor{Type: partnerDomain.AuditActorPartner, ID: partner.ID}, events[0].Actor)
+		assert.NotEmpty(t, events[0].SourceIP)
+		assert.Equal(t, partnerDomain.AuditPartnerUpdated, events[1].Action)
+		assert.Equal(t, partnerDomain.AuditActor{Type: partnerDomain.AuditActorAdmin, ID: adminName}, events[1].Actor)
+		assert.Equal(t, events[0].Hash, events[1].PreviousHash)
+		assert.JSONEq(t, `{"name": {"before": "180 Seguros", "after": "181 Seguros"}}`, string(events[1].Changes))
+
+		result, err := helpers.Service.VerifyAuditLog(ctx)
+
+		assert.NoError(t, err)
+		assert.Equal(t, partnerDomain.AuditVerification{Checked: 2, Valid: true}, result)
+	})
+
+	t.Run("Should detect an audit event changed in the database", func(t *testing.T) {
+		defer clearAllDataBase()
+
+		fakePartner := createAFakePartner()
+
+		_, err := helpers.Service.SuspendPartner(ctx, fakePartner.ID)
+		assert.NoError(t, err)
+
+		_, err = helpers.Service.UpdatePartner(ctx, &partnerDomain.PartnerEntity{ID: fakePartner.ID, Name: "other-name"})
+		assert.NoError(t, err)
+
+		_, err = helpers.DBclient.Database(databaseName).
+			Collection(auditRepo.CollectionName).
+			UpdateOne(ctx, bson.M{"sequence": 1}, bson.M{"$set": bson.M{"actor_type": "admin"}})
+		assert.NoError(t, err)
+
+		result, err := helpers.Service.VerifyAuditLog(ctx)
+
+		assert.NoError(t, err)
+		assert.False(t, result.Valid)
+		assert.Equal(t, int64(1), result.BrokenAt)
+	})
+}
@@ -0,0 +1,182 @@
+package partners_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	partnersHandler "main-api/api/web/partners"
+	"main-api/internal/pkg/validator"
+	"net/http"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateCustomer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	_, server, cleanUp, clearAllDataBase := testContext(ctrl)
+	defer cleanUp()
+
+	payload := map[string]interface{}{
+		"name":          "test-customer",
+		"sex":           "F",
+		"date_of_birth": "1998-09-28",
+		"cpf":           "529.982.247-25",
+		"email":         "customer@test.com",
+		"phone":         "+5511999998888",
+	}
+
+	t.Run("Should create sucessfuly a customer and return", func(t *testing.T) {
+		defer clearAllDataBase()
+
+		fakePartner := createAFakePartner()
+
+		jsonData, err := json.Marshal(payload)
+		assert.NoError(t, err)
+
+		path := fmt.Sprintf("%s%s/customers", PartnerPath, fakePartner.ID)
+
+		req, _ := http.NewRequest(http.MethodPost, path, bytes.NewReader(jsonData))
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := server.Test(req, -1)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		defer resp.Body.Close()
+
+		var response partnersHandler.CustomerResponseData
+		err = json.NewDecoder(resp.Body).Decode(&response)
+		assert.NoError(t, err)
+
+		assert.NotEmpty(t, response.ID)
+		assert.Equal(t, fakeHolderCpf, response.Cpf)
+		assert.Equal(t, "test-customer", response.Name)
+	})
+
+	t.Run("Not should create a customer with the same CPF for the same partner", func(t *testing.T) {
+		defer clearAllDataBase()
+
+		fakePartner := createAFakePartner()
+		createAFakeCustomer(fakePartner.ID)
+
+		jsonData, err := json.Marshal(payload)
+		assert.NoError(t, err)
+
+		path := fmt.Sprintf("%s%s/customers", PartnerPath, fakePartner.ID)
+
+		req, _ := http.NewRequest(http.MethodPost, path, bytes.NewReader(jsonData))
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := server.Test(req, -1)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+	})
+}
+
+func TestCustomerLifecycle(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	_, server, cleanUp, clearAllDataBase := testContext(ctrl)
+	defer cleanUp()
+
+	t.Run("Should get, update and delete a customer", func(t *testing.T) {
+		defer clearAllDataBase()
+
+		fakePartner := createAFakePartner()
+		fakeCustomer := createAFakeCustomer(fakePartner.ID)
+		path := fmt.Sprintf("%s%s/customers/%s", PartnerPath, fakePartner.ID, fakeCustomer.ID)
+
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		resp, err := server.Test(req, -1)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		jsonData, err := json.Marshal(map[string]interface{}{
+			"name":          "updated-customer",
+			"sex":           "F",
+			"date_of_birth": "1998-09-28",
+			"cpf":           fakeHolderCpf,
+		})
+		assert.NoError(t, err)
+
+		req, _ = http.NewRequest(http.MethodPut, path, bytes.NewReader(jsonData))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err = server.Test(req, -1)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var response partnersHandler.CustomerResponseData
+		err = json.NewDecoder(resp.Body).Decode(&response)
+		assert.NoError(t, err)
+		assert.Equal(t, "updated-customer", response.Name)
+
+		req, _ = http.NewRequest(http.MethodDelete, path, nil)
+		resp, err = server.Test(req, -1)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+		req, _ = http.NewRequest(http.MethodGet, path, nil)
+		resp, err = server.Test(req, -1)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("Should list the policies of a customer", func(t *testing.T) {
+		defer clearAllDataBase()
+
+		fakePartner := createAFakePartner()
+		fakeCustomer := createAFakeCustomer(fakePartner.ID)
+		createAFakePolicy(fakePartner.ID)
+
+		path := fmt.Sprintf("%s%s/customers/%s/policies", PartnerPath, fakePartner.ID, fakeCustomer.ID)
+
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		resp, err := server.Test(req, -1)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		defer resp.Body.Close()
+
+		var response []partnersHandler.CreatePolicyResponseData
+		err = json.NewDecoder(resp.Body).Decode(&response)
+		assert.NoError(t, err)
+		assert.Len(t, response, 1)
+	})
+	t.Run("Not should create a policy with a customer and the holder data", func(t *testing.T) {
+		defer clearAllDataBase()
+
+		fakePartner := createAFakePartner()
+		fakeCustomer := createAFakeCustomer(fakePartner.ID)
+
+		jsonData, err := json.Marshal(map[string]interface{}{
+			"quotation_id": uuid.NewString(),
+			"customer_id":  fakeCustomer.ID,
+			"name":         "other-holder",
+		})
+		assert.NoError(t, err)
+
+		path := fmt.Sprintf("%s%s/policies", PartnerPath, fakePartner.ID)
+
+		req, _ := http.NewRequest(http.MethodPost, path, bytes.NewReader(jsonData))
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := server.Test(req, -1)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		defer resp.Body.Close()
+
+		var problem validator.ProblemDetails
+		err = json.NewDecoder(resp.Body).Decode(&problem)
+		assert.NoError(t, err)
+		assert.Len(t, problem.Errors, 1)
+		assert.Equal(t, "name", problem.Errors[0].Field)
+		assert.Equal(t, "excluded_with", problem.Errors[0].Code)
+	})
+}
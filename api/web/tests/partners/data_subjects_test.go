@@ -0,0 +1,116 @@
+package partners_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	partnersHandler "main-api/api/web/partners"
+	partnerDomain "main-api/internal/domain/partners"
+	customersRepo "main-api/internal/infra/repository/customers"
+	policiesRepo "main-api/internal/infra/repository/policies"
+	"net/http"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDataSubjects(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx, server, cleanUp, clearAllDataBase := testContext(ctrl)
+	defer cleanUp()
+
+	t.Run("Should keep a deleted customer out of the API and free its cpf", func(t *testing.T) {
+		defer clearAllDataBase()
+
+		fakePartner := createAFakePartner()
+		fakeCustomer := createAFakeCustomer(fakePartner.ID)
+
+		req, _ := http.NewRequest(http.MethodDelete, fmt.Sprintf("%s%s/customers/%s", PartnerPath, fakePartner.ID, fakeCustomer.ID), nil)
+		resp, err := server.Test(req, -1)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+		jsonData, err := json.Marshal(map[string]interface{}{
+			"name":          "test-customer",
+			"sex":           "F",
+			"date_of_birth": "1998-09-28",
+			"cpf":           fakeHolderCpf,
+		})
+		assert.NoError(t, err)
+
+		req, _ = http.NewRequest(http.MethodPost, fmt.Sprintf("%s%s/customers", PartnerPath, fakePartner.ID), bytes.NewReader(jsonData))
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err = server.Test(req, -1)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		customers, err := customersRepo.NewRepo(helpers.DBclient, databaseName, helpers.Cipher).ListByCpf(ctx, fakeHolderCpf)
+
+		assert.NoError(t, err)
+		assert.Len(t, customers, 2)
+		assert.False(t, customers[0].DeletedAt.IsZero())
+		assert.True(t, customers[1].DeletedAt.IsZero())
+	})
+
+	t.Run("Should export and then erase the personal data of a holder", func(t *testing.T) {
+		defer clearAllDataBase()
+
+		fakePartner := createAFakePartner()
+		fakeCustomer := createAFakeCustomer(fakePartner.ID)
+		fakePolicy := createAFakePolicy(fakePartner.ID)
+
+		export, err := helpers.Service.ExportDataSubject(ctx, fakeHolderCpf)
+
+		assert.NoError(t, err)
+		assert.Len(t, export.Customers, 1)
+		assert.Len(t, export.Policies, 1)
+
+		result, err := helpers.Service.EraseDataSubject(ctx, fakeHolderCpf)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), result.Customers)
+		assert.Equal(t, int64(1), result.Policies)
+
+		policy, err := policiesRepo.NewRepo(helpers.DBclient, databaseName, helpers.Cipher).GetByIdAndPartnerID(ctx, fakePolicy.ID, fakePartner.ID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, result.Pseudonym, policy.Name)
+		assert.Equal(t, result.Pseudonym, policy.Cpf)
+		assert.Empty(t, policy.DateOfBirth)
+		assert.Equal(t, fakePolicy.ProviderID, policy.ProviderID)
+		assert.False(t, policy.ErasedAt.IsZero())
+
+		// The provider isn't asked for the policy, since it still has the
+		// holder's data.
+		req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("%s%s/policies/%s", PartnerPath, fakePartner.ID, fakePolicy.ID), nil)
+		resp, err := server.Test(req, -1)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var response partnersHandler.CreatePolicyResponseData
+		err = json.NewDecoder(resp.Body).Decode(&response)
+		assert.NoError(t, err)
+		assert.Equal(t, result.Pseudonym, response.Name)
+		assert.Empty(t, response.DateOfBirth)
+
+		events, err := helpers.Service.ListAuditEvents(ctx, partnerDomain.AuditEventFilter{EntityID: fakeCustomer.ID, Limit: 10})
+
+		assert.NoError(t, err)
+		assert.Len(t, events, 2)
+		assert.Equal(t, partnerDomain.AuditCustomerExported, events[0].Action)
+		assert.Equal(t, partnerDomain.AuditCustomerErased, events[1].Action)
+
+		_, err = helpers.Service.ExportDataSubject(ctx, fakeHolderCpf)
+
+		assert.Equal(t, partnerDomain.ErrDataSubjectNotFound, err)
+
+		verification, err := helpers.Service.VerifyAuditLog(ctx)
+
+		assert.NoError(t, err)
+		assert.True(t, verification.Valid)
+	})
+}
@@ -0,0 +1,192 @@
+package partners_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	customersRepo "main-api/internal/infra/repository/customers"
+	dataKeysRepo "main-api/internal/infra/repository/datakeys"
+	policiesRepo "main-api/internal/infra/repository/policies"
+	"main-api/internal/infra/repository/transaction"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestPersonalDataEncryption(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx, server, cleanUp, clearAllDataBase := testContext(ctrl)
+	defer cleanUp()
+
+	t.Run("Should store the customer's personal data encrypted and find it by the CPF", func(t *testing.T) {
+		defer clearAllDataBase()
+
+		fakePartner := createAFakePartner()
+
+		jsonData, err := json.Marshal(map[string]interface{}{
+			"name":          "test-customer",
+			"sex":           "F",
+			"date_of_birth": "1998-09-28",
+			"cpf":           fakeHolderCpf,
+			"email":         "customer@test.com",
+		})
+		assert.NoError(t, err)
+
+		path := fmt.Sprintf("%s%s/customers", PartnerPath, fakePartner.ID)
+
+		req, _ := http.NewRequest(http.MethodPost, path, bytes.NewReader(jsonData))
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := server.Test(req, -1)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		var stored bson.M
+		err = helpers.DBclient.Database(databaseName).
+			Collection(customersRepo.CollectionName).
+			FindOne(ctx, bson.M{"partner_id": fakePartner.ID}).
+			Decode(&stored)
+		assert.NoError(t, err)
+
+		for _, field := range []string{"name", "date_of_birth", "cpf", "email"} {
+			assert.True(t, strings.HasPrefix(stored[field].(string), "enc:v1:"), field)
+		}
+
+		assert.Equal(t, helpers.Cipher.Hash(fakeHolderCpf), stored["cpf_hash"])
+
+		customer, err := customersRepo.NewRepo(helpers.DBclient, databaseName, helpers.Cipher).
+			GetByCpfAndPartnerID(ctx, fakeHolderCpf, fakePartner.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, "test-customer", customer.Name)
+		assert.Equal(t, "customer@test.com", customer.Email)
+	})
+
+	t.Run("Should create a single first key for the partner outside of the transactions", func(t *testing.T) {
+		defer clearAllDataBase()
+
+		var (
+			wg       sync.WaitGroup
+			errAbort = errors.New("abort")
+			keyIDs   = make([]string, 5)
+		)
+
+		transactor := transaction.NewMongoTransactor(helpers.DBclient)
+
+		for index := range keyIDs {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				err := transactor.WithTransaction(ctx, func(ctx context.Context) error {
+					encrypter := helpers.Cipher.Encrypter(ctx, "partner-keys")
+					assert.NoError(t, encrypter.Err())
+
+					keyIDs[index] = encrypter.KeyID()
+
+					return errAbort
+				})
+				assert.ErrorIs(t, err, errAbort)
+			}()
+		}
+
+		wg.Wait()
+
+		for _, keyID := range keyIDs {
+			assert.Equal(t, keyIDs[0], keyID)
+		}
+
+		// The key outlives the aborted transactions.
+		count, err := helpers.DBclient.Database(databaseName).
+			Collection(dataKeysRepo.CollectionName).
+			CountDocuments(ctx, bson.M{"partner_id": "partner-keys"})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("Should keep the provider data healed on a plaintext policy when it is reencrypted", func(t *testing.T) {
+		defer clearAllDataBase()
+
+		fakePartner := createAFakePartner()
+		fakePolicy := createAFakePolicy(fakePartner.ID)
+
+		repository := policiesRepo.NewRepo(helpers.DBclient, databaseName, helpers.Cipher)
+
+		healed := fakePolicy
+		healed.Name = "healed-policy"
+
+		updated, err := repository.UpdateProviderData(ctx, &healed)
+		assert.NoError(t, err)
+		assert.True(t, updated)
+
+		reencrypted, err := repository.Reencrypt(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), reencrypted)
+
+		var stored bson.M
+		err = helpers.DBclient.Database(databaseName).
+			Collection(policiesRepo.CollectionName).
+			FindOne(ctx, bson.M{"partner_id": fakePartner.ID}).
+			Decode(&stored)
+		assert.NoError(t, err)
+		assert.EqualValues(t, 2, stored["personal_data_version"])
+
+		policy, err := repository.GetByIdAndPartnerID(ctx, fakePolicy.ID, fakePartner.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, "healed-policy", policy.Name)
+		assert.Equal(t, fakeHolderCpf, policy.Cpf)
+	})
+
+	t.Run("Should read the policies stored in plaintext and encrypt them when the keys are rotated", func(t *testing.T) {
+		defer clearAllDataBase()
+
+		fakePartner := createAFakePartner()
+		fakePolicy := createAFakePolicy(fakePartner.ID)
+
+		repository := policiesRepo.NewRepo(helpers.DBclient, databaseName, helpers.Cipher)
+
+		policies, err := repository.ListByPartnerIDAndCpf(ctx, fakePartner.ID, fakeHolderCpf)
+		assert.NoError(t, err)
+		assert.Len(t, policies, 1)
+		assert.Equal(t, fakePolicy.Name, policies[0].Name)
+
+		updated, err := repository.Reencrypt(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), updated)
+
+		var stored bson.M
+		err = helpers.DBclient.Database(databaseName).
+			Collection(policiesRepo.CollectionName).
+			FindOne(ctx, bson.M{"partner_id": fakePartner.ID}).
+			Decode(&stored)
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(stored["name"].(string), "enc:v1:"))
+		assert.Equal(t, helpers.Cipher.Hash(fakeHolderCpf), stored["cpf_hash"])
+
+		_, err = helpers.Cipher.RotateDataKeys(ctx)
+		assert.NoError(t, err)
+
+		updated, err = repository.Reencrypt(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), updated)
+
+		updated, err = repository.Reencrypt(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), updated)
+
+		policies, err = repository.ListByPartnerIDAndCpf(ctx, fakePartner.ID, fakeHolderCpf)
+		assert.NoError(t, err)
+		assert.Len(t, policies, 1)
+		assert.Equal(t, fakePolicy.Name, policies[0].Name)
+		assert.Equal(t, fakePolicy.DateOfBirth, policies[0].DateOfBirth)
+	})
+}
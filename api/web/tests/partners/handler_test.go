@@ -4,18 +4,24 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"main-api/api/web/admin"
 	partnersHandler "main-api/api/web/partners"
 	partnerDomain "main-api/internal/domain/partners"
+	outboxRepo "main-api/internal/infra/repository/outbox"
+	"main-api/internal/pkg/validator"
 	"net/http"
 	"testing"
 
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/v2/bson"
 )
 
 const (
-	PartnerPath = "/partners/"
+	PartnerPath      = "/partners/"
+	AdminPartnerPath = "/admin/partners/"
+	fakeHolderCpf    = "52998224725"
 )
 
 func TestCreatePartner(t *testing.T) {
@@ -53,11 +59,23 @@ func TestCreatePartner(t *testing.T) {
 			ID:        response.ID,
 			Name:      "180 Seguros",
 			Cnpj:      "12345678901234",
+			Language:  "pt-BR",
 			CreatedAt: response.CreatedAt,
+			UpdatedAt: response.UpdatedAt,
 		}
 
 		assert.NoError(t, err)
 		assert.EqualValues(t, expectedResponse, response)
+
+		var event bson.M
+		err = helpers.DBclient.Database(databaseName).
+			Collection(outboxRepo.CollectionName).
+			FindOne(*helpers.ctx, bson.M{"aggregate_id": response.ID}).
+			Decode(&event)
+
+		assert.NoError(t, err)
+		assert.Equal(t, string(partnerDomain.EventPartnerCreated), event["type"])
+		assert.Nil(t, event["published_at"])
 	})
 
 	t.Run("Not should create a partner when have an invalid payload", func(t *testing.T) {
@@ -103,6 +121,250 @@ func TestCreatePartner(t *testing.T) {
 
 		assert.NoError(t, err)
 		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+
+		events, err := helpers.DBclient.Database(databaseName).
+			Collection(outboxRepo.CollectionName).
+			CountDocuments(*helpers.ctx, bson.M{})
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), events, "the rejected partner doesn't store an event")
+	})
+}
+
+func TestUpdatePartner(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	_, server, cleanUp, clearAllDataBase := testContext(ctrl)
+	defer cleanUp()
+
+	t.Run("Should update sucessfuly a partner and return", func(t *testing.T) {
+		defer clearAllDataBase()
+
+		fakePartner := createAFakePartner()
+
+		payload := map[string]interface{}{
+			"name":     "181 Seguros",
+			"language": "pt-BR",
+		}
+
+		jsonData, err := json.Marshal(payload)
+		assert.NoError(t, err)
+
+		req, _ := http.NewRequest(http.MethodPut, AdminPartnerPath+fakePartner.ID, bytes.NewReader(jsonData))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+
+		resp, err := server.Test(req, -1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		defer resp.Body.Close()
+
+		var response admin.PartnerResponseData
+		err = json.NewDecoder(resp.Body).Decode(&response)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fakePartner.ID, response.ID)
+		assert.Equal(t, "181 Seguros", response.Name)
+		assert.Equal(t, fakePartner.Cnpj, response.Cnpj)
+		assert.Equal(t, "pt-BR", response.Language)
+	})
+
+	t.Run("Not should update a partner that not exists", func(t *testing.T) {
+		jsonData, err := json.Marshal(map[string]interface{}{"name": "181 Seguros"})
+		assert.NoError(t, err)
+
+		req, _ := http.NewRequest(http.MethodPut, AdminPartnerPath+bson.NewObjectID().Hex(), bytes.NewReader(jsonData))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+
+		resp, err := server.Test(req, -1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("Not should update a partner without the admin token", func(t *testing.T) {
+		defer clearAllDataBase()
+
+		fakePartner := createAFakePartner()
+
+		jsonData, err := json.Marshal(map[string]interface{}{"name": "181 Seguros"})
+		assert.NoError(t, err)
+
+		req, _ := http.NewRequest(http.MethodPut, AdminPartnerPath+fakePartner.ID, bytes.NewReader(jsonData))
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := server.Test(req, -1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+}
+
+func TestUpdatePartnerLimits(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	_, server, cleanUp, clearAllDataBase := testContext(ctrl)
+	defer cleanUp()
+
+	limitsPath := func(partnerID string) string {
+		return fmt.Sprintf("%s%s/limits", AdminPartnerPath, partnerID)
+	}
+
+	t.Run("Should override the limits of a partner", func(t *testing.T) {
+		defer clearAllDataBase()
+
+		fakePartner := createAFakePartner()
+
+		jsonData, err := json.Marshal(map[string]interface{}{"daily_quotes": 10})
+		assert.NoError(t, err)
+
+		req, _ := http.NewRequest(http.MethodPut, limitsPath(fakePartner.ID), bytes.NewReader(jsonData))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+
+		resp, err := server.Test(req, -1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		defer resp.Body.Close()
+
+		var response admin.PartnerResponseData
+		err = json.NewDecoder(resp.Body).Decode(&response)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fakePartner.ID, response.ID)
+		assert.Equal(t, 10, response.Limits.DailyQuotes)
+	})
+
+	t.Run("Not should override the limits without the admin token", func(t *testing.T) {
+		defer clearAllDataBase()
+
+		fakePartner := createAFakePartner()
+
+		jsonData, err := json.Marshal(map[string]interface{}{"daily_quotes": 10})
+		assert.NoError(t, err)
+
+		req, _ := http.NewRequest(http.MethodPut, limitsPath(fakePartner.ID), bytes.NewReader(jsonData))
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := server.Test(req, -1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("Not should take limits sent when creating a partner", func(t *testing.T) {
+		defer clearAllDataBase()
+
+		jsonData, err := json.Marshal(map[string]interface{}{
+			"name":   "180 Seguros",
+			"cnpj":   "12345678901234",
+			"limits": map[string]interface{}{"daily_quotes": 1000000},
+		})
+		assert.NoError(t, err)
+
+		req, _ := http.NewRequest(http.MethodPost, PartnerPath, bytes.NewReader(jsonData))
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := server.Test(req, -1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		defer resp.Body.Close()
+
+		var response partnersHandler.CreatePartnerResponseData
+		err = json.NewDecoder(resp.Body).Decode(&response)
+
+		assert.NoError(t, err)
+		assert.Zero(t, response.Limits.DailyQuotes)
+	})
+}
+
+func TestSuspendPartner(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	_, server, cleanUp, clearAllDataBase := testContext(ctrl)
+	defer cleanUp()
+
+	t.Run("Should suspend a partner and reject its new quotes", func(t *testing.T) {
+		defer clearAllDataBase()
+
+		fakePartner := createAFakePartner()
+
+		req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("%s%s/suspend", AdminPartnerPath, fakePartner.ID), nil)
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+
+		resp, err := server.Test(req, -1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var response admin.PartnerResponseData
+		err = json.NewDecoder(resp.Body).Decode(&response)
+		resp.Body.Close()
+
+		assert.NoError(t, err)
+		assert.True(t, response.Suspended)
+
+		jsonData, err := json.Marshal(map[string]interface{}{"age": 10, "sex": "M"})
+		assert.NoError(t, err)
+
+		req, _ = http.NewRequest(http.MethodPost, fmt.Sprintf("%s%s/quotes", PartnerPath, fakePartner.ID), bytes.NewReader(jsonData))
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err = server.Test(req, -1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("Should unsuspend a partner", func(t *testing.T) {
+		defer clearAllDataBase()
+
+		fakePartner := createAFakePartner()
+
+		for _, action := range []string{"suspend", "unsuspend"} {
+			req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("%s%s/%s", AdminPartnerPath, fakePartner.ID, action), nil)
+			req.Header.Set("Authorization", "Bearer "+adminToken)
+
+			resp, err := server.Test(req, -1)
+
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+			var response admin.PartnerResponseData
+			err = json.NewDecoder(resp.Body).Decode(&response)
+			resp.Body.Close()
+
+			assert.NoError(t, err)
+			assert.Equal(t, action == "suspend", response.Suspended)
+		}
+
+		partner, err := helpers.Service.GetPartner(*helpers.ctx, fakePartner.ID)
+
+		assert.NoError(t, err)
+		assert.False(t, partner.Suspended)
+	})
+
+	t.Run("Not should suspend a partner without the admin token", func(t *testing.T) {
+		defer clearAllDataBase()
+
+		fakePartner := createAFakePartner()
+
+		req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("%s%s/suspend", AdminPartnerPath, fakePartner.ID), nil)
+
+		resp, err := server.Test(req, -1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
 	})
 }
 
@@ -223,6 +485,7 @@ func TestCreatePolicy(t *testing.T) {
 		defer clearAllDataBase()
 
 		fakePartner := createAFakePartner()
+		createAFakeQuote(fakePartner.ID, fakeInsuranceCreatePolicy.QuotationID, fakeInsuranceCreatePolicy.DateOfBirth)
 		setResponseInsurancePolicy(fakeInsuranceCreatePolicy)
 
 		payload := map[string]interface{}{
@@ -230,6 +493,7 @@ func TestCreatePolicy(t *testing.T) {
 			"name":          fakeInsuranceCreatePolicy.Name,
 			"sex":           fakeInsuranceCreatePolicy.Sex,
 			"date_of_birth": fakeInsuranceCreatePolicy.DateOfBirth,
+			"cpf":           fakeHolderCpf,
 		}
 
 		jsonData, err := json.Marshal(payload)
@@ -288,6 +552,7 @@ func TestCreatePolicy(t *testing.T) {
 			"name":          fakeInsuranceCreatePolicy.Name,
 			"sex":           fakeInsuranceCreatePolicy.Sex,
 			"date_of_birth": fakeInsuranceCreatePolicy.DateOfBirth,
+			"cpf":           fakeHolderCpf,
 		}
 
 		jsonData, err := json.Marshal(payload)
@@ -311,9 +576,11 @@ func TestCreatePolicy(t *testing.T) {
 			"name":          fakeInsuranceCreatePolicy.Name,
 			"sex":           fakeInsuranceCreatePolicy.Sex,
 			"date_of_birth": fakeInsuranceCreatePolicy.DateOfBirth,
+			"cpf":           fakeHolderCpf,
 		}
 
 		fakePartner := createAFakePartner()
+		createAFakeQuote(fakePartner.ID, fakeInsuranceCreatePolicy.QuotationID, fakeInsuranceCreatePolicy.DateOfBirth)
 		setResponseInsurancePolicyError()
 
 		jsonData, err := json.Marshal(payload)
@@ -329,6 +596,62 @@ func TestCreatePolicy(t *testing.T) {
 		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
 	})
 
+	t.Run("Not should create a policy when date of birth doesn't match the quotation age", func(t *testing.T) {
+		defer clearAllDataBase()
+
+		fakePartner := createAFakePartner()
+		createAFakeQuote(fakePartner.ID, fakeInsuranceCreatePolicy.QuotationID, fakeInsuranceCreatePolicy.DateOfBirth)
+
+		payload := map[string]interface{}{
+			"quotation_id":  fakeInsuranceCreatePolicy.QuotationID,
+			"name":          fakeInsuranceCreatePolicy.Name,
+			"sex":           fakeInsuranceCreatePolicy.Sex,
+			"date_of_birth": "1970-01-01",
+			"cpf":           fakeHolderCpf,
+		}
+
+		jsonData, err := json.Marshal(payload)
+		assert.NoError(t, err)
+
+		path := fmt.Sprintf("%s%s/policies", PartnerPath, fakePartner.ID)
+
+		req, _ := http.NewRequest(http.MethodPost, path, bytes.NewReader(jsonData))
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := server.Test(req, -1)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("Not should create a policy when beneficiaries percentages not add up to 100", func(t *testing.T) {
+		defer clearAllDataBase()
+
+		fakePartner := createAFakePartner()
+
+		payload := map[string]interface{}{
+			"quotation_id":  fakeInsuranceCreatePolicy.QuotationID,
+			"name":          fakeInsuranceCreatePolicy.Name,
+			"sex":           fakeInsuranceCreatePolicy.Sex,
+			"date_of_birth": fakeInsuranceCreatePolicy.DateOfBirth,
+			"cpf":           fakeHolderCpf,
+			"beneficiaries": []map[string]interface{}{
+				{"name": "beneficiary-one", "relationship": "spouse", "percentage": 50},
+				{"name": "beneficiary-two", "relationship": "child", "percentage": 30},
+			},
+		}
+
+		jsonData, err := json.Marshal(payload)
+		assert.NoError(t, err)
+
+		path := fmt.Sprintf("%s%s/policies", PartnerPath, fakePartner.ID)
+
+		req, _ := http.NewRequest(http.MethodPost, path, bytes.NewReader(jsonData))
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := server.Test(req, -1)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
 }
 
 func TestGetPolicy(t *testing.T) {
@@ -392,4 +715,143 @@ func TestGetPolicy(t *testing.T) {
 
 		defer resp.Body.Close()
 	})
+
+	t.Run("Should return not found when the policy id is not a valid id", func(t *testing.T) {
+		defer clearAllDataBase()
+
+		fakePartner := createAFakePartner()
+
+		path := fmt.Sprintf("%s%s/policies/%s", PartnerPath, fakePartner.ID, "invalid-id")
+
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+
+		resp, err := server.Test(req, -1)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+		defer resp.Body.Close()
+	})
+
+	t.Run("Should return not found when the partner id is not a valid id", func(t *testing.T) {
+		path := fmt.Sprintf("%s%s/policies/%s", PartnerPath, "invalid-id", bson.NewObjectID().Hex())
+
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+
+		resp, err := server.Test(req, -1)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+		defer resp.Body.Close()
+	})
+}
+
+func TestListPolicies(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	_, server, cleanUp, clearAllDataBase := testContext(ctrl)
+	defer cleanUp()
+
+	t.Run("Should return all policies of a holder by cpf", func(t *testing.T) {
+		defer clearAllDataBase()
+
+		fakePartner := createAFakePartner()
+		createAFakePolicy(fakePartner.ID)
+		createAFakePolicy(fakePartner.ID)
+		createAFakePolicy(uuid.NewString())
+
+		path := fmt.Sprintf("%s%s/policies?cpf=%s", PartnerPath, fakePartner.ID, "529.982.247-25")
+
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+
+		resp, err := server.Test(req, -1)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		defer resp.Body.Close()
+
+		var response []partnersHandler.CreatePolicyResponseData
+		err = json.NewDecoder(resp.Body).Decode(&response)
+		assert.NoError(t, err)
+
+		assert.Len(t, response, 2)
+		assert.Equal(t, fakeHolderCpf, response[0].Cpf)
+	})
+
+	t.Run("Not should list policies when cpf is invalid", func(t *testing.T) {
+		fakePartner := createAFakePartner()
+
+		path := fmt.Sprintf("%s%s/policies?cpf=%s", PartnerPath, fakePartner.ID, "12345678900")
+
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+
+		resp, err := server.Test(req, -1)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+func TestLocalizedErrors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	_, server, cleanUp, clearAllDataBase := testContext(ctrl)
+	defer cleanUp()
+
+	doRequest := func(t *testing.T, path, acceptLanguage string) (*http.Response, validator.ProblemDetails) {
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		if acceptLanguage != "" {
+			req.Header.Set("Accept-Language", acceptLanguage)
+		}
+
+		resp, err := server.Test(req, -1)
+		assert.NoError(t, err)
+
+		defer resp.Body.Close()
+
+		var problem validator.ProblemDetails
+		err = json.NewDecoder(resp.Body).Decode(&problem)
+		assert.NoError(t, err)
+
+		return resp, problem
+	}
+
+	t.Run("Should answer in the language asked by the request", func(t *testing.T) {
+		defer clearAllDataBase()
+
+		fakePartner := createAFakePartner()
+		path := fmt.Sprintf("%s%s/customers/%s", PartnerPath, fakePartner.ID, bson.NewObjectID().Hex())
+
+		resp, problem := doRequest(t, path, "pt-BR,pt;q=0.9,en;q=0.8")
+
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		assert.Equal(t, "pt-BR", resp.Header.Get("Content-Language"))
+		assert.Equal(t, "cliente não encontrado", problem.Detail)
+	})
+
+	t.Run("Should answer in the partner language when the request doesn't ask for one", func(t *testing.T) {
+		defer clearAllDataBase()
+
+		fakePartner := createAFakePartner()
+		path := fmt.Sprintf("%s%s/customers/%s", PartnerPath, fakePartner.ID, bson.NewObjectID().Hex())
+
+		resp, problem := doRequest(t, path, "")
+
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		assert.Equal(t, "en", resp.Header.Get("Content-Language"))
+		assert.Equal(t, "customer not found", problem.Detail)
+	})
+
+	t.Run("Should translate validation errors", func(t *testing.T) {
+		defer clearAllDataBase()
+
+		fakePartner := createAFakePartner()
+		path := fmt.Sprintf("%s%s/policies?cpf=%s", PartnerPath, fakePartner.ID, "123")
+
+		resp, problem := doRequest(t, path, "pt-BR")
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		assert.Equal(t, "cpf", problem.Errors[0].Field)
+		assert.Equal(t, "cpf deve ser um CPF válido", problem.Errors[0].Message)
+	})
 }
@@ -0,0 +1,66 @@
+package partners_test
+
+import (
+	"errors"
+	partnerDomain "main-api/internal/domain/partners"
+	outboxRepo "main-api/internal/infra/repository/outbox"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutboxListPending(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx, _, cleanUp, clearAllDataBase := testContext(ctrl)
+	defer cleanUp()
+
+	repo := outboxRepo.NewRepo(helpers.DBclient, databaseName)
+
+	newEvent := func(aggregateID string, occurredAt time.Time) partnerDomain.Event {
+		return partnerDomain.Event{
+			ID:          uuid.NewString(),
+			Type:        partnerDomain.EventQuoteCreated,
+			AggregateID: aggregateID,
+			EntityID:    uuid.NewString(),
+			Payload:     map[string]string{},
+			OccurredAt:  occurredAt,
+		}
+	}
+
+	t.Run("Not should list the messages added to an aggregate waiting to be retried", func(t *testing.T) {
+		defer clearAllDataBase()
+
+		now := time.Now()
+		failed := newEvent("partner-01", now.Add(-time.Minute))
+		other := newEvent("partner-02", now.Add(-time.Minute))
+
+		assert.NoError(t, repo.Add(ctx, failed, other))
+
+		pending, err := repo.ListPending(ctx, now, 10)
+		assert.NoError(t, err)
+		assert.Len(t, pending, 2)
+
+		err = repo.MarkFailed(ctx, pending[0], errors.New("publisher unavailable"), now.Add(time.Hour))
+		assert.NoError(t, err)
+
+		added := newEvent("partner-01", now)
+		assert.NoError(t, repo.Add(ctx, added))
+
+		pending, err = repo.ListPending(ctx, now.Add(time.Second), 10)
+
+		assert.NoError(t, err)
+		assert.Len(t, pending, 1)
+		assert.Equal(t, other.ID, pending[0].ID)
+
+		pending, err = repo.ListPending(ctx, now.Add(2*time.Hour), 10)
+
+		assert.NoError(t, err)
+		assert.Len(t, pending, 3)
+		assert.Equal(t, []string{failed.ID, other.ID, added.ID}, []string{pending[0].ID, pending[1].ID, pending[2].ID})
+	})
+}
@@ -0,0 +1,116 @@
+package partners_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"main-api/api/web/middlewares"
+	providerHandler "main-api/api/web/provider"
+	partnerDomain "main-api/internal/domain/partners"
+	"main-api/internal/infra/http/webhook"
+	outboxRepo "main-api/internal/infra/repository/outbox"
+	policiesRepo "main-api/internal/infra/repository/policies"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const ProviderPolicyEventsPath = "/provider/events/policies"
+
+func sendProviderEvent(t *testing.T, server *fiber.App, secret string, payload map[string]interface{}) *http.Response {
+	body, err := json.Marshal(payload)
+	assert.NoError(t, err)
+
+	timestamp := time.Now().Unix()
+
+	req, _ := http.NewRequest(http.MethodPost, ProviderPolicyEventsPath, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(middlewares.ProviderTimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(middlewares.ProviderSignatureHeader, webhook.Sign(secret, timestamp, body))
+
+	resp, err := server.Test(req, -1)
+	assert.NoError(t, err)
+
+	return resp
+}
+
+func TestReceiveProviderPolicyEvent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx, server, cleanUp, clearAllDataBase := testContext(ctrl)
+	defer cleanUp()
+
+	t.Run("Should update the policy status once per event", func(t *testing.T) {
+		defer clearAllDataBase()
+
+		fakePartner := createAFakePartner()
+		fakePolicy := createAFakePolicy(fakePartner.ID)
+
+		payload := map[string]interface{}{
+			"id":          "event-01",
+			"type":        "policy.status_changed",
+			"policy_id":   fakePolicy.ProviderID.String(),
+			"status":      "cancelled",
+			"occurred_at": time.Now().Format(time.RFC3339),
+		}
+
+		resp := sendProviderEvent(t, server, providerSecret, payload)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var response providerHandler.PolicyEventResponseData
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		assert.Equal(t, string(partnerDomain.ProviderEventApplied), response.Result)
+
+		policy, err := policiesRepo.NewRepo(helpers.DBclient, databaseName, helpers.Cipher).GetByProviderID(ctx, fakePolicy.ProviderID)
+		assert.NoError(t, err)
+		assert.Equal(t, partnerDomain.PolicyStatusCancelled, policy.Status)
+
+		events, err := helpers.DBclient.Database(databaseName).
+			Collection(outboxRepo.CollectionName).
+			CountDocuments(ctx, bson.M{"aggregate_id": fakePartner.ID, "type": partnerDomain.EventPolicyStatusChanged})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), events)
+
+		resp = sendProviderEvent(t, server, providerSecret, payload)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		assert.Equal(t, string(partnerDomain.ProviderEventDuplicate), response.Result)
+
+		events, err = helpers.DBclient.Database(databaseName).
+			Collection(outboxRepo.CollectionName).
+			CountDocuments(ctx, bson.M{"aggregate_id": fakePartner.ID, "type": partnerDomain.EventPolicyStatusChanged})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), events, "the redelivered event isn't stored again")
+	})
+
+	t.Run("Not should accept an event with an invalid signature", func(t *testing.T) {
+		resp := sendProviderEvent(t, server, "other-secret", map[string]interface{}{
+			"id":          "event-02",
+			"type":        "policy.status_changed",
+			"policy_id":   "0b6b2f6e-8f47-4a7c-a0f1-5b6d4c2a9e11",
+			"status":      "cancelled",
+			"occurred_at": time.Now().Format(time.RFC3339),
+		})
+
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("Not should accept an event with an invalid payload", func(t *testing.T) {
+		resp := sendProviderEvent(t, server, providerSecret, map[string]interface{}{
+			"id":          "event-03",
+			"type":        "policy.status_changed",
+			"policy_id":   "0b6b2f6e-8f47-4a7c-a0f1-5b6d4c2a9e11",
+			"status":      "unknown",
+			"occurred_at": time.Now().Format(time.RFC3339),
+		})
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
@@ -0,0 +1,72 @@
+package partners_test
+
+import (
+	partnerDomain "main-api/internal/domain/partners"
+	policiesRepo "main-api/internal/infra/repository/policies"
+	reconciliationRepo "main-api/internal/infra/repository/reconciliation"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestReconcilePolicies(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx, _, cleanUp, clearAllDataBase := testContext(ctrl)
+	defer cleanUp()
+
+	t.Run("Should fill in the missing fields and report the mismatches", func(t *testing.T) {
+		defer clearAllDataBase()
+
+		fakePartner := createAFakePartner()
+		fakePolicy := createAFakePolicy(fakePartner.ID)
+
+		helpers.InsuranceProviderClient.EXPECT().
+			GetPolicy(gomock.Any(), fakePolicy.ProviderID.String()).
+			Return(&partnerDomain.InsuranceProviderCreatePolicyResponse{
+				ID:          fakePolicy.ProviderID,
+				QuotationID: fakePolicy.QuotationID,
+				Name:        "provider-name",
+				Sex:         string(fakePolicy.Sex),
+				DateOfBirth: fakePolicy.DateOfBirth,
+				Beneficiaries: []partnerDomain.InsuranceProviderBeneficiary{
+					{Name: "test-beneficiary", Relationship: "spouse", Percentage: 100},
+				},
+			}, nil)
+
+		result, err := helpers.Service.ReconcilePolicies(ctx)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), result.Checked)
+		assert.Equal(t, int64(2), result.Mismatches)
+		assert.Equal(t, int64(1), result.Healed)
+
+		objectID, _ := bson.ObjectIDFromHex(fakePolicy.ID)
+
+		var policy bson.M
+		err = helpers.DBclient.Database(databaseName).
+			Collection(policiesRepo.CollectionName).
+			FindOne(ctx, bson.M{"_id": objectID}).
+			Decode(&policy)
+		assert.NoError(t, err)
+		assert.Len(t, policy["beneficiaries"], 1)
+		assert.Equal(t, fakePolicy.Name, policy["name"])
+
+		var reports []bson.M
+		cursor, err := helpers.DBclient.Database(databaseName).
+			Collection(reconciliationRepo.CollectionName).
+			Find(ctx, bson.M{"run_id": result.RunID, "policy_id": fakePolicy.ID})
+		assert.NoError(t, err)
+		assert.NoError(t, cursor.All(ctx, &reports))
+
+		fields := make(map[string]bool)
+		for _, report := range reports {
+			fields[report["field"].(string)] = report["healed"].(bool)
+		}
+
+		assert.Equal(t, map[string]bool{"name": false, "beneficiaries": true}, fields)
+	})
+}
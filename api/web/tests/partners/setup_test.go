@@ -2,19 +2,40 @@ package partners_test
 
 import (
 	"context"
+	"crypto/rand"
+	"main-api/api/web/admin"
+	"main-api/api/web/middlewares"
 	partnersHandler "main-api/api/web/partners"
+	providerHandler "main-api/api/web/provider"
 	tests_test "main-api/api/web/tests"
 	partnersDomain "main-api/internal/domain/partners"
+	"main-api/internal/infra/cache"
+	"main-api/internal/infra/encryption"
+	"main-api/internal/infra/http/webhook"
+	"main-api/internal/infra/outbox"
+	"main-api/internal/infra/ratelimit"
+	auditRepo "main-api/internal/infra/repository/audit"
+	customersRepo "main-api/internal/infra/repository/customers"
+	dataKeysRepo "main-api/internal/infra/repository/datakeys"
+	issuancesRepo "main-api/internal/infra/repository/issuances"
 	mocks "main-api/internal/infra/repository/mocks"
+	outboxRepo "main-api/internal/infra/repository/outbox"
 	partnersRepo "main-api/internal/infra/repository/partners"
 	policiesRepo "main-api/internal/infra/repository/policies"
+	providerEventsRepo "main-api/internal/infra/repository/providerevents"
 	quotesRepo "main-api/internal/infra/repository/quotes"
+	reconciliationRepo "main-api/internal/infra/repository/reconciliation"
+	"main-api/internal/infra/repository/transaction"
+	webhooksRepo "main-api/internal/infra/repository/webhooks"
 
 	"time"
 
+	"github.com/alicebob/miniredis/v2"
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
 )
@@ -23,18 +44,25 @@ type (
 	testHelpers struct {
 		ctx                     *context.Context
 		DBclient                *mongo.Client
+		Cipher                  *encryption.Cipher
 		InsuranceProviderClient *mocks.MockInsuranceProvider
+		Service                 partnersDomain.Service
+		// Relay publishes the outbox to the webhooks, as the background job does.
+		Relay *outbox.Relay
 	}
 )
 
 var (
 	helpers      *testHelpers
 	databaseName = "test-DB"
+	// providerSecret signs the requests of the insurance provider.
+	providerSecret = "provider-secret"
+	adminToken     = "admin-token"
+	adminName      = "ops-admin"
 )
 
 func testContext(ctrlGoMock *gomock.Controller) (context.Context, *fiber.App, func(), func()) {
 	ctx := context.TODO()
-	app := fiber.New()
 
 	mongoDBConnection, closeDbConnection, clearAllDataBase := tests_test.ConnectionToDB(
 		ctx,
@@ -42,37 +70,159 @@ func testContext(ctrlGoMock *gomock.Controller) (context.Context, *fiber.App, fu
 	)
 
 	partnersRepository := partnersRepo.NewRepo(mongoDBConnection, databaseName)
+	app := fiber.New(fiber.Config{
+		ErrorHandler: partnersHandler.ErrorHandler,
+	})
+	app.Use(requestid.New())
+
+	dataKeysRepository := dataKeysRepo.NewRepo(mongoDBConnection, databaseName)
+	if err := dataKeysRepository.CreateIndexes(ctx); err != nil {
+		panic("failed to create data keys indexes")
+	}
+
+	cipher, err := encryption.NewCipher(
+		dataKeysRepository,
+		map[string][]byte{"test-master": randomKey()},
+		"test-master",
+		randomKey(),
+	)
+	if err != nil {
+		panic("failed to create cipher")
+	}
+
 	quotesRepository := quotesRepo.NewRepo(mongoDBConnection, databaseName)
-	policiesRepository := policiesRepo.NewRepo(mongoDBConnection, databaseName)
+	if err := quotesRepository.CreateIndexes(ctx); err != nil {
+		panic("failed to create quotes indexes")
+	}
+
+	policiesRepository := policiesRepo.NewRepo(mongoDBConnection, databaseName, cipher)
+	if err := policiesRepository.CreateIndexes(ctx); err != nil {
+		panic("failed to create policies indexes")
+	}
+
+	customersRepository := customersRepo.NewRepo(mongoDBConnection, databaseName, cipher)
+	if err := customersRepository.CreateIndexes(ctx); err != nil {
+		panic("failed to create customers indexes")
+	}
+
+	outboxRepository := outboxRepo.NewRepo(mongoDBConnection, databaseName)
+	if err := outboxRepository.CreateIndexes(ctx, time.Hour); err != nil {
+		panic("failed to create outbox indexes")
+	}
+
+	webhooksRepository := webhooksRepo.NewRepo(mongoDBConnection, databaseName)
+	if err := webhooksRepository.CreateIndexes(ctx); err != nil {
+		panic("failed to create webhooks indexes")
+	}
+
+	webhookDeliveriesRepository := webhooksRepo.NewDeliveriesRepo(mongoDBConnection, databaseName)
+	if err := webhookDeliveriesRepository.CreateIndexes(ctx); err != nil {
+		panic("failed to create webhook deliveries indexes")
+	}
+
+	providerEventsRepository := providerEventsRepo.NewRepo(mongoDBConnection, databaseName)
+	if err := providerEventsRepository.CreateIndexes(ctx, time.Hour); err != nil {
+		panic("failed to create provider events indexes")
+	}
+
+	reconciliationRepository := reconciliationRepo.NewRepo(mongoDBConnection, databaseName)
+	if err := reconciliationRepository.CreateIndexes(ctx, time.Hour); err != nil {
+		panic("failed to create reconciliation indexes")
+	}
+
+	issuancesRepository := issuancesRepo.NewRepo(mongoDBConnection, databaseName)
+	if err := issuancesRepository.CreateIndexes(ctx, time.Hour); err != nil {
+		panic("failed to create policy issuances indexes")
+	}
+
+	auditRepository := auditRepo.NewRepo(mongoDBConnection, databaseName, cipher)
+	if err := auditRepository.CreateIndexes(ctx); err != nil {
+		panic("failed to create audit indexes")
+	}
+
 	insuranceProviderClient := mocks.NewMockInsuranceProvider(ctrlGoMock)
 
 	partnersService := partnersDomain.NewService(partnersDomain.ServiceParams{
 		PartnerRepo:             partnersRepository,
 		QuoteRepo:               quotesRepository,
 		PolicyRepo:              policiesRepository,
+		CustomerRepo:            customersRepository,
+		WebhookRepo:             webhooksRepository,
+		WebhookDeliveryRepo:     webhookDeliveriesRepository,
+		WebhookSender:           webhook.NewSender(time.Second, true),
+		ProviderEventRepo:       providerEventsRepository,
+		PolicyMismatchRepo:      reconciliationRepository,
+		PolicyIssuanceRepo:      issuancesRepository,
+		AuditRepo:               auditRepository,
+		Outbox:                  outboxRepository,
+		Transactor:              transaction.NewMongoTransactor(mongoDBConnection),
 		InsuranceClientProvider: insuranceProviderClient,
 	})
 
-	partnersHandler.NewHTTPHandler(app, partnersService)
+	testRedis, err := miniredis.Run()
+	if err != nil {
+		panic("failed to start redis")
+	}
+
+	redisClient := redis.NewClient(&redis.Options{Addr: testRedis.Addr()})
+
+	partnersHandler.NewHTTPHandler(partnersHandler.HTTPHandlerParams{
+		App:            app,
+		Service:        partnersService,
+		CacheStore:     cache.NewRedisCacheAdapter(redisClient),
+		IdempotencyTTL: time.Hour,
+		RateLimiter:    ratelimit.NewSlidingWindow(redisClient, time.Minute),
+		QuoteQuota:     ratelimit.NewDailyQuota(redisClient, ratelimit.QuotaLocation),
+		DefaultLimits: middlewares.Limits{
+			RequestsPerWindow: 1000,
+			DailyQuotes:       1000,
+		},
+	})
+
+	providerHandler.NewHTTPHandler(providerHandler.HTTPHandlerParams{
+		App:     app,
+		Service: partnersService,
+		Secret:  providerSecret,
+	})
+
+	admin.NewHTTPHandler(admin.HTTPHandlerParams{
+		App:         app,
+		Service:     partnersService,
+		AdminTokens: map[string]string{adminName: adminToken},
+	})
 
 	clearEnviroment := func() {
 		closeDbConnection()
+		testRedis.Close()
 		app.Shutdown()
 	}
 
 	helpers = &testHelpers{
 		DBclient:                mongoDBConnection,
+		Cipher:                  cipher,
 		ctx:                     &ctx,
 		InsuranceProviderClient: insuranceProviderClient,
+		Service:                 partnersService,
+		Relay:                   outbox.NewRelay(outboxRepository, outbox.NewWebhookPublisher(partnersService), 100),
 	}
 
 	return ctx, app, clearEnviroment, clearAllDataBase
 }
 
+func randomKey() []byte {
+	key := make([]byte, encryption.KeySize)
+	if _, err := rand.Read(key); err != nil {
+		panic("failed to generate key")
+	}
+
+	return key
+}
+
 func createAFakePartner() partnersDomain.PartnerEntity {
 	entity := partnersDomain.PartnerEntity{
 		Name:      "test-patner",
 		Cnpj:      "69766865000160",
+		Language:  "en",
 		CreatedAt: time.Now(),
 	}
 
@@ -97,6 +247,7 @@ func createAFakePolicy(partnerID string) partnersDomain.PolicyEntity {
 		Sex:         "M",
 		Name:        "test-policy",
 		DateOfBirth: "1998-09-28",
+		Cpf:         fakeHolderCpf,
 		PartnerID:   partnerID,
 	}
 
@@ -109,6 +260,7 @@ func createAFakePolicy(partnerID string) partnersDomain.PolicyEntity {
 			"name":          entity.Name,
 			"sex":           entity.Sex,
 			"date_of_birth": entity.DateOfBirth,
+			"cpf":           entity.Cpf,
 		})
 	if err != nil {
 		panic("failed to create partner")
@@ -122,6 +274,49 @@ func createAFakePolicy(partnerID string) partnersDomain.PolicyEntity {
 
 }
 
+func createAFakeQuote(partnerID string, providerID uuid.UUID, dateOfBirth string) partnersDomain.QuoteEntity {
+	parsedDateOfBirth, err := time.Parse("2006-01-02", dateOfBirth)
+	if err != nil {
+		panic("invalid date of birth for quote")
+	}
+
+	entity := partnersDomain.QuoteEntity{
+		ProviderID: providerID,
+		PartnerID:  partnerID,
+		Age:        uint(partnersDomain.AgeAt(parsedDateOfBirth, time.Now())),
+		Sex:        "F",
+		Price:      130.99,
+		ExpiresAt:  time.Now().Add(24 * time.Hour),
+		CreatedAt:  time.Now(),
+	}
+
+	err = quotesRepo.NewRepo(helpers.DBclient, databaseName).Create(*helpers.ctx, &entity)
+	if err != nil {
+		panic("failed to create quote")
+	}
+
+	return entity
+}
+
+func createAFakeCustomer(partnerID string) partnersDomain.CustomerEntity {
+	entity := partnersDomain.NewCustomerEntity(
+		partnerID,
+		"test-customer",
+		"F",
+		"1998-09-28",
+		fakeHolderCpf,
+		"customer@test.com",
+		"+5511999998888",
+	)
+
+	err := customersRepo.NewRepo(helpers.DBclient, databaseName, helpers.Cipher).Create(*helpers.ctx, entity)
+	if err != nil {
+		panic("failed to create customer")
+	}
+
+	return *entity
+}
+
 func setResponseInsuranceQuotation(
 	dataReturn partnersDomain.InsuranceProviderCreateQuotationResponse,
 ) {
@@ -0,0 +1,288 @@
+package partners_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	partnersHandler "main-api/api/web/partners"
+	partnerDomain "main-api/internal/domain/partners"
+	"main-api/internal/infra/http/webhook"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type (
+	webhookReceiver struct {
+		*httptest.Server
+		mu       sync.Mutex
+		requests []receivedWebhook
+		// failures is how many of the next requests are answered with 500.
+		failures atomic.Int32
+	}
+
+	receivedWebhook struct {
+		Header http.Header
+		Body   []byte
+	}
+)
+
+func newWebhookReceiver() *webhookReceiver {
+	receiver := &webhookReceiver{}
+	receiver.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		receiver.mu.Lock()
+		receiver.requests = append(receiver.requests, receivedWebhook{Header: r.Header, Body: body})
+		receiver.mu.Unlock()
+
+		if receiver.failures.Add(-1) >= 0 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	return receiver
+}
+
+func (r *webhookReceiver) received() []receivedWebhook {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]receivedWebhook(nil), r.requests...)
+}
+
+func registerWebhook(t *testing.T, server *fiber.App, partnerID, url string, eventTypes ...string) *http.Response {
+	jsonData, err := json.Marshal(map[string]interface{}{
+		"url":         url,
+		"event_types": eventTypes,
+	})
+	assert.NoError(t, err)
+
+	path := fmt.Sprintf("%s%s/webhooks", PartnerPath, partnerID)
+
+	req, _ := http.NewRequest(http.MethodPost, path, bytes.NewReader(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := server.Test(req, -1)
+	assert.NoError(t, err)
+
+	return resp
+}
+
+func requestQuote(t *testing.T, server *fiber.App, partnerID string) {
+	setResponseInsuranceQuotation(partnerDomain.InsuranceProviderCreateQuotationResponse{
+		ProviderID: uuid.New(),
+		Age:        10,
+		Price:      130.99,
+		Sex:        "M",
+		ExpiresAt:  "2999-03-24",
+	})
+
+	jsonData, err := json.Marshal(map[string]interface{}{"age": 10, "sex": "M"})
+	assert.NoError(t, err)
+
+	path := fmt.Sprintf("%s%s/quotes", PartnerPath, partnerID)
+
+	req, _ := http.NewRequest(http.MethodPost, path, bytes.NewReader(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := server.Test(req, -1)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func listWebhookDeliveries(t *testing.T, server *fiber.App, partnerID, status string) []partnersHandler.WebhookDeliveryResponseData {
+	path := fmt.Sprintf("%s%s/webhooks/deliveries?status=%s", PartnerPath, partnerID, status)
+
+	req, _ := http.NewRequest(http.MethodGet, path, nil)
+
+	resp, err := server.Test(req, -1)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	defer resp.Body.Close()
+
+	var response []partnersHandler.WebhookDeliveryResponseData
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+
+	return response
+}
+
+func TestWebhooks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx, server, cleanUp, clearAllDataBase := testContext(ctrl)
+	defer cleanUp()
+
+	t.Run("Should register, list and delete the webhooks of a partner", func(t *testing.T) {
+		defer clearAllDataBase()
+
+		fakePartner := createAFakePartner()
+
+		resp := registerWebhook(t, server, fakePartner.ID, "https://partner.test/hooks", "policy.issued")
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		var webhookCreated partnersHandler.WebhookResponseData
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&webhookCreated))
+		assert.Equal(t, []string{"policy.issued"}, webhookCreated.EventTypes)
+		assert.True(t, strings.HasPrefix(webhookCreated.Secret, "whsec_"), "the first webhook returns the secret")
+
+		resp = registerWebhook(t, server, fakePartner.ID, "https://partner.test/other-hooks", "quote.created")
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		var otherWebhook partnersHandler.WebhookResponseData
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&otherWebhook))
+		assert.Empty(t, otherWebhook.Secret, "the secret is returned only once")
+
+		webhookCreated.Secret = ""
+
+		path := fmt.Sprintf("%s%s/webhooks", PartnerPath, fakePartner.ID)
+
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		resp, err := server.Test(req, -1)
+		assert.NoError(t, err)
+
+		var webhooks []partnersHandler.WebhookResponseData
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&webhooks))
+		assert.Equal(t, []partnersHandler.WebhookResponseData{webhookCreated, otherWebhook}, webhooks)
+
+		req, _ = http.NewRequest(http.MethodDelete, path+"/"+webhookCreated.ID, nil)
+		resp, err = server.Test(req, -1)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+		resp, err = server.Test(req, -1)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("Not should register a webhook with an invalid payload", func(t *testing.T) {
+		defer clearAllDataBase()
+
+		fakePartner := createAFakePartner()
+
+		resp := registerWebhook(t, server, fakePartner.ID, "not-a-url", "policy.issued")
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		resp = registerWebhook(t, server, fakePartner.ID, "ftp://partner.test/hooks", "policy.issued")
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		resp = registerWebhook(t, server, fakePartner.ID, "https://partner.test/hooks", "policy.cancelled")
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		resp = registerWebhook(t, server, fakePartner.ID, "https://partner.test/hooks")
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("Should deliver the events signed with the partner secret", func(t *testing.T) {
+		defer clearAllDataBase()
+
+		receiver := newWebhookReceiver()
+		defer receiver.Close()
+
+		fakePartner := createAFakePartner()
+
+		resp := registerWebhook(t, server, fakePartner.ID, receiver.URL, "quote.created")
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		var webhookCreated partnersHandler.WebhookResponseData
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&webhookCreated))
+
+		requestQuote(t, server, fakePartner.ID)
+
+		_, err := helpers.Relay.RelayPending(ctx)
+		assert.NoError(t, err)
+
+		result, err := helpers.Service.DeliverWebhooks(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), result.Delivered)
+
+		received := receiver.received()
+		assert.Len(t, received, 1)
+
+		timestamp, err := strconv.ParseInt(received[0].Header.Get(webhook.HeaderTimestamp), 10, 64)
+		assert.NoError(t, err)
+		assert.Equal(t, webhook.Sign(webhookCreated.Secret, timestamp, received[0].Body), received[0].Header.Get(webhook.HeaderSignature))
+		assert.Equal(t, "quote.created", received[0].Header.Get(webhook.HeaderEvent))
+
+		deliveries := listWebhookDeliveries(t, server, fakePartner.ID, "delivered")
+		assert.Len(t, deliveries, 1)
+		assert.Equal(t, received[0].Header.Get(webhook.HeaderDeliveryID), deliveries[0].ID)
+		assert.JSONEq(t, string(received[0].Body), string(deliveries[0].Payload))
+
+		_, err = helpers.Relay.RelayPending(ctx)
+		assert.NoError(t, err)
+
+		result, err = helpers.Service.DeliverWebhooks(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, partnerDomain.WebhookDeliveryResult{}, result, "the event is delivered only once")
+	})
+
+	t.Run("Should redeliver a failed delivery", func(t *testing.T) {
+		defer clearAllDataBase()
+
+		receiver := newWebhookReceiver()
+		defer receiver.Close()
+
+		receiver.failures.Store(1)
+
+		fakePartner := createAFakePartner()
+
+		resp := registerWebhook(t, server, fakePartner.ID, receiver.URL, "quote.created")
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		requestQuote(t, server, fakePartner.ID)
+
+		_, err := helpers.Relay.RelayPending(ctx)
+		assert.NoError(t, err)
+
+		result, err := helpers.Service.DeliverWebhooks(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), result.Retried)
+
+		deliveries := listWebhookDeliveries(t, server, fakePartner.ID, "pending")
+		assert.Len(t, deliveries, 1)
+		assert.Equal(t, http.StatusInternalServerError, deliveries[0].LastStatusCode)
+		assert.NotNil(t, deliveries[0].NextAttemptAt)
+
+		path := fmt.Sprintf("%s%s/webhooks/deliveries/%s/redeliver", PartnerPath, fakePartner.ID, deliveries[0].ID)
+
+		req, _ := http.NewRequest(http.MethodPost, path, nil)
+		resp, err = server.Test(req, -1)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var redelivered partnersHandler.WebhookDeliveryResponseData
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&redelivered))
+		assert.Equal(t, "delivered", redelivered.Status)
+		assert.Equal(t, 2, redelivered.Attempts)
+		assert.Len(t, receiver.received(), 2)
+	})
+
+	t.Run("Not should redeliver an unknown delivery", func(t *testing.T) {
+		defer clearAllDataBase()
+
+		fakePartner := createAFakePartner()
+
+		path := fmt.Sprintf("%s%s/webhooks/deliveries/%s/redeliver", PartnerPath, fakePartner.ID, "unknown")
+
+		req, _ := http.NewRequest(http.MethodPost, path, nil)
+		resp, err := server.Test(req, -1)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
@@ -0,0 +1,388 @@
+package main
+
+import (
+	"context"
+	"log"
+	"main-api/api/web/admin"
+	"main-api/api/web/middlewares"
+	partnersHandler "main-api/api/web/partners"
+	providerHandler "main-api/api/web/provider"
+	cacheConfig "main-api/configs/cache"
+	"main-api/configs/database"
+	"main-api/configs/envs"
+	"main-api/internal/domain/partners"
+	"main-api/internal/infra/cache"
+	"main-api/internal/infra/encryption"
+	"main-api/internal/infra/http/insurance"
+	"main-api/internal/infra/http/webhook"
+	"main-api/internal/infra/outbox"
+	"main-api/internal/infra/ratelimit"
+	"main-api/internal/infra/repository/audit"
+	"main-api/internal/infra/repository/customers"
+	"main-api/internal/infra/repository/datakeys"
+	"main-api/internal/infra/repository/issuances"
+	outboxRepo "main-api/internal/infra/repository/outbox"
+	partnersRepo "main-api/internal/infra/repository/partners"
+	"main-api/internal/infra/repository/policies"
+	"main-api/internal/infra/repository/providerevents"
+	"main-api/internal/infra/repository/quotes"
+	"main-api/internal/infra/repository/reconciliation"
+	"main-api/internal/infra/repository/transaction"
+	"main-api/internal/infra/repository/webhooks"
+	"main-api/internal/infra/scheduler"
+	"main-api/internal/jobs"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/gofiber/contrib/swagger"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+type (
+	dependencies struct {
+		mongoClient *mongo.Client
+		redisClient *redis.Client
+		cacheStore  cache.CacheStore
+		outboxRepo  *outboxRepo.Repo
+		service     *partners.Servicer
+		cipher      *encryption.Cipher
+		// reencrypters rewrite the encrypted documents with the active data
+		// keys, by the name of their collection.
+		reencrypters map[string]func(ctx context.Context) (int64, error)
+	}
+)
+
+const (
+	commandServe      = "serve"
+	commandReconcile  = "reconcile"
+	commandRotateKeys = "rotate-keys"
+
+	shutdownTimeout = 10 * time.Second
+)
+
+// main serves the API and runs the background jobs. The reconcile command
+// runs a reconciliation of the policies with the provider right away and
+// exits, for when it can't wait for the job. The rotate-keys command rotates
+// the encryption keys of the personal data.
+func main() {
+	envs.LoadEnvs()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	command := commandServe
+	if len(os.Args) > 1 {
+		command = os.Args[1]
+	}
+
+	deps := newDependencies(ctx)
+	defer deps.close()
+
+	switch command {
+	case commandServe:
+		serve(ctx, deps)
+	case commandReconcile:
+		reconcile(ctx, deps)
+	case commandRotateKeys:
+		rotateKeys(ctx, deps)
+	default:
+		log.Fatalf(
+			"Comando desconhecido: %q. Use %q, %q ou %q",
+			command, commandServe, commandReconcile, commandRotateKeys,
+		)
+	}
+}
+
+func newDependencies(ctx context.Context) *dependencies {
+	config := envs.AppConfig
+
+	mongoClient := database.InitMongoDB()
+	redisClient := cacheConfig.InitRedisStorage()
+	cacheStore := cacheConfig.InitCacheStore(ctx, redisClient)
+
+	partnersRepository := partnersRepo.NewCachedRepo(
+		partnersRepo.NewRepo(mongoClient, config.MongoDB),
+		cacheStore,
+		config.PartnerCacheTTL,
+		config.PartnerCacheNegativeTTL,
+	)
+
+	dataKeysRepository := datakeys.NewRepo(mongoClient, config.MongoDB)
+	cipher := newCipher(dataKeysRepository)
+
+	quotesRepository := quotes.NewRepo(mongoClient, config.MongoDB)
+	policiesRepository := policies.NewRepo(mongoClient, config.MongoDB, cipher)
+	customersRepository := customers.NewRepo(mongoClient, config.MongoDB, cipher)
+	outboxRepository := outboxRepo.NewRepo(mongoClient, config.MongoDB)
+	webhooksRepository := webhooks.NewRepo(mongoClient, config.MongoDB)
+	webhookDeliveriesRepository := webhooks.NewDeliveriesRepo(mongoClient, config.MongoDB)
+	providerEventsRepository := providerevents.NewRepo(mongoClient, config.MongoDB)
+	reconciliationRepository := reconciliation.NewRepo(mongoClient, config.MongoDB)
+	issuancesRepository := issuances.NewRepo(mongoClient, config.MongoDB)
+	auditRepository := audit.NewRepo(mongoClient, config.MongoDB, cipher)
+
+	indexes := map[string]func(ctx context.Context) error{
+		"quotes":   quotesRepository.CreateIndexes,
+		"policies": policiesRepository.CreateIndexes,
+		"quotes retention": func(ctx context.Context) error {
+			return quotesRepository.CreateRetentionIndex(ctx, config.QuoteRetention)
+		},
+		"customers": customersRepository.CreateIndexes,
+		"outbox": func(ctx context.Context) error {
+			return outboxRepository.CreateIndexes(ctx, config.OutboxRetention)
+		},
+		"webhooks":           webhooksRepository.CreateIndexes,
+		"webhook deliveries": webhookDeliveriesRepository.CreateIndexes,
+		"provider events": func(ctx context.Context) error {
+			return providerEventsRepository.CreateIndexes(ctx, config.ProviderEventRetention)
+		},
+		"reconciliation reports": func(ctx context.Context) error {
+			return reconciliationRepository.CreateIndexes(ctx, config.ReconciliationRetention)
+		},
+		"policy issuances": func(ctx context.Context) error {
+			return issuancesRepository.CreateIndexes(ctx, config.IssuanceRetention)
+		},
+		"audit events": auditRepository.CreateIndexes,
+		"data keys":    dataKeysRepository.CreateIndexes,
+	}
+
+	for name, createIndexes := range indexes {
+		if err := createIndexes(ctx); err != nil {
+			log.Fatalf("Erro ao criar os índices de %s: %v", name, err)
+		}
+	}
+
+	service := partners.NewService(partners.ServiceParams{
+		PartnerRepo:             partnersRepository,
+		QuoteRepo:               quotesRepository,
+		PolicyRepo:              policiesRepository,
+		CustomerRepo:            customersRepository,
+		WebhookRepo:             webhooksRepository,
+		WebhookDeliveryRepo:     webhookDeliveriesRepository,
+		WebhookSender:           webhook.NewSender(config.WebhookTimeout, config.WebhookAllowPrivateNetworks),
+		ProviderEventRepo:       providerEventsRepository,
+		PolicyMismatchRepo:      reconciliationRepository,
+		PolicyIssuanceRepo:      issuancesRepository,
+		AuditRepo:               auditRepository,
+		Outbox:                  outboxRepository,
+		Transactor:              transaction.NewMongoTransactor(mongoClient),
+		InsuranceClientProvider: newInsuranceProvider(redisClient, cacheStore),
+		AgeToleranceDays:        config.AgeToleranceDays,
+		ReuseQuotes:             config.QuoteReuseEnabled,
+	})
+
+	return &dependencies{
+		mongoClient: mongoClient,
+		redisClient: redisClient,
+		cacheStore:  cacheStore,
+		outboxRepo:  outboxRepository,
+		service:     service,
+		cipher:      cipher,
+		reencrypters: map[string]func(ctx context.Context) (int64, error){
+			customers.CollectionName: customersRepository.Reencrypt,
+			policies.CollectionName:  policiesRepository.Reencrypt,
+			audit.CollectionName:     auditRepository.Reencrypt,
+		},
+	}
+}
+
+func newCipher(store encryption.DataKeyStore) *encryption.Cipher {
+	config := envs.AppConfig
+
+	masterKeys := make(map[string][]byte, len(config.EncryptionMasterKeys))
+	for id, value := range config.EncryptionMasterKeys {
+		key, err := encryption.ParseKey(value)
+		if err != nil {
+			log.Fatalf("Erro ao carregar a chave mestra %q: %v", id, err)
+		}
+
+		masterKeys[id] = key
+	}
+
+	hashKey, err := encryption.ParseKey(config.EncryptionHashKey)
+	if err != nil {
+		log.Fatalf("Erro ao carregar a chave de hash: %v", err)
+	}
+
+	cipher, err := encryption.NewCipher(store, masterKeys, config.EncryptionMasterKeyID, hashKey)
+	if err != nil {
+		log.Fatalf("Erro ao configurar a criptografia: %v", err)
+	}
+
+	return cipher
+}
+
+func newInsuranceProvider(redisClient *redis.Client, cacheStore cache.CacheStore) partners.InsuranceProvider {
+	config := envs.AppConfig
+
+	client := insurance.NewInsuranceProviderClient(cacheStore, config.InsuranceProviderURL, config.InsuranceProvideToken)
+	client.SetTimeout(config.InsuranceProviderTimeout)
+	budgets, err := ratelimit.BudgetsFromRates(config.ProviderRateLimits)
+	if err != nil {
+		log.Fatalf("Erro ao carregar os limites do provedor de seguros: %v", err)
+	}
+
+	client.SetRateLimiter(ratelimit.NewTokenBucket(redisClient, config.ProviderRateMaxWait), budgets)
+	client.SetQuoteReusePolicy(partners.QuoteReusePolicy{
+		AllowShared: config.ProviderSharedQuotes,
+		MinValidity: config.ProviderQuoteMinValidity,
+	})
+
+	return insurance.NewCachedProvider(client, cacheStore, config.PolicyCacheTTL, config.PolicyCacheStaleTTL)
+}
+
+// idempotencyLockTTL covers the slowest request: issuing a policy may
+// authenticate, create it and cancel it when it can't be saved, each call
+// waiting for the provider budget first. The margin is for our own work.
+func idempotencyLockTTL(config envs.Config) time.Duration {
+	return 3*(config.ProviderRateMaxWait+config.InsuranceProviderTimeout) + 10*time.Second
+}
+
+func (d *dependencies) close() {
+	if err := d.mongoClient.Disconnect(context.Background()); err != nil {
+		log.Printf("Erro ao desconectar do MongoDB: %v", err)
+	}
+
+	if err := d.redisClient.Close(); err != nil {
+		log.Printf("Erro ao desconectar do Redis: %v", err)
+	}
+}
+
+func serve(ctx context.Context, deps *dependencies) {
+	config := envs.AppConfig
+
+	relay := outbox.NewRelay(
+		deps.outboxRepo,
+		outbox.MultiPublisher{
+			outbox.NewRedisStreamPublisher(deps.redisClient, config.OutboxStream, config.OutboxStreamMaxLen),
+			outbox.NewWebhookPublisher(deps.service),
+		},
+		config.OutboxBatchSize,
+	)
+
+	// The reconciliation runs at night for our partners, in their time zone,
+	// the same the daily quotas are counted in.
+	reconciliationTime, err := scheduler.ParseTimeOfDay(config.ReconciliationTime, ratelimit.QuotaLocation)
+	if err != nil {
+		log.Fatalf("Erro ao carregar o horário da conciliação: %v", err)
+	}
+
+	jobScheduler := scheduler.New(scheduler.NewRedisLocker(deps.redisClient), deps.cacheStore)
+	jobScheduler.Register(jobs.NewExpireQuotes(deps.service, config.QuoteExpiryInterval))
+	jobScheduler.Register(jobs.NewRelayOutbox(relay, config.OutboxRelayInterval))
+	jobScheduler.Register(jobs.NewDeliverWebhooks(deps.service, config.WebhookDeliveryInterval))
+	jobScheduler.Register(jobs.NewReconcilePolicies(deps.service, reconciliationTime))
+	jobScheduler.Register(jobs.NewRecoverPolicyIssuances(deps.service, config.IssuanceRecoveryInterval))
+
+	app := fiber.New(fiber.Config{
+		ErrorHandler: partnersHandler.ErrorHandler,
+	})
+
+	app.Use(requestid.New())
+	app.Use(swagger.New(swagger.Config{
+		BasePath: "/api/v1/",
+		FilePath: "./api/docs/v1/swagger.json",
+		Path:     "docs",
+	}))
+
+	partnersHandler.NewHTTPHandler(partnersHandler.HTTPHandlerParams{
+		App:                app,
+		Service:            deps.service,
+		CacheStore:         deps.cacheStore,
+		IdempotencyTTL:     config.IdempotencyKeyTTL,
+		IdempotencyLockTTL: idempotencyLockTTL(config),
+		RateLimiter:        ratelimit.NewSlidingWindow(deps.redisClient, config.RateLimitWindow),
+		QuoteQuota:         ratelimit.NewDailyQuota(deps.redisClient, ratelimit.QuotaLocation),
+		DefaultLimits: middlewares.Limits{
+			RequestsPerWindow: config.RateLimitRequests,
+			DailyQuotes:       config.DailyQuotesQuota,
+		},
+	})
+
+	providerHandler.NewHTTPHandler(providerHandler.HTTPHandlerParams{
+		App:     app,
+		Service: deps.service,
+		Secret:  config.ProviderWebhookSecret,
+	})
+
+	admin.NewHTTPHandler(admin.HTTPHandlerParams{
+		App:         app,
+		Scheduler:   jobScheduler,
+		Service:     deps.service,
+		AdminTokens: config.AdminTokens,
+	})
+
+	schedulerDone := make(chan struct{})
+	go func() {
+		defer close(schedulerDone)
+
+		jobScheduler.Run(ctx)
+	}()
+
+	go func() {
+		<-ctx.Done()
+
+		if err := app.ShutdownWithTimeout(shutdownTimeout); err != nil {
+			log.Printf("Erro ao encerrar o servidor: %v", err)
+		}
+	}()
+
+	if err := app.Listen(":3000"); err != nil {
+		log.Fatalf("Erro ao iniciar o servidor: %v", err)
+	}
+
+	<-schedulerDone
+}
+
+// reconcile runs a reconciliation outside the scheduler. It doesn't take the
+// job's lock, so it may overlap a scheduled run; both only fill in missing
+// fields, and their reports are told apart by the run ID.
+func reconcile(ctx context.Context, deps *dependencies) {
+	result, err := deps.service.ReconcilePolicies(ctx)
+
+	for _, policyErr := range result.Errors {
+		log.Printf("Erro ao conciliar: %v", policyErr)
+	}
+
+	log.Printf(
+		"Conciliação %s: %d apólices verificadas, %d divergências, %d corrigidas, %d falhas",
+		result.RunID, result.Checked, result.Mismatches, result.Healed, result.Failed,
+	)
+
+	if err != nil {
+		log.Fatalf("Erro ao conciliar as apólices: %v", err)
+	}
+}
+
+// rotateKeys wraps the data keys with the current master key, creates a new
+// data key for each partner and re-encrypts the personal data with it, along
+// with the data stored before it was encrypted. It can run while the API
+// serves: documents changed meanwhile are already saved with the new keys, and
+// a failed run can just be run again.
+func rotateKeys(ctx context.Context, deps *dependencies) {
+	rewrapped, err := deps.cipher.RewrapDataKeys(ctx)
+	if err != nil {
+		log.Fatalf("Erro ao trocar a chave mestra das chaves de dados: %v", err)
+	}
+
+	rotated, err := deps.cipher.RotateDataKeys(ctx)
+	if err != nil {
+		log.Fatalf("Erro ao rotacionar as chaves de dados: %v", err)
+	}
+
+	log.Printf("Chaves de dados: %d com a nova chave mestra, %d rotacionadas", rewrapped, rotated)
+
+	for collection, reencrypt := range deps.reencrypters {
+		updated, err := reencrypt(ctx)
+		if err != nil {
+			log.Fatalf("Erro ao criptografar novamente %s: %v", collection, err)
+		}
+
+		log.Printf("%s: %d documentos criptografados novamente", collection, updated)
+	}
+}
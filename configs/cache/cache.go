@@ -2,12 +2,19 @@ package cache
 
 import (
 	"context"
+	"expvar"
 	"log"
 	"main-api/configs/envs"
+	"main-api/internal/infra/cache"
 
 	"github.com/redis/go-redis/v9"
 )
 
+const (
+	DriverRedis  = "redis"
+	DriverMemory = "memory"
+)
+
 func InitRedisStorage() *redis.Client {
 	client := redis.NewClient(&redis.Options{
 		Addr:     envs.AppConfig.RedisURL,
@@ -22,3 +29,41 @@ func InitRedisStorage() *redis.Client {
 
 	return client
 }
+
+// InitCacheStore builds the cache chosen by CACHE_DRIVER. The memory driver only
+// moves the cached values into the process: the rate limits, the job lock and
+// the domain events still go through the redis client. The invalidations of
+// the tiered cache are received until ctx is done.
+func InitCacheStore(ctx context.Context, redisClient *redis.Client) cache.CacheStore {
+	switch envs.AppConfig.CacheDriver {
+	case DriverMemory:
+		return cache.NewMemoryCacheAdapter(envs.AppConfig.CacheMaxEntries)
+	case DriverRedis:
+		redisStore := cache.NewRedisCacheAdapter(redisClient)
+		if envs.AppConfig.CacheLocalTTL <= 0 {
+			return redisStore
+		}
+
+		tieredStore := cache.NewTieredCacheAdapter(
+			cache.NewMemoryCacheAdapter(envs.AppConfig.CacheMaxEntries),
+			redisStore,
+			envs.AppConfig.CacheLocalTTL,
+			cache.NewRedisInvalidationBus(redisClient, envs.AppConfig.CacheInvalidationChannel),
+		)
+
+		err := tieredStore.Listen(ctx)
+		if err != nil {
+			log.Fatalf("Erro ao assinar as invalidações do cache: %v", err)
+		}
+
+		expvar.Publish("cache", expvar.Func(func() any {
+			return tieredStore.Stats()
+		}))
+
+		return tieredStore
+	default:
+		log.Fatalf("Driver de cache inválido: %q", envs.AppConfig.CacheDriver)
+
+		return nil
+	}
+}
@@ -2,16 +2,56 @@ package envs
 
 import (
 	"log"
+	"time"
 
 	"github.com/kelseyhightower/envconfig"
 )
 
 type Config struct {
-	MongoURL              string `envconfig:"MONGO_URL" required:"true"`
-	MongoDB               string `envconfig:"MONGO_DATABASE" required:"true"`
-	RedisURL              string `envconfig:"REDIS_URL" required:"true"`
-	InsuranceProviderURL  string `envconfig:"INSURANCE_PROVIDER_URL" required:"true"`
-	InsuranceProvideToken string `envconfig:"INSURANCE_PROVIDER_TOKEN" required:"true"`
+	MongoURL                    string             `envconfig:"MONGO_URL" required:"true"`
+	MongoDB                     string             `envconfig:"MONGO_DATABASE" required:"true"`
+	RedisURL                    string             `envconfig:"REDIS_URL" required:"true"`
+	InsuranceProviderURL        string             `envconfig:"INSURANCE_PROVIDER_URL" required:"true"`
+	InsuranceProvideToken       string             `envconfig:"INSURANCE_PROVIDER_TOKEN" required:"true"`
+	AgeToleranceDays            int                `envconfig:"AGE_TOLERANCE_DAYS" default:"1"`
+	IdempotencyKeyTTL           time.Duration      `envconfig:"IDEMPOTENCY_KEY_TTL" default:"24h"`
+	RateLimitWindow             time.Duration      `envconfig:"RATE_LIMIT_WINDOW" default:"1m"`
+	RateLimitRequests           int                `envconfig:"RATE_LIMIT_REQUESTS" default:"120"`
+	DailyQuotesQuota            int                `envconfig:"DAILY_QUOTES_QUOTA" default:"1000"`
+	ProviderRateLimits          map[string]float64 `envconfig:"INSURANCE_PROVIDER_RATE_LIMITS" default:"create_quotation:10,create_policy:5,get_policy:20,cancel_policy:5"`
+	ProviderRateMaxWait         time.Duration      `envconfig:"INSURANCE_PROVIDER_RATE_MAX_WAIT" default:"2s"`
+	InsuranceProviderTimeout    time.Duration      `envconfig:"INSURANCE_PROVIDER_TIMEOUT" default:"10s"`
+	QuoteReuseEnabled           bool               `envconfig:"QUOTE_REUSE_ENABLED" default:"false"`
+	ProviderSharedQuotes        bool               `envconfig:"INSURANCE_PROVIDER_SHARED_QUOTATIONS" default:"false"`
+	ProviderQuoteMinValidity    time.Duration      `envconfig:"INSURANCE_PROVIDER_QUOTE_MIN_VALIDITY" default:"1h"`
+	PolicyCacheTTL              time.Duration      `envconfig:"POLICY_CACHE_TTL" default:"5m"`
+	PolicyCacheStaleTTL         time.Duration      `envconfig:"POLICY_CACHE_STALE_TTL" default:"24h"`
+	CacheDriver                 string             `envconfig:"CACHE_DRIVER" default:"redis"`
+	CacheMaxEntries             int                `envconfig:"CACHE_MAX_ENTRIES" default:"10000"`
+	CacheLocalTTL               time.Duration      `envconfig:"CACHE_LOCAL_TTL" default:"0"`
+	PartnerCacheTTL             time.Duration      `envconfig:"PARTNER_CACHE_TTL" default:"1m"`
+	PartnerCacheNegativeTTL     time.Duration      `envconfig:"PARTNER_CACHE_NEGATIVE_TTL" default:"10s"`
+	QuoteExpiryInterval         time.Duration      `envconfig:"QUOTE_EXPIRY_INTERVAL" default:"15m"`
+	QuoteRetention              time.Duration      `envconfig:"QUOTE_RETENTION" default:"720h"`
+	AdminTokens                 map[string]string  `envconfig:"ADMIN_TOKENS"`
+	CacheInvalidationChannel    string             `envconfig:"CACHE_INVALIDATION_CHANNEL" default:"cache:invalidation"`
+	OutboxRelayInterval         time.Duration      `envconfig:"OUTBOX_RELAY_INTERVAL" default:"1s"`
+	OutboxBatchSize             int                `envconfig:"OUTBOX_BATCH_SIZE" default:"100"`
+	OutboxStream                string             `envconfig:"OUTBOX_STREAM" default:"domain-events"`
+	OutboxStreamMaxLen          int64              `envconfig:"OUTBOX_STREAM_MAX_LEN" default:"100000"`
+	OutboxRetention             time.Duration      `envconfig:"OUTBOX_RETENTION" default:"168h"`
+	WebhookDeliveryInterval     time.Duration      `envconfig:"WEBHOOK_DELIVERY_INTERVAL" default:"10s"`
+	WebhookTimeout              time.Duration      `envconfig:"WEBHOOK_TIMEOUT" default:"5s"`
+	WebhookAllowPrivateNetworks bool               `envconfig:"WEBHOOK_ALLOW_PRIVATE_NETWORKS" default:"false"`
+	ProviderWebhookSecret       string             `envconfig:"INSURANCE_PROVIDER_WEBHOOK_SECRET"`
+	ProviderEventRetention      time.Duration      `envconfig:"INSURANCE_PROVIDER_EVENT_RETENTION" default:"720h"`
+	ReconciliationTime          string             `envconfig:"RECONCILIATION_TIME" default:"03:00"`
+	ReconciliationRetention     time.Duration      `envconfig:"RECONCILIATION_RETENTION" default:"2160h"`
+	IssuanceRecoveryInterval    time.Duration      `envconfig:"POLICY_ISSUANCE_RECOVERY_INTERVAL" default:"1m"`
+	IssuanceRetention           time.Duration      `envconfig:"POLICY_ISSUANCE_RETENTION" default:"720h"`
+	EncryptionMasterKeys        map[string]string  `envconfig:"ENCRYPTION_MASTER_KEYS" required:"true"`
+	EncryptionMasterKeyID       string             `envconfig:"ENCRYPTION_MASTER_KEY_ID" required:"true"`
+	EncryptionHashKey           string             `envconfig:"ENCRYPTION_HASH_KEY" required:"true"`
 }
 
 var AppConfig Config
@@ -0,0 +1,427 @@
+package partners
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type (
+	AuditActorTypeEnum string
+	AuditActionEnum    string
+
+	// AuditActor is who made a change.
+	AuditActor struct {
+		Type AuditActorTypeEnum
+		// ID identifies the actor within its type, such as the partner.
+		ID string
+	}
+
+	// AuditRequest is where a change came from. The web layer puts it in the
+	// context of each request; changes made without one are the system's.
+	AuditRequest struct {
+		Actor     AuditActor
+		RequestID string
+		SourceIP  string
+	}
+
+	// AuditEventEntity records a change to a partner's data. The entries form
+	// a hash chain in the order of their sequence: each hash covers the
+	// previous one, so changing or removing an entry breaks every hash after
+	// it.
+	AuditEventEntity struct {
+		ID         string
+		Sequence   int64
+		Actor      AuditActor
+		Action     AuditActionEnum
+		EntityType string
+		EntityID   string
+		PartnerID  string
+		// Changes is a JSON object with the value of each changed field before
+		// and after the change. The chain covers its digest, ChangesHash, so
+		// personal data can be erased from it without breaking the chain.
+		Changes      []byte
+		ChangesHash  string
+		RequestID    string
+		SourceIP     string
+		OccurredAt   time.Time
+		PreviousHash string
+		Hash         string
+	}
+
+	// AuditChainHead is the last entry appended to the chain.
+	AuditChainHead struct {
+		Sequence int64
+		Hash     string
+	}
+
+	// AuditEventFilter selects the audit events, in the order of their
+	// sequence. Empty fields don't filter.
+	AuditEventFilter struct {
+		PartnerID  string
+		EntityType string
+		EntityID   string
+		Action     AuditActionEnum
+		ActorID    string
+		From       time.Time
+		To         time.Time
+		// AfterSequence pages through the events.
+		AfterSequence int64
+		Limit         int
+	}
+
+	// AuditVerification is the result of checking the hash chain.
+	AuditVerification struct {
+		Checked int64
+		Valid   bool
+		// BrokenAt is the sequence of the first entry that doesn't verify.
+		BrokenAt int64
+		Reason   string
+	}
+
+	auditChange struct {
+		Before any `json:"before,omitempty"`
+		After  any `json:"after,omitempty"`
+	}
+
+	// auditHashData is what the hash of an entry covers, in a fixed order.
+	auditHashData struct {
+		Sequence     int64              `json:"sequence"`
+		PreviousHash string             `json:"previous_hash"`
+		ActorType    AuditActorTypeEnum `json:"actor_type"`
+		ActorID      string             `json:"actor_id"`
+		Action       AuditActionEnum    `json:"action"`
+		EntityType   string             `json:"entity_type"`
+		EntityID     string             `json:"entity_id"`
+		PartnerID    string             `json:"partner_id"`
+		ChangesHash  string             `json:"changes_hash"`
+		RequestID    string             `json:"request_id"`
+		SourceIP     string             `json:"source_ip"`
+		OccurredAt   string             `json:"occurred_at"`
+	}
+
+	auditRequestContextKey struct{}
+)
+
+const (
+	// AuditActorPartner is a partner using the API. The partner routes aren't
+	// authenticated, so the partner is the one whose data changed.
+	AuditActorPartner  AuditActorTypeEnum = "partner"
+	AuditActorAdmin    AuditActorTypeEnum = "admin"
+	AuditActorProvider AuditActorTypeEnum = "provider"
+	// AuditActorSystem is the API itself, such as its background jobs.
+	AuditActorSystem AuditActorTypeEnum = "system"
+)
+
+const (
+	AuditPartnerCreated             AuditActionEnum = "partner.created"
+	AuditPartnerUpdated             AuditActionEnum = "partner.updated"
+	AuditPartnerLimitsUpdated       AuditActionEnum = "partner.limits_updated"
+	AuditPartnerSuspended           AuditActionEnum = "partner.suspended"
+	AuditPartnerUnsuspended         AuditActionEnum = "partner.unsuspended"
+	AuditQuoteCreated               AuditActionEnum = "quote.created"
+	AuditPolicyIssued               AuditActionEnum = "policy.issued"
+	AuditPolicyStatusChanged        AuditActionEnum = "policy.status_changed"
+	AuditPolicyReconciled           AuditActionEnum = "policy.reconciled"
+	AuditPolicyExported             AuditActionEnum = "policy.exported"
+	AuditPolicyErased               AuditActionEnum = "policy.erased"
+	AuditCustomerCreated            AuditActionEnum = "customer.created"
+	AuditCustomerUpdated            AuditActionEnum = "customer.updated"
+	AuditCustomerDeleted            AuditActionEnum = "customer.deleted"
+	AuditCustomerExported           AuditActionEnum = "customer.exported"
+	AuditCustomerErased             AuditActionEnum = "customer.erased"
+	AuditWebhookRegistered          AuditActionEnum = "webhook.registered"
+	AuditWebhookDeleted             AuditActionEnum = "webhook.deleted"
+	AuditWebhookSecretRotated       AuditActionEnum = "webhook.secret_rotated"
+	AuditWebhookDeliveryRedelivered AuditActionEnum = "webhook_delivery.redelivered"
+)
+
+const auditVerifyBatchSize = 500
+
+// AuditRequestContextKey is the context key of the AuditRequest. It is
+// exported for the web layer, which sets it with fasthttp's SetUserValue.
+var AuditRequestContextKey = auditRequestContextKey{}
+
+func WithAuditRequest(ctx context.Context, request AuditRequest) context.Context {
+	return context.WithValue(ctx, AuditRequestContextKey, request)
+}
+
+func AuditRequestFromContext(ctx context.Context) AuditRequest {
+	request, ok := ctx.Value(AuditRequestContextKey).(AuditRequest)
+	if !ok {
+		return AuditRequest{Actor: AuditActor{Type: AuditActorSystem}}
+	}
+
+	return request
+}
+
+// newAuditEvent records the change from before to after, the fields of the
+// entity as returned by its auditFields. Before is nil for the entities
+// created and after for the deleted ones.
+func newAuditEvent(ctx context.Context, action AuditActionEnum, partnerID, entityID string, before, after map[string]any) (AuditEventEntity, error) {
+	request := AuditRequestFromContext(ctx)
+
+	actor := request.Actor
+	if actor.Type == AuditActorPartner && actor.ID == "" {
+		actor.ID = partnerID
+	}
+
+	changes, err := auditChanges(before, after)
+	if err != nil {
+		return AuditEventEntity{}, err
+	}
+
+	entityType, _, _ := strings.Cut(string(action), ".")
+
+	return AuditEventEntity{
+		Actor:       actor,
+		Action:      action,
+		EntityType:  entityType,
+		EntityID:    entityID,
+		PartnerID:   partnerID,
+		Changes:     changes,
+		ChangesHash: hashHex(changes),
+		RequestID:   request.RequestID,
+		SourceIP:    request.SourceIP,
+		// Mongo keeps dates in milliseconds, and the hash must survive it.
+		OccurredAt: time.Now().UTC().Truncate(time.Millisecond),
+	}, nil
+}
+
+// auditChanges builds the JSON of the fields whose value differs between
+// before and after.
+func auditChanges(before, after map[string]any) ([]byte, error) {
+	changes := make(map[string]auditChange)
+
+	for _, fields := range []map[string]any{before, after} {
+		for field := range fields {
+			if _, ok := changes[field]; ok {
+				continue
+			}
+
+			beforeValue, err := json.Marshal(before[field])
+			if err != nil {
+				return nil, err
+			}
+
+			afterValue, err := json.Marshal(after[field])
+			if err != nil {
+				return nil, err
+			}
+
+			if bytes.Equal(beforeValue, afterValue) {
+				continue
+			}
+
+			changes[field] = auditChange{Before: before[field], After: after[field]}
+		}
+	}
+
+	return json.Marshal(changes)
+}
+
+// Seal places the event in the chain, after the entry with previousHash.
+func (e *AuditEventEntity) Seal(sequence int64, previousHash string) {
+	e.Sequence = sequence
+	e.PreviousHash = previousHash
+	e.Hash = e.computeHash()
+}
+
+func (e *AuditEventEntity) computeHash() string {
+	data, _ := json.Marshal(auditHashData{
+		Sequence:     e.Sequence,
+		PreviousHash: e.PreviousHash,
+		ActorType:    e.Actor.Type,
+		ActorID:      e.Actor.ID,
+		Action:       e.Action,
+		EntityType:   e.EntityType,
+		EntityID:     e.EntityID,
+		PartnerID:    e.PartnerID,
+		ChangesHash:  e.ChangesHash,
+		RequestID:    e.RequestID,
+		SourceIP:     e.SourceIP,
+		OccurredAt:   e.OccurredAt.UTC().Format(time.RFC3339Nano),
+	})
+
+	return hashHex(data)
+}
+
+// verify checks the entry against the chain so far. Entries whose changes
+// were erased are checked by their digest alone.
+func (e *AuditEventEntity) verify(previous AuditChainHead) string {
+	switch {
+	case e.Sequence != previous.Sequence+1:
+		return fmt.Sprintf("expected sequence %d", previous.Sequence+1)
+	case e.PreviousHash != previous.Hash:
+		return "previous hash doesn't match the previous entry"
+	case e.Changes != nil && hashHex(e.Changes) != e.ChangesHash:
+		return "changes don't match their hash"
+	case e.computeHash() != e.Hash:
+		return "hash doesn't match the entry"
+	}
+
+	return ""
+}
+
+func hashHex(data []byte) string {
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:])
+}
+
+// audit appends the change to the audit log. It should run in the transaction
+// of the change, so one isn't stored without the other.
+func (s *Servicer) audit(ctx context.Context, action AuditActionEnum, partnerID, entityID string, before, after map[string]any) error {
+	event, err := newAuditEvent(ctx, action, partnerID, entityID, before, after)
+	if err != nil {
+		return err
+	}
+
+	return s.auditRepo.Append(ctx, event)
+}
+
+func (s *Servicer) ListAuditEvents(ctx context.Context, filter AuditEventFilter) ([]AuditEventEntity, error) {
+	return s.auditRepo.List(ctx, filter)
+}
+
+// VerifyAuditLog walks the hash chain from its first entry, stopping at the
+// first one that doesn't verify. The last entry must also be the head of the
+// chain, or entries were removed from its end.
+func (s *Servicer) VerifyAuditLog(ctx context.Context) (AuditVerification, error) {
+	var (
+		result   AuditVerification
+		previous AuditChainHead
+	)
+
+	head, err := s.auditRepo.Head(ctx)
+	if err != nil {
+		return result, err
+	}
+
+	for {
+		events, err := s.auditRepo.List(ctx, AuditEventFilter{
+			AfterSequence: previous.Sequence,
+			Limit:         auditVerifyBatchSize,
+		})
+		if err != nil {
+			return result, err
+		}
+
+		for _, event := range events {
+			if reason := event.verify(previous); reason != "" {
+				result.BrokenAt = previous.Sequence + 1
+				result.Reason = reason
+
+				return result, nil
+			}
+
+			result.Checked++
+			previous = AuditChainHead{Sequence: event.Sequence, Hash: event.Hash}
+		}
+
+		if len(events) < auditVerifyBatchSize {
+			break
+		}
+	}
+
+	// Entries appended while verifying are past the head read at the start.
+	if previous.Sequence < head.Sequence || (previous.Sequence == head.Sequence && previous.Hash != head.Hash) {
+		result.BrokenAt = previous.Sequence + 1
+		result.Reason = "the chain ends before its head"
+
+		return result, nil
+	}
+
+	result.Valid = true
+
+	return result, nil
+}
+
+func (e *PartnerEntity) auditFields() map[string]any {
+	return map[string]any{
+		"name":                e.Name,
+		"cnpj":                e.Cnpj,
+		"language":            e.Language,
+		"requests_per_window": e.Limits.RequestsPerWindow,
+		"daily_quotes":        e.Limits.DailyQuotes,
+		"suspended":           e.Suspended,
+	}
+}
+
+func (e *QuoteEntity) auditFields() map[string]any {
+	return map[string]any{
+		"provider_id":    e.ProviderID,
+		"age":            e.Age,
+		"sex":            e.Sex,
+		"price":          e.Price,
+		"expires_at":     e.ExpiresAt,
+		"reused_from_id": e.ReusedFromID,
+	}
+}
+
+func (e *PolicyEntity) auditFields() map[string]any {
+	return map[string]any{
+		"quotation_id":  e.QuotationID,
+		"provider_id":   e.ProviderID,
+		"customer_id":   e.CustomerID,
+		"name":          e.Name,
+		"sex":           e.Sex,
+		"date_of_birth": e.DateOfBirth,
+		"cpf":           e.Cpf,
+		"email":         e.Email,
+		"phone":         e.Phone,
+		"beneficiaries": beneficiariesAuditFields(e.Beneficiaries),
+		"dependents":    dependentsAuditFields(e.Dependents),
+		"status":        e.Status,
+	}
+}
+
+func beneficiariesAuditFields(beneficiaries []BeneficiaryEntity) []map[string]any {
+	fields := make([]map[string]any, len(beneficiaries))
+	for index, beneficiary := range beneficiaries {
+		fields[index] = map[string]any{
+			"name":         beneficiary.Name,
+			"relationship": beneficiary.Relationship,
+			"percentage":   beneficiary.Percentage,
+		}
+	}
+
+	return fields
+}
+
+func dependentsAuditFields(dependents []DependentEntity) []map[string]any {
+	fields := make([]map[string]any, len(dependents))
+	for index, dependent := range dependents {
+		fields[index] = map[string]any{
+			"name":          dependent.Name,
+			"sex":           dependent.Sex,
+			"date_of_birth": dependent.DateOfBirth,
+			"relationship":  dependent.Relationship,
+		}
+	}
+
+	return fields
+}
+
+func (e *CustomerEntity) auditFields() map[string]any {
+	return map[string]any{
+		"name":          e.Name,
+		"sex":           e.Sex,
+		"date_of_birth": e.DateOfBirth,
+		"cpf":           e.Cpf,
+		"email":         e.Email,
+		"phone":         e.Phone,
+	}
+}
+
+func (e *WebhookEntity) auditFields() map[string]any {
+	return map[string]any{
+		"url":         e.URL,
+		"event_types": e.EventTypes,
+	}
+}
@@ -0,0 +1,189 @@
+package partners_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"main-api/internal/domain/partners"
+	mocks "main-api/internal/infra/repository/mocks"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServiceAudit(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	partnersRepo := mocks.NewMockPartnerRepository(ctrl)
+	auditRepo := mocks.NewMockAuditLogRepository(ctrl)
+
+	transactor, _ := newTransactionMocks(ctrl)
+
+	service := partners.NewService(partners.ServiceParams{
+		PartnerRepo: partnersRepo,
+		Transactor:  transactor,
+		AuditRepo:   auditRepo,
+	})
+
+	t.Run("Should record who changed the partner and the fields changed", func(t *testing.T) {
+		current := &partners.PartnerEntity{ID: uuid.NewString(), Name: "180 Seguros", Cnpj: "12345678901234", Language: "pt-BR"}
+
+		partnersRepo.EXPECT().GetByID(gomock.Any(), current.ID).Return(current, nil)
+		partnersRepo.EXPECT().Update(gomock.Any(), current).Return(nil)
+		auditRepo.EXPECT().Append(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, events ...partners.AuditEventEntity) error {
+				assert.Len(t, events, 1)
+
+				event := events[0]
+				assert.Equal(t, partners.AuditActor{Type: partners.AuditActorPartner, ID: current.ID}, event.Actor)
+				assert.Equal(t, partners.AuditPartnerUpdated, event.Action)
+				assert.Equal(t, "partner", event.EntityType)
+				assert.Equal(t, current.ID, event.EntityID)
+				assert.Equal(t, "request-01", event.RequestID)
+				assert.Equal(t, "10.0.0.1", event.SourceIP)
+				assert.JSONEq(t, `{"name": {"before": "180 Seguros", "after": "181 Seguros"}}`, string(event.Changes))
+				assert.NotEmpty(t, event.ChangesHash)
+
+				return nil
+			})
+
+		ctx := partners.WithAuditRequest(t.Context(), partners.AuditRequest{
+			Actor:     partners.AuditActor{Type: partners.AuditActorPartner},
+			RequestID: "request-01",
+			SourceIP:  "10.0.0.1",
+		})
+
+		_, err := service.UpdatePartner(ctx, &partners.PartnerEntity{ID: current.ID, Name: "181 Seguros", Language: "pt-BR"})
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("Should record the system as the actor of the changes outside a request", func(t *testing.T) {
+		current := &partners.PartnerEntity{ID: uuid.NewString(), Name: "180 Seguros"}
+
+		partnersRepo.EXPECT().GetByID(gomock.Any(), current.ID).Return(current, nil)
+		partnersRepo.EXPECT().Update(gomock.Any(), current).Return(nil)
+		auditRepo.EXPECT().Append(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, events ...partners.AuditEventEntity) error {
+				assert.Equal(t, partners.AuditActor{Type: partners.AuditActorSystem}, events[0].Actor)
+				assert.JSONEq(t, `{"suspended": {"before": false, "after": true}}`, string(events[0].Changes))
+
+				return nil
+			})
+
+		_, err := service.SuspendPartner(t.Context(), current.ID)
+
+		assert.NoError(t, err)
+	})
+}
+
+func TestServiceVerifyAuditLog(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	auditRepo := mocks.NewMockAuditLogRepository(ctrl)
+
+	service := partners.NewService(partners.ServiceParams{
+		AuditRepo: auditRepo,
+	})
+
+	// newChain seals three entries the way the repository appends them.
+	newChain := func() []partners.AuditEventEntity {
+		events := make([]partners.AuditEventEntity, 3)
+		changes := []byte(`{"name":{"after":"customer-test"}}`)
+		changesHash := sha256.Sum256(changes)
+
+		var previousHash string
+		for index := range events {
+			events[index] = partners.AuditEventEntity{
+				Actor:       partners.AuditActor{Type: partners.AuditActorPartner, ID: "67e1ae8c7e7a8b2b0f1e4b11"},
+				Action:      partners.AuditCustomerCreated,
+				EntityType:  "customer",
+				EntityID:    uuid.NewString(),
+				PartnerID:   "67e1ae8c7e7a8b2b0f1e4b11",
+				Changes:     changes,
+				ChangesHash: hex.EncodeToString(changesHash[:]),
+				OccurredAt:  time.Now().UTC().Truncate(time.Millisecond),
+			}
+			events[index].Seal(int64(index+1), previousHash)
+			previousHash = events[index].Hash
+		}
+
+		return events
+	}
+
+	expectChain := func(events []partners.AuditEventEntity, head partners.AuditChainHead) {
+		auditRepo.EXPECT().Head(gomock.Any()).Return(head, nil)
+		auditRepo.EXPECT().List(gomock.Any(), partners.AuditEventFilter{AfterSequence: 0, Limit: 500}).Return(events, nil)
+	}
+
+	headOf := func(events []partners.AuditEventEntity) partners.AuditChainHead {
+		last := events[len(events)-1]
+
+		return partners.AuditChainHead{Sequence: last.Sequence, Hash: last.Hash}
+	}
+
+	t.Run("Should verify an intact chain", func(t *testing.T) {
+		events := newChain()
+		expectChain(events, headOf(events))
+
+		result, err := service.VerifyAuditLog(t.Context())
+
+		assert.NoError(t, err)
+		assert.Equal(t, partners.AuditVerification{Checked: 3, Valid: true}, result)
+	})
+
+	t.Run("Should verify a chain whose changes were erased", func(t *testing.T) {
+		events := newChain()
+		events[1].Changes = nil
+		expectChain(events, headOf(events))
+
+		result, err := service.VerifyAuditLog(t.Context())
+
+		assert.NoError(t, err)
+		assert.True(t, result.Valid)
+	})
+
+	t.Run("Not should verify a chain with a changed entry", func(t *testing.T) {
+		events := newChain()
+		events[1].Actor.ID = "67e1ae8c7e7a8b2b0f1e4b12"
+		expectChain(events, headOf(events))
+
+		result, err := service.VerifyAuditLog(t.Context())
+
+		assert.NoError(t, err)
+		assert.False(t, result.Valid)
+		assert.Equal(t, int64(1), result.Checked)
+		assert.Equal(t, int64(2), result.BrokenAt)
+	})
+
+	t.Run("Not should verify a chain with a removed entry", func(t *testing.T) {
+		events := newChain()
+		expectChain([]partners.AuditEventEntity{events[0], events[2]}, headOf(events))
+
+		result, err := service.VerifyAuditLog(t.Context())
+
+		assert.NoError(t, err)
+		assert.False(t, result.Valid)
+		assert.Equal(t, int64(2), result.BrokenAt)
+	})
+
+	t.Run("Not should verify a chain whose last entries were removed", func(t *testing.T) {
+		events := newChain()
+		expectChain(events[:2], headOf(events))
+
+		result, err := service.VerifyAuditLog(t.Context())
+
+		assert.NoError(t, err)
+		assert.False(t, result.Valid)
+		assert.Equal(t, int64(3), result.BrokenAt)
+	})
+}
@@ -0,0 +1,156 @@
+package partners
+
+import (
+	"context"
+	"time"
+)
+
+func (s *Servicer) CreateCustomer(ctx context.Context, customer *CustomerEntity) (*CustomerEntity, error) {
+	if err := customer.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := s.ensurePartnerExists(ctx, customer.PartnerID); err != nil {
+		return nil, err
+	}
+
+	customerExists, err := s.customerRepo.GetByCpfAndPartnerID(ctx, customer.Cpf, customer.PartnerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if customerExists != nil {
+		return nil, ErrCustomerAlreadyExists
+	}
+
+	err = s.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.customerRepo.Create(ctx, customer); err != nil {
+			return err
+		}
+
+		return s.audit(ctx, AuditCustomerCreated, customer.PartnerID, customer.ID, nil, customer.auditFields())
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return customer, nil
+}
+
+func (s *Servicer) GetCustomer(ctx context.Context, partnerID, customerID string) (*CustomerEntity, error) {
+	if err := s.ensurePartnerExists(ctx, partnerID); err != nil {
+		return nil, err
+	}
+
+	return s.getCustomer(ctx, partnerID, customerID)
+}
+
+func (s *Servicer) ListCustomers(ctx context.Context, partnerID string) ([]CustomerEntity, error) {
+	if err := s.ensurePartnerExists(ctx, partnerID); err != nil {
+		return nil, err
+	}
+
+	return s.customerRepo.ListByPartnerID(ctx, partnerID)
+}
+
+func (s *Servicer) UpdateCustomer(ctx context.Context, customer *CustomerEntity) (*CustomerEntity, error) {
+	if err := customer.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := s.ensurePartnerExists(ctx, customer.PartnerID); err != nil {
+		return nil, err
+	}
+
+	current, err := s.getCustomer(ctx, customer.PartnerID, customer.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if customer.Cpf != current.Cpf {
+		customerWithCpf, err := s.customerRepo.GetByCpfAndPartnerID(ctx, customer.Cpf, customer.PartnerID)
+		if err != nil {
+			return nil, err
+		}
+
+		if customerWithCpf != nil {
+			return nil, ErrCustomerAlreadyExists
+		}
+	}
+
+	customer.CreatedAt = current.CreatedAt
+	customer.UpdatedAt = time.Now()
+
+	err = s.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.customerRepo.Update(ctx, customer); err != nil {
+			return err
+		}
+
+		return s.audit(ctx, AuditCustomerUpdated, customer.PartnerID, customer.ID, current.auditFields(), customer.auditFields())
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return customer, nil
+}
+
+func (s *Servicer) DeleteCustomer(ctx context.Context, partnerID, customerID string) error {
+	if err := s.ensurePartnerExists(ctx, partnerID); err != nil {
+		return err
+	}
+
+	customer, err := s.getCustomer(ctx, partnerID, customerID)
+	if err != nil {
+		return err
+	}
+
+	return s.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.customerRepo.Delete(ctx, customerID, partnerID); err != nil {
+			return err
+		}
+
+		return s.audit(ctx, AuditCustomerDeleted, partnerID, customerID, customer.auditFields(), nil)
+	})
+}
+
+// ListCustomerPolicies returns the policies issued for the customer record and
+// the ones issued with the customer's CPF as inline holder data.
+func (s *Servicer) ListCustomerPolicies(ctx context.Context, partnerID, customerID string) ([]PolicyEntity, error) {
+	if err := s.ensurePartnerExists(ctx, partnerID); err != nil {
+		return nil, err
+	}
+
+	customer, err := s.getCustomer(ctx, partnerID, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.policyRepo.ListByPartnerIDAndHolder(ctx, partnerID, customer.ID, customer.Cpf)
+}
+
+func (s *Servicer) getCustomer(ctx context.Context, partnerID, customerID string) (*CustomerEntity, error) {
+	customer, err := s.customerRepo.GetByIDAndPartnerID(ctx, customerID, partnerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if customer == nil {
+		return nil, ErrCustomerNotFound
+	}
+
+	return customer, nil
+}
+
+func (s *Servicer) ensurePartnerExists(ctx context.Context, partnerID string) error {
+	partner, err := s.partnerRepo.GetByID(ctx, partnerID)
+	if err != nil {
+		return err
+	}
+
+	if partner == nil {
+		return ErrPartnerNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,227 @@
+package partners_test
+
+import (
+	"context"
+	"main-api/internal/domain/partners"
+	mocks "main-api/internal/infra/repository/mocks"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServiceCreateCustomer(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	partnersRepo := mocks.NewMockPartnerRepository(ctrl)
+	customersRepo := mocks.NewMockCustomersRepository(ctrl)
+
+	transactor, _ := newTransactionMocks(ctrl)
+
+	service := partners.NewService(partners.ServiceParams{
+		PartnerRepo:  partnersRepo,
+		CustomerRepo: customersRepo,
+		Transactor:   transactor,
+		AuditRepo:    newAuditLogMock(ctrl),
+	})
+
+	fakePartner := partners.PartnerEntity{
+		ID:        uuid.NewString(),
+		Name:      "partner-test",
+		Cnpj:      "12345678901234",
+		CreatedAt: time.Now(),
+	}
+
+	t.Run("Should create a customer", func(t *testing.T) {
+		customer := partners.NewCustomerEntity(fakePartner.ID, "customer-test", "f", "1998-09-28", "52998224725", "", "")
+
+		partnersRepo.EXPECT().GetByID(gomock.Any(), fakePartner.ID).Return(&fakePartner, nil)
+		customersRepo.EXPECT().GetByCpfAndPartnerID(gomock.Any(), "52998224725", fakePartner.ID).Return(nil, nil)
+		customersRepo.EXPECT().Create(gomock.Any(), customer).Return(nil)
+
+		customerCreated, err := service.CreateCustomer(t.Context(), customer)
+
+		assert.NoError(t, err)
+		assert.Equal(t, partners.SexFemale, customerCreated.Sex)
+	})
+
+	t.Run("Not should create a customer when cpf already registered", func(t *testing.T) {
+		customer := partners.NewCustomerEntity(fakePartner.ID, "customer-test", "F", "1998-09-28", "52998224725", "", "")
+
+		partnersRepo.EXPECT().GetByID(gomock.Any(), fakePartner.ID).Return(&fakePartner, nil)
+		customersRepo.EXPECT().GetByCpfAndPartnerID(gomock.Any(), gomock.Any(), gomock.Any()).Return(customer, nil)
+
+		customerCreated, err := service.CreateCustomer(t.Context(), customer)
+
+		assert.Nil(t, customerCreated)
+		assert.Equal(t, partners.ErrCustomerAlreadyExists, err)
+	})
+
+	t.Run("Not should create a customer when partner not found", func(t *testing.T) {
+		customer := partners.NewCustomerEntity(fakePartner.ID, "customer-test", "F", "1998-09-28", "52998224725", "", "")
+
+		partnersRepo.EXPECT().GetByID(gomock.Any(), gomock.Any()).Return(nil, nil)
+
+		customerCreated, err := service.CreateCustomer(t.Context(), customer)
+
+		assert.Nil(t, customerCreated)
+		assert.Equal(t, partners.ErrPartnerNotFound, err)
+	})
+}
+
+func TestServiceUpdateCustomer(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	partnersRepo := mocks.NewMockPartnerRepository(ctrl)
+	customersRepo := mocks.NewMockCustomersRepository(ctrl)
+
+	transactor, _ := newTransactionMocks(ctrl)
+
+	service := partners.NewService(partners.ServiceParams{
+		PartnerRepo:  partnersRepo,
+		CustomerRepo: customersRepo,
+		Transactor:   transactor,
+		AuditRepo:    newAuditLogMock(ctrl),
+	})
+
+	fakePartner := partners.PartnerEntity{ID: uuid.NewString()}
+	current := partners.NewCustomerEntity(fakePartner.ID, "customer-test", "F", "1998-09-28", "52998224725", "", "")
+	current.ID = uuid.NewString()
+	current.CreatedAt = time.Now().Add(-time.Hour)
+
+	t.Run("Should update a customer keeping its creation date", func(t *testing.T) {
+		updated := partners.NewCustomerEntity(fakePartner.ID, "customer-updated", "F", "1998-09-28", "52998224725", "", "")
+		updated.ID = current.ID
+
+		partnersRepo.EXPECT().GetByID(gomock.Any(), fakePartner.ID).Return(&fakePartner, nil)
+		customersRepo.EXPECT().GetByIDAndPartnerID(gomock.Any(), current.ID, fakePartner.ID).Return(current, nil)
+		customersRepo.EXPECT().Update(gomock.Any(), updated).Return(nil)
+
+		result, err := service.UpdateCustomer(t.Context(), updated)
+
+		assert.NoError(t, err)
+		assert.Equal(t, current.CreatedAt, result.CreatedAt)
+		assert.Equal(t, "customer-updated", result.Name)
+	})
+
+	t.Run("Not should update a customer to a cpf already registered", func(t *testing.T) {
+		updated := partners.NewCustomerEntity(fakePartner.ID, "customer-updated", "F", "1998-09-28", "11144477735", "", "")
+		updated.ID = current.ID
+
+		partnersRepo.EXPECT().GetByID(gomock.Any(), fakePartner.ID).Return(&fakePartner, nil)
+		customersRepo.EXPECT().GetByIDAndPartnerID(gomock.Any(), current.ID, fakePartner.ID).Return(current, nil)
+		customersRepo.EXPECT().GetByCpfAndPartnerID(gomock.Any(), "11144477735", fakePartner.ID).
+			Return(&partners.CustomerEntity{ID: uuid.NewString()}, nil)
+
+		result, err := service.UpdateCustomer(t.Context(), updated)
+
+		assert.Nil(t, result)
+		assert.Equal(t, partners.ErrCustomerAlreadyExists, err)
+	})
+
+	t.Run("Not should update a customer that not exists", func(t *testing.T) {
+		partnersRepo.EXPECT().GetByID(gomock.Any(), fakePartner.ID).Return(&fakePartner, nil)
+		customersRepo.EXPECT().GetByIDAndPartnerID(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil)
+
+		result, err := service.UpdateCustomer(t.Context(), current)
+
+		assert.Nil(t, result)
+		assert.Equal(t, partners.ErrCustomerNotFound, err)
+	})
+}
+
+func TestServiceCustomerPolicies(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	partnersRepo := mocks.NewMockPartnerRepository(ctrl)
+	customersRepo := mocks.NewMockCustomersRepository(ctrl)
+	quotesRepo := mocks.NewMockQuotesRepository(ctrl)
+	policyRepo := mocks.NewMockPoliciesRepository(ctrl)
+	insuranceProviderClient := mocks.NewMockInsuranceProvider(ctrl)
+	policyIssuancesRepo := mocks.NewMockPolicyIssuancesRepository(ctrl)
+
+	policyIssuancesRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	policyIssuancesRepo.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	transactor, outbox := newTransactionMocks(ctrl)
+
+	service := partners.NewService(partners.ServiceParams{
+		PartnerRepo:             partnersRepo,
+		CustomerRepo:            customersRepo,
+		QuoteRepo:               quotesRepo,
+		PolicyRepo:              policyRepo,
+		PolicyIssuanceRepo:      policyIssuancesRepo,
+		InsuranceClientProvider: insuranceProviderClient,
+		Transactor:              transactor,
+		Outbox:                  outbox,
+		AuditRepo:               newAuditLogMock(ctrl),
+	})
+
+	fakePartner := partners.PartnerEntity{ID: uuid.NewString()}
+	customer := partners.NewCustomerEntity(fakePartner.ID, "customer-test", "F", "1998-09-28", "52998224725", "customer@test.com", "")
+	customer.ID = uuid.NewString()
+
+	t.Run("Should create a policy using the customer as holder", func(t *testing.T) {
+		partnersRepo.EXPECT().GetByID(gomock.Any(), fakePartner.ID).Return(&fakePartner, nil)
+		customersRepo.EXPECT().GetByIDAndPartnerID(gomock.Any(), customer.ID, fakePartner.ID).Return(customer, nil)
+		quotesRepo.EXPECT().GetByProviderIDAndPartnerID(gomock.Any(), gomock.Any(), fakePartner.ID).
+			Return(&partners.QuoteEntity{Age: uint(partners.AgeAt(time.Date(1998, 9, 28, 0, 0, 0, 0, time.UTC), time.Now()))}, nil)
+		insuranceProviderClient.EXPECT().CreatePolicy(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(ctx context.Context, data partners.InsuranceProviderCreatePolicyRequest) (*partners.InsuranceProviderCreatePolicyResponse, error) {
+				assert.Equal(t, customer.Name, data.Name)
+				assert.Equal(t, customer.DateOfBirth, data.DateOfBirth)
+
+				return &partners.InsuranceProviderCreatePolicyResponse{ID: uuid.New()}, nil
+			},
+		)
+		policyRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+
+		policy, err := service.CreatePolicy(t.Context(), &partners.PolicyEntity{
+			QuotationID: uuid.New(),
+			PartnerID:   fakePartner.ID,
+			CustomerID:  customer.ID,
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, customer.ID, policy.CustomerID)
+		assert.Equal(t, customer.Cpf, policy.Cpf)
+		assert.Equal(t, customer.Email, policy.Email)
+	})
+
+	t.Run("Not should create a policy when customer not found", func(t *testing.T) {
+		partnersRepo.EXPECT().GetByID(gomock.Any(), fakePartner.ID).Return(&fakePartner, nil)
+		customersRepo.EXPECT().GetByIDAndPartnerID(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil)
+
+		policy, err := service.CreatePolicy(t.Context(), &partners.PolicyEntity{
+			QuotationID: uuid.New(),
+			PartnerID:   fakePartner.ID,
+			CustomerID:  uuid.NewString(),
+		})
+
+		assert.Nil(t, policy)
+		assert.Equal(t, partners.ErrCustomerNotFound, err)
+	})
+
+	t.Run("Should list the policies held by a customer", func(t *testing.T) {
+		partnersRepo.EXPECT().GetByID(gomock.Any(), fakePartner.ID).Return(&fakePartner, nil)
+		customersRepo.EXPECT().GetByIDAndPartnerID(gomock.Any(), customer.ID, fakePartner.ID).Return(customer, nil)
+		policyRepo.EXPECT().ListByPartnerIDAndHolder(gomock.Any(), fakePartner.ID, customer.ID, customer.Cpf).
+			Return([]partners.PolicyEntity{{ID: uuid.NewString()}, {ID: uuid.NewString()}}, nil)
+
+		policies, err := service.ListCustomerPolicies(t.Context(), fakePartner.ID, customer.ID)
+
+		assert.NoError(t, err)
+		assert.Len(t, policies, 2)
+	})
+}
@@ -0,0 +1,224 @@
+package partners
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type (
+	// DataSubjectExport is the personal data kept about a holder, the data
+	// subject of the LGPD, across every partner.
+	DataSubjectExport struct {
+		Cpf       string
+		Customers []CustomerEntity
+		Policies  []PolicyEntity
+		// AuditEvents is the history of the customers and policies, whose
+		// changes hold their past personal data.
+		AuditEvents []AuditEventEntity
+	}
+
+	// DataSubjectErasure is the result of pseudonymizing a holder's data.
+	DataSubjectErasure struct {
+		// Pseudonym replaces the holder's name and CPF in the erased records.
+		Pseudonym         string
+		Customers         int64
+		Policies          int64
+		WebhookDeliveries int64
+	}
+
+	// dataSubjectRecord is a record of the holder, as written in the audit log.
+	dataSubjectRecord struct {
+		action    AuditActionEnum
+		partnerID string
+		entityID  string
+	}
+)
+
+const dataSubjectPseudonymPrefix = "erased-"
+
+// ExportDataSubject gathers the customers and policies of the holder with the
+// CPF, soft deleted customers included, along with their audit history. The
+// export is recorded in the audit log of each record.
+func (s *Servicer) ExportDataSubject(ctx context.Context, cpf string) (*DataSubjectExport, error) {
+	customers, policies, err := s.findDataSubject(ctx, cpf)
+	if err != nil {
+		return nil, err
+	}
+
+	export := &DataSubjectExport{Cpf: cpf, Customers: customers, Policies: policies}
+
+	var events []AuditEventEntity
+	for _, record := range dataSubjectRecords(customers, policies, AuditCustomerExported, AuditPolicyExported) {
+		history, err := s.listAuditHistory(ctx, record.entityID)
+		if err != nil {
+			return nil, err
+		}
+
+		export.AuditEvents = append(export.AuditEvents, history...)
+
+		event, err := newAuditEvent(ctx, record.action, record.partnerID, record.entityID, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		events = append(events, event)
+	}
+
+	if err := s.auditRepo.Append(ctx, events...); err != nil {
+		return nil, err
+	}
+
+	return export, nil
+}
+
+// EraseDataSubject pseudonymizes the holder with the CPF: the name and CPF of
+// its customers and policies are replaced by a pseudonym and the rest of the
+// personal data is cleared, keeping what the policies need for the financial
+// and regulatory records. The changes kept in the audit log of those records
+// are erased and their webhook deliveries deleted. The customers are also
+// soft deleted. An erasure may come with ErrPolicyCacheNotInvalidated, when
+// the provider's copy of a policy is still cached.
+func (s *Servicer) EraseDataSubject(ctx context.Context, cpf string) (DataSubjectErasure, error) {
+	var (
+		result    DataSubjectErasure
+		policies  []PolicyEntity
+		pseudonym = dataSubjectPseudonymPrefix + uuid.NewString()
+	)
+
+	err := s.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		result = DataSubjectErasure{Pseudonym: pseudonym}
+
+		var (
+			customers []CustomerEntity
+			err       error
+		)
+
+		customers, policies, err = s.findDataSubject(ctx, cpf)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+
+		for index := range customers {
+			customers[index].Erase(pseudonym, now)
+
+			if err := s.customerRepo.Erase(ctx, &customers[index]); err != nil {
+				return err
+			}
+		}
+
+		for index := range policies {
+			policies[index].Erase(pseudonym, now)
+
+			if err := s.policyRepo.Erase(ctx, &policies[index]); err != nil {
+				return err
+			}
+		}
+
+		records := dataSubjectRecords(customers, policies, AuditCustomerErased, AuditPolicyErased)
+
+		entityIDs := make([]string, len(records))
+		for index, record := range records {
+			entityIDs[index] = record.entityID
+		}
+
+		if err := s.auditRepo.EraseChanges(ctx, entityIDs...); err != nil {
+			return err
+		}
+
+		result.WebhookDeliveries, err = s.webhookDeliveryRepo.DeleteByEntityIDs(ctx, entityIDs...)
+		if err != nil {
+			return err
+		}
+
+		events := make([]AuditEventEntity, len(records))
+		for index, record := range records {
+			events[index], err = newAuditEvent(ctx, record.action, record.partnerID, record.entityID, nil, map[string]any{"pseudonym": pseudonym})
+			if err != nil {
+				return err
+			}
+		}
+
+		result.Customers = int64(len(customers))
+		result.Policies = int64(len(policies))
+
+		return s.auditRepo.Append(ctx, events...)
+	})
+	if err != nil {
+		return DataSubjectErasure{}, err
+	}
+
+	var errs []error
+	for _, policy := range policies {
+		if err := s.invalidateProviderPolicy(ctx, policy.ProviderID.String()); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return result, errors.Join(errs...)
+}
+
+// findDataSubject returns the customers with the CPF and the policies issued
+// for them or with the CPF as inline holder data.
+func (s *Servicer) findDataSubject(ctx context.Context, cpf string) ([]CustomerEntity, []PolicyEntity, error) {
+	customers, err := s.customerRepo.ListByCpf(ctx, cpf)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	customerIDs := make([]string, len(customers))
+	for index, customer := range customers {
+		customerIDs[index] = customer.ID
+	}
+
+	policies, err := s.policyRepo.ListByHolder(ctx, customerIDs, cpf)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(customers) == 0 && len(policies) == 0 {
+		return nil, nil, ErrDataSubjectNotFound
+	}
+
+	return customers, policies, nil
+}
+
+func (s *Servicer) listAuditHistory(ctx context.Context, entityID string) ([]AuditEventEntity, error) {
+	var history []AuditEventEntity
+
+	filter := AuditEventFilter{EntityID: entityID, Limit: auditVerifyBatchSize}
+	for {
+		events, err := s.auditRepo.List(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+
+		history = append(history, events...)
+
+		if len(events) < filter.Limit {
+			return history, nil
+		}
+
+		filter.AfterSequence = events[len(events)-1].Sequence
+	}
+}
+
+// dataSubjectRecords lists the customers and policies as the records of the
+// audit events with the given actions.
+func dataSubjectRecords(customers []CustomerEntity, policies []PolicyEntity, customerAction, policyAction AuditActionEnum) []dataSubjectRecord {
+	records := make([]dataSubjectRecord, 0, len(customers)+len(policies))
+
+	for _, customer := range customers {
+		records = append(records, dataSubjectRecord{action: customerAction, partnerID: customer.PartnerID, entityID: customer.ID})
+	}
+
+	for _, policy := range policies {
+		records = append(records, dataSubjectRecord{action: policyAction, partnerID: policy.PartnerID, entityID: policy.ID})
+	}
+
+	return records
+}
@@ -0,0 +1,202 @@
+package partners_test
+
+import (
+	"context"
+	"main-api/internal/domain/partners"
+	mocks "main-api/internal/infra/repository/mocks"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServiceExportDataSubject(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	customersRepo := mocks.NewMockCustomersRepository(ctrl)
+	policiesRepo := mocks.NewMockPoliciesRepository(ctrl)
+	auditRepo := mocks.NewMockAuditLogRepository(ctrl)
+
+	service := partners.NewService(partners.ServiceParams{
+		CustomerRepo: customersRepo,
+		PolicyRepo:   policiesRepo,
+		AuditRepo:    auditRepo,
+	})
+
+	const cpf = "12345678909"
+
+	t.Run("Should export the holder's customers, policies and their audit history", func(t *testing.T) {
+		customer := partners.CustomerEntity{ID: "customer-01", PartnerID: "partner-01", Name: "customer-test", Cpf: cpf, DeletedAt: time.Now()}
+		policy := partners.PolicyEntity{ID: "policy-01", PartnerID: "partner-02", Name: "customer-test", Cpf: cpf}
+		history := []partners.AuditEventEntity{{Sequence: 4, Action: partners.AuditCustomerCreated, EntityID: customer.ID}}
+
+		customersRepo.EXPECT().ListByCpf(gomock.Any(), cpf).Return([]partners.CustomerEntity{customer}, nil)
+		policiesRepo.EXPECT().ListByHolder(gomock.Any(), []string{customer.ID}, cpf).Return([]partners.PolicyEntity{policy}, nil)
+		auditRepo.EXPECT().List(gomock.Any(), partners.AuditEventFilter{EntityID: customer.ID, Limit: 500}).Return(history, nil)
+		auditRepo.EXPECT().List(gomock.Any(), partners.AuditEventFilter{EntityID: policy.ID, Limit: 500}).Return(nil, nil)
+		auditRepo.EXPECT().Append(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, events ...partners.AuditEventEntity) error {
+				assert.Equal(t, partners.AuditCustomerExported, events[0].Action)
+				assert.Equal(t, "partner-01", events[0].PartnerID)
+				assert.Equal(t, partners.AuditPolicyExported, events[1].Action)
+				assert.Equal(t, policy.ID, events[1].EntityID)
+
+				return nil
+			})
+
+		export, err := service.ExportDataSubject(t.Context(), cpf)
+
+		assert.NoError(t, err)
+		assert.Equal(t, &partners.DataSubjectExport{
+			Cpf:         cpf,
+			Customers:   []partners.CustomerEntity{customer},
+			Policies:    []partners.PolicyEntity{policy},
+			AuditEvents: history,
+		}, export)
+	})
+
+	t.Run("Should return error when no record has the cpf", func(t *testing.T) {
+		customersRepo.EXPECT().ListByCpf(gomock.Any(), cpf).Return(nil, nil)
+		policiesRepo.EXPECT().ListByHolder(gomock.Any(), []string{}, cpf).Return(nil, nil)
+
+		_, err := service.ExportDataSubject(t.Context(), cpf)
+
+		assert.Equal(t, partners.ErrDataSubjectNotFound, err)
+	})
+}
+
+func TestServiceEraseDataSubject(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	customersRepo := mocks.NewMockCustomersRepository(ctrl)
+	policiesRepo := mocks.NewMockPoliciesRepository(ctrl)
+	deliveriesRepo := mocks.NewMockWebhookDeliveriesRepository(ctrl)
+	auditRepo := mocks.NewMockAuditLogRepository(ctrl)
+	provider := &cachingProvider{MockInsuranceProvider: mocks.NewMockInsuranceProvider(ctrl)}
+
+	transactor, _ := newTransactionMocks(ctrl)
+
+	service := partners.NewService(partners.ServiceParams{
+		CustomerRepo:            customersRepo,
+		PolicyRepo:              policiesRepo,
+		WebhookDeliveryRepo:     deliveriesRepo,
+		AuditRepo:               auditRepo,
+		Transactor:              transactor,
+		InsuranceClientProvider: provider,
+	})
+
+	const cpf = "12345678909"
+
+	t.Run("Should pseudonymize the holder's data and keep the policy records", func(t *testing.T) {
+		customer := partners.CustomerEntity{
+			ID:          "customer-01",
+			PartnerID:   "partner-01",
+			Name:        "customer-test",
+			Sex:         partners.SexFemale,
+			DateOfBirth: "1990-01-01",
+			Cpf:         cpf,
+			Email:       "customer@test.com",
+		}
+		policy := partners.PolicyEntity{
+			ID:            "policy-01",
+			PartnerID:     "partner-01",
+			CustomerID:    customer.ID,
+			ProviderID:    uuid.New(),
+			QuotationID:   uuid.New(),
+			Name:          "customer-test",
+			Cpf:           cpf,
+			Status:        partners.PolicyStatusActive,
+			Beneficiaries: []partners.BeneficiaryEntity{{Name: "beneficiary-test", Relationship: partners.RelationshipChild, Percentage: 100}},
+			Dependents:    []partners.DependentEntity{{Name: "dependent-test", Sex: partners.SexMale, DateOfBirth: "2015-01-01", Relationship: partners.RelationshipChild}},
+		}
+
+		customersRepo.EXPECT().ListByCpf(gomock.Any(), cpf).Return([]partners.CustomerEntity{customer}, nil)
+		policiesRepo.EXPECT().ListByHolder(gomock.Any(), []string{customer.ID}, cpf).Return([]partners.PolicyEntity{policy}, nil)
+
+		var pseudonym string
+		customersRepo.EXPECT().Erase(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, erased *partners.CustomerEntity) error {
+				pseudonym = erased.Name
+				assert.True(t, strings.HasPrefix(pseudonym, "erased-"))
+				assert.Equal(t, pseudonym, erased.Cpf)
+				assert.Empty(t, erased.DateOfBirth)
+				assert.Empty(t, erased.Email)
+				assert.False(t, erased.DeletedAt.IsZero())
+				assert.False(t, erased.ErasedAt.IsZero())
+
+				return nil
+			})
+		policiesRepo.EXPECT().Erase(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, erased *partners.PolicyEntity) error {
+				assert.Equal(t, pseudonym, erased.Name)
+				assert.Equal(t, pseudonym, erased.Cpf)
+				assert.Equal(t, policy.ProviderID, erased.ProviderID)
+				assert.Equal(t, partners.PolicyStatusActive, erased.Status)
+				assert.Equal(t, []partners.BeneficiaryEntity{{Relationship: partners.RelationshipChild, Percentage: 100}}, erased.Beneficiaries)
+				assert.Equal(t, []partners.DependentEntity{{Relationship: partners.RelationshipChild}}, erased.Dependents)
+
+				return nil
+			})
+		auditRepo.EXPECT().EraseChanges(gomock.Any(), customer.ID, policy.ID).Return(nil)
+		deliveriesRepo.EXPECT().DeleteByEntityIDs(gomock.Any(), customer.ID, policy.ID).Return(int64(2), nil)
+		auditRepo.EXPECT().Append(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, events ...partners.AuditEventEntity) error {
+				assert.Equal(t, partners.AuditCustomerErased, events[0].Action)
+				assert.Equal(t, partners.AuditPolicyErased, events[1].Action)
+				assert.NotContains(t, string(events[1].Changes), cpf)
+
+				return nil
+			})
+
+		result, err := service.EraseDataSubject(t.Context(), cpf)
+
+		assert.NoError(t, err)
+		assert.Equal(t, partners.DataSubjectErasure{
+			Pseudonym:         pseudonym,
+			Customers:         1,
+			Policies:          1,
+			WebhookDeliveries: 2,
+		}, result)
+		assert.Equal(t, []string{policy.ProviderID.String()}, provider.invalidated)
+	})
+
+	t.Run("Should return the erasure with error when the provider's policy isn't invalidated", func(t *testing.T) {
+		customer := partners.CustomerEntity{ID: "customer-02", PartnerID: "partner-01", Name: "customer-test", Cpf: cpf}
+		policy := partners.PolicyEntity{ID: "policy-02", PartnerID: "partner-01", CustomerID: customer.ID, ProviderID: uuid.New(), Name: "customer-test", Cpf: cpf}
+		provider.err = assert.AnError
+
+		customersRepo.EXPECT().ListByCpf(gomock.Any(), cpf).Return([]partners.CustomerEntity{customer}, nil)
+		policiesRepo.EXPECT().ListByHolder(gomock.Any(), []string{customer.ID}, cpf).Return([]partners.PolicyEntity{policy}, nil)
+		customersRepo.EXPECT().Erase(gomock.Any(), gomock.Any()).Return(nil)
+		policiesRepo.EXPECT().Erase(gomock.Any(), gomock.Any()).Return(nil)
+		auditRepo.EXPECT().EraseChanges(gomock.Any(), customer.ID, policy.ID).Return(nil)
+		deliveriesRepo.EXPECT().DeleteByEntityIDs(gomock.Any(), customer.ID, policy.ID).Return(int64(0), nil)
+		auditRepo.EXPECT().Append(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+
+		result, err := service.EraseDataSubject(t.Context(), cpf)
+
+		assert.ErrorIs(t, err, partners.ErrPolicyCacheNotInvalidated)
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.Contains(t, err.Error(), policy.ProviderID.String())
+		assert.True(t, strings.HasPrefix(result.Pseudonym, "erased-"))
+		assert.Equal(t, int64(1), result.Policies)
+	})
+
+	t.Run("Should return error when no record has the cpf", func(t *testing.T) {
+		customersRepo.EXPECT().ListByCpf(gomock.Any(), cpf).Return(nil, nil)
+		policiesRepo.EXPECT().ListByHolder(gomock.Any(), []string{}, cpf).Return(nil, nil)
+
+		_, err := service.EraseDataSubject(t.Context(), cpf)
+
+		assert.Equal(t, partners.ErrDataSubjectNotFound, err)
+	})
+}
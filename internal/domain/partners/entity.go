@@ -1,6 +1,9 @@
 package partners
 
 import (
+	"math"
+	"net/url"
+	"slices"
 	"strings"
 	"time"
 
@@ -8,13 +11,32 @@ import (
 )
 
 type (
-	SexEnum string
+	SexEnum          string
+	RelationshipEnum string
+	QuoteStatusEnum  string
+
+	WebhookDeliveryStatusEnum string
+	PolicyStatusEnum          string
+	PolicyIssuanceStatusEnum  string
 
 	PartnerEntity struct {
-		ID        string
-		Name      string
-		Cnpj      string
+		ID       string
+		Name     string
+		Cnpj     string
+		Language string
+		Limits   PartnerLimitsEntity
+		// Suspended partners can still read their data but can't request new
+		// quotes or policies.
+		Suspended bool
 		CreatedAt time.Time
+		UpdatedAt time.Time
+	}
+
+	// PartnerLimitsEntity overrides the default request limits for a partner.
+	// Zero values keep the defaults.
+	PartnerLimitsEntity struct {
+		RequestsPerWindow int
+		DailyQuotes       int
 	}
 
 	QuoteEntity struct {
@@ -26,16 +48,123 @@ type (
 		Price      float64
 		ExpiresAt  time.Time
 		CreatedAt  time.Time
+		// ReusedFromID is the quote whose provider quotation this one reuses.
+		ReusedFromID string
+		Status       QuoteStatusEnum
+		// ExpiredAt is when the quote was marked as expired, which starts its
+		// retention period.
+		ExpiredAt time.Time
 	}
 
 	PolicyEntity struct {
+		ID            string
+		QuotationID   uuid.UUID
+		ProviderID    uuid.UUID
+		Sex           SexEnum
+		Name          string
+		DateOfBirth   string
+		Cpf           string
+		Email         string
+		Phone         string
+		PartnerID     string
+		CustomerID    string
+		Beneficiaries []BeneficiaryEntity
+		Dependents    []DependentEntity
+		// Status is kept in sync with the provider by the events it sends.
+		Status PolicyStatusEnum
+		// StatusUpdatedAt is when the provider changed the status, used to
+		// ignore events that arrive out of order.
+		StatusUpdatedAt time.Time
+		// Stale is set when the provider data may be outdated.
+		Stale bool
+		// ErasedAt is when the holder's personal data was pseudonymized.
+		ErasedAt time.Time
+	}
+
+	// PolicyIssuanceEntity tracks a request to issue a policy at the provider.
+	// It is stored before the provider is called, so a policy the provider
+	// issued is never lost when storing it fails.
+	PolicyIssuanceEntity struct {
 		ID          string
+		PartnerID   string
 		QuotationID uuid.UUID
-		ProviderID  uuid.UUID
-		Sex         SexEnum
+		// ProviderID is set once the provider issued the policy.
+		ProviderID uuid.UUID
+		// PolicyID is our policy, set when the issuance completes.
+		PolicyID  string
+		Status    PolicyIssuanceStatusEnum
+		LastError string
+		CreatedAt time.Time
+		// ResolvedAt is when the issuance left the pending status, which
+		// starts its retention period.
+		ResolvedAt time.Time
+	}
+
+	CustomerEntity struct {
+		ID          string
+		PartnerID   string
 		Name        string
+		Sex         SexEnum
 		DateOfBirth string
-		PartnerID   string
+		Cpf         string
+		Email       string
+		Phone       string
+		CreatedAt   time.Time
+		UpdatedAt   time.Time
+		// DeletedAt is set on the customers deleted by the partner, which are
+		// kept for the data subject requests.
+		DeletedAt time.Time
+		// ErasedAt is when the customer's personal data was pseudonymized.
+		ErasedAt time.Time
+	}
+
+	BeneficiaryEntity struct {
+		Name         string
+		Relationship RelationshipEnum
+		Percentage   float64
+	}
+
+	DependentEntity struct {
+		Name         string
+		Sex          SexEnum
+		DateOfBirth  string
+		Relationship RelationshipEnum
+	}
+
+	// WebhookEntity is a URL a partner registered to be told about the events
+	// of the given types.
+	WebhookEntity struct {
+		ID         string
+		PartnerID  string
+		URL        string
+		EventTypes []EventType
+		CreatedAt  time.Time
+	}
+
+	// WebhookDeliveryEntity is an event sent, or to be sent, to a webhook. The
+	// body is built when the delivery is created, so every attempt sends the
+	// same payload.
+	WebhookDeliveryEntity struct {
+		ID        string
+		WebhookID string
+		PartnerID string
+		EventID   string
+		// EntityID is the record the event is about.
+		EntityID  string
+		EventType EventType
+		URL       string
+		Body      []byte
+		Status    WebhookDeliveryStatusEnum
+		Attempts  int
+		// LastStatusCode is zero when the last attempt got no response.
+		LastStatusCode int
+		LastError      string
+		NextAttemptAt  time.Time
+		// RetryUntil is when the delivery stops being retried and goes to the
+		// dead letter list.
+		RetryUntil  time.Time
+		CreatedAt   time.Time
+		DeliveredAt time.Time
 	}
 )
 
@@ -45,11 +174,60 @@ const (
 	SexNeutral SexEnum = "N"
 )
 
-func NewEntity(name, cnpj string) *PartnerEntity {
+const (
+	QuoteStatusActive  QuoteStatusEnum = "active"
+	QuoteStatusExpired QuoteStatusEnum = "expired"
+)
+
+const (
+	PolicyStatusActive    PolicyStatusEnum = "active"
+	PolicyStatusSuspended PolicyStatusEnum = "suspended"
+	PolicyStatusCancelled PolicyStatusEnum = "cancelled"
+	PolicyStatusExpired   PolicyStatusEnum = "expired"
+)
+
+const (
+	PolicyIssuancePending PolicyIssuanceStatusEnum = "pending"
+	PolicyIssuanceIssued  PolicyIssuanceStatusEnum = "issued"
+	// PolicyIssuanceCompensated marks the issuances whose policy was
+	// cancelled at the provider because we failed to store it.
+	PolicyIssuanceCompensated PolicyIssuanceStatusEnum = "compensated"
+	// PolicyIssuanceFailed marks the issuances the provider didn't complete.
+	PolicyIssuanceFailed PolicyIssuanceStatusEnum = "failed"
+)
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatusEnum = "pending"
+	WebhookDeliveryDelivered WebhookDeliveryStatusEnum = "delivered"
+	// WebhookDeliveryDead marks the deliveries that ran out of retries.
+	WebhookDeliveryDead WebhookDeliveryStatusEnum = "dead"
+)
+
+const (
+	RelationshipSpouse  RelationshipEnum = "spouse"
+	RelationshipChild   RelationshipEnum = "child"
+	RelationshipParent  RelationshipEnum = "parent"
+	RelationshipSibling RelationshipEnum = "sibling"
+	RelationshipOther   RelationshipEnum = "other"
+)
+
+const (
+	beneficiariesTotalPercentage = 100.0
+	percentageTolerance          = 0.001
+
+	dateLayout   = "2006-01-02"
+	maxHolderAge = 120
+)
+
+func NewEntity(name, cnpj, language string) *PartnerEntity {
+	now := time.Now()
+
 	return &PartnerEntity{
 		Name:      name,
 		Cnpj:      cnpj,
-		CreatedAt: time.Now(),
+		Language:  language,
+		CreatedAt: now,
+		UpdatedAt: now,
 	}
 }
 
@@ -64,14 +242,13 @@ func NewQuoteEntity(age uint, sex string, partnerID string) *QuoteEntity {
 		Age:       age,
 		Sex:       SexEnum(strings.ToUpper(sex)),
 		PartnerID: partnerID,
+		Status:    QuoteStatusActive,
 		CreatedAt: time.Now(),
 	}
 }
 
 func (e *QuoteEntity) ParseDateToEndOfDay(dateStr string) error {
-	const layout = "2006-01-02"
-
-	parsedDate, err := time.Parse(layout, dateStr)
+	parsedDate, err := time.Parse(dateLayout, dateStr)
 	if err != nil {
 		return err
 	}
@@ -88,3 +265,212 @@ func (e *QuoteEntity) ParseDateToEndOfDay(dateStr string) error {
 
 	return nil
 }
+
+// MatchesDateOfBirth reports whether the age derived from dateOfBirth at the
+// reference date is the quoted age. Around a birthday the holder may have been
+// quoted with the age before or after it, so the age is also checked at both
+// ends of the tolerance window.
+func (e *QuoteEntity) MatchesDateOfBirth(dateOfBirth, reference time.Time, tolerance time.Duration) bool {
+	for _, date := range []time.Time{reference, reference.Add(-tolerance), reference.Add(tolerance)} {
+		if AgeAt(dateOfBirth, date) == int(e.Age) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ParseDateOfBirth parses an ISO date of birth, rejecting future dates and
+// ages no holder could have.
+func ParseDateOfBirth(value string) (time.Time, error) {
+	dateOfBirth, err := time.Parse(dateLayout, value)
+	if err != nil {
+		return time.Time{}, ErrInvalidDateOfBirth
+	}
+
+	now := time.Now()
+	if dateOfBirth.After(now) {
+		return time.Time{}, ErrDateOfBirthInFuture
+	}
+
+	if AgeAt(dateOfBirth, now) > maxHolderAge {
+		return time.Time{}, ErrHolderAgeOutOfRange
+	}
+
+	return dateOfBirth, nil
+}
+
+func AgeAt(dateOfBirth, reference time.Time) int {
+	age := reference.Year() - dateOfBirth.Year()
+
+	if reference.Month() < dateOfBirth.Month() ||
+		(reference.Month() == dateOfBirth.Month() && reference.Day() < dateOfBirth.Day()) {
+		age--
+	}
+
+	return age
+}
+
+func NewCustomerEntity(partnerID, name, sex, dateOfBirth, cpf, email, phone string) *CustomerEntity {
+	now := time.Now()
+
+	return &CustomerEntity{
+		PartnerID:   partnerID,
+		Name:        name,
+		Sex:         SexEnum(strings.ToUpper(sex)),
+		DateOfBirth: dateOfBirth,
+		Cpf:         cpf,
+		Email:       email,
+		Phone:       phone,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
+func NewWebhookEntity(partnerID, url string, eventTypes []EventType) *WebhookEntity {
+	return &WebhookEntity{
+		PartnerID:  partnerID,
+		URL:        url,
+		EventTypes: eventTypes,
+		CreatedAt:  time.Now(),
+	}
+}
+
+func (e *WebhookEntity) Validate() error {
+	parsedURL, err := url.Parse(e.URL)
+	if err != nil || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") || parsedURL.Host == "" {
+		return ErrInvalidWebhookURL
+	}
+
+	for _, eventType := range e.EventTypes {
+		if !slices.Contains(EventTypes, eventType) {
+			return ErrInvalidWebhookEventType
+		}
+	}
+
+	return nil
+}
+
+func (e *WebhookEntity) Subscribes(eventType EventType) bool {
+	return slices.Contains(e.EventTypes, eventType)
+}
+
+func (e *CustomerEntity) Validate() error {
+	_, err := ParseDateOfBirth(e.DateOfBirth)
+
+	return err
+}
+
+// FillHolder copies the customer's personal data into the policy holder fields.
+func (e *PolicyEntity) FillHolder(customer *CustomerEntity) {
+	e.CustomerID = customer.ID
+	e.Name = customer.Name
+	e.Sex = customer.Sex
+	e.DateOfBirth = customer.DateOfBirth
+	e.Cpf = customer.Cpf
+	e.Email = customer.Email
+	e.Phone = customer.Phone
+}
+
+// Erase replaces the customer's name and CPF with the pseudonym and clears the
+// rest of its personal data. Erased customers are deleted too.
+func (e *CustomerEntity) Erase(pseudonym string, now time.Time) {
+	e.Name = pseudonym
+	e.Cpf = pseudonym
+	e.Sex = ""
+	e.DateOfBirth = ""
+	e.Email = ""
+	e.Phone = ""
+	e.UpdatedAt = now
+	e.ErasedAt = now
+
+	if e.DeletedAt.IsZero() {
+		e.DeletedAt = now
+	}
+}
+
+// Erase replaces the holder's name and CPF with the pseudonym and clears the
+// rest of the personal data of the holder, beneficiaries and dependents. The
+// policy identifiers, status, relationships and percentages are kept.
+func (e *PolicyEntity) Erase(pseudonym string, now time.Time) {
+	e.Name = pseudonym
+	e.Cpf = pseudonym
+	e.Sex = ""
+	e.DateOfBirth = ""
+	e.Email = ""
+	e.Phone = ""
+	e.ErasedAt = now
+
+	for index := range e.Beneficiaries {
+		e.Beneficiaries[index].Name = ""
+	}
+
+	for index := range e.Dependents {
+		e.Dependents[index].Name = ""
+		e.Dependents[index].Sex = ""
+		e.Dependents[index].DateOfBirth = ""
+	}
+}
+
+func (e *PolicyEntity) Validate() error {
+	if err := e.validateBeneficiaries(); err != nil {
+		return err
+	}
+
+	return e.validateDependents()
+}
+
+func (e *PolicyEntity) validateBeneficiaries() error {
+	if len(e.Beneficiaries) == 0 {
+		return nil
+	}
+
+	var total float64
+	for _, beneficiary := range e.Beneficiaries {
+		if beneficiary.Percentage <= 0 {
+			return ErrInvalidBeneficiaryPercentage
+		}
+
+		if beneficiary.Relationship != "" && !beneficiary.Relationship.IsValid() {
+			return ErrInvalidRelationship
+		}
+
+		total += beneficiary.Percentage
+	}
+
+	if math.Abs(total-beneficiariesTotalPercentage) > percentageTolerance {
+		return ErrBeneficiariesPercentageSum
+	}
+
+	return nil
+}
+
+func (e *PolicyEntity) validateDependents() error {
+	for _, dependent := range e.Dependents {
+		if !dependent.Relationship.IsDependent() {
+			return ErrInvalidDependentRelationship
+		}
+	}
+
+	return nil
+}
+
+func (r RelationshipEnum) IsValid() bool {
+	switch r {
+	case RelationshipSpouse, RelationshipChild, RelationshipParent, RelationshipSibling, RelationshipOther:
+		return true
+	}
+
+	return false
+}
+
+// IsDependent reports whether the relationship is accepted for dependents,
+// which only cover the holder's spouse, children and parents.
+func (r RelationshipEnum) IsDependent() bool {
+	switch r {
+	case RelationshipSpouse, RelationshipChild, RelationshipParent:
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,69 @@
+package partners_test
+
+import (
+	"main-api/internal/domain/partners"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDateOfBirth(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Should parse a valid ISO date of birth", func(t *testing.T) {
+		dateOfBirth, err := partners.ParseDateOfBirth("1998-09-28")
+
+		assert.NoError(t, err)
+		assert.Equal(t, time.Date(1998, 9, 28, 0, 0, 0, 0, time.UTC), dateOfBirth)
+	})
+
+	t.Run("Should return error when date of birth is not an ISO date", func(t *testing.T) {
+		_, err := partners.ParseDateOfBirth("28/09/1998")
+
+		assert.Equal(t, partners.ErrInvalidDateOfBirth, err)
+	})
+
+	t.Run("Should return error when date of birth is in the future", func(t *testing.T) {
+		_, err := partners.ParseDateOfBirth(time.Now().AddDate(0, 0, 2).Format("2006-01-02"))
+
+		assert.Equal(t, partners.ErrDateOfBirthInFuture, err)
+	})
+
+	t.Run("Should return error when holder age is unreasonable", func(t *testing.T) {
+		_, err := partners.ParseDateOfBirth("1850-01-01")
+
+		assert.Equal(t, partners.ErrHolderAgeOutOfRange, err)
+	})
+}
+
+func TestQuoteMatchesDateOfBirth(t *testing.T) {
+	t.Parallel()
+
+	dateOfBirth := time.Date(1998, 9, 28, 0, 0, 0, 0, time.UTC)
+	quote := partners.QuoteEntity{Age: 26}
+
+	t.Run("Should match when the age at the reference date is the quoted age", func(t *testing.T) {
+		reference := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+		assert.True(t, quote.MatchesDateOfBirth(dateOfBirth, reference, 0))
+	})
+
+	t.Run("Should not match a birthday just passed without tolerance", func(t *testing.T) {
+		reference := time.Date(2025, 9, 29, 0, 0, 0, 0, time.UTC)
+
+		assert.False(t, quote.MatchesDateOfBirth(dateOfBirth, reference, 0))
+	})
+
+	t.Run("Should match a birthday inside the tolerance window", func(t *testing.T) {
+		reference := time.Date(2025, 9, 29, 0, 0, 0, 0, time.UTC)
+
+		assert.True(t, quote.MatchesDateOfBirth(dateOfBirth, reference, 2*24*time.Hour))
+	})
+
+	t.Run("Should not match ages far from the quoted one", func(t *testing.T) {
+		reference := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		assert.False(t, quote.MatchesDateOfBirth(dateOfBirth, reference, 30*24*time.Hour))
+	})
+}
@@ -1,11 +1,77 @@
 package partners
 
 import (
-	"github.com/gofiber/fiber/v2"
+	"errors"
+	"fmt"
+)
+
+type (
+	// FieldError reports a domain rule broken by a single field of the request,
+	// such as a date of birth that doesn't match the quoted age.
+	FieldError struct {
+		Field   string
+		Code    string
+		Message string
+	}
 )
 
 var (
-	ErrPartnerAlreadyExists = fiber.NewError(fiber.StatusConflict, "partner already exists")
-	ErrPartnerNotFound      = fiber.NewError(fiber.StatusNotFound, "partner not found")
-	ErrPolicyNotFound       = fiber.NewError(fiber.StatusNotFound, "policy not found")
+	ErrPartnerAlreadyExists = errors.New("partner already exists")
+	ErrPartnerNotFound      = errors.New("partner not found")
+	ErrPartnerSuspended     = errors.New("partner is suspended")
+	ErrPolicyNotFound       = errors.New("policy not found")
+	ErrQuoteNotFound        = errors.New("quote not found")
+
+	// ErrProviderRejected is returned when the provider refused a request with
+	// a client error, so it's known to have done nothing. Any other failure of
+	// a call, such as a timeout, may come after the provider acted on it.
+	ErrProviderRejected = errors.New("request rejected by the insurance provider")
+	// ErrProviderPolicyNotFound is returned when the provider doesn't know a
+	// policy we issued through it.
+	ErrProviderPolicyNotFound = errors.New("policy not found at the insurance provider")
+
+	// ErrDuplicateProviderEvent is returned when an event of the provider was
+	// already received.
+	ErrDuplicateProviderEvent = errors.New("provider event already received")
+	// ErrPolicyCacheNotInvalidated is returned along with the result of a
+	// provider event that was applied, but whose policy is still cached. The
+	// change shows once the cache expires.
+	ErrPolicyCacheNotInvalidated = errors.New("provider policy cache not invalidated")
+	// ErrPolicyIssuanceUnresolved is joined to the error of a policy creation
+	// whose issuance was left pending for RecoverPolicyIssuances.
+	ErrPolicyIssuanceUnresolved = errors.New("policy issuance left pending")
+
+	ErrCustomerAlreadyExists = errors.New("customer already exists")
+	ErrCustomerNotFound      = errors.New("customer not found")
+
+	// ErrDataSubjectNotFound is returned when no customer or policy has the
+	// CPF of a data subject request.
+	ErrDataSubjectNotFound = errors.New("no personal data found for the cpf")
+
+	ErrWebhookNotFound         = errors.New("webhook not found")
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+	ErrInvalidWebhookEventType = errors.New("invalid webhook event type")
+	ErrInvalidWebhookURL       = errors.New("webhook url must be an http or https url")
+
+	ErrBeneficiariesPercentageSum   = errors.New("beneficiaries percentages must add up to 100")
+	ErrInvalidBeneficiaryPercentage = errors.New("beneficiary percentage must be greater than 0")
+	ErrInvalidRelationship          = errors.New("invalid relationship type")
+	ErrInvalidDependentRelationship = errors.New("dependent relationship must be spouse, child or parent")
+
+	ErrInvalidDateOfBirth     = NewFieldError("date_of_birth", "datetime", "date of birth must be a valid date in the format YYYY-MM-DD")
+	ErrDateOfBirthInFuture    = NewFieldError("date_of_birth", "past_date", "date of birth can't be in the future")
+	ErrHolderAgeOutOfRange    = NewFieldError("date_of_birth", "age_range", "holder age must be between 0 and 120 years")
+	ErrDateOfBirthAgeMismatch = NewFieldError("date_of_birth", "quotation_age", "date of birth doesn't match the quotation age")
 )
+
+func NewFieldError(field, code, message string) *FieldError {
+	return &FieldError{
+		Field:   field,
+		Code:    code,
+		Message: message,
+	}
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("[%s]: %s", e.Field, e.Message)
+}
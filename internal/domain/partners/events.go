@@ -0,0 +1,117 @@
+package partners
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type (
+	EventType string
+
+	// Event tells other systems about a change in a partner's data. Events are
+	// stored in the outbox with the change itself and published afterwards, in
+	// order for each partner, which is the aggregate every event belongs to.
+	Event struct {
+		ID          string
+		Type        EventType
+		AggregateID string
+		// EntityID is the ID of the record the event is about.
+		EntityID   string
+		Payload    any
+		OccurredAt time.Time
+	}
+
+	PartnerCreatedPayload struct {
+		ID        string    `json:"id"`
+		Name      string    `json:"name"`
+		Cnpj      string    `json:"cnpj"`
+		CreatedAt time.Time `json:"created_at"`
+	}
+
+	QuoteCreatedPayload struct {
+		ID         string    `json:"id"`
+		ProviderID uuid.UUID `json:"provider_id"`
+		Age        uint      `json:"age"`
+		Sex        SexEnum   `json:"sex"`
+		Price      float64   `json:"price"`
+		ExpiresAt  time.Time `json:"expires_at"`
+		CreatedAt  time.Time `json:"created_at"`
+	}
+
+	PolicyStatusChangedPayload struct {
+		ID             string           `json:"id"`
+		ProviderID     uuid.UUID        `json:"provider_id"`
+		PreviousStatus PolicyStatusEnum `json:"previous_status"`
+		Status         PolicyStatusEnum `json:"status"`
+		ChangedAt      time.Time        `json:"changed_at"`
+	}
+
+	PolicyIssuedPayload struct {
+		ID          string    `json:"id"`
+		ProviderID  uuid.UUID `json:"provider_id"`
+		QuotationID uuid.UUID `json:"quotation_id"`
+		CustomerID  string    `json:"customer_id,omitempty"`
+	}
+)
+
+const (
+	EventPartnerCreated      EventType = "partner.created"
+	EventQuoteCreated        EventType = "quote.created"
+	EventPolicyIssued        EventType = "policy.issued"
+	EventPolicyStatusChanged EventType = "policy.status_changed"
+)
+
+// EventTypes lists the events partners can subscribe webhooks to.
+var EventTypes = []EventType{EventPartnerCreated, EventQuoteCreated, EventPolicyIssued, EventPolicyStatusChanged}
+
+func newEvent(eventType EventType, partnerID, entityID string, payload any) Event {
+	return Event{
+		ID:          uuid.NewString(),
+		Type:        eventType,
+		AggregateID: partnerID,
+		EntityID:    entityID,
+		Payload:     payload,
+		OccurredAt:  time.Now(),
+	}
+}
+
+func NewPartnerCreatedEvent(partner *PartnerEntity) Event {
+	return newEvent(EventPartnerCreated, partner.ID, partner.ID, PartnerCreatedPayload{
+		ID:        partner.ID,
+		Name:      partner.Name,
+		Cnpj:      partner.Cnpj,
+		CreatedAt: partner.CreatedAt,
+	})
+}
+
+func NewQuoteCreatedEvent(quote *QuoteEntity) Event {
+	return newEvent(EventQuoteCreated, quote.PartnerID, quote.ID, QuoteCreatedPayload{
+		ID:         quote.ID,
+		ProviderID: quote.ProviderID,
+		Age:        quote.Age,
+		Sex:        quote.Sex,
+		Price:      quote.Price,
+		ExpiresAt:  quote.ExpiresAt,
+		CreatedAt:  quote.CreatedAt,
+	})
+}
+
+func NewPolicyIssuedEvent(policy *PolicyEntity) Event {
+	return newEvent(EventPolicyIssued, policy.PartnerID, policy.ID, PolicyIssuedPayload{
+		ID:          policy.ID,
+		ProviderID:  policy.ProviderID,
+		QuotationID: policy.QuotationID,
+		CustomerID:  policy.CustomerID,
+	})
+}
+
+func NewPolicyStatusChangedEvent(policy *PolicyEntity, previousStatus PolicyStatusEnum) Event {
+	return newEvent(EventPolicyStatusChanged, policy.PartnerID, policy.ID, PolicyStatusChangedPayload{
+		ID:             policy.ID,
+		ProviderID:     policy.ProviderID,
+		PreviousStatus: previousStatus,
+		Status:         policy.Status,
+		ChangedAt:      policy.StatusUpdatedAt,
+	})
+}
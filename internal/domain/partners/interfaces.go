@@ -2,6 +2,7 @@ package partners
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -11,15 +12,149 @@ type (
 		GetByFilter(ctx context.Context, filter map[string]interface{}) (*PartnerEntity, error)
 		GetByID(ctx context.Context, id string) (*PartnerEntity, error)
 		Create(ctx context.Context, partner *PartnerEntity) error
+		Update(ctx context.Context, partner *PartnerEntity) error
 	}
 
 	QuotesRepository interface {
 		Create(ctx context.Context, quote *QuoteEntity) error
+		GetByProviderIDAndPartnerID(ctx context.Context, providerID uuid.UUID, partnerID string) (*QuoteEntity, error)
+		// GetReusable returns the quote for the age and sex that expires last,
+		// among the ones still valid at validAt. An empty partnerID searches the
+		// quotes of every partner.
+		GetReusable(ctx context.Context, age uint, sex SexEnum, partnerID string, validAt time.Time) (*QuoteEntity, error)
+		// MarkExpired marks the active quotes that expired before now, returning
+		// how many were marked.
+		MarkExpired(ctx context.Context, now time.Time) (int64, error)
 	}
 
 	PoliciesRepository interface {
 		Create(ctx context.Context, policy *PolicyEntity) error
 		GetByIdAndPartnerID(ctx context.Context, policyID, partnerID string) (*PolicyEntity, error)
+		ListByPartnerIDAndCpf(ctx context.Context, partnerID, cpf string) ([]PolicyEntity, error)
+		ListByPartnerIDAndHolder(ctx context.Context, partnerID, customerID, cpf string) ([]PolicyEntity, error)
+		// GetByProviderID returns the policy with the provider's ID, whatever
+		// partner it belongs to.
+		GetByProviderID(ctx context.Context, providerID uuid.UUID) (*PolicyEntity, error)
+		UpdateStatus(ctx context.Context, policy *PolicyEntity) error
+		// ListAfter pages through the policies of every partner in the order
+		// they were created, starting after afterID. An empty afterID starts
+		// from the first policy.
+		ListAfter(ctx context.Context, afterID string, limit int) ([]PolicyEntity, error)
+		// UpdateProviderData saves the policy data that comes from the
+		// provider: the quotation, holder, beneficiaries and dependents. It
+		// returns false, leaving the policy as is, when the holder was erased.
+		UpdateProviderData(ctx context.Context, policy *PolicyEntity) (bool, error)
+		// ListByHolder returns the policies of every partner issued for one of
+		// the customers or with the CPF as inline holder data.
+		ListByHolder(ctx context.Context, customerIDs []string, cpf string) ([]PolicyEntity, error)
+		// Erase saves the pseudonymized personal data of the policy.
+		Erase(ctx context.Context, policy *PolicyEntity) error
+	}
+
+	PolicyIssuancesRepository interface {
+		Create(ctx context.Context, issuance *PolicyIssuanceEntity) error
+		Update(ctx context.Context, issuance *PolicyIssuanceEntity) error
+		// ListPending returns the issuances still pending that were created
+		// before createdBefore, oldest first.
+		ListPending(ctx context.Context, createdBefore time.Time, limit int) ([]PolicyIssuanceEntity, error)
+	}
+
+	// PolicyMismatchesRepository keeps the reports of the reconciliations
+	// between our policies and the provider's.
+	PolicyMismatchesRepository interface {
+		Create(ctx context.Context, mismatches ...PolicyMismatchEntity) error
+	}
+
+	// ProviderEventsRepository records the events received from the provider,
+	// so redeliveries are only applied once. Add returns
+	// ErrDuplicateProviderEvent for an event already recorded.
+	ProviderEventsRepository interface {
+		Add(ctx context.Context, eventID string, receivedAt time.Time) error
+	}
+
+	CustomersRepository interface {
+		Create(ctx context.Context, customer *CustomerEntity) error
+		GetByIDAndPartnerID(ctx context.Context, customerID, partnerID string) (*CustomerEntity, error)
+		GetByCpfAndPartnerID(ctx context.Context, cpf, partnerID string) (*CustomerEntity, error)
+		ListByPartnerID(ctx context.Context, partnerID string) ([]CustomerEntity, error)
+		Update(ctx context.Context, customer *CustomerEntity) error
+		// Delete soft deletes the customer, which is left out of every other
+		// lookup but ListByCpf.
+		Delete(ctx context.Context, customerID, partnerID string) error
+		// ListByCpf returns the customers of every partner with the CPF, the
+		// deleted ones included.
+		ListByCpf(ctx context.Context, cpf string) ([]CustomerEntity, error)
+		// Erase saves the pseudonymized personal data of the customer.
+		Erase(ctx context.Context, customer *CustomerEntity) error
+	}
+
+	WebhooksRepository interface {
+		Create(ctx context.Context, webhook *WebhookEntity) error
+		ListByPartnerID(ctx context.Context, partnerID string) ([]WebhookEntity, error)
+		Delete(ctx context.Context, partnerID, webhookID string) error
+		// GetSecret returns the secret the partner's deliveries are signed
+		// with, or an empty string when the partner doesn't have one yet.
+		GetSecret(ctx context.Context, partnerID string) (string, error)
+		SetSecret(ctx context.Context, partnerID, secret string) error
+	}
+
+	WebhookDeliveriesRepository interface {
+		// Create skips the deliveries of an event already created for the same
+		// webhook, since events may be published more than once.
+		Create(ctx context.Context, deliveries ...WebhookDeliveryEntity) error
+		GetByIDAndPartnerID(ctx context.Context, deliveryID, partnerID string) (*WebhookDeliveryEntity, error)
+		// ListByPartnerID returns the partner's latest deliveries, newest first.
+		// An empty status lists deliveries in any status.
+		ListByPartnerID(ctx context.Context, partnerID string, status WebhookDeliveryStatusEnum, limit int) ([]WebhookDeliveryEntity, error)
+		// ListDue returns the pending deliveries whose next attempt is due.
+		ListDue(ctx context.Context, now time.Time, limit int) ([]WebhookDeliveryEntity, error)
+		Update(ctx context.Context, delivery *WebhookDeliveryEntity) error
+		// DeleteByEntityIDs deletes the deliveries of the events about the
+		// records, returning how many were deleted.
+		DeleteByEntityIDs(ctx context.Context, entityIDs ...string) (int64, error)
+	}
+
+	WebhookRequest struct {
+		URL        string
+		DeliveryID string
+		EventType  EventType
+		Secret     string
+		Body       []byte
+	}
+
+	// WebhookSender posts a delivery to the partner, signed with the secret.
+	// Responses outside 2xx are returned as errors, along with their status.
+	// CheckURL returns an error for the URLs the sender won't post to.
+	WebhookSender interface {
+		CheckURL(ctx context.Context, url string) error
+		Send(ctx context.Context, request WebhookRequest) (statusCode int, err error)
+	}
+
+	// OutboxRepository stores the events to be published. It must take part in
+	// the transaction of the context, so events are only stored with the
+	// change they describe.
+	OutboxRepository interface {
+		Add(ctx context.Context, events ...Event) error
+	}
+
+	// AuditLogRepository keeps the append-only audit log. Append seals the
+	// events into the hash chain, in the order given, and must take part in the
+	// transaction of the context like the outbox.
+	AuditLogRepository interface {
+		Append(ctx context.Context, events ...AuditEventEntity) error
+		List(ctx context.Context, filter AuditEventFilter) ([]AuditEventEntity, error)
+		// Head returns the last entry of the chain, or a zero head when the
+		// chain is empty.
+		Head(ctx context.Context) (AuditChainHead, error)
+		// EraseChanges removes the changes from the events of the records,
+		// keeping their digest in the chain.
+		EraseChanges(ctx context.Context, entityIDs ...string) error
+	}
+
+	// Transactor runs fn in a transaction, carried by the context passed to
+	// it. fn may run more than once when the transaction is retried.
+	Transactor interface {
+		WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error
 	}
 
 	InsuranceProviderCreateQuotationRequest struct {
@@ -35,19 +170,42 @@ type (
 		ExpiresAt  string
 	}
 
+	InsuranceProviderBeneficiary struct {
+		Name         string  `json:"name"`
+		Relationship string  `json:"relationship,omitempty"`
+		Percentage   float64 `json:"percentage"`
+	}
+
+	InsuranceProviderDependent struct {
+		Name         string `json:"name"`
+		Sex          string `json:"sex"`
+		DateOfBirth  string `json:"date_of_birth"`
+		Relationship string `json:"relationship"`
+	}
+
 	InsuranceProviderCreatePolicyRequest struct {
-		QuotationID uuid.UUID `json:"quotation_id"`
-		Name        string    `json:"name"`
-		Sex         string    `json:"sex"`
-		DateOfBirth string    `json:"date_of_birth"`
+		QuotationID   uuid.UUID                      `json:"quotation_id"`
+		Name          string                         `json:"name"`
+		Sex           string                         `json:"sex"`
+		DateOfBirth   string                         `json:"date_of_birth"`
+		Beneficiaries []InsuranceProviderBeneficiary `json:"beneficiaries,omitempty"`
+		Dependents    []InsuranceProviderDependent   `json:"dependents,omitempty"`
+		// ClientReference is our ID of the issuance, which the provider keeps
+		// with the policy so it can be found when its ID never reached us.
+		ClientReference string `json:"client_reference,omitempty"`
 	}
 
 	InsuranceProviderCreatePolicyResponse struct {
-		ID          uuid.UUID
-		QuotationID uuid.UUID
-		Name        string
-		Sex         string
-		DateOfBirth string
+		ID            uuid.UUID
+		QuotationID   uuid.UUID
+		Name          string
+		Sex           string
+		DateOfBirth   string
+		Beneficiaries []InsuranceProviderBeneficiary
+		Dependents    []InsuranceProviderDependent
+		// Stale is set when the provider couldn't be reached and the data
+		// comes from an older cached copy.
+		Stale bool
 	}
 
 	InsuranceProvider interface {
@@ -60,5 +218,41 @@ type (
 			data InsuranceProviderCreatePolicyRequest,
 		) (*InsuranceProviderCreatePolicyResponse, error)
 		GetPolicy(ctx context.Context, policyID string) (*InsuranceProviderCreatePolicyResponse, error)
+		// FindPolicyByReference returns the policy created with the client
+		// reference, or ErrProviderPolicyNotFound when there's none.
+		FindPolicyByReference(ctx context.Context, reference string) (*InsuranceProviderCreatePolicyResponse, error)
+		// CancelPolicy returns ErrProviderPolicyNotFound when the provider
+		// doesn't know the policy.
+		CancelPolicy(ctx context.Context, policyID string) error
+		QuoteReusePolicy() QuoteReusePolicy
+	}
+
+	// PartnerCacheInvalidator is implemented by the partner repositories that
+	// cache partners, to drop a partner whose change was committed. Dropping it
+	// before the commit would let a lookup in between cache the old partner.
+	PartnerCacheInvalidator interface {
+		InvalidatePartner(ctx context.Context, partnerID string)
+	}
+
+	// PolicyCacheInvalidator is implemented by the providers that cache
+	// policies, to drop a policy the provider told us has changed.
+	PolicyCacheInvalidator interface {
+		InvalidatePolicy(ctx context.Context, policyID string) error
+	}
+
+	// FreshPolicyGetter is implemented by the providers that cache policies,
+	// to ask the provider itself for a policy when a cached copy won't do.
+	FreshPolicyGetter interface {
+		GetFreshPolicy(ctx context.Context, policyID string) (*InsuranceProviderCreatePolicyResponse, error)
+	}
+
+	// QuoteReusePolicy says how a provider lets its quotations back more than
+	// one quote, when quote reuse is enabled.
+	QuoteReusePolicy struct {
+		// AllowShared lets a quotation requested by one partner back the quotes
+		// of other partners. Otherwise partners only reuse their own quotations.
+		AllowShared bool
+		// MinValidity is how long a quotation must still be valid to be reused.
+		MinValidity time.Duration
 	}
 )
@@ -0,0 +1,155 @@
+package partners
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type (
+	// PolicyIssuanceRecoveryResult counts what a run of RecoverPolicyIssuances
+	// did.
+	PolicyIssuanceRecoveryResult struct {
+		Compensated int64
+		Failed      int64
+	}
+)
+
+const (
+	// policyIssuanceTimeout is how long an issuance may stay pending before it
+	// is considered abandoned. It must outlast the request that created it.
+	policyIssuanceTimeout = 5 * time.Minute
+	// policyIssuanceCallTimeout bounds the provider call of an issuance well
+	// within policyIssuanceTimeout, so the provider can't issue a policy after
+	// the issuance was recovered.
+	policyIssuanceCallTimeout = time.Minute
+	policyIssuanceBatchSize   = 50
+)
+
+// RecoverPolicyIssuances resolves the issuances abandoned while pending, such
+// as when the replica handling the request stopped or we failed to store the
+// policy and to cancel it.
+func (s *Servicer) RecoverPolicyIssuances(ctx context.Context) (PolicyIssuanceRecoveryResult, error) {
+	var result PolicyIssuanceRecoveryResult
+
+	issuances, err := s.policyIssuanceRepo.ListPending(ctx, time.Now().Add(-policyIssuanceTimeout), policyIssuanceBatchSize)
+	if err != nil {
+		return result, err
+	}
+
+	var errs []error
+	for index := range issuances {
+		issuance := &issuances[index]
+
+		if err := s.recoverIssuance(ctx, issuance); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		switch issuance.Status {
+		case PolicyIssuanceCompensated:
+			result.Compensated++
+		case PolicyIssuanceFailed:
+			result.Failed++
+		}
+	}
+
+	return result, errors.Join(errs...)
+}
+
+// recoverIssuance asks the provider about an abandoned issuance and cancels
+// its policy if the provider issued one. The partner was answered with an
+// error, so it doesn't expect the policy. When the provider's ID never reached
+// us, the policy is looked up by the issuance ID sent as its client reference.
+func (s *Servicer) recoverIssuance(ctx context.Context, issuance *PolicyIssuanceEntity) error {
+	var (
+		providerPolicy *InsuranceProviderCreatePolicyResponse
+		err            error
+	)
+
+	if issuance.ProviderID == uuid.Nil {
+		providerPolicy, err = s.insuranceProvider.FindPolicyByReference(ctx, issuance.ID)
+	} else {
+		providerPolicy, err = s.insuranceProvider.GetPolicy(ctx, issuance.ProviderID.String())
+	}
+
+	switch {
+	case errors.Is(err, ErrProviderPolicyNotFound):
+		issuance.Status = PolicyIssuanceFailed
+	case err != nil:
+		return err
+	default:
+		issuance.ProviderID = providerPolicy.ID
+
+		if err := s.cancelIssuedPolicy(ctx, issuance); err != nil {
+			return err
+		}
+
+		issuance.Status = PolicyIssuanceCompensated
+	}
+
+	issuance.ResolvedAt = time.Now()
+
+	return s.policyIssuanceRepo.Update(ctx, issuance)
+}
+
+// compensateIssuance cancels at the provider a policy we failed to store, so
+// the customer isn't insured without us knowing. When the cancel fails too,
+// the issuance stays pending for RecoverPolicyIssuances to try again. It
+// returns the cause, joined with ErrPolicyIssuanceUnresolved in that case.
+func (s *Servicer) compensateIssuance(ctx context.Context, issuance *PolicyIssuanceEntity, cause error) error {
+	// The request may have been cancelled, but the compensation must go on.
+	ctx = context.WithoutCancel(ctx)
+
+	issuance.PolicyID = ""
+	issuance.Status = PolicyIssuancePending
+	issuance.ResolvedAt = time.Time{}
+
+	if err := s.cancelIssuedPolicy(ctx, issuance); err != nil {
+		return s.leaveIssuancePending(ctx, issuance, cause, fmt.Errorf("failed to cancel policy %q at the provider: %w", issuance.ProviderID, err))
+	}
+
+	return s.resolveIssuance(ctx, issuance, PolicyIssuanceCompensated, cause)
+}
+
+// leaveIssuancePending saves the cause in the issuance, which stays pending
+// for RecoverPolicyIssuances, and returns the cause joined with
+// ErrPolicyIssuanceUnresolved and the reason it couldn't be resolved.
+func (s *Servicer) leaveIssuancePending(ctx context.Context, issuance *PolicyIssuanceEntity, cause, reason error) error {
+	issuance.LastError = cause.Error()
+
+	errs := []error{cause, fmt.Errorf("%w: issuance %q: %w", ErrPolicyIssuanceUnresolved, issuance.ID, reason)}
+	if err := s.policyIssuanceRepo.Update(context.WithoutCancel(ctx), issuance); err != nil {
+		errs = append(errs, fmt.Errorf("failed to save issuance %q: %w", issuance.ID, err))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (s *Servicer) cancelIssuedPolicy(ctx context.Context, issuance *PolicyIssuanceEntity) error {
+	err := s.insuranceProvider.CancelPolicy(ctx, issuance.ProviderID.String())
+	if errors.Is(err, ErrProviderPolicyNotFound) {
+		return nil
+	}
+
+	return err
+}
+
+// resolveIssuance saves how an issuance ended and returns the cause. Failing
+// to save it leaves the issuance pending for RecoverPolicyIssuances, so the
+// cause is joined with ErrPolicyIssuanceUnresolved for the caller to report,
+// not to fail on.
+func (s *Servicer) resolveIssuance(ctx context.Context, issuance *PolicyIssuanceEntity, status PolicyIssuanceStatusEnum, cause error) error {
+	issuance.Status = status
+	issuance.LastError = cause.Error()
+	issuance.ResolvedAt = time.Now()
+
+	if err := s.policyIssuanceRepo.Update(context.WithoutCancel(ctx), issuance); err != nil {
+		return errors.Join(cause, fmt.Errorf("%w: failed to save issuance %q: %w", ErrPolicyIssuanceUnresolved, issuance.ID, err))
+	}
+
+	return cause
+}
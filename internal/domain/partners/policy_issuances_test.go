@@ -0,0 +1,266 @@
+package partners_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"main-api/internal/domain/partners"
+	mocks "main-api/internal/infra/repository/mocks"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServiceCreatePolicyCompensation(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	partnersRepo := mocks.NewMockPartnerRepository(ctrl)
+	quotesRepo := mocks.NewMockQuotesRepository(ctrl)
+	policyRepo := mocks.NewMockPoliciesRepository(ctrl)
+	policyIssuancesRepo := mocks.NewMockPolicyIssuancesRepository(ctrl)
+	insuranceProvider := mocks.NewMockInsuranceProvider(ctrl)
+
+	transactor, outbox := newTransactionMocks(ctrl)
+
+	service := partners.NewService(partners.ServiceParams{
+		PartnerRepo:             partnersRepo,
+		QuoteRepo:               quotesRepo,
+		PolicyRepo:              policyRepo,
+		PolicyIssuanceRepo:      policyIssuancesRepo,
+		InsuranceClientProvider: insuranceProvider,
+		Transactor:              transactor,
+		Outbox:                  outbox,
+	})
+
+	fakePartner := partners.PartnerEntity{ID: uuid.NewString()}
+	providerPolicy := partners.InsuranceProviderCreatePolicyResponse{ID: uuid.New()}
+	fakeQuote := partners.QuoteEntity{
+		PartnerID: fakePartner.ID,
+		Age:       uint(partners.AgeAt(time.Date(1998, 9, 28, 0, 0, 0, 0, time.UTC), time.Now())),
+		Sex:       "F",
+	}
+
+	newPolicy := func() *partners.PolicyEntity {
+		return &partners.PolicyEntity{
+			QuotationID: uuid.New(),
+			PartnerID:   fakePartner.ID,
+			Sex:         "F",
+			Name:        "policy-test",
+			DateOfBirth: "1998-09-28",
+		}
+	}
+
+	var updates []partners.PolicyIssuanceEntity
+	policyIssuancesRepo.EXPECT().Update(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, issuance *partners.PolicyIssuanceEntity) error {
+			updates = append(updates, *issuance)
+
+			return nil
+		}).
+		AnyTimes()
+
+	// issueAtProvider expects everything up to the provider issuing the policy.
+	issueAtProvider := func() {
+		updates = nil
+
+		partnersRepo.EXPECT().GetByID(gomock.Any(), fakePartner.ID).Return(&fakePartner, nil)
+		quotesRepo.EXPECT().GetByProviderIDAndPartnerID(gomock.Any(), gomock.Any(), fakePartner.ID).Return(&fakeQuote, nil)
+		policyIssuancesRepo.EXPECT().Create(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, issuance *partners.PolicyIssuanceEntity) error {
+				assert.Equal(t, partners.PolicyIssuancePending, issuance.Status)
+				issuance.ID = "67e1ae8c7e7a8b2b0f1e4b30"
+
+				return nil
+			})
+		insuranceProvider.EXPECT().CreatePolicy(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, request partners.InsuranceProviderCreatePolicyRequest) (*partners.InsuranceProviderCreatePolicyResponse, error) {
+				deadline, ok := ctx.Deadline()
+				assert.True(t, ok, "the provider call is bounded")
+				assert.True(t, deadline.Before(time.Now().Add(5*time.Minute)))
+				assert.Equal(t, "67e1ae8c7e7a8b2b0f1e4b30", request.ClientReference)
+
+				return &providerPolicy, nil
+			})
+	}
+
+	t.Run("Should cancel the policy at the provider when storing it fails", func(t *testing.T) {
+		issueAtProvider()
+
+		policyRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(errors.New("write conflict"))
+		insuranceProvider.EXPECT().CancelPolicy(gomock.Any(), providerPolicy.ID.String()).Return(nil)
+
+		createdPolicy, err := service.CreatePolicy(t.Context(), newPolicy())
+
+		assert.Nil(t, createdPolicy)
+		assert.EqualError(t, err, "write conflict")
+
+		last := updates[len(updates)-1]
+		assert.Equal(t, partners.PolicyIssuanceCompensated, last.Status)
+		assert.Equal(t, providerPolicy.ID, last.ProviderID)
+		assert.Equal(t, "write conflict", last.LastError)
+		assert.False(t, last.ResolvedAt.IsZero())
+	})
+
+	t.Run("Should keep the issuance pending when the cancel fails", func(t *testing.T) {
+		issueAtProvider()
+
+		policyRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(errors.New("write conflict"))
+		insuranceProvider.EXPECT().CancelPolicy(gomock.Any(), providerPolicy.ID.String()).Return(errors.New("timeout"))
+
+		createdPolicy, err := service.CreatePolicy(t.Context(), newPolicy())
+
+		assert.Nil(t, createdPolicy)
+		assert.ErrorContains(t, err, "write conflict")
+		assert.ErrorIs(t, err, partners.ErrPolicyIssuanceUnresolved)
+
+		last := updates[len(updates)-1]
+		assert.Equal(t, partners.PolicyIssuancePending, last.Status)
+		assert.Equal(t, providerPolicy.ID, last.ProviderID)
+		assert.Empty(t, last.PolicyID)
+		assert.True(t, last.ResolvedAt.IsZero())
+	})
+
+	t.Run("Should keep the issuance pending when the provider call times out and compensate it on recovery", func(t *testing.T) {
+		updates = nil
+
+		partnersRepo.EXPECT().GetByID(gomock.Any(), fakePartner.ID).Return(&fakePartner, nil)
+		quotesRepo.EXPECT().GetByProviderIDAndPartnerID(gomock.Any(), gomock.Any(), fakePartner.ID).Return(&fakeQuote, nil)
+		policyIssuancesRepo.EXPECT().Create(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, issuance *partners.PolicyIssuanceEntity) error {
+				issuance.ID = "67e1ae8c7e7a8b2b0f1e4b3a"
+
+				return nil
+			})
+		insuranceProvider.EXPECT().CreatePolicy(gomock.Any(), gomock.Any()).Return(nil, context.DeadlineExceeded)
+
+		createdPolicy, err := service.CreatePolicy(t.Context(), newPolicy())
+
+		assert.Nil(t, createdPolicy)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.ErrorIs(t, err, partners.ErrPolicyIssuanceUnresolved)
+
+		pending := updates[len(updates)-1]
+		assert.Equal(t, partners.PolicyIssuancePending, pending.Status)
+		assert.Equal(t, uuid.Nil, pending.ProviderID)
+		assert.Equal(t, context.DeadlineExceeded.Error(), pending.LastError)
+		assert.True(t, pending.ResolvedAt.IsZero())
+
+		// The provider issued the policy after all.
+		policyIssuancesRepo.EXPECT().ListPending(gomock.Any(), gomock.Any(), gomock.Any()).Return([]partners.PolicyIssuanceEntity{pending}, nil)
+		insuranceProvider.EXPECT().FindPolicyByReference(gomock.Any(), pending.ID).Return(&providerPolicy, nil)
+		insuranceProvider.EXPECT().CancelPolicy(gomock.Any(), providerPolicy.ID.String()).Return(nil)
+
+		result, err := service.RecoverPolicyIssuances(t.Context())
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), result.Compensated)
+
+		last := updates[len(updates)-1]
+		assert.Equal(t, partners.PolicyIssuanceCompensated, last.Status)
+		assert.Equal(t, providerPolicy.ID, last.ProviderID)
+		assert.False(t, last.ResolvedAt.IsZero())
+	})
+
+	t.Run("Should fail the issuance when the provider rejects the policy", func(t *testing.T) {
+		updates = nil
+
+		partnersRepo.EXPECT().GetByID(gomock.Any(), fakePartner.ID).Return(&fakePartner, nil)
+		quotesRepo.EXPECT().GetByProviderIDAndPartnerID(gomock.Any(), gomock.Any(), fakePartner.ID).Return(&fakeQuote, nil)
+		policyIssuancesRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+		insuranceProvider.EXPECT().CreatePolicy(gomock.Any(), gomock.Any()).
+			Return(nil, fmt.Errorf("%w: quotation expired", partners.ErrProviderRejected))
+
+		createdPolicy, err := service.CreatePolicy(t.Context(), newPolicy())
+
+		assert.Nil(t, createdPolicy)
+		assert.ErrorIs(t, err, partners.ErrProviderRejected)
+		assert.NotErrorIs(t, err, partners.ErrPolicyIssuanceUnresolved)
+
+		last := updates[len(updates)-1]
+		assert.Equal(t, partners.PolicyIssuanceFailed, last.Status)
+		assert.False(t, last.ResolvedAt.IsZero())
+	})
+}
+
+func TestServiceRecoverPolicyIssuances(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	policyIssuancesRepo := mocks.NewMockPolicyIssuancesRepository(ctrl)
+	insuranceProvider := mocks.NewMockInsuranceProvider(ctrl)
+
+	service := partners.NewService(partners.ServiceParams{
+		PolicyIssuanceRepo:      policyIssuancesRepo,
+		InsuranceClientProvider: insuranceProvider,
+	})
+
+	t.Run("Should resolve the abandoned issuances", func(t *testing.T) {
+		issued := partners.PolicyIssuanceEntity{ID: "67e1ae8c7e7a8b2b0f1e4b31", ProviderID: uuid.New(), Status: partners.PolicyIssuancePending}
+		notIssued := partners.PolicyIssuanceEntity{ID: "67e1ae8c7e7a8b2b0f1e4b32", ProviderID: uuid.New(), Status: partners.PolicyIssuancePending}
+		withoutProviderID := partners.PolicyIssuanceEntity{ID: "67e1ae8c7e7a8b2b0f1e4b33", Status: partners.PolicyIssuancePending}
+		foundByReference := partners.PolicyIssuanceEntity{ID: "67e1ae8c7e7a8b2b0f1e4b35", Status: partners.PolicyIssuancePending}
+		referencedPolicyID := uuid.New()
+
+		policyIssuancesRepo.EXPECT().ListPending(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, createdBefore time.Time, _ int) ([]partners.PolicyIssuanceEntity, error) {
+				assert.True(t, createdBefore.Before(time.Now().Add(-time.Minute)))
+
+				return []partners.PolicyIssuanceEntity{issued, notIssued, withoutProviderID, foundByReference}, nil
+			})
+
+		insuranceProvider.EXPECT().GetPolicy(gomock.Any(), issued.ProviderID.String()).
+			Return(&partners.InsuranceProviderCreatePolicyResponse{ID: issued.ProviderID}, nil)
+		insuranceProvider.EXPECT().CancelPolicy(gomock.Any(), issued.ProviderID.String()).Return(nil)
+		insuranceProvider.EXPECT().GetPolicy(gomock.Any(), notIssued.ProviderID.String()).
+			Return(nil, fmt.Errorf("%w: policy not found", partners.ErrProviderPolicyNotFound))
+		insuranceProvider.EXPECT().FindPolicyByReference(gomock.Any(), withoutProviderID.ID).
+			Return(nil, fmt.Errorf("%w: policy not found", partners.ErrProviderPolicyNotFound))
+		insuranceProvider.EXPECT().FindPolicyByReference(gomock.Any(), foundByReference.ID).
+			Return(&partners.InsuranceProviderCreatePolicyResponse{ID: referencedPolicyID}, nil)
+		insuranceProvider.EXPECT().CancelPolicy(gomock.Any(), referencedPolicyID.String()).Return(nil)
+
+		statuses := make(map[string]partners.PolicyIssuanceStatusEnum)
+		policyIssuancesRepo.EXPECT().Update(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, issuance *partners.PolicyIssuanceEntity) error {
+				assert.False(t, issuance.ResolvedAt.IsZero())
+				statuses[issuance.ID] = issuance.Status
+
+				return nil
+			}).
+			Times(4)
+
+		result, err := service.RecoverPolicyIssuances(t.Context())
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), result.Compensated)
+		assert.Equal(t, int64(2), result.Failed)
+		assert.Equal(t, map[string]partners.PolicyIssuanceStatusEnum{
+			issued.ID:            partners.PolicyIssuanceCompensated,
+			notIssued.ID:         partners.PolicyIssuanceFailed,
+			withoutProviderID.ID: partners.PolicyIssuanceFailed,
+			foundByReference.ID:  partners.PolicyIssuanceCompensated,
+		}, statuses)
+	})
+
+	t.Run("Not should resolve an issuance when the provider fails", func(t *testing.T) {
+		issuance := partners.PolicyIssuanceEntity{ID: "67e1ae8c7e7a8b2b0f1e4b34", ProviderID: uuid.New(), Status: partners.PolicyIssuancePending}
+
+		policyIssuancesRepo.EXPECT().ListPending(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return([]partners.PolicyIssuanceEntity{issuance}, nil)
+		insuranceProvider.EXPECT().GetPolicy(gomock.Any(), issuance.ProviderID.String()).Return(nil, errors.New("timeout"))
+
+		result, err := service.RecoverPolicyIssuances(t.Context())
+
+		assert.EqualError(t, err, "timeout")
+		assert.Zero(t, result.Compensated)
+		assert.Zero(t, result.Failed)
+	})
+}
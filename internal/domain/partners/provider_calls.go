@@ -0,0 +1,33 @@
+package partners
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+type (
+	// ProviderCalls records whether a request reached the insurance provider
+	// with a call that may have changed something there, so a failed request
+	// can be told apart from one that may have issued a quotation or a policy.
+	ProviderCalls struct {
+		called atomic.Bool
+	}
+
+	providerCallsContextKey struct{}
+)
+
+// ProviderCallsContextKey is the context key of the ProviderCalls. It is
+// exported for the web layer, which sets it with fasthttp's SetUserValue.
+var ProviderCallsContextKey = providerCallsContextKey{}
+
+func (p *ProviderCalls) Called() bool {
+	return p.called.Load()
+}
+
+// MarkProviderCalled flags the ProviderCalls of the context, if any, right
+// before a call that changes something at the provider is sent.
+func MarkProviderCalled(ctx context.Context) {
+	if calls, ok := ctx.Value(ProviderCallsContextKey).(*ProviderCalls); ok {
+		calls.called.Store(true)
+	}
+}
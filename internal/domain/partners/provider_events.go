@@ -0,0 +1,117 @@
+package partners
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type (
+	ProviderEventResultEnum string
+
+	// ProviderPolicyEvent is a change of a policy the provider tells us about.
+	ProviderPolicyEvent struct {
+		ID string
+		// PolicyID is the provider's ID of the policy.
+		PolicyID   uuid.UUID
+		Status     PolicyStatusEnum
+		OccurredAt time.Time
+	}
+)
+
+const (
+	ProviderEventApplied ProviderEventResultEnum = "applied"
+	// ProviderEventDuplicate is the result of an event already received.
+	ProviderEventDuplicate ProviderEventResultEnum = "duplicate"
+	// ProviderEventOutdated is the result of an event older than the last
+	// change applied to the policy.
+	ProviderEventOutdated ProviderEventResultEnum = "outdated"
+	// ProviderEventIgnored is the result of an event about a policy that
+	// wasn't issued through us.
+	ProviderEventIgnored ProviderEventResultEnum = "ignored"
+)
+
+// HandleProviderPolicyEvent applies a status change sent by the provider to
+// the policy. Each event is applied once, in the same transaction that
+// records it and stores the domain event for the partner. An applied event
+// may come with ErrPolicyCacheNotInvalidated, which shouldn't fail the request.
+func (s *Servicer) HandleProviderPolicyEvent(ctx context.Context, event ProviderPolicyEvent) (ProviderEventResultEnum, error) {
+	var (
+		result ProviderEventResultEnum
+		policy *PolicyEntity
+	)
+
+	err := s.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.providerEventRepo.Add(ctx, event.ID, time.Now()); err != nil {
+			return err
+		}
+
+		var err error
+		policy, err = s.policyRepo.GetByProviderID(ctx, event.PolicyID)
+		if err != nil {
+			return err
+		}
+
+		if policy == nil {
+			result = ProviderEventIgnored
+			return nil
+		}
+
+		if event.OccurredAt.Before(policy.StatusUpdatedAt) {
+			result = ProviderEventOutdated
+			return nil
+		}
+
+		before := policy.auditFields()
+		previousStatus := policy.Status
+		policy.Status = event.Status
+		policy.StatusUpdatedAt = event.OccurredAt
+
+		if err := s.policyRepo.UpdateStatus(ctx, policy); err != nil {
+			return err
+		}
+
+		result = ProviderEventApplied
+
+		if previousStatus == policy.Status {
+			return nil
+		}
+
+		if err := s.audit(ctx, AuditPolicyStatusChanged, policy.PartnerID, policy.ID, before, policy.auditFields()); err != nil {
+			return err
+		}
+
+		return s.outbox.Add(ctx, NewPolicyStatusChangedEvent(policy, previousStatus))
+	})
+	if errors.Is(err, ErrDuplicateProviderEvent) {
+		return ProviderEventDuplicate, nil
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	if result == ProviderEventApplied {
+		return result, s.invalidateProviderPolicy(ctx, policy.ProviderID.String())
+	}
+
+	return result, nil
+}
+
+// invalidateProviderPolicy drops the provider's policy from the cache, if the
+// provider has one.
+func (s *Servicer) invalidateProviderPolicy(ctx context.Context, providerPolicyID string) error {
+	invalidator, ok := s.insuranceProvider.(PolicyCacheInvalidator)
+	if !ok {
+		return nil
+	}
+
+	if err := invalidator.InvalidatePolicy(ctx, providerPolicyID); err != nil {
+		return fmt.Errorf("%w: policy %q: %w", ErrPolicyCacheNotInvalidated, providerPolicyID, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,192 @@
+package partners_test
+
+import (
+	"context"
+	"errors"
+	"main-api/internal/domain/partners"
+	mocks "main-api/internal/infra/repository/mocks"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type (
+	// cachingProvider is a provider that caches policies.
+	cachingProvider struct {
+		*mocks.MockInsuranceProvider
+		invalidated []string
+		// err is returned by the next invalidation.
+		err error
+	}
+)
+
+func (p *cachingProvider) InvalidatePolicy(_ context.Context, policyID string) error {
+	if err := p.err; err != nil {
+		p.err = nil
+		return err
+	}
+
+	p.invalidated = append(p.invalidated, policyID)
+
+	return nil
+}
+
+func TestServiceHandleProviderPolicyEvent(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	policiesRepo := mocks.NewMockPoliciesRepository(ctrl)
+	providerEventsRepo := mocks.NewMockProviderEventsRepository(ctrl)
+	outbox := mocks.NewMockOutboxRepository(ctrl)
+	provider := &cachingProvider{MockInsuranceProvider: mocks.NewMockInsuranceProvider(ctrl)}
+
+	transactor := mocks.NewMockTransactor(ctrl)
+	transactor.EXPECT().WithTransaction(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+			return fn(ctx)
+		}).
+		AnyTimes()
+
+	service := partners.NewService(partners.ServiceParams{
+		PolicyRepo:              policiesRepo,
+		ProviderEventRepo:       providerEventsRepo,
+		Outbox:                  outbox,
+		Transactor:              transactor,
+		InsuranceClientProvider: provider,
+		AuditRepo:               newAuditLogMock(ctrl),
+	})
+
+	newPolicy := func() *partners.PolicyEntity {
+		return &partners.PolicyEntity{
+			ID:              "67e1ae8c7e7a8b2b0f1e4b10",
+			ProviderID:      uuid.New(),
+			PartnerID:       "67e1ae8c7e7a8b2b0f1e4b11",
+			Status:          partners.PolicyStatusActive,
+			StatusUpdatedAt: time.Now().Add(-time.Hour),
+		}
+	}
+
+	t.Run("Should update the policy status and store the event", func(t *testing.T) {
+		policy := newPolicy()
+		event := partners.ProviderPolicyEvent{
+			ID:         "event-01",
+			PolicyID:   policy.ProviderID,
+			Status:     partners.PolicyStatusCancelled,
+			OccurredAt: time.Now(),
+		}
+
+		providerEventsRepo.EXPECT().Add(gomock.Any(), "event-01", gomock.Any()).Return(nil)
+		policiesRepo.EXPECT().GetByProviderID(gomock.Any(), policy.ProviderID).Return(policy, nil)
+		policiesRepo.EXPECT().UpdateStatus(gomock.Any(), policy).Return(nil)
+		outbox.EXPECT().Add(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, events ...partners.Event) error {
+			assert.Len(t, events, 1)
+			assert.Equal(t, partners.EventPolicyStatusChanged, events[0].Type)
+			assert.Equal(t, policy.PartnerID, events[0].AggregateID)
+
+			payload := events[0].Payload.(partners.PolicyStatusChangedPayload)
+			assert.Equal(t, partners.PolicyStatusActive, payload.PreviousStatus)
+			assert.Equal(t, partners.PolicyStatusCancelled, payload.Status)
+
+			return nil
+		})
+
+		result, err := service.HandleProviderPolicyEvent(t.Context(), event)
+
+		assert.NoError(t, err)
+		assert.Equal(t, partners.ProviderEventApplied, result)
+		assert.Equal(t, partners.PolicyStatusCancelled, policy.Status)
+		assert.Equal(t, event.OccurredAt, policy.StatusUpdatedAt)
+		assert.Contains(t, provider.invalidated, policy.ProviderID.String())
+	})
+
+	t.Run("Should apply the event and report a policy it couldn't drop from the cache", func(t *testing.T) {
+		policy := newPolicy()
+		provider.err = errors.New("redis unavailable")
+
+		providerEventsRepo.EXPECT().Add(gomock.Any(), "event-02", gomock.Any()).Return(nil)
+		policiesRepo.EXPECT().GetByProviderID(gomock.Any(), policy.ProviderID).Return(policy, nil)
+		policiesRepo.EXPECT().UpdateStatus(gomock.Any(), policy).Return(nil)
+		outbox.EXPECT().Add(gomock.Any(), gomock.Any()).Return(nil)
+
+		result, err := service.HandleProviderPolicyEvent(t.Context(), partners.ProviderPolicyEvent{
+			ID:         "event-02",
+			PolicyID:   policy.ProviderID,
+			Status:     partners.PolicyStatusSuspended,
+			OccurredAt: time.Now(),
+		})
+
+		assert.ErrorIs(t, err, partners.ErrPolicyCacheNotInvalidated)
+		assert.Equal(t, partners.ProviderEventApplied, result)
+		assert.Equal(t, partners.PolicyStatusSuspended, policy.Status)
+	})
+
+	t.Run("Should ignore an event already received", func(t *testing.T) {
+		providerEventsRepo.EXPECT().Add(gomock.Any(), "event-01", gomock.Any()).Return(partners.ErrDuplicateProviderEvent)
+
+		result, err := service.HandleProviderPolicyEvent(t.Context(), partners.ProviderPolicyEvent{
+			ID:         "event-01",
+			PolicyID:   uuid.New(),
+			Status:     partners.PolicyStatusCancelled,
+			OccurredAt: time.Now(),
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, partners.ProviderEventDuplicate, result)
+	})
+
+	t.Run("Should ignore an event older than the current status", func(t *testing.T) {
+		policy := newPolicy()
+
+		providerEventsRepo.EXPECT().Add(gomock.Any(), "event-02", gomock.Any()).Return(nil)
+		policiesRepo.EXPECT().GetByProviderID(gomock.Any(), policy.ProviderID).Return(policy, nil)
+
+		result, err := service.HandleProviderPolicyEvent(t.Context(), partners.ProviderPolicyEvent{
+			ID:         "event-02",
+			PolicyID:   policy.ProviderID,
+			Status:     partners.PolicyStatusSuspended,
+			OccurredAt: time.Now().Add(-2 * time.Hour),
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, partners.ProviderEventOutdated, result)
+		assert.Equal(t, partners.PolicyStatusActive, policy.Status)
+	})
+
+	t.Run("Should ignore an event about an unknown policy", func(t *testing.T) {
+		providerEventsRepo.EXPECT().Add(gomock.Any(), "event-03", gomock.Any()).Return(nil)
+		policiesRepo.EXPECT().GetByProviderID(gomock.Any(), gomock.Any()).Return(nil, nil)
+
+		result, err := service.HandleProviderPolicyEvent(t.Context(), partners.ProviderPolicyEvent{
+			ID:         "event-03",
+			PolicyID:   uuid.New(),
+			Status:     partners.PolicyStatusCancelled,
+			OccurredAt: time.Now(),
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, partners.ProviderEventIgnored, result)
+	})
+
+	t.Run("Not should store an event when the status didn't change", func(t *testing.T) {
+		policy := newPolicy()
+
+		providerEventsRepo.EXPECT().Add(gomock.Any(), "event-04", gomock.Any()).Return(nil)
+		policiesRepo.EXPECT().GetByProviderID(gomock.Any(), policy.ProviderID).Return(policy, nil)
+		policiesRepo.EXPECT().UpdateStatus(gomock.Any(), policy).Return(nil)
+
+		result, err := service.HandleProviderPolicyEvent(t.Context(), partners.ProviderPolicyEvent{
+			ID:         "event-04",
+			PolicyID:   policy.ProviderID,
+			Status:     partners.PolicyStatusActive,
+			OccurredAt: time.Now(),
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, partners.ProviderEventApplied, result)
+	})
+}
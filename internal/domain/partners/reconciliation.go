@@ -0,0 +1,306 @@
+package partners
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type (
+	PolicyMismatchKindEnum string
+
+	// PolicyMismatchEntity is a difference found between one of our policies
+	// and the provider's copy of it.
+	PolicyMismatchEntity struct {
+		// RunID groups the mismatches found by the same reconciliation.
+		RunID      string
+		PolicyID   string
+		ProviderID uuid.UUID
+		PartnerID  string
+		Kind       PolicyMismatchKindEnum
+		// Field is the policy field that differs, empty when the whole policy
+		// is missing at the provider.
+		Field string
+		// Local and Provider are the values of the field, left empty for the
+		// holder's personal data.
+		Local    string
+		Provider string
+		// Healed is set when our policy was fixed with the provider's value.
+		Healed     bool
+		DetectedAt time.Time
+	}
+
+	ReconciliationResult struct {
+		RunID      string
+		Checked    int64
+		Mismatches int64
+		Healed     int64
+		// Failed counts the policies that couldn't be checked, such as when
+		// the provider is down. They are checked again on the next run.
+		Failed int64
+		// Errors are why the first of the Failed policies couldn't be checked,
+		// up to reconciliationMaxErrors, since an outage fails them all.
+		Errors []error
+	}
+)
+
+const (
+	// PolicyMismatchMissingAtProvider is a policy the provider doesn't know.
+	PolicyMismatchMissingAtProvider PolicyMismatchKindEnum = "missing_at_provider"
+	// PolicyMismatchMissingField is a field we don't have and the provider
+	// does. It is always healed.
+	PolicyMismatchMissingField PolicyMismatchKindEnum = "missing_field"
+	// PolicyMismatchFieldDiffers is a field whose value differs from the
+	// provider's. Which one is right isn't known, so it is only reported.
+	PolicyMismatchFieldDiffers PolicyMismatchKindEnum = "field_differs"
+)
+
+const (
+	reconciliationPageSize    = 100
+	reconciliationConcurrency = 5
+	reconciliationMaxErrors   = 10
+)
+
+// policyPersonalFields are the policy fields holding the holder's personal
+// data. Their values are kept out of the mismatches, which outlive an erasure
+// of the holder.
+var policyPersonalFields = map[string]bool{
+	"name":          true,
+	"sex":           true,
+	"date_of_birth": true,
+	"beneficiaries": true,
+	"dependents":    true,
+}
+
+// ReconcilePolicies compares every policy with the provider's copy, a page at
+// a time, and records the mismatches found. Fields we lack are filled with
+// the provider's values; any other difference is left for someone to look at.
+func (s *Servicer) ReconcilePolicies(ctx context.Context) (ReconciliationResult, error) {
+	result := ReconciliationResult{RunID: uuid.NewString()}
+
+	afterID := ""
+	for {
+		policies, err := s.policyRepo.ListAfter(ctx, afterID, reconciliationPageSize)
+		if err != nil {
+			return result, err
+		}
+
+		if len(policies) == 0 {
+			return result, nil
+		}
+
+		mismatches := s.reconcilePage(ctx, policies, &result)
+		if len(mismatches) > 0 {
+			if err := s.policyMismatchRepo.Create(ctx, mismatches...); err != nil {
+				return result, err
+			}
+		}
+
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		afterID = policies[len(policies)-1].ID
+	}
+}
+
+// reconcilePage checks the policies of a page with a few requests to the
+// provider at a time, returning the mismatches found in them.
+func (s *Servicer) reconcilePage(ctx context.Context, policies []PolicyEntity, result *ReconciliationResult) []PolicyMismatchEntity {
+	var (
+		wg         sync.WaitGroup
+		mu         sync.Mutex
+		mismatches []PolicyMismatchEntity
+	)
+
+	slots := make(chan struct{}, reconciliationConcurrency)
+
+	for index := range policies {
+		policy := &policies[index]
+
+		wg.Add(1)
+		slots <- struct{}{}
+
+		go func() {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+
+			found, err := s.reconcilePolicy(ctx, policy, result.RunID)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if err != nil {
+				if len(result.Errors) < reconciliationMaxErrors {
+					result.Errors = append(result.Errors, fmt.Errorf("policy %q: %w", policy.ID, err))
+				}
+
+				result.Failed++
+				return
+			}
+
+			result.Checked++
+			result.Mismatches += int64(len(found))
+
+			for _, mismatch := range found {
+				if mismatch.Healed {
+					result.Healed++
+				}
+			}
+
+			mismatches = append(mismatches, found...)
+		}()
+	}
+
+	wg.Wait()
+
+	return mismatches
+}
+
+func (s *Servicer) reconcilePolicy(ctx context.Context, policy *PolicyEntity, runID string) ([]PolicyMismatchEntity, error) {
+	// The holder data of an erased policy is missing on purpose and must not
+	// be filled in with the provider's.
+	if !policy.ErasedAt.IsZero() {
+		return nil, nil
+	}
+
+	newMismatch := func(kind PolicyMismatchKindEnum, field, local, provider string) PolicyMismatchEntity {
+		if policyPersonalFields[field] {
+			local, provider = "", ""
+		}
+
+		return PolicyMismatchEntity{
+			RunID:      runID,
+			PolicyID:   policy.ID,
+			ProviderID: policy.ProviderID,
+			PartnerID:  policy.PartnerID,
+			Kind:       kind,
+			Field:      field,
+			Local:      local,
+			Provider:   provider,
+			Healed:     kind == PolicyMismatchMissingField,
+			DetectedAt: time.Now(),
+		}
+	}
+
+	providerPolicy, err := s.getFreshProviderPolicy(ctx, policy.ProviderID.String())
+	if errors.Is(err, ErrProviderPolicyNotFound) {
+		return []PolicyMismatchEntity{newMismatch(PolicyMismatchMissingAtProvider, "", policy.ProviderID.String(), "")}, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	// A stale copy is what the provider said a while ago, not what it says now.
+	if providerPolicy.Stale {
+		return nil, fmt.Errorf("provider policy %q is stale", policy.ProviderID)
+	}
+
+	var mismatches []PolicyMismatchEntity
+
+	before := policy.auditFields()
+
+	compare := func(field, local, provider string, heal func()) {
+		switch {
+		case local == provider || provider == "":
+		case local == "":
+			heal()
+			mismatches = append(mismatches, newMismatch(PolicyMismatchMissingField, field, local, provider))
+		default:
+			mismatches = append(mismatches, newMismatch(PolicyMismatchFieldDiffers, field, local, provider))
+		}
+	}
+
+	compare("quotation_id", uuidOrEmpty(policy.QuotationID), uuidOrEmpty(providerPolicy.QuotationID), func() {
+		policy.QuotationID = providerPolicy.QuotationID
+	})
+	compare("name", policy.Name, providerPolicy.Name, func() {
+		policy.Name = providerPolicy.Name
+	})
+	compare("sex", string(policy.Sex), providerPolicy.Sex, func() {
+		policy.Sex = SexEnum(providerPolicy.Sex)
+	})
+	compare("date_of_birth", policy.DateOfBirth, providerPolicy.DateOfBirth, func() {
+		policy.DateOfBirth = providerPolicy.DateOfBirth
+	})
+
+	beneficiaries := fromProviderBeneficiaries(providerPolicy.Beneficiaries)
+	if !slices.Equal(policy.Beneficiaries, beneficiaries) {
+		compare("beneficiaries", listOrEmpty(policy.Beneficiaries), listOrEmpty(beneficiaries), func() {
+			policy.Beneficiaries = beneficiaries
+		})
+	}
+
+	dependents := fromProviderDependents(providerPolicy.Dependents)
+	if !slices.Equal(policy.Dependents, dependents) {
+		compare("dependents", listOrEmpty(policy.Dependents), listOrEmpty(dependents), func() {
+			policy.Dependents = dependents
+		})
+	}
+
+	healed := slices.ContainsFunc(mismatches, func(mismatch PolicyMismatchEntity) bool {
+		return mismatch.Healed
+	})
+	if !healed {
+		return mismatches, nil
+	}
+
+	var updated bool
+
+	err = s.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		var err error
+
+		updated, err = s.policyRepo.UpdateProviderData(ctx, policy)
+		if err != nil || !updated {
+			return err
+		}
+
+		return s.audit(ctx, AuditPolicyReconciled, policy.PartnerID, policy.ID, before, policy.auditFields())
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The holder was erased after the page was read, so there's nothing left
+	// to compare.
+	if !updated {
+		return nil, nil
+	}
+
+	return mismatches, nil
+}
+
+// getFreshProviderPolicy asks the provider for the policy, skipping its cache
+// when it has one: a cached copy is what the provider said a while ago, and
+// the policy may have changed there since.
+func (s *Servicer) getFreshProviderPolicy(ctx context.Context, providerPolicyID string) (*InsuranceProviderCreatePolicyResponse, error) {
+	if getter, ok := s.insuranceProvider.(FreshPolicyGetter); ok {
+		return getter.GetFreshPolicy(ctx, providerPolicyID)
+	}
+
+	return s.insuranceProvider.GetPolicy(ctx, providerPolicyID)
+}
+
+func uuidOrEmpty(id uuid.UUID) string {
+	if id == uuid.Nil {
+		return ""
+	}
+
+	return id.String()
+}
+
+func listOrEmpty[T any](items []T) string {
+	if len(items) == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("%+v", items)
+}
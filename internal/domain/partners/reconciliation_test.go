@@ -0,0 +1,259 @@
+package partners_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"main-api/internal/domain/partners"
+	mocks "main-api/internal/infra/repository/mocks"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServiceReconcilePolicies(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	policiesRepo := mocks.NewMockPoliciesRepository(ctrl)
+	mismatchesRepo := mocks.NewMockPolicyMismatchesRepository(ctrl)
+	insuranceProvider := mocks.NewMockInsuranceProvider(ctrl)
+
+	transactor, _ := newTransactionMocks(ctrl)
+
+	service := partners.NewService(partners.ServiceParams{
+		PolicyRepo:              policiesRepo,
+		PolicyMismatchRepo:      mismatchesRepo,
+		InsuranceClientProvider: insuranceProvider,
+		Transactor:              transactor,
+		AuditRepo:               newAuditLogMock(ctrl),
+	})
+
+	newPolicy := func(id string) partners.PolicyEntity {
+		return partners.PolicyEntity{
+			ID:          id,
+			ProviderID:  uuid.New(),
+			QuotationID: uuid.New(),
+			PartnerID:   "67e1ae8c7e7a8b2b0f1e4b11",
+			Name:        "test-policy",
+			Sex:         partners.SexFemale,
+			DateOfBirth: "1998-09-28",
+		}
+	}
+
+	providerCopy := func(policy partners.PolicyEntity) *partners.InsuranceProviderCreatePolicyResponse {
+		return &partners.InsuranceProviderCreatePolicyResponse{
+			ID:          policy.ProviderID,
+			QuotationID: policy.QuotationID,
+			Name:        policy.Name,
+			Sex:         string(policy.Sex),
+			DateOfBirth: policy.DateOfBirth,
+		}
+	}
+
+	t.Run("Should page through the policies without recording matching ones", func(t *testing.T) {
+		first := newPolicy("67e1ae8c7e7a8b2b0f1e4b20")
+		second := newPolicy("67e1ae8c7e7a8b2b0f1e4b21")
+
+		policiesRepo.EXPECT().ListAfter(gomock.Any(), "", gomock.Any()).Return([]partners.PolicyEntity{first}, nil)
+		policiesRepo.EXPECT().ListAfter(gomock.Any(), first.ID, gomock.Any()).Return([]partners.PolicyEntity{second}, nil)
+		policiesRepo.EXPECT().ListAfter(gomock.Any(), second.ID, gomock.Any()).Return(nil, nil)
+		insuranceProvider.EXPECT().GetPolicy(gomock.Any(), first.ProviderID.String()).Return(providerCopy(first), nil)
+		insuranceProvider.EXPECT().GetPolicy(gomock.Any(), second.ProviderID.String()).Return(providerCopy(second), nil)
+
+		result, err := service.ReconcilePolicies(t.Context())
+
+		assert.NoError(t, err)
+		assert.NotEmpty(t, result.RunID)
+		assert.Equal(t, int64(2), result.Checked)
+		assert.Zero(t, result.Mismatches)
+	})
+
+	t.Run("Should fill in the missing fields and report the differing ones", func(t *testing.T) {
+		policy := newPolicy("67e1ae8c7e7a8b2b0f1e4b22")
+		provider := providerCopy(policy)
+		provider.Name = "provider-name"
+		provider.Beneficiaries = []partners.InsuranceProviderBeneficiary{
+			{Name: "beneficiary", Relationship: "spouse", Percentage: 100},
+		}
+
+		policy.QuotationID = uuid.Nil
+		policy.DateOfBirth = ""
+
+		policiesRepo.EXPECT().ListAfter(gomock.Any(), "", gomock.Any()).Return([]partners.PolicyEntity{policy}, nil)
+		policiesRepo.EXPECT().ListAfter(gomock.Any(), policy.ID, gomock.Any()).Return(nil, nil)
+		insuranceProvider.EXPECT().GetPolicy(gomock.Any(), policy.ProviderID.String()).Return(provider, nil)
+		policiesRepo.EXPECT().UpdateProviderData(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, healed *partners.PolicyEntity) (bool, error) {
+				assert.Equal(t, provider.QuotationID, healed.QuotationID)
+				assert.Equal(t, provider.DateOfBirth, healed.DateOfBirth)
+				assert.Len(t, healed.Beneficiaries, 1)
+				assert.Equal(t, "test-policy", healed.Name)
+
+				return true, nil
+			})
+		mismatchesRepo.EXPECT().Create(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, mismatches ...partners.PolicyMismatchEntity) error {
+				kinds := make(map[string]partners.PolicyMismatchKindEnum)
+				for _, mismatch := range mismatches {
+					kinds[mismatch.Field] = mismatch.Kind
+					assert.Equal(t, policy.ID, mismatch.PolicyID)
+					assert.Equal(t, mismatch.Kind == partners.PolicyMismatchMissingField, mismatch.Healed)
+
+					if mismatch.Field == "quotation_id" {
+						assert.Equal(t, provider.QuotationID.String(), mismatch.Provider)
+					} else {
+						assert.Empty(t, mismatch.Local)
+						assert.Empty(t, mismatch.Provider)
+					}
+				}
+
+				assert.Equal(t, map[string]partners.PolicyMismatchKindEnum{
+					"quotation_id":  partners.PolicyMismatchMissingField,
+					"date_of_birth": partners.PolicyMismatchMissingField,
+					"beneficiaries": partners.PolicyMismatchMissingField,
+					"name":          partners.PolicyMismatchFieldDiffers,
+				}, kinds)
+
+				return nil
+			})
+
+		result, err := service.ReconcilePolicies(t.Context())
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), result.Checked)
+		assert.Equal(t, int64(4), result.Mismatches)
+		assert.Equal(t, int64(3), result.Healed)
+	})
+
+	t.Run("Not should fill in the holder data of an erased policy", func(t *testing.T) {
+		policy := newPolicy("67e1ae8c7e7a8b2b0f1e4b25")
+		policy.Erase("erased-test", time.Now())
+
+		policiesRepo.EXPECT().ListAfter(gomock.Any(), "", gomock.Any()).Return([]partners.PolicyEntity{policy}, nil)
+		policiesRepo.EXPECT().ListAfter(gomock.Any(), policy.ID, gomock.Any()).Return(nil, nil)
+
+		result, err := service.ReconcilePolicies(t.Context())
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), result.Checked)
+		assert.Zero(t, result.Mismatches)
+	})
+
+	t.Run("Not should fill in the holder data of a policy erased while it was reconciled", func(t *testing.T) {
+		policy := newPolicy("67e1ae8c7e7a8b2b0f1e4b26")
+		provider := providerCopy(policy)
+
+		policy.DateOfBirth = ""
+
+		policiesRepo.EXPECT().ListAfter(gomock.Any(), "", gomock.Any()).Return([]partners.PolicyEntity{policy}, nil)
+		policiesRepo.EXPECT().ListAfter(gomock.Any(), policy.ID, gomock.Any()).Return(nil, nil)
+		insuranceProvider.EXPECT().GetPolicy(gomock.Any(), policy.ProviderID.String()).Return(provider, nil)
+		policiesRepo.EXPECT().UpdateProviderData(gomock.Any(), gomock.Any()).Return(false, nil)
+
+		result, err := service.ReconcilePolicies(t.Context())
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), result.Checked)
+		assert.Zero(t, result.Mismatches)
+		assert.Zero(t, result.Healed)
+	})
+
+	t.Run("Should report the policies missing at the provider", func(t *testing.T) {
+		policy := newPolicy("67e1ae8c7e7a8b2b0f1e4b23")
+
+		policiesRepo.EXPECT().ListAfter(gomock.Any(), "", gomock.Any()).Return([]partners.PolicyEntity{policy}, nil)
+		policiesRepo.EXPECT().ListAfter(gomock.Any(), policy.ID, gomock.Any()).Return(nil, nil)
+		insuranceProvider.EXPECT().GetPolicy(gomock.Any(), policy.ProviderID.String()).
+			Return(nil, fmt.Errorf("%w: policy not found", partners.ErrProviderPolicyNotFound))
+		mismatchesRepo.EXPECT().Create(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, mismatches ...partners.PolicyMismatchEntity) error {
+				assert.Len(t, mismatches, 1)
+				assert.Equal(t, partners.PolicyMismatchMissingAtProvider, mismatches[0].Kind)
+				assert.False(t, mismatches[0].Healed)
+
+				return nil
+			})
+
+		result, err := service.ReconcilePolicies(t.Context())
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), result.Mismatches)
+		assert.Zero(t, result.Healed)
+	})
+
+	t.Run("Not should stop the run when the provider fails for a policy", func(t *testing.T) {
+		failing := newPolicy("67e1ae8c7e7a8b2b0f1e4b24")
+		stale := newPolicy("67e1ae8c7e7a8b2b0f1e4b25")
+		staleCopy := providerCopy(stale)
+		staleCopy.Name = "outdated-name"
+		staleCopy.Stale = true
+
+		policiesRepo.EXPECT().ListAfter(gomock.Any(), "", gomock.Any()).Return([]partners.PolicyEntity{failing, stale}, nil)
+		policiesRepo.EXPECT().ListAfter(gomock.Any(), stale.ID, gomock.Any()).Return(nil, nil)
+		insuranceProvider.EXPECT().GetPolicy(gomock.Any(), failing.ProviderID.String()).Return(nil, errors.New("timeout"))
+		insuranceProvider.EXPECT().GetPolicy(gomock.Any(), stale.ProviderID.String()).Return(staleCopy, nil)
+
+		result, err := service.ReconcilePolicies(t.Context())
+
+		assert.NoError(t, err)
+		assert.Zero(t, result.Checked)
+		assert.Equal(t, int64(2), result.Failed)
+		assert.Len(t, result.Errors, 2)
+		assert.ErrorContains(t, errors.Join(result.Errors...), "timeout")
+	})
+}
+
+type (
+	// freshProvider is a provider that caches policies and can skip its cache.
+	freshProvider struct {
+		*mocks.MockInsuranceProvider
+		fresh map[string]*partners.InsuranceProviderCreatePolicyResponse
+	}
+)
+
+func (p *freshProvider) GetFreshPolicy(_ context.Context, policyID string) (*partners.InsuranceProviderCreatePolicyResponse, error) {
+	return p.fresh[policyID], nil
+}
+
+func TestServiceReconcilePoliciesSkipsTheProviderCache(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	policiesRepo := mocks.NewMockPoliciesRepository(ctrl)
+	policy := partners.PolicyEntity{
+		ID:         "67e1ae8c7e7a8b2b0f1e4b20",
+		ProviderID: uuid.New(),
+		PartnerID:  "67e1ae8c7e7a8b2b0f1e4b11",
+		Name:       "test-policy",
+	}
+
+	// GetPolicy isn't expected: the cached copy mustn't be read.
+	provider := &freshProvider{
+		MockInsuranceProvider: mocks.NewMockInsuranceProvider(ctrl),
+		fresh: map[string]*partners.InsuranceProviderCreatePolicyResponse{
+			policy.ProviderID.String(): {ID: policy.ProviderID, Name: policy.Name},
+		},
+	}
+
+	service := partners.NewService(partners.ServiceParams{
+		PolicyRepo:              policiesRepo,
+		InsuranceClientProvider: provider,
+	})
+
+	policiesRepo.EXPECT().ListAfter(gomock.Any(), "", gomock.Any()).Return([]partners.PolicyEntity{policy}, nil)
+	policiesRepo.EXPECT().ListAfter(gomock.Any(), policy.ID, gomock.Any()).Return(nil, nil)
+
+	result, err := service.ReconcilePolicies(t.Context())
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), result.Checked)
+	assert.Zero(t, result.Mismatches)
+}
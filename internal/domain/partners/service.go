@@ -2,37 +2,108 @@ package partners
 
 import (
 	"context"
+	"errors"
+	"time"
 )
 
 type (
 	Service interface {
 		CreatePartner(ctx context.Context, partner *PartnerEntity) (*PartnerEntity, error)
+		GetPartner(ctx context.Context, partnerID string) (*PartnerEntity, error)
+		UpdatePartner(ctx context.Context, partner *PartnerEntity) (*PartnerEntity, error)
+		UpdatePartnerLimits(ctx context.Context, partnerID string, limits PartnerLimitsEntity) (*PartnerEntity, error)
+		SuspendPartner(ctx context.Context, partnerID string) (*PartnerEntity, error)
+		UnsuspendPartner(ctx context.Context, partnerID string) (*PartnerEntity, error)
 		CreateQuote(ctx context.Context, quote *QuoteEntity) (*QuoteEntity, error)
 		CreatePolicy(ctx context.Context, policy *PolicyEntity) (*PolicyEntity, error)
 		GetPolicy(ctx context.Context, partnerID, policyID string) (*PolicyEntity, error)
+		ListPoliciesByCpf(ctx context.Context, partnerID, cpf string) ([]PolicyEntity, error)
+		CreateCustomer(ctx context.Context, customer *CustomerEntity) (*CustomerEntity, error)
+		GetCustomer(ctx context.Context, partnerID, customerID string) (*CustomerEntity, error)
+		ListCustomers(ctx context.Context, partnerID string) ([]CustomerEntity, error)
+		UpdateCustomer(ctx context.Context, customer *CustomerEntity) (*CustomerEntity, error)
+		DeleteCustomer(ctx context.Context, partnerID, customerID string) error
+		ListCustomerPolicies(ctx context.Context, partnerID, customerID string) ([]PolicyEntity, error)
+		ExpireQuotes(ctx context.Context) (int64, error)
+		RegisterWebhook(ctx context.Context, webhook *WebhookEntity) (*WebhookEntity, string, error)
+		ListWebhooks(ctx context.Context, partnerID string) ([]WebhookEntity, error)
+		DeleteWebhook(ctx context.Context, partnerID, webhookID string) error
+		RotateWebhookSecret(ctx context.Context, partnerID string) (string, error)
+		ListWebhookDeliveries(ctx context.Context, partnerID string, status WebhookDeliveryStatusEnum) ([]WebhookDeliveryEntity, error)
+		RedeliverWebhook(ctx context.Context, partnerID, deliveryID string) (*WebhookDeliveryEntity, error)
+		EnqueueWebhookDeliveries(ctx context.Context, event Event) error
+		DeliverWebhooks(ctx context.Context) (WebhookDeliveryResult, error)
+		HandleProviderPolicyEvent(ctx context.Context, event ProviderPolicyEvent) (ProviderEventResultEnum, error)
+		ReconcilePolicies(ctx context.Context) (ReconciliationResult, error)
+		RecoverPolicyIssuances(ctx context.Context) (PolicyIssuanceRecoveryResult, error)
+		ListAuditEvents(ctx context.Context, filter AuditEventFilter) ([]AuditEventEntity, error)
+		VerifyAuditLog(ctx context.Context) (AuditVerification, error)
+		ExportDataSubject(ctx context.Context, cpf string) (*DataSubjectExport, error)
+		EraseDataSubject(ctx context.Context, cpf string) (DataSubjectErasure, error)
 	}
 
 	Servicer struct {
-		partnerRepo       PartnerRepository
-		quoteRepo         QuotesRepository
-		policyRepo        PoliciesRepository
-		insuranceProvider InsuranceProvider
+		partnerRepo         PartnerRepository
+		quoteRepo           QuotesRepository
+		policyRepo          PoliciesRepository
+		customerRepo        CustomersRepository
+		webhookRepo         WebhooksRepository
+		webhookDeliveryRepo WebhookDeliveriesRepository
+		webhookSender       WebhookSender
+		providerEventRepo   ProviderEventsRepository
+		policyMismatchRepo  PolicyMismatchesRepository
+		policyIssuanceRepo  PolicyIssuancesRepository
+		auditRepo           AuditLogRepository
+		outbox              OutboxRepository
+		transactor          Transactor
+		insuranceProvider   InsuranceProvider
+		ageTolerance        time.Duration
+		reuseQuotes         bool
 	}
 
 	ServiceParams struct {
 		PartnerRepo             PartnerRepository
 		QuoteRepo               QuotesRepository
 		PolicyRepo              PoliciesRepository
+		CustomerRepo            CustomersRepository
+		WebhookRepo             WebhooksRepository
+		WebhookDeliveryRepo     WebhookDeliveriesRepository
+		WebhookSender           WebhookSender
+		ProviderEventRepo       ProviderEventsRepository
+		PolicyMismatchRepo      PolicyMismatchesRepository
+		PolicyIssuanceRepo      PolicyIssuancesRepository
+		AuditRepo               AuditLogRepository
+		Outbox                  OutboxRepository
+		Transactor              Transactor
 		InsuranceClientProvider InsuranceProvider
+		// AgeToleranceDays is how many days around the holder's birthday the
+		// quoted age is still accepted for the date of birth.
+		AgeToleranceDays int
+		// ReuseQuotes answers quotes with an unexpired provider quotation for
+		// the same age and sex, when the provider's QuoteReusePolicy allows it,
+		// instead of requesting a new one.
+		ReuseQuotes bool
 	}
 )
 
 func NewService(data ServiceParams) *Servicer {
 	return &Servicer{
-		partnerRepo:       data.PartnerRepo,
-		quoteRepo:         data.QuoteRepo,
-		policyRepo:        data.PolicyRepo,
-		insuranceProvider: data.InsuranceClientProvider,
+		partnerRepo:         data.PartnerRepo,
+		quoteRepo:           data.QuoteRepo,
+		policyRepo:          data.PolicyRepo,
+		customerRepo:        data.CustomerRepo,
+		webhookRepo:         data.WebhookRepo,
+		webhookDeliveryRepo: data.WebhookDeliveryRepo,
+		webhookSender:       data.WebhookSender,
+		providerEventRepo:   data.ProviderEventRepo,
+		policyMismatchRepo:  data.PolicyMismatchRepo,
+		policyIssuanceRepo:  data.PolicyIssuanceRepo,
+		auditRepo:           data.AuditRepo,
+		outbox:              data.Outbox,
+		transactor:          data.Transactor,
+		insuranceProvider:   data.InsuranceClientProvider,
+		ageTolerance:        time.Duration(data.AgeToleranceDays) * 24 * time.Hour,
+		reuseQuotes:         data.ReuseQuotes,
 	}
 }
 
@@ -46,14 +117,147 @@ func (s *Servicer) CreatePartner(ctx context.Context, partner *PartnerEntity) (*
 		return nil, ErrPartnerAlreadyExists
 	}
 
-	err = s.partnerRepo.Create(ctx, partner)
+	err = s.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.partnerRepo.Create(ctx, partner); err != nil {
+			return err
+		}
+
+		if err := s.audit(ctx, AuditPartnerCreated, partner.ID, partner.ID, nil, partner.auditFields()); err != nil {
+			return err
+		}
+
+		return s.outbox.Add(ctx, NewPartnerCreatedEvent(partner))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidatePartner(ctx, partner.ID)
+
+	return partner, nil
+}
+
+func (s *Servicer) GetPartner(ctx context.Context, partnerID string) (*PartnerEntity, error) {
+	partner, err := s.partnerRepo.GetByID(ctx, partnerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if partner == nil {
+		return nil, ErrPartnerNotFound
+	}
+
+	return partner, nil
+}
+
+// UpdatePartner changes the name and language of a partner. The CNPJ
+// identifies the partner and can't be changed, and its limits are changed with
+// UpdatePartnerLimits.
+func (s *Servicer) UpdatePartner(ctx context.Context, partner *PartnerEntity) (*PartnerEntity, error) {
+	current, err := s.GetPartner(ctx, partner.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	before := current.auditFields()
+
+	current.Name = partner.Name
+	current.Language = partner.Language
+	current.UpdatedAt = time.Now()
+
+	err = s.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.partnerRepo.Update(ctx, current); err != nil {
+			return err
+		}
+
+		return s.audit(ctx, AuditPartnerUpdated, current.ID, current.ID, before, current.auditFields())
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidatePartner(ctx, current.ID)
+
+	return current, nil
+}
+
+// UpdatePartnerLimits overrides the default rate limit and daily quotes of a
+// partner. A zero limit falls back to the default.
+func (s *Servicer) UpdatePartnerLimits(ctx context.Context, partnerID string, limits PartnerLimitsEntity) (*PartnerEntity, error) {
+	partner, err := s.GetPartner(ctx, partnerID)
+	if err != nil {
+		return nil, err
+	}
+
+	before := partner.auditFields()
+
+	partner.Limits = limits
+	partner.UpdatedAt = time.Now()
+
+	err = s.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.partnerRepo.Update(ctx, partner); err != nil {
+			return err
+		}
+
+		return s.audit(ctx, AuditPartnerLimitsUpdated, partner.ID, partner.ID, before, partner.auditFields())
+	})
 	if err != nil {
 		return nil, err
 	}
 
+	s.invalidatePartner(ctx, partner.ID)
+
+	return partner, nil
+}
+
+func (s *Servicer) SuspendPartner(ctx context.Context, partnerID string) (*PartnerEntity, error) {
+	return s.setPartnerSuspended(ctx, partnerID, true, AuditPartnerSuspended)
+}
+
+// UnsuspendPartner lets a suspended partner create quotes and policies again.
+func (s *Servicer) UnsuspendPartner(ctx context.Context, partnerID string) (*PartnerEntity, error) {
+	return s.setPartnerSuspended(ctx, partnerID, false, AuditPartnerUnsuspended)
+}
+
+func (s *Servicer) setPartnerSuspended(ctx context.Context, partnerID string, suspended bool, action AuditActionEnum) (*PartnerEntity, error) {
+	partner, err := s.GetPartner(ctx, partnerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if partner.Suspended == suspended {
+		return partner, nil
+	}
+
+	before := partner.auditFields()
+
+	partner.Suspended = suspended
+	partner.UpdatedAt = time.Now()
+
+	err = s.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.partnerRepo.Update(ctx, partner); err != nil {
+			return err
+		}
+
+		return s.audit(ctx, action, partner.ID, partner.ID, before, partner.auditFields())
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidatePartner(ctx, partner.ID)
+
 	return partner, nil
 }
 
+// invalidatePartner drops the cached partner, if the partner repository caches
+// them, once its change is committed.
+func (s *Servicer) invalidatePartner(ctx context.Context, partnerID string) {
+	if invalidator, ok := s.partnerRepo.(PartnerCacheInvalidator); ok {
+		invalidator.InvalidatePartner(ctx, partnerID)
+	}
+}
+
 func (s *Servicer) CreateQuote(ctx context.Context, quote *QuoteEntity) (*QuoteEntity, error) {
 	partner, err := s.partnerRepo.GetByID(ctx, quote.PartnerID)
 	if err != nil {
@@ -64,6 +268,21 @@ func (s *Servicer) CreateQuote(ctx context.Context, quote *QuoteEntity) (*QuoteE
 		return nil, ErrPartnerNotFound
 	}
 
+	if partner.Suspended {
+		return nil, ErrPartnerSuspended
+	}
+
+	if s.reuseQuotes {
+		reusedQuote, err := s.reuseQuote(ctx, quote)
+		if err != nil {
+			return nil, err
+		}
+
+		if reusedQuote != nil {
+			return reusedQuote, nil
+		}
+	}
+
 	response, err := s.insuranceProvider.CreateQuotation(ctx, InsuranceProviderCreateQuotationRequest{
 		Age: quote.Age,
 		Sex: quote.Sex,
@@ -78,6 +297,7 @@ func (s *Servicer) CreateQuote(ctx context.Context, quote *QuoteEntity) (*QuoteE
 		Sex:        response.Sex,
 		Price:      response.Price,
 		PartnerID:  quote.PartnerID,
+		Status:     QuoteStatusActive,
 		CreatedAt:  quote.CreatedAt,
 	}
 
@@ -86,7 +306,7 @@ func (s *Servicer) CreateQuote(ctx context.Context, quote *QuoteEntity) (*QuoteE
 		return nil, err
 	}
 
-	err = s.quoteRepo.Create(ctx, quoteCreated)
+	err = s.createQuote(ctx, quoteCreated)
 	if err != nil {
 		return nil, err
 	}
@@ -94,7 +314,73 @@ func (s *Servicer) CreateQuote(ctx context.Context, quote *QuoteEntity) (*QuoteE
 	return quoteCreated, nil
 }
 
+// reuseQuote mints a quote for the partner backed by an unexpired provider
+// quotation for the same age and sex. It returns nil when there is none the
+// provider lets the partner reuse.
+func (s *Servicer) reuseQuote(ctx context.Context, quote *QuoteEntity) (*QuoteEntity, error) {
+	policy := s.insuranceProvider.QuoteReusePolicy()
+
+	partnerID := quote.PartnerID
+	if policy.AllowShared {
+		partnerID = ""
+	}
+
+	existing, err := s.quoteRepo.GetReusable(ctx, quote.Age, quote.Sex, partnerID, time.Now().Add(policy.MinValidity))
+	if err != nil || existing == nil {
+		return nil, err
+	}
+
+	reusedFromID := existing.ID
+	if existing.ReusedFromID != "" {
+		reusedFromID = existing.ReusedFromID
+	}
+
+	reusedQuote := &QuoteEntity{
+		ProviderID:   existing.ProviderID,
+		Age:          existing.Age,
+		Sex:          existing.Sex,
+		Price:        existing.Price,
+		PartnerID:    quote.PartnerID,
+		ExpiresAt:    existing.ExpiresAt,
+		Status:       QuoteStatusActive,
+		CreatedAt:    quote.CreatedAt,
+		ReusedFromID: reusedFromID,
+	}
+
+	err = s.createQuote(ctx, reusedQuote)
+	if err != nil {
+		return nil, err
+	}
+
+	return reusedQuote, nil
+}
+
+// createQuote stores the quote along with its QuoteCreated event.
+func (s *Servicer) createQuote(ctx context.Context, quote *QuoteEntity) error {
+	return s.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.quoteRepo.Create(ctx, quote); err != nil {
+			return err
+		}
+
+		if err := s.audit(ctx, AuditQuoteCreated, quote.PartnerID, quote.ID, nil, quote.auditFields()); err != nil {
+			return err
+		}
+
+		return s.outbox.Add(ctx, NewQuoteCreatedEvent(quote))
+	})
+}
+
+// ExpireQuotes marks the active quotes past their expiration date as expired,
+// returning how many were marked.
+func (s *Servicer) ExpireQuotes(ctx context.Context) (int64, error) {
+	return s.quoteRepo.MarkExpired(ctx, time.Now())
+}
+
 func (s *Servicer) CreatePolicy(ctx context.Context, policy *PolicyEntity) (*PolicyEntity, error) {
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+
 	partner, err := s.partnerRepo.GetByID(ctx, policy.PartnerID)
 	if err != nil {
 		return nil, err
@@ -104,20 +390,96 @@ func (s *Servicer) CreatePolicy(ctx context.Context, policy *PolicyEntity) (*Pol
 		return nil, ErrPartnerNotFound
 	}
 
-	response, err := s.insuranceProvider.CreatePolicy(ctx, InsuranceProviderCreatePolicyRequest{
+	if partner.Suspended {
+		return nil, ErrPartnerSuspended
+	}
+
+	if policy.CustomerID != "" {
+		customer, err := s.customerRepo.GetByIDAndPartnerID(ctx, policy.CustomerID, policy.PartnerID)
+		if err != nil {
+			return nil, err
+		}
+
+		if customer == nil {
+			return nil, ErrCustomerNotFound
+		}
+
+		policy.FillHolder(customer)
+	}
+
+	err = s.validateHolderAge(ctx, policy)
+	if err != nil {
+		return nil, err
+	}
+
+	issuance := &PolicyIssuanceEntity{
+		PartnerID:   policy.PartnerID,
 		QuotationID: policy.QuotationID,
-		Name:        policy.Name,
-		Sex:         string(policy.Sex),
-		DateOfBirth: policy.DateOfBirth,
-	})
+		Status:      PolicyIssuancePending,
+		CreatedAt:   time.Now(),
+	}
+
+	err = s.policyIssuanceRepo.Create(ctx, issuance)
 	if err != nil {
 		return nil, err
 	}
 
+	callCtx, cancel := context.WithTimeout(ctx, policyIssuanceCallTimeout)
+	defer cancel()
+
+	response, err := s.insuranceProvider.CreatePolicy(callCtx, InsuranceProviderCreatePolicyRequest{
+		QuotationID:     policy.QuotationID,
+		Name:            policy.Name,
+		Sex:             string(policy.Sex),
+		DateOfBirth:     policy.DateOfBirth,
+		Beneficiaries:   toProviderBeneficiaries(policy.Beneficiaries),
+		Dependents:      toProviderDependents(policy.Dependents),
+		ClientReference: issuance.ID,
+	})
+	if errors.Is(err, ErrProviderRejected) {
+		return nil, s.resolveIssuance(ctx, issuance, PolicyIssuanceFailed, err)
+	}
+
+	// Short of a rejection, the provider may have issued the policy before the
+	// call failed, so it's left for RecoverPolicyIssuances to look it up.
+	if err != nil {
+		return nil, s.leaveIssuancePending(ctx, issuance, err, errors.New("the provider may have issued the policy"))
+	}
+
 	policy.ProviderID = response.ID
-	err = s.policyRepo.Create(ctx, policy)
+	policy.Status = PolicyStatusActive
+	policy.StatusUpdatedAt = time.Now()
+
+	// The provider's ID is saved before the policy, so an issuance abandoned
+	// after this point can still be compensated.
+	issuance.ProviderID = response.ID
+
+	err = s.policyIssuanceRepo.Update(ctx, issuance)
 	if err != nil {
-		return nil, err
+		return nil, s.compensateIssuance(ctx, issuance, err)
+	}
+
+	err = s.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.policyRepo.Create(ctx, policy); err != nil {
+			return err
+		}
+
+		issuance.PolicyID = policy.ID
+		issuance.Status = PolicyIssuanceIssued
+		issuance.ResolvedAt = time.Now()
+
+		if err := s.policyIssuanceRepo.Update(ctx, issuance); err != nil {
+			return err
+		}
+
+		if err := s.audit(ctx, AuditPolicyIssued, policy.PartnerID, policy.ID, nil, policy.auditFields()); err != nil {
+			return err
+		}
+
+		return s.outbox.Add(ctx, NewPolicyIssuedEvent(policy))
+	})
+	if err != nil {
+		return nil, s.compensateIssuance(ctx, issuance, err)
 	}
 
 	return policy, nil
@@ -142,16 +504,136 @@ func (s *Servicer) GetPolicy(ctx context.Context, partnerID, policyID string) (*
 		return nil, ErrPolicyNotFound
 	}
 
+	// The provider still has the personal data of an erased holder, which
+	// must not be shown again.
+	if !userHasPolicy.ErasedAt.IsZero() {
+		return userHasPolicy, nil
+	}
+
 	policy, err := s.insuranceProvider.GetPolicy(ctx, userHasPolicy.ProviderID.String())
 	if err != nil {
 		return nil, err
 	}
 
 	return &PolicyEntity{
-		ID:          userHasPolicy.ID,
-		Sex:         SexEnum(policy.Sex),
-		Name:        policy.Name,
-		QuotationID: policy.QuotationID,
-		DateOfBirth: policy.DateOfBirth,
+		ID:            userHasPolicy.ID,
+		Sex:           SexEnum(policy.Sex),
+		Name:          policy.Name,
+		QuotationID:   policy.QuotationID,
+		DateOfBirth:   policy.DateOfBirth,
+		Cpf:           userHasPolicy.Cpf,
+		Email:         userHasPolicy.Email,
+		Phone:         userHasPolicy.Phone,
+		PartnerID:     userHasPolicy.PartnerID,
+		CustomerID:    userHasPolicy.CustomerID,
+		Beneficiaries: fromProviderBeneficiaries(policy.Beneficiaries),
+		Dependents:    fromProviderDependents(policy.Dependents),
+		Status:        userHasPolicy.Status,
+		Stale:         policy.Stale,
 	}, nil
 }
+
+func (s *Servicer) ListPoliciesByCpf(ctx context.Context, partnerID, cpf string) ([]PolicyEntity, error) {
+	partner, err := s.partnerRepo.GetByID(ctx, partnerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if partner == nil {
+		return nil, ErrPartnerNotFound
+	}
+
+	return s.policyRepo.ListByPartnerIDAndCpf(ctx, partnerID, cpf)
+}
+
+func (s *Servicer) validateHolderAge(ctx context.Context, policy *PolicyEntity) error {
+	dateOfBirth, err := ParseDateOfBirth(policy.DateOfBirth)
+	if err != nil {
+		return err
+	}
+
+	quote, err := s.quoteRepo.GetByProviderIDAndPartnerID(ctx, policy.QuotationID, policy.PartnerID)
+	if err != nil {
+		return err
+	}
+
+	if quote == nil {
+		return ErrQuoteNotFound
+	}
+
+	if !quote.MatchesDateOfBirth(dateOfBirth, time.Now(), s.ageTolerance) {
+		return ErrDateOfBirthAgeMismatch
+	}
+
+	return nil
+}
+
+func toProviderBeneficiaries(beneficiaries []BeneficiaryEntity) []InsuranceProviderBeneficiary {
+	if len(beneficiaries) == 0 {
+		return nil
+	}
+
+	result := make([]InsuranceProviderBeneficiary, len(beneficiaries))
+	for index, beneficiary := range beneficiaries {
+		result[index] = InsuranceProviderBeneficiary{
+			Name:         beneficiary.Name,
+			Relationship: string(beneficiary.Relationship),
+			Percentage:   beneficiary.Percentage,
+		}
+	}
+
+	return result
+}
+
+func toProviderDependents(dependents []DependentEntity) []InsuranceProviderDependent {
+	if len(dependents) == 0 {
+		return nil
+	}
+
+	result := make([]InsuranceProviderDependent, len(dependents))
+	for index, dependent := range dependents {
+		result[index] = InsuranceProviderDependent{
+			Name:         dependent.Name,
+			Sex:          string(dependent.Sex),
+			DateOfBirth:  dependent.DateOfBirth,
+			Relationship: string(dependent.Relationship),
+		}
+	}
+
+	return result
+}
+
+func fromProviderBeneficiaries(beneficiaries []InsuranceProviderBeneficiary) []BeneficiaryEntity {
+	if len(beneficiaries) == 0 {
+		return nil
+	}
+
+	result := make([]BeneficiaryEntity, len(beneficiaries))
+	for index, beneficiary := range beneficiaries {
+		result[index] = BeneficiaryEntity{
+			Name:         beneficiary.Name,
+			Relationship: RelationshipEnum(beneficiary.Relationship),
+			Percentage:   beneficiary.Percentage,
+		}
+	}
+
+	return result
+}
+
+func fromProviderDependents(dependents []InsuranceProviderDependent) []DependentEntity {
+	if len(dependents) == 0 {
+		return nil
+	}
+
+	result := make([]DependentEntity, len(dependents))
+	for index, dependent := range dependents {
+		result[index] = DependentEntity{
+			Name:         dependent.Name,
+			Sex:          SexEnum(dependent.Sex),
+			DateOfBirth:  dependent.DateOfBirth,
+			Relationship: RelationshipEnum(dependent.Relationship),
+		}
+	}
+
+	return result
+}
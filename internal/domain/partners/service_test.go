@@ -3,6 +3,7 @@ package partners_test
 import (
 	"context"
 	"errors"
+	"fmt"
 	"main-api/internal/domain/partners"
 	mocks "main-api/internal/infra/repository/mocks"
 	"testing"
@@ -21,12 +22,17 @@ func TestServiceCreatePartner(t *testing.T) {
 
 	partnersRepo := mocks.NewMockPartnerRepository(ctrl)
 
+	transactor, outbox := newTransactionMocks(ctrl)
+
 	service := partners.NewService(partners.ServiceParams{
 		PartnerRepo: partnersRepo,
+		Transactor:  transactor,
+		Outbox:      outbox,
+		AuditRepo:   newAuditLogMock(ctrl),
 	})
 
 	t.Run("Should return success when creating a partner", func(t *testing.T) {
-		partner := partners.NewEntity("180 Seguros", "12345678901234")
+		partner := partners.NewEntity("180 Seguros", "12345678901234", "pt-BR")
 
 		partnersRepo.EXPECT().GetByFilter(gomock.Any(), gomock.Any()).Return(nil, nil)
 		partnersRepo.EXPECT().Create(gomock.Any(), partner).Return(nil)
@@ -38,7 +44,7 @@ func TestServiceCreatePartner(t *testing.T) {
 	})
 
 	t.Run("Should return error when partner already exists", func(t *testing.T) {
-		partner := partners.NewEntity("180 Seguros", "12345678901234")
+		partner := partners.NewEntity("180 Seguros", "12345678901234", "pt-BR")
 
 		partnersRepo.EXPECT().GetByFilter(gomock.Any(), gomock.Any()).Return(partner, nil)
 
@@ -49,7 +55,7 @@ func TestServiceCreatePartner(t *testing.T) {
 	})
 
 	t.Run("Should return error when creating a partner and have an internal error", func(t *testing.T) {
-		partner := partners.NewEntity("180 Seguros", "12345678901234")
+		partner := partners.NewEntity("180 Seguros", "12345678901234", "pt-BR")
 
 		partnersRepo.EXPECT().GetByFilter(gomock.Any(), gomock.Any()).Return(nil, errors.New("internal error"))
 
@@ -60,6 +66,332 @@ func TestServiceCreatePartner(t *testing.T) {
 	})
 }
 
+func TestServiceEvents(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	partnersRepo := mocks.NewMockPartnerRepository(ctrl)
+	outbox := mocks.NewMockOutboxRepository(ctrl)
+	transactor := mocks.NewMockTransactor(ctrl)
+
+	service := partners.NewService(partners.ServiceParams{
+		PartnerRepo: partnersRepo,
+		Outbox:      outbox,
+		Transactor:  transactor,
+		AuditRepo:   newAuditLogMock(ctrl),
+	})
+
+	runInTransaction := func(ctx context.Context, fn func(ctx context.Context) error) error {
+		return fn(ctx)
+	}
+
+	t.Run("Should store the partner created event in the same transaction", func(t *testing.T) {
+		partner := partners.NewEntity("180 Seguros", "12345678901234", "pt-BR")
+
+		partnersRepo.EXPECT().GetByFilter(gomock.Any(), gomock.Any()).Return(nil, nil)
+		transactor.EXPECT().WithTransaction(gomock.Any(), gomock.Any()).DoAndReturn(runInTransaction)
+		partnersRepo.EXPECT().Create(gomock.Any(), partner).DoAndReturn(func(_ context.Context, partner *partners.PartnerEntity) error {
+			partner.ID = "67e1ae8c7e7a8b2b0f1e4b10"
+
+			return nil
+		})
+		outbox.EXPECT().Add(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, events ...partners.Event) error {
+			assert.Len(t, events, 1)
+			assert.Equal(t, partners.EventPartnerCreated, events[0].Type)
+			assert.Equal(t, "67e1ae8c7e7a8b2b0f1e4b10", events[0].AggregateID)
+			assert.Equal(t, "180 Seguros", events[0].Payload.(partners.PartnerCreatedPayload).Name)
+
+			return nil
+		})
+
+		_, err := service.CreatePartner(t.Context(), partner)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("Should fail the creation when the event can't be stored", func(t *testing.T) {
+		partner := partners.NewEntity("180 Seguros", "12345678901234", "pt-BR")
+
+		partnersRepo.EXPECT().GetByFilter(gomock.Any(), gomock.Any()).Return(nil, nil)
+		transactor.EXPECT().WithTransaction(gomock.Any(), gomock.Any()).DoAndReturn(runInTransaction)
+		partnersRepo.EXPECT().Create(gomock.Any(), partner).Return(nil)
+		outbox.EXPECT().Add(gomock.Any(), gomock.Any()).Return(errors.New("outbox error"))
+
+		partnerCreated, err := service.CreatePartner(t.Context(), partner)
+
+		assert.Nil(t, partnerCreated)
+		assert.EqualError(t, err, "outbox error")
+	})
+}
+
+func TestServiceGetPartner(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	partnersRepo := mocks.NewMockPartnerRepository(ctrl)
+
+	service := partners.NewService(partners.ServiceParams{
+		PartnerRepo: partnersRepo,
+	})
+
+	t.Run("Should return the partner", func(t *testing.T) {
+		fakePartner := &partners.PartnerEntity{ID: uuid.NewString(), Name: "180 Seguros"}
+
+		partnersRepo.EXPECT().GetByID(gomock.Any(), fakePartner.ID).Return(fakePartner, nil)
+
+		partner, err := service.GetPartner(t.Context(), fakePartner.ID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fakePartner, partner)
+	})
+
+	t.Run("Not should return a partner that not exists", func(t *testing.T) {
+		partnersRepo.EXPECT().GetByID(gomock.Any(), gomock.Any()).Return(nil, nil)
+
+		partner, err := service.GetPartner(t.Context(), uuid.NewString())
+
+		assert.Nil(t, partner)
+		assert.Equal(t, partners.ErrPartnerNotFound, err)
+	})
+}
+
+func TestServiceUpdatePartner(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	partnersRepo := mocks.NewMockPartnerRepository(ctrl)
+
+	transactor, _ := newTransactionMocks(ctrl)
+
+	service := partners.NewService(partners.ServiceParams{
+		PartnerRepo: partnersRepo,
+		Transactor:  transactor,
+		AuditRepo:   newAuditLogMock(ctrl),
+	})
+
+	t.Run("Should update the partner and keep its cnpj and limits", func(t *testing.T) {
+		createdAt := time.Now().Add(-time.Hour)
+		current := &partners.PartnerEntity{
+			ID:        uuid.NewString(),
+			Name:      "180 Seguros",
+			Cnpj:      "12345678901234",
+			Language:  "pt-BR",
+			Limits:    partners.PartnerLimitsEntity{DailyQuotes: 5},
+			CreatedAt: createdAt,
+		}
+
+		partnersRepo.EXPECT().GetByID(gomock.Any(), current.ID).Return(current, nil)
+		partnersRepo.EXPECT().Update(gomock.Any(), current).Return(nil)
+
+		partner, err := service.UpdatePartner(t.Context(), &partners.PartnerEntity{
+			ID:       current.ID,
+			Name:     "181 Seguros",
+			Cnpj:     "00000000000000",
+			Language: "en",
+			Limits:   partners.PartnerLimitsEntity{DailyQuotes: 10},
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "181 Seguros", partner.Name)
+		assert.Equal(t, "12345678901234", partner.Cnpj)
+		assert.Equal(t, "en", partner.Language)
+		assert.Equal(t, 5, partner.Limits.DailyQuotes)
+		assert.Equal(t, createdAt, partner.CreatedAt)
+		assert.True(t, partner.UpdatedAt.After(createdAt))
+	})
+
+	t.Run("Not should update a partner that not exists", func(t *testing.T) {
+		partnersRepo.EXPECT().GetByID(gomock.Any(), gomock.Any()).Return(nil, nil)
+
+		partner, err := service.UpdatePartner(t.Context(), &partners.PartnerEntity{ID: uuid.NewString()})
+
+		assert.Nil(t, partner)
+		assert.Equal(t, partners.ErrPartnerNotFound, err)
+	})
+}
+
+func TestServiceUpdatePartnerLimits(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	partnersRepo := mocks.NewMockPartnerRepository(ctrl)
+
+	transactor, _ := newTransactionMocks(ctrl)
+
+	service := partners.NewService(partners.ServiceParams{
+		PartnerRepo: partnersRepo,
+		Transactor:  transactor,
+		AuditRepo:   newAuditLogMock(ctrl),
+	})
+
+	t.Run("Should override the limits of the partner", func(t *testing.T) {
+		current := &partners.PartnerEntity{ID: uuid.NewString(), Name: "180 Seguros", Limits: partners.PartnerLimitsEntity{DailyQuotes: 5}}
+
+		partnersRepo.EXPECT().GetByID(gomock.Any(), current.ID).Return(current, nil)
+		partnersRepo.EXPECT().Update(gomock.Any(), current).Return(nil)
+
+		partner, err := service.UpdatePartnerLimits(t.Context(), current.ID, partners.PartnerLimitsEntity{RequestsPerWindow: 10})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "180 Seguros", partner.Name)
+		assert.Equal(t, partners.PartnerLimitsEntity{RequestsPerWindow: 10}, partner.Limits)
+	})
+
+	t.Run("Not should update the limits of a partner that not exists", func(t *testing.T) {
+		partnersRepo.EXPECT().GetByID(gomock.Any(), gomock.Any()).Return(nil, nil)
+
+		partner, err := service.UpdatePartnerLimits(t.Context(), uuid.NewString(), partners.PartnerLimitsEntity{})
+
+		assert.Nil(t, partner)
+		assert.Equal(t, partners.ErrPartnerNotFound, err)
+	})
+}
+
+func TestServiceSuspendPartner(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	partnersRepo := mocks.NewMockPartnerRepository(ctrl)
+
+	transactor, _ := newTransactionMocks(ctrl)
+
+	service := partners.NewService(partners.ServiceParams{
+		PartnerRepo: partnersRepo,
+		Transactor:  transactor,
+		AuditRepo:   newAuditLogMock(ctrl),
+	})
+
+	t.Run("Should suspend the partner", func(t *testing.T) {
+		current := &partners.PartnerEntity{ID: uuid.NewString(), Name: "180 Seguros"}
+
+		partnersRepo.EXPECT().GetByID(gomock.Any(), current.ID).Return(current, nil)
+		partnersRepo.EXPECT().Update(gomock.Any(), current).Return(nil)
+
+		partner, err := service.SuspendPartner(t.Context(), current.ID)
+
+		assert.NoError(t, err)
+		assert.True(t, partner.Suspended)
+	})
+
+	t.Run("Not should update a partner already suspended", func(t *testing.T) {
+		current := &partners.PartnerEntity{ID: uuid.NewString(), Name: "180 Seguros", Suspended: true}
+
+		partnersRepo.EXPECT().GetByID(gomock.Any(), current.ID).Return(current, nil)
+
+		partner, err := service.SuspendPartner(t.Context(), current.ID)
+
+		assert.NoError(t, err)
+		assert.True(t, partner.Suspended)
+	})
+
+	t.Run("Not should suspend a partner that not exists", func(t *testing.T) {
+		partnersRepo.EXPECT().GetByID(gomock.Any(), gomock.Any()).Return(nil, nil)
+
+		partner, err := service.SuspendPartner(t.Context(), uuid.NewString())
+
+		assert.Nil(t, partner)
+		assert.Equal(t, partners.ErrPartnerNotFound, err)
+	})
+}
+
+func TestServicePartnerCacheInvalidation(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	partnersRepo := &cachingPartnerRepo{MockPartnerRepository: mocks.NewMockPartnerRepository(ctrl)}
+	transactor := mocks.NewMockTransactor(ctrl)
+
+	service := partners.NewService(partners.ServiceParams{
+		PartnerRepo: partnersRepo,
+		Transactor:  transactor,
+		AuditRepo:   newAuditLogMock(ctrl),
+	})
+
+	t.Run("Should drop the cached partner once the suspension is committed", func(t *testing.T) {
+		current := &partners.PartnerEntity{ID: uuid.NewString(), Name: "180 Seguros"}
+
+		partnersRepo.EXPECT().GetByID(gomock.Any(), current.ID).Return(current, nil)
+		partnersRepo.EXPECT().Update(gomock.Any(), current).Return(nil)
+		transactor.EXPECT().WithTransaction(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+				err := fn(ctx)
+				assert.Empty(t, partnersRepo.invalidated, "the partner is invalidated after the commit")
+
+				return err
+			})
+
+		_, err := service.SuspendPartner(t.Context(), current.ID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{current.ID}, partnersRepo.invalidated)
+	})
+
+	t.Run("Not should drop the cached partner when the transaction fails", func(t *testing.T) {
+		partnersRepo.invalidated = nil
+		current := &partners.PartnerEntity{ID: uuid.NewString(), Name: "180 Seguros"}
+
+		partnersRepo.EXPECT().GetByID(gomock.Any(), current.ID).Return(current, nil)
+		transactor.EXPECT().WithTransaction(gomock.Any(), gomock.Any()).Return(errors.New("write conflict"))
+
+		_, err := service.UpdatePartnerLimits(t.Context(), current.ID, partners.PartnerLimitsEntity{DailyQuotes: 10})
+
+		assert.EqualError(t, err, "write conflict")
+		assert.Empty(t, partnersRepo.invalidated)
+	})
+}
+
+func TestServiceUnsuspendPartner(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	partnersRepo := mocks.NewMockPartnerRepository(ctrl)
+
+	transactor, _ := newTransactionMocks(ctrl)
+
+	service := partners.NewService(partners.ServiceParams{
+		PartnerRepo: partnersRepo,
+		Transactor:  transactor,
+		AuditRepo:   newAuditLogMock(ctrl),
+	})
+
+	t.Run("Should unsuspend the partner", func(t *testing.T) {
+		current := &partners.PartnerEntity{ID: uuid.NewString(), Name: "180 Seguros", Suspended: true}
+
+		partnersRepo.EXPECT().GetByID(gomock.Any(), current.ID).Return(current, nil)
+		partnersRepo.EXPECT().Update(gomock.Any(), current).Return(nil)
+
+		partner, err := service.UnsuspendPartner(t.Context(), current.ID)
+
+		assert.NoError(t, err)
+		assert.False(t, partner.Suspended)
+	})
+
+	t.Run("Not should update a partner that isn't suspended", func(t *testing.T) {
+		current := &partners.PartnerEntity{ID: uuid.NewString(), Name: "180 Seguros"}
+
+		partnersRepo.EXPECT().GetByID(gomock.Any(), current.ID).Return(current, nil)
+
+		partner, err := service.UnsuspendPartner(t.Context(), current.ID)
+
+		assert.NoError(t, err)
+		assert.False(t, partner.Suspended)
+	})
+}
+
 func TestServiceCreateQuote(t *testing.T) {
 	t.Parallel()
 	ctrl := gomock.NewController(t)
@@ -70,10 +402,15 @@ func TestServiceCreateQuote(t *testing.T) {
 	quotesRepo := mocks.NewMockQuotesRepository(ctrl)
 	insuranceProviderClient := mocks.NewMockInsuranceProvider(ctrl)
 
+	transactor, outbox := newTransactionMocks(ctrl)
+
 	service := partners.NewService(partners.ServiceParams{
 		PartnerRepo:             partnersRepo,
 		QuoteRepo:               quotesRepo,
 		InsuranceClientProvider: insuranceProviderClient,
+		Transactor:              transactor,
+		Outbox:                  outbox,
+		AuditRepo:               newAuditLogMock(ctrl),
 	})
 
 	fakePartner := partners.PartnerEntity{
@@ -130,6 +467,18 @@ func TestServiceCreateQuote(t *testing.T) {
 		assert.Equal(t, partners.ErrPartnerNotFound, err)
 	})
 
+	t.Run("Should return error when partner is suspended", func(t *testing.T) {
+		suspendedPartner := fakePartner
+		suspendedPartner.Suspended = true
+
+		partnersRepo.EXPECT().GetByID(gomock.Any(), quote.PartnerID).Return(&suspendedPartner, nil)
+
+		createdQuote, err := service.CreateQuote(context.Background(), quote)
+
+		assert.Nil(t, createdQuote)
+		assert.Equal(t, partners.ErrPartnerSuspended, err)
+	})
+
 	t.Run("Should return error when GetByID fails", func(t *testing.T) {
 		partnersRepo.EXPECT().GetByID(gomock.Any(), quote.PartnerID).Return(nil, errors.New("database error"))
 
@@ -183,6 +532,115 @@ func TestServiceCreateQuote(t *testing.T) {
 	})
 }
 
+func TestServiceCreateQuoteReuse(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	partnersRepo := mocks.NewMockPartnerRepository(ctrl)
+	quotesRepo := mocks.NewMockQuotesRepository(ctrl)
+	insuranceProviderClient := mocks.NewMockInsuranceProvider(ctrl)
+
+	transactor, outbox := newTransactionMocks(ctrl)
+
+	service := partners.NewService(partners.ServiceParams{
+		PartnerRepo:             partnersRepo,
+		QuoteRepo:               quotesRepo,
+		InsuranceClientProvider: insuranceProviderClient,
+		ReuseQuotes:             true,
+		Transactor:              transactor,
+		Outbox:                  outbox,
+		AuditRepo:               newAuditLogMock(ctrl),
+	})
+
+	fakePartner := partners.PartnerEntity{
+		ID:        uuid.NewString(),
+		Name:      "partner-test",
+		Cnpj:      "12345678901234",
+		CreatedAt: time.Now(),
+	}
+
+	existingQuote := &partners.QuoteEntity{
+		ID:         uuid.NewString(),
+		ProviderID: uuid.New(),
+		Age:        26,
+		Sex:        "M",
+		PartnerID:  uuid.NewString(),
+		Price:      12.78,
+		ExpiresAt:  time.Now().Add(12 * time.Hour),
+	}
+
+	t.Run("Should reuse a quotation of another partner when the provider allows shared quotations", func(t *testing.T) {
+		quote := partners.NewQuoteEntity(26, "m", fakePartner.ID)
+
+		partnersRepo.EXPECT().GetByID(gomock.Any(), fakePartner.ID).Return(&fakePartner, nil)
+		insuranceProviderClient.EXPECT().QuoteReusePolicy().Return(partners.QuoteReusePolicy{AllowShared: true, MinValidity: time.Hour})
+		quotesRepo.EXPECT().GetReusable(gomock.Any(), uint(26), partners.SexEnum("M"), "", gomock.Any()).Return(existingQuote, nil)
+		quotesRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+
+		createdQuote, err := service.CreateQuote(t.Context(), quote)
+
+		assert.NoError(t, err)
+		assert.Equal(t, existingQuote.ProviderID, createdQuote.ProviderID)
+		assert.Equal(t, existingQuote.Price, createdQuote.Price)
+		assert.Equal(t, existingQuote.ExpiresAt, createdQuote.ExpiresAt)
+		assert.Equal(t, existingQuote.ID, createdQuote.ReusedFromID)
+		assert.Equal(t, fakePartner.ID, createdQuote.PartnerID)
+	})
+
+	t.Run("Should reference the original quote when reusing a reused quote", func(t *testing.T) {
+		reused := *existingQuote
+		reused.ID = uuid.NewString()
+		reused.ReusedFromID = existingQuote.ID
+
+		partnersRepo.EXPECT().GetByID(gomock.Any(), fakePartner.ID).Return(&fakePartner, nil)
+		insuranceProviderClient.EXPECT().QuoteReusePolicy().Return(partners.QuoteReusePolicy{AllowShared: true})
+		quotesRepo.EXPECT().GetReusable(gomock.Any(), gomock.Any(), gomock.Any(), "", gomock.Any()).Return(&reused, nil)
+		quotesRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+
+		createdQuote, err := service.CreateQuote(t.Context(), partners.NewQuoteEntity(26, "M", fakePartner.ID))
+
+		assert.NoError(t, err)
+		assert.Equal(t, existingQuote.ID, createdQuote.ReusedFromID)
+	})
+
+	t.Run("Should only search the partner quotations when the provider doesn't allow shared quotations", func(t *testing.T) {
+		partnersRepo.EXPECT().GetByID(gomock.Any(), fakePartner.ID).Return(&fakePartner, nil)
+		insuranceProviderClient.EXPECT().QuoteReusePolicy().Return(partners.QuoteReusePolicy{MinValidity: time.Hour})
+		quotesRepo.EXPECT().GetReusable(gomock.Any(), uint(26), partners.SexEnum("M"), fakePartner.ID, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ uint, _ partners.SexEnum, _ string, validAt time.Time) (*partners.QuoteEntity, error) {
+				assert.WithinDuration(t, time.Now().Add(time.Hour), validAt, time.Minute)
+
+				return nil, nil
+			})
+		insuranceProviderClient.EXPECT().CreateQuotation(gomock.Any(), gomock.Any()).Return(&partners.InsuranceProviderCreateQuotationResponse{
+			ProviderID: uuid.New(),
+			Age:        26,
+			Price:      12.78,
+			Sex:        "M",
+			ExpiresAt:  "2999-12-31",
+		}, nil)
+		quotesRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+
+		createdQuote, err := service.CreateQuote(t.Context(), partners.NewQuoteEntity(26, "M", fakePartner.ID))
+
+		assert.NoError(t, err)
+		assert.Empty(t, createdQuote.ReusedFromID)
+	})
+
+	t.Run("Should return error when the reusable quote lookup fails", func(t *testing.T) {
+		partnersRepo.EXPECT().GetByID(gomock.Any(), fakePartner.ID).Return(&fakePartner, nil)
+		insuranceProviderClient.EXPECT().QuoteReusePolicy().Return(partners.QuoteReusePolicy{})
+		quotesRepo.EXPECT().GetReusable(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("database error"))
+
+		createdQuote, err := service.CreateQuote(t.Context(), partners.NewQuoteEntity(26, "M", fakePartner.ID))
+
+		assert.Nil(t, createdQuote)
+		assert.EqualError(t, err, "database error")
+	})
+}
+
 func TestServiceCreatePolicy(t *testing.T) {
 	t.Parallel()
 	ctrl := gomock.NewController(t)
@@ -194,11 +652,21 @@ func TestServiceCreatePolicy(t *testing.T) {
 	policyRepo := mocks.NewMockPoliciesRepository(ctrl)
 	insuranceProviderClient := mocks.NewMockInsuranceProvider(ctrl)
 
+	policyIssuancesRepo := mocks.NewMockPolicyIssuancesRepository(ctrl)
+	policyIssuancesRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	policyIssuancesRepo.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	transactor, outbox := newTransactionMocks(ctrl)
+
 	service := partners.NewService(partners.ServiceParams{
 		PartnerRepo:             partnersRepo,
 		QuoteRepo:               quotesRepo,
 		PolicyRepo:              policyRepo,
+		PolicyIssuanceRepo:      policyIssuancesRepo,
 		InsuranceClientProvider: insuranceProviderClient,
+		Transactor:              transactor,
+		Outbox:                  outbox,
+		AuditRepo:               newAuditLogMock(ctrl),
 	})
 
 	fakePartner := partners.PartnerEntity{
@@ -216,9 +684,18 @@ func TestServiceCreatePolicy(t *testing.T) {
 		DateOfBirth: "1998-09-28",
 	}
 
+	fakeQuote := partners.QuoteEntity{
+		ProviderID: insuranceProviderFakeRes.QuotationID,
+		PartnerID:  fakePartner.ID,
+		Age:        uint(partners.AgeAt(time.Date(1998, 9, 28, 0, 0, 0, 0, time.UTC), time.Now())),
+		Sex:        "F",
+	}
+
 	t.Run("Should create a policy succesfuly and return", func(t *testing.T) {
 		partnersRepo.EXPECT().GetByID(gomock.Any(), gomock.Any()).
 			Return(&fakePartner, nil)
+		quotesRepo.EXPECT().GetByProviderIDAndPartnerID(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(&fakeQuote, nil)
 		insuranceProviderClient.EXPECT().CreatePolicy(gomock.Any(), gomock.Any()).
 			Return(&insuranceProviderFakeRes, nil)
 		policyRepo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(
@@ -263,8 +740,10 @@ func TestServiceCreatePolicy(t *testing.T) {
 	t.Run("Not should create a policy when provider was error", func(t *testing.T) {
 		partnersRepo.EXPECT().GetByID(gomock.Any(), gomock.Any()).
 			Return(&fakePartner, nil)
+		quotesRepo.EXPECT().GetByProviderIDAndPartnerID(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(&fakeQuote, nil)
 		insuranceProviderClient.EXPECT().CreatePolicy(gomock.Any(), gomock.Any()).
-			Return(nil, errors.New(`{"message": "The quotation was expired"}`))
+			Return(nil, fmt.Errorf("%w: %w", partners.ErrProviderRejected, errors.New(`{"message": "The quotation was expired"}`)))
 
 		createdPolicy, err := service.CreatePolicy(context.Background(), &partners.PolicyEntity{
 			QuotationID: uuid.New(),
@@ -275,7 +754,130 @@ func TestServiceCreatePolicy(t *testing.T) {
 		})
 
 		assert.Nil(t, createdPolicy)
-		assert.Equal(t, "{\"message\": \"The quotation was expired\"}", err.Error())
+		assert.ErrorIs(t, err, partners.ErrProviderRejected)
+		assert.NotErrorIs(t, err, partners.ErrPolicyIssuanceUnresolved)
+		assert.Contains(t, err.Error(), "The quotation was expired")
+	})
+
+	t.Run("Should create a policy with beneficiaries and dependents", func(t *testing.T) {
+		partnersRepo.EXPECT().GetByID(gomock.Any(), gomock.Any()).
+			Return(&fakePartner, nil)
+		quotesRepo.EXPECT().GetByProviderIDAndPartnerID(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(&fakeQuote, nil)
+		insuranceProviderClient.EXPECT().CreatePolicy(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(ctx context.Context, data partners.InsuranceProviderCreatePolicyRequest) (*partners.InsuranceProviderCreatePolicyResponse, error) {
+				assert.Len(t, data.Beneficiaries, 2)
+				assert.Len(t, data.Dependents, 1)
+				assert.Equal(t, "child", data.Dependents[0].Relationship)
+
+				return &insuranceProviderFakeRes, nil
+			},
+		)
+		policyRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+
+		policyCreated, err := service.CreatePolicy(t.Context(), &partners.PolicyEntity{
+			QuotationID: uuid.New(),
+			PartnerID:   fakePartner.ID,
+			Sex:         "F",
+			Name:        "policy-test",
+			DateOfBirth: "1998-09-28",
+			Beneficiaries: []partners.BeneficiaryEntity{
+				{Name: "beneficiary-one", Relationship: partners.RelationshipSpouse, Percentage: 66.67},
+				{Name: "beneficiary-two", Relationship: partners.RelationshipChild, Percentage: 33.33},
+			},
+			Dependents: []partners.DependentEntity{
+				{Name: "dependent-one", Sex: "M", DateOfBirth: "2015-01-10", Relationship: partners.RelationshipChild},
+			},
+		})
+
+		assert.NoError(t, err)
+		assert.Len(t, policyCreated.Beneficiaries, 2)
+		assert.Len(t, policyCreated.Dependents, 1)
+	})
+
+	t.Run("Not should create a policy when beneficiaries percentages not add up to 100", func(t *testing.T) {
+		createdPolicy, err := service.CreatePolicy(t.Context(), &partners.PolicyEntity{
+			QuotationID: uuid.New(),
+			PartnerID:   fakePartner.ID,
+			Sex:         "F",
+			Name:        "policy-test",
+			DateOfBirth: "1998-09-28",
+			Beneficiaries: []partners.BeneficiaryEntity{
+				{Name: "beneficiary-one", Percentage: 50},
+				{Name: "beneficiary-two", Percentage: 30},
+			},
+		})
+
+		assert.Nil(t, createdPolicy)
+		assert.Equal(t, partners.ErrBeneficiariesPercentageSum, err)
+	})
+
+	t.Run("Not should create a policy when dependent relationship is invalid", func(t *testing.T) {
+		createdPolicy, err := service.CreatePolicy(t.Context(), &partners.PolicyEntity{
+			QuotationID: uuid.New(),
+			PartnerID:   fakePartner.ID,
+			Sex:         "F",
+			Name:        "policy-test",
+			DateOfBirth: "1998-09-28",
+			Dependents: []partners.DependentEntity{
+				{Name: "dependent-one", Sex: "M", DateOfBirth: "2015-01-10", Relationship: partners.RelationshipOther},
+			},
+		})
+
+		assert.Nil(t, createdPolicy)
+		assert.Equal(t, partners.ErrInvalidDependentRelationship, err)
+	})
+
+	t.Run("Not should create a policy when date of birth doesn't match the quotation age", func(t *testing.T) {
+		partnersRepo.EXPECT().GetByID(gomock.Any(), gomock.Any()).
+			Return(&fakePartner, nil)
+		quotesRepo.EXPECT().GetByProviderIDAndPartnerID(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(&fakeQuote, nil)
+
+		createdPolicy, err := service.CreatePolicy(t.Context(), &partners.PolicyEntity{
+			QuotationID: fakeQuote.ProviderID,
+			PartnerID:   fakePartner.ID,
+			Sex:         "F",
+			Name:        "policy-test",
+			DateOfBirth: "1978-09-28",
+		})
+
+		assert.Nil(t, createdPolicy)
+		assert.Equal(t, partners.ErrDateOfBirthAgeMismatch, err)
+	})
+
+	t.Run("Not should create a policy when date of birth is in the future", func(t *testing.T) {
+		partnersRepo.EXPECT().GetByID(gomock.Any(), gomock.Any()).
+			Return(&fakePartner, nil)
+
+		createdPolicy, err := service.CreatePolicy(t.Context(), &partners.PolicyEntity{
+			QuotationID: fakeQuote.ProviderID,
+			PartnerID:   fakePartner.ID,
+			Sex:         "F",
+			Name:        "policy-test",
+			DateOfBirth: time.Now().AddDate(1, 0, 0).Format("2006-01-02"),
+		})
+
+		assert.Nil(t, createdPolicy)
+		assert.Equal(t, partners.ErrDateOfBirthInFuture, err)
+	})
+
+	t.Run("Not should create a policy when quote not found", func(t *testing.T) {
+		partnersRepo.EXPECT().GetByID(gomock.Any(), gomock.Any()).
+			Return(&fakePartner, nil)
+		quotesRepo.EXPECT().GetByProviderIDAndPartnerID(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(nil, nil)
+
+		createdPolicy, err := service.CreatePolicy(t.Context(), &partners.PolicyEntity{
+			QuotationID: uuid.New(),
+			PartnerID:   fakePartner.ID,
+			Sex:         "F",
+			Name:        "policy-test",
+			DateOfBirth: "1998-09-28",
+		})
+
+		assert.Nil(t, createdPolicy)
+		assert.Equal(t, partners.ErrQuoteNotFound, err)
 	})
 }
 
@@ -336,6 +938,42 @@ func TestServiceGetPolicy(t *testing.T) {
 		assert.Equal(t, string(policyCreated.Sex), string(insuranceProviderFakeRes.Sex))
 		assert.Equal(t, policyCreated.Name, insuranceProviderFakeRes.Name)
 		assert.Equal(t, policyCreated.DateOfBirth, insuranceProviderFakeRes.DateOfBirth)
+		assert.False(t, policyCreated.Stale)
+	})
+
+	t.Run("Should return an erased policy without the provider's data", func(t *testing.T) {
+		erasedPolicy := fakePolicyCreated
+		erasedPolicy.Name = "erased-test"
+		erasedPolicy.DateOfBirth = ""
+		erasedPolicy.ErasedAt = time.Now()
+
+		partnersRepo.EXPECT().GetByID(gomock.Any(), gomock.Any()).
+			Return(&fakePartner, nil)
+		policyRepo.EXPECT().GetByIdAndPartnerID(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(&erasedPolicy, nil)
+
+		policy, err := service.GetPolicy(t.Context(), fakePartner.ID, erasedPolicy.ID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "erased-test", policy.Name)
+		assert.Empty(t, policy.DateOfBirth)
+	})
+
+	t.Run("Should flag the policy when the provider data is stale", func(t *testing.T) {
+		staleRes := insuranceProviderFakeRes
+		staleRes.Stale = true
+
+		partnersRepo.EXPECT().GetByID(gomock.Any(), gomock.Any()).
+			Return(&fakePartner, nil)
+		policyRepo.EXPECT().GetByIdAndPartnerID(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(&fakePolicyCreated, nil)
+		insuranceProviderClient.EXPECT().GetPolicy(gomock.Any(), gomock.Any()).
+			Return(&staleRes, nil)
+
+		policy, err := service.GetPolicy(t.Context(), fakePartner.ID, fakePolicyCreated.ID)
+
+		assert.NoError(t, err)
+		assert.True(t, policy.Stale)
 	})
 
 	t.Run("Not should return a policy when patner not found", func(t *testing.T) {
@@ -373,3 +1011,116 @@ func TestServiceGetPolicy(t *testing.T) {
 		assert.Equal(t, "{\"message\": \"The policy not found\"}", err.Error())
 	})
 }
+
+func TestServiceListPoliciesByCpf(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	partnersRepo := mocks.NewMockPartnerRepository(ctrl)
+	policyRepo := mocks.NewMockPoliciesRepository(ctrl)
+
+	service := partners.NewService(partners.ServiceParams{
+		PartnerRepo: partnersRepo,
+		PolicyRepo:  policyRepo,
+	})
+
+	fakePartner := partners.PartnerEntity{
+		ID:        uuid.NewString(),
+		Name:      "partner-test",
+		Cnpj:      "12345678901234",
+		CreatedAt: time.Now(),
+	}
+
+	t.Run("Should list the policies of a holder", func(t *testing.T) {
+		partnersRepo.EXPECT().GetByID(gomock.Any(), fakePartner.ID).
+			Return(&fakePartner, nil)
+		policyRepo.EXPECT().ListByPartnerIDAndCpf(gomock.Any(), fakePartner.ID, "52998224725").
+			Return([]partners.PolicyEntity{{ID: uuid.NewString(), Cpf: "52998224725"}}, nil)
+
+		policies, err := service.ListPoliciesByCpf(t.Context(), fakePartner.ID, "52998224725")
+
+		assert.NoError(t, err)
+		assert.Len(t, policies, 1)
+	})
+
+	t.Run("Not should list policies when partner not found", func(t *testing.T) {
+		partnersRepo.EXPECT().GetByID(gomock.Any(), gomock.Any()).
+			Return(nil, nil)
+
+		policies, err := service.ListPoliciesByCpf(t.Context(), fakePartner.ID, "52998224725")
+
+		assert.Nil(t, policies)
+		assert.Equal(t, partners.ErrPartnerNotFound, err)
+	})
+}
+
+func TestServiceExpireQuotes(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	quotesRepo := mocks.NewMockQuotesRepository(ctrl)
+
+	service := partners.NewService(partners.ServiceParams{
+		QuoteRepo: quotesRepo,
+	})
+
+	t.Run("Should mark the quotes expired until now", func(t *testing.T) {
+		quotesRepo.EXPECT().MarkExpired(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, now time.Time) (int64, error) {
+				assert.WithinDuration(t, time.Now(), now, time.Second)
+
+				return 3, nil
+			})
+
+		expired, err := service.ExpireQuotes(t.Context())
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), expired)
+	})
+
+	t.Run("Should return error when marking the quotes fails", func(t *testing.T) {
+		quotesRepo.EXPECT().MarkExpired(gomock.Any(), gomock.Any()).Return(int64(0), errors.New("database error"))
+
+		_, err := service.ExpireQuotes(t.Context())
+
+		assert.EqualError(t, err, "database error")
+	})
+}
+
+// newTransactionMocks returns a transactor that runs the callbacks right away
+// and an outbox that accepts every event.
+// cachingPartnerRepo is a partner repository that caches partners.
+type cachingPartnerRepo struct {
+	*mocks.MockPartnerRepository
+	invalidated []string
+}
+
+func (r *cachingPartnerRepo) InvalidatePartner(_ context.Context, partnerID string) {
+	r.invalidated = append(r.invalidated, partnerID)
+}
+
+func newTransactionMocks(ctrl *gomock.Controller) (*mocks.MockTransactor, *mocks.MockOutboxRepository) {
+	transactor := mocks.NewMockTransactor(ctrl)
+	transactor.EXPECT().WithTransaction(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+			return fn(ctx)
+		}).
+		AnyTimes()
+
+	outbox := mocks.NewMockOutboxRepository(ctrl)
+	outbox.EXPECT().Add(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	return transactor, outbox
+}
+
+// newAuditLogMock returns an audit log that accepts every entry.
+func newAuditLogMock(ctrl *gomock.Controller) *mocks.MockAuditLogRepository {
+	auditLog := mocks.NewMockAuditLogRepository(ctrl)
+	auditLog.EXPECT().Append(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	return auditLog
+}
@@ -0,0 +1,377 @@
+package partners
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+type (
+	// WebhookDeliveryResult counts what a run of DeliverWebhooks did.
+	WebhookDeliveryResult struct {
+		Delivered int64
+		Retried   int64
+		Dead      int64
+	}
+
+	webhookBody struct {
+		ID         string    `json:"id"`
+		Type       EventType `json:"type"`
+		OccurredAt time.Time `json:"occurred_at"`
+		Data       any       `json:"data"`
+	}
+)
+
+const (
+	webhookRetryBaseDelay = 30 * time.Second
+	webhookRetryMaxDelay  = time.Hour
+	// webhookRetryWindow is how long a delivery is retried before it goes to
+	// the dead letter list.
+	webhookRetryWindow = 24 * time.Hour
+
+	webhookDeliveryBatchSize   = 20
+	webhookDeliveryConcurrency = 10
+	webhookDeliveriesListLimit = 100
+
+	webhookSecretPrefix = "whsec_"
+)
+
+// RegisterWebhook saves the webhook. The partner's secret is created with its
+// first webhook, so the partner can verify the deliveries before any is sent,
+// and it is returned only then; it is empty for the following webhooks. A
+// partner that lost its secret has to rotate it.
+func (s *Servicer) RegisterWebhook(ctx context.Context, webhook *WebhookEntity) (*WebhookEntity, string, error) {
+	if err := webhook.Validate(); err != nil {
+		return nil, "", err
+	}
+
+	if err := s.webhookSender.CheckURL(ctx, webhook.URL); err != nil {
+		return nil, "", fmt.Errorf("%w: %w", ErrInvalidWebhookURL, err)
+	}
+
+	if err := s.ensurePartnerExists(ctx, webhook.PartnerID); err != nil {
+		return nil, "", err
+	}
+
+	var secret string
+
+	err := s.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		secret = ""
+
+		current, err := s.webhookRepo.GetSecret(ctx, webhook.PartnerID)
+		if err != nil {
+			return err
+		}
+
+		if current == "" {
+			secret = newWebhookSecret()
+			if err := s.webhookRepo.SetSecret(ctx, webhook.PartnerID, secret); err != nil {
+				return err
+			}
+		}
+
+		if err := s.webhookRepo.Create(ctx, webhook); err != nil {
+			return err
+		}
+
+		return s.audit(ctx, AuditWebhookRegistered, webhook.PartnerID, webhook.ID, nil, webhook.auditFields())
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return webhook, secret, nil
+}
+
+func (s *Servicer) ListWebhooks(ctx context.Context, partnerID string) ([]WebhookEntity, error) {
+	if err := s.ensurePartnerExists(ctx, partnerID); err != nil {
+		return nil, err
+	}
+
+	return s.webhookRepo.ListByPartnerID(ctx, partnerID)
+}
+
+// DeleteWebhook stops new events from being delivered to the webhook. The
+// deliveries already created are still retried.
+func (s *Servicer) DeleteWebhook(ctx context.Context, partnerID, webhookID string) error {
+	if err := s.ensurePartnerExists(ctx, partnerID); err != nil {
+		return err
+	}
+
+	return s.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.webhookRepo.Delete(ctx, partnerID, webhookID); err != nil {
+			return err
+		}
+
+		return s.audit(ctx, AuditWebhookDeleted, partnerID, webhookID, nil, nil)
+	})
+}
+
+// RotateWebhookSecret replaces the partner's secret. Deliveries sent from then
+// on, including retries, are signed with the new one.
+func (s *Servicer) RotateWebhookSecret(ctx context.Context, partnerID string) (string, error) {
+	if err := s.ensurePartnerExists(ctx, partnerID); err != nil {
+		return "", err
+	}
+
+	secret := newWebhookSecret()
+
+	// The secret itself is kept out of the audit log.
+	err := s.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.webhookRepo.SetSecret(ctx, partnerID, secret); err != nil {
+			return err
+		}
+
+		return s.audit(ctx, AuditWebhookSecretRotated, partnerID, partnerID, nil, nil)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return secret, nil
+}
+
+func (s *Servicer) ListWebhookDeliveries(
+	ctx context.Context,
+	partnerID string,
+	status WebhookDeliveryStatusEnum,
+) ([]WebhookDeliveryEntity, error) {
+	if err := s.ensurePartnerExists(ctx, partnerID); err != nil {
+		return nil, err
+	}
+
+	return s.webhookDeliveryRepo.ListByPartnerID(ctx, partnerID, status, webhookDeliveriesListLimit)
+}
+
+// RedeliverWebhook sends a delivery again right away, whatever its status. If
+// it fails, the delivery is retried for another retry window.
+func (s *Servicer) RedeliverWebhook(ctx context.Context, partnerID, deliveryID string) (*WebhookDeliveryEntity, error) {
+	if err := s.ensurePartnerExists(ctx, partnerID); err != nil {
+		return nil, err
+	}
+
+	delivery, err := s.webhookDeliveryRepo.GetByIDAndPartnerID(ctx, deliveryID, partnerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if delivery == nil {
+		return nil, ErrWebhookDeliveryNotFound
+	}
+
+	secret, err := s.webhookSecret(ctx, partnerID)
+	if err != nil {
+		return nil, err
+	}
+
+	before := map[string]any{"status": delivery.Status, "retry_until": delivery.RetryUntil}
+
+	delivery.Status = WebhookDeliveryPending
+	delivery.RetryUntil = time.Now().Add(webhookRetryWindow)
+
+	// The delivery is saved by deliverWebhook whatever the outcome, so the
+	// request is audited before it.
+	err = s.audit(ctx, AuditWebhookDeliveryRedelivered, partnerID, delivery.ID, before, map[string]any{
+		"status":      delivery.Status,
+		"retry_until": delivery.RetryUntil,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.deliverWebhook(ctx, delivery, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	return delivery, nil
+}
+
+// EnqueueWebhookDeliveries creates a delivery of the event for each webhook of
+// the partner subscribed to its type.
+func (s *Servicer) EnqueueWebhookDeliveries(ctx context.Context, event Event) error {
+	webhooks, err := s.webhookRepo.ListByPartnerID(ctx, event.AggregateID)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(webhookBody{
+		ID:         event.ID,
+		Type:       event.Type,
+		OccurredAt: event.OccurredAt,
+		Data:       event.Payload,
+	})
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	var deliveries []WebhookDeliveryEntity
+	for _, webhook := range webhooks {
+		if !webhook.Subscribes(event.Type) {
+			continue
+		}
+
+		deliveries = append(deliveries, WebhookDeliveryEntity{
+			WebhookID:     webhook.ID,
+			PartnerID:     event.AggregateID,
+			EventID:       event.ID,
+			EntityID:      event.EntityID,
+			EventType:     event.Type,
+			URL:           webhook.URL,
+			Body:          body,
+			Status:        WebhookDeliveryPending,
+			NextAttemptAt: now,
+			RetryUntil:    now.Add(webhookRetryWindow),
+			CreatedAt:     now,
+		})
+	}
+
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	return s.webhookDeliveryRepo.Create(ctx, deliveries...)
+}
+
+// DeliverWebhooks sends a batch of the deliveries due, a few at a time.
+func (s *Servicer) DeliverWebhooks(ctx context.Context) (WebhookDeliveryResult, error) {
+	var result WebhookDeliveryResult
+
+	deliveries, err := s.webhookDeliveryRepo.ListDue(ctx, time.Now(), webhookDeliveryBatchSize)
+	if err != nil {
+		return result, err
+	}
+
+	secrets := make(map[string]string)
+	for _, delivery := range deliveries {
+		if _, ok := secrets[delivery.PartnerID]; ok {
+			continue
+		}
+
+		secret, err := s.webhookSecret(ctx, delivery.PartnerID)
+		if err != nil {
+			return result, err
+		}
+
+		secrets[delivery.PartnerID] = secret
+	}
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+
+	slots := make(chan struct{}, webhookDeliveryConcurrency)
+
+	for index := range deliveries {
+		delivery := &deliveries[index]
+
+		wg.Add(1)
+		slots <- struct{}{}
+
+		go func() {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+
+			err := s.deliverWebhook(ctx, delivery, secrets[delivery.PartnerID])
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if err != nil {
+				errs = append(errs, err)
+				return
+			}
+
+			switch delivery.Status {
+			case WebhookDeliveryDelivered:
+				result.Delivered++
+			case WebhookDeliveryDead:
+				result.Dead++
+			default:
+				result.Retried++
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	return result, errors.Join(errs...)
+}
+
+// deliverWebhook makes an attempt to send the delivery and saves its outcome.
+// Failed attempts are retried with a growing delay until the retry window
+// ends.
+func (s *Servicer) deliverWebhook(ctx context.Context, delivery *WebhookDeliveryEntity, secret string) error {
+	statusCode, err := s.webhookSender.Send(ctx, WebhookRequest{
+		URL:        delivery.URL,
+		DeliveryID: delivery.ID,
+		EventType:  delivery.EventType,
+		Secret:     secret,
+		Body:       delivery.Body,
+	})
+
+	now := time.Now()
+
+	delivery.Attempts++
+	delivery.LastStatusCode = statusCode
+
+	switch {
+	case err == nil:
+		delivery.Status = WebhookDeliveryDelivered
+		delivery.LastError = ""
+		delivery.DeliveredAt = now
+	case now.After(delivery.RetryUntil):
+		// Counted in the job's result, and listed in the dead letter list.
+		delivery.Status = WebhookDeliveryDead
+		delivery.LastError = err.Error()
+	default:
+		delivery.LastError = err.Error()
+		delivery.NextAttemptAt = now.Add(webhookRetryDelay(delivery.Attempts))
+	}
+
+	return s.webhookDeliveryRepo.Update(ctx, delivery)
+}
+
+// webhookSecret returns the secret the partner's deliveries are signed with.
+// It is created along with the partner's first webhook, so it exists for every
+// delivery.
+func (s *Servicer) webhookSecret(ctx context.Context, partnerID string) (string, error) {
+	secret, err := s.webhookRepo.GetSecret(ctx, partnerID)
+	if err != nil {
+		return "", err
+	}
+
+	if secret == "" {
+		return "", fmt.Errorf("partner %q has no webhook secret", partnerID)
+	}
+
+	return secret, nil
+}
+
+func newWebhookSecret() string {
+	return webhookSecretPrefix + rand.Text()
+}
+
+// webhookRetryDelay doubles the wait after each failed attempt, up to
+// webhookRetryMaxDelay.
+func webhookRetryDelay(attempts int) time.Duration {
+	delay := webhookRetryBaseDelay
+	for range attempts - 1 {
+		delay *= 2
+		if delay >= webhookRetryMaxDelay {
+			return webhookRetryMaxDelay
+		}
+	}
+
+	return delay
+}
@@ -0,0 +1,346 @@
+package partners_test
+
+import (
+	"encoding/json"
+	"errors"
+	"main-api/internal/domain/partners"
+	mocks "main-api/internal/infra/repository/mocks"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServiceRegisterWebhook(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	partnersRepo := mocks.NewMockPartnerRepository(ctrl)
+	webhooksRepo := mocks.NewMockWebhooksRepository(ctrl)
+	sender := mocks.NewMockWebhookSender(ctrl)
+
+	transactor, _ := newTransactionMocks(ctrl)
+
+	service := partners.NewService(partners.ServiceParams{
+		PartnerRepo:   partnersRepo,
+		WebhookRepo:   webhooksRepo,
+		WebhookSender: sender,
+		Transactor:    transactor,
+		AuditRepo:     newAuditLogMock(ctrl),
+	})
+
+	fakePartner := partners.PartnerEntity{ID: uuid.NewString(), Name: "partner-test"}
+
+	t.Run("Should register a webhook and create the partner secret", func(t *testing.T) {
+		webhook := partners.NewWebhookEntity(fakePartner.ID, "https://partner.test/hooks", []partners.EventType{
+			partners.EventPolicyIssued,
+		})
+
+		var created string
+
+		sender.EXPECT().CheckURL(gomock.Any(), webhook.URL).Return(nil)
+		partnersRepo.EXPECT().GetByID(gomock.Any(), fakePartner.ID).Return(&fakePartner, nil)
+		webhooksRepo.EXPECT().GetSecret(gomock.Any(), fakePartner.ID).Return("", nil)
+		webhooksRepo.EXPECT().SetSecret(gomock.Any(), fakePartner.ID, gomock.Any()).
+			DoAndReturn(func(_ any, _ string, secret string) error {
+				created = secret
+				return nil
+			})
+		webhooksRepo.EXPECT().Create(gomock.Any(), webhook).Return(nil)
+
+		result, secret, err := service.RegisterWebhook(t.Context(), webhook)
+
+		assert.NoError(t, err)
+		assert.Equal(t, webhook, result)
+		assert.True(t, strings.HasPrefix(secret, "whsec_"))
+		assert.Equal(t, created, secret)
+	})
+
+	t.Run("Should keep the secret the partner already has", func(t *testing.T) {
+		webhook := partners.NewWebhookEntity(fakePartner.ID, "https://partner.test/hooks", []partners.EventType{
+			partners.EventQuoteCreated,
+		})
+
+		sender.EXPECT().CheckURL(gomock.Any(), webhook.URL).Return(nil)
+		partnersRepo.EXPECT().GetByID(gomock.Any(), fakePartner.ID).Return(&fakePartner, nil)
+		webhooksRepo.EXPECT().GetSecret(gomock.Any(), fakePartner.ID).Return("whsec_current", nil)
+		webhooksRepo.EXPECT().Create(gomock.Any(), webhook).Return(nil)
+
+		_, secret, err := service.RegisterWebhook(t.Context(), webhook)
+
+		assert.NoError(t, err)
+		assert.Empty(t, secret, "the secret is returned only when it is created")
+	})
+
+	t.Run("Not should register a webhook with a url that isn't http", func(t *testing.T) {
+		webhook := partners.NewWebhookEntity(fakePartner.ID, "ftp://partner.test/hooks", []partners.EventType{
+			partners.EventPolicyIssued,
+		})
+
+		result, _, err := service.RegisterWebhook(t.Context(), webhook)
+
+		assert.Nil(t, result)
+		assert.Equal(t, partners.ErrInvalidWebhookURL, err)
+	})
+
+	t.Run("Not should register a webhook the sender won't post to", func(t *testing.T) {
+		webhook := partners.NewWebhookEntity(fakePartner.ID, "http://169.254.169.254/latest/meta-data", []partners.EventType{
+			partners.EventPolicyIssued,
+		})
+
+		sender.EXPECT().CheckURL(gomock.Any(), webhook.URL).Return(errors.New("webhook address is not allowed"))
+
+		result, _, err := service.RegisterWebhook(t.Context(), webhook)
+
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, partners.ErrInvalidWebhookURL)
+	})
+
+	t.Run("Not should register a webhook for an unknown event type", func(t *testing.T) {
+		webhook := partners.NewWebhookEntity(fakePartner.ID, "https://partner.test/hooks", []partners.EventType{
+			"policy.cancelled",
+		})
+
+		result, _, err := service.RegisterWebhook(t.Context(), webhook)
+
+		assert.Nil(t, result)
+		assert.Equal(t, partners.ErrInvalidWebhookEventType, err)
+	})
+}
+
+func TestServiceEnqueueWebhookDeliveries(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	webhooksRepo := mocks.NewMockWebhooksRepository(ctrl)
+	deliveriesRepo := mocks.NewMockWebhookDeliveriesRepository(ctrl)
+
+	service := partners.NewService(partners.ServiceParams{
+		WebhookRepo:         webhooksRepo,
+		WebhookDeliveryRepo: deliveriesRepo,
+	})
+
+	partnerID := uuid.NewString()
+	event := partners.Event{
+		ID:          uuid.NewString(),
+		Type:        partners.EventPolicyIssued,
+		AggregateID: partnerID,
+		EntityID:    "policy-01",
+		Payload:     json.RawMessage(`{"id":"policy-01"}`),
+		OccurredAt:  time.Now(),
+	}
+
+	t.Run("Should create a delivery for each webhook subscribed to the event", func(t *testing.T) {
+		webhooksRepo.EXPECT().ListByPartnerID(gomock.Any(), partnerID).Return([]partners.WebhookEntity{
+			{ID: "webhook-01", URL: "https://partner.test/policies", EventTypes: []partners.EventType{partners.EventPolicyIssued}},
+			{ID: "webhook-02", URL: "https://partner.test/quotes", EventTypes: []partners.EventType{partners.EventQuoteCreated}},
+		}, nil)
+		deliveriesRepo.EXPECT().Create(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ any, deliveries ...partners.WebhookDeliveryEntity) error {
+				assert.Len(t, deliveries, 1)
+				assert.Equal(t, "webhook-01", deliveries[0].WebhookID)
+				assert.Equal(t, event.ID, deliveries[0].EventID)
+				assert.Equal(t, partners.WebhookDeliveryPending, deliveries[0].Status)
+				assert.WithinDuration(t, time.Now().Add(24*time.Hour), deliveries[0].RetryUntil, time.Minute)
+
+				var body map[string]any
+				assert.NoError(t, json.Unmarshal(deliveries[0].Body, &body))
+				assert.Equal(t, event.ID, body["id"])
+				assert.Equal(t, "policy.issued", body["type"])
+				assert.Equal(t, map[string]any{"id": "policy-01"}, body["data"])
+
+				return nil
+			})
+
+		err := service.EnqueueWebhookDeliveries(t.Context(), event)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("Should do nothing when no webhook is subscribed to the event", func(t *testing.T) {
+		webhooksRepo.EXPECT().ListByPartnerID(gomock.Any(), partnerID).Return(nil, nil)
+
+		err := service.EnqueueWebhookDeliveries(t.Context(), event)
+
+		assert.NoError(t, err)
+	})
+}
+
+func TestServiceDeliverWebhooks(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	webhooksRepo := mocks.NewMockWebhooksRepository(ctrl)
+	deliveriesRepo := mocks.NewMockWebhookDeliveriesRepository(ctrl)
+	sender := mocks.NewMockWebhookSender(ctrl)
+
+	service := partners.NewService(partners.ServiceParams{
+		WebhookRepo:         webhooksRepo,
+		WebhookDeliveryRepo: deliveriesRepo,
+		WebhookSender:       sender,
+	})
+
+	newDelivery := func(id string, retryUntil time.Time) partners.WebhookDeliveryEntity {
+		return partners.WebhookDeliveryEntity{
+			ID:         id,
+			PartnerID:  "partner-01",
+			URL:        "https://partner.test/" + id,
+			Body:       []byte(`{}`),
+			Status:     partners.WebhookDeliveryPending,
+			RetryUntil: retryUntil,
+		}
+	}
+
+	t.Run("Should deliver, retry or give up on the due deliveries", func(t *testing.T) {
+		now := time.Now()
+		updated := make(map[string]partners.WebhookDeliveryEntity)
+
+		deliveriesRepo.EXPECT().ListDue(gomock.Any(), gomock.Any(), gomock.Any()).Return([]partners.WebhookDeliveryEntity{
+			newDelivery("delivered", now.Add(time.Hour)),
+			newDelivery("retried", now.Add(time.Hour)),
+			newDelivery("dead", now.Add(-time.Second)),
+		}, nil)
+		webhooksRepo.EXPECT().GetSecret(gomock.Any(), "partner-01").Return("whsec_test", nil).Times(1)
+		sender.EXPECT().Send(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ any, request partners.WebhookRequest) (int, error) {
+				assert.Equal(t, "whsec_test", request.Secret)
+
+				if request.DeliveryID == "delivered" {
+					return http.StatusOK, nil
+				}
+
+				return http.StatusInternalServerError, errors.New("webhook responded with status 500")
+			}).
+			Times(3)
+		deliveriesRepo.EXPECT().Update(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ any, delivery *partners.WebhookDeliveryEntity) error {
+				updated[delivery.ID] = *delivery
+				return nil
+			}).
+			Times(3)
+
+		result, err := service.DeliverWebhooks(t.Context())
+
+		assert.NoError(t, err)
+		assert.Equal(t, partners.WebhookDeliveryResult{Delivered: 1, Retried: 1, Dead: 1}, result)
+
+		assert.Equal(t, partners.WebhookDeliveryDelivered, updated["delivered"].Status)
+		assert.False(t, updated["delivered"].DeliveredAt.IsZero())
+
+		assert.Equal(t, partners.WebhookDeliveryPending, updated["retried"].Status)
+		assert.Equal(t, 1, updated["retried"].Attempts)
+		assert.Equal(t, http.StatusInternalServerError, updated["retried"].LastStatusCode)
+		assert.WithinDuration(t, now.Add(30*time.Second), updated["retried"].NextAttemptAt, 5*time.Second)
+
+		assert.Equal(t, partners.WebhookDeliveryDead, updated["dead"].Status)
+		assert.Equal(t, "webhook responded with status 500", updated["dead"].LastError)
+	})
+
+	t.Run("Should wait longer after each failed attempt", func(t *testing.T) {
+		delivery := newDelivery("retried", time.Now().Add(24*time.Hour))
+		delivery.Attempts = 20
+
+		deliveriesRepo.EXPECT().ListDue(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return([]partners.WebhookDeliveryEntity{delivery}, nil)
+		webhooksRepo.EXPECT().GetSecret(gomock.Any(), "partner-01").Return("whsec_test", nil)
+		sender.EXPECT().Send(gomock.Any(), gomock.Any()).Return(0, errors.New("connection refused"))
+		deliveriesRepo.EXPECT().Update(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ any, delivery *partners.WebhookDeliveryEntity) error {
+				assert.WithinDuration(t, time.Now().Add(time.Hour), delivery.NextAttemptAt, 5*time.Second)
+				return nil
+			})
+
+		result, err := service.DeliverWebhooks(t.Context())
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), result.Retried)
+	})
+}
+
+func TestServiceRedeliverWebhook(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	partnersRepo := mocks.NewMockPartnerRepository(ctrl)
+	webhooksRepo := mocks.NewMockWebhooksRepository(ctrl)
+	deliveriesRepo := mocks.NewMockWebhookDeliveriesRepository(ctrl)
+	sender := mocks.NewMockWebhookSender(ctrl)
+
+	transactor, _ := newTransactionMocks(ctrl)
+
+	service := partners.NewService(partners.ServiceParams{
+		PartnerRepo:         partnersRepo,
+		WebhookRepo:         webhooksRepo,
+		WebhookDeliveryRepo: deliveriesRepo,
+		WebhookSender:       sender,
+		Transactor:          transactor,
+		AuditRepo:           newAuditLogMock(ctrl),
+	})
+
+	fakePartner := partners.PartnerEntity{ID: uuid.NewString(), Name: "partner-test"}
+
+	t.Run("Should send a dead delivery again", func(t *testing.T) {
+		delivery := &partners.WebhookDeliveryEntity{
+			ID:         "delivery-01",
+			PartnerID:  fakePartner.ID,
+			Status:     partners.WebhookDeliveryDead,
+			Attempts:   30,
+			RetryUntil: time.Now().Add(-time.Hour),
+		}
+
+		partnersRepo.EXPECT().GetByID(gomock.Any(), fakePartner.ID).Return(&fakePartner, nil)
+		deliveriesRepo.EXPECT().GetByIDAndPartnerID(gomock.Any(), "delivery-01", fakePartner.ID).Return(delivery, nil)
+		webhooksRepo.EXPECT().GetSecret(gomock.Any(), fakePartner.ID).Return("whsec_test", nil)
+		sender.EXPECT().Send(gomock.Any(), gomock.Any()).Return(http.StatusOK, nil)
+		deliveriesRepo.EXPECT().Update(gomock.Any(), delivery).Return(nil)
+
+		result, err := service.RedeliverWebhook(t.Context(), fakePartner.ID, "delivery-01")
+
+		assert.NoError(t, err)
+		assert.Equal(t, partners.WebhookDeliveryDelivered, result.Status)
+		assert.Equal(t, 31, result.Attempts)
+	})
+
+	t.Run("Should retry a failed redelivery for another retry window", func(t *testing.T) {
+		delivery := &partners.WebhookDeliveryEntity{
+			ID:         "delivery-02",
+			PartnerID:  fakePartner.ID,
+			Status:     partners.WebhookDeliveryDead,
+			RetryUntil: time.Now().Add(-time.Hour),
+		}
+
+		partnersRepo.EXPECT().GetByID(gomock.Any(), fakePartner.ID).Return(&fakePartner, nil)
+		deliveriesRepo.EXPECT().GetByIDAndPartnerID(gomock.Any(), "delivery-02", fakePartner.ID).Return(delivery, nil)
+		webhooksRepo.EXPECT().GetSecret(gomock.Any(), fakePartner.ID).Return("whsec_test", nil)
+		sender.EXPECT().Send(gomock.Any(), gomock.Any()).Return(http.StatusBadGateway, errors.New("bad gateway"))
+		deliveriesRepo.EXPECT().Update(gomock.Any(), delivery).Return(nil)
+
+		result, err := service.RedeliverWebhook(t.Context(), fakePartner.ID, "delivery-02")
+
+		assert.NoError(t, err)
+		assert.Equal(t, partners.WebhookDeliveryPending, result.Status)
+		assert.True(t, result.RetryUntil.After(time.Now().Add(23*time.Hour)))
+	})
+
+	t.Run("Not should redeliver a delivery of another partner", func(t *testing.T) {
+		partnersRepo.EXPECT().GetByID(gomock.Any(), fakePartner.ID).Return(&fakePartner, nil)
+		deliveriesRepo.EXPECT().GetByIDAndPartnerID(gomock.Any(), "delivery-03", fakePartner.ID).Return(nil, nil)
+
+		result, err := service.RedeliverWebhook(t.Context(), fakePartner.ID, "delivery-03")
+
+		assert.Nil(t, result)
+		assert.Equal(t, partners.ErrWebhookDeliveryNotFound, err)
+	})
+}
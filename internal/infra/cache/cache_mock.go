@@ -35,6 +35,20 @@ func (m *MockCacheStore) EXPECT() *MockCacheStoreMockRecorder {
 	return m.recorder
 }
 
+// Delete mocks base method.
+func (m *MockCacheStore) Delete(ctx context.Context, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockCacheStoreMockRecorder) Delete(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockCacheStore)(nil).Delete), ctx, key)
+}
+
 // Get mocks base method.
 func (m *MockCacheStore) Get(ctx context.Context, key string) (string, error) {
 	m.ctrl.T.Helper()
@@ -50,6 +64,41 @@ func (mr *MockCacheStoreMockRecorder) Get(ctx, key interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockCacheStore)(nil).Get), ctx, key)
 }
 
+// Incr mocks base method.
+func (m *MockCacheStore) Incr(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Incr", ctx, key, ttl)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Incr indicates an expected call of Incr.
+func (mr *MockCacheStoreMockRecorder) Incr(ctx, key, ttl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Incr", reflect.TypeOf((*MockCacheStore)(nil).Incr), ctx, key, ttl)
+}
+
+// MGet mocks base method.
+func (m *MockCacheStore) MGet(ctx context.Context, keys ...string) (map[string]string, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx}
+	for _, a := range keys {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "MGet", varargs...)
+	ret0, _ := ret[0].(map[string]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MGet indicates an expected call of MGet.
+func (mr *MockCacheStoreMockRecorder) MGet(ctx interface{}, keys ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx}, keys...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MGet", reflect.TypeOf((*MockCacheStore)(nil).MGet), varargs...)
+}
+
 // Set mocks base method.
 func (m *MockCacheStore) Set(ctx context.Context, key string, value any, ttl time.Duration) error {
 	m.ctrl.T.Helper()
@@ -63,3 +112,61 @@ func (mr *MockCacheStoreMockRecorder) Set(ctx, key, value, ttl interface{}) *gom
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Set", reflect.TypeOf((*MockCacheStore)(nil).Set), ctx, key, value, ttl)
 }
+
+// SetNX mocks base method.
+func (m *MockCacheStore) SetNX(ctx context.Context, key string, value any, ttl time.Duration) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetNX", ctx, key, value, ttl)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetNX indicates an expected call of SetNX.
+func (mr *MockCacheStoreMockRecorder) SetNX(ctx, key, value, ttl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetNX", reflect.TypeOf((*MockCacheStore)(nil).SetNX), ctx, key, value, ttl)
+}
+
+// MockExpiringStore is a mock of ExpiringStore interface.
+type MockExpiringStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockExpiringStoreMockRecorder
+}
+
+// MockExpiringStoreMockRecorder is the mock recorder for MockExpiringStore.
+type MockExpiringStoreMockRecorder struct {
+	mock *MockExpiringStore
+}
+
+// NewMockExpiringStore creates a new mock instance.
+func NewMockExpiringStore(ctrl *gomock.Controller) *MockExpiringStore {
+	mock := &MockExpiringStore{ctrl: ctrl}
+	mock.recorder = &MockExpiringStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockExpiringStore) EXPECT() *MockExpiringStoreMockRecorder {
+	return m.recorder
+}
+
+// MGetWithTTL mocks base method.
+func (m *MockExpiringStore) MGetWithTTL(ctx context.Context, keys ...string) (map[string]ExpiringValue, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx}
+	for _, a := range keys {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "MGetWithTTL", varargs...)
+	ret0, _ := ret[0].(map[string]ExpiringValue)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MGetWithTTL indicates an expected call of MGetWithTTL.
+func (mr *MockExpiringStoreMockRecorder) MGetWithTTL(ctx interface{}, keys ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx}, keys...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MGetWithTTL", reflect.TypeOf((*MockExpiringStore)(nil).MGetWithTTL), varargs...)
+}
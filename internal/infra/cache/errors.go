@@ -3,5 +3,6 @@ package cache
 import "errors"
 
 var (
-	ErrCacheMiss = errors.New("cache: key not found")
+	ErrCacheMiss  = errors.New("cache: key not found")
+	ErrNotInteger = errors.New("cache: value is not an integer")
 )
@@ -9,5 +9,28 @@ type (
 	CacheStore interface {
 		Set(ctx context.Context, key string, value any, ttl time.Duration) error
 		Get(ctx context.Context, key string) (string, error)
+		// MGet gets many keys at once. Keys that don't exist are left out of the
+		// returned map instead of failing the call.
+		MGet(ctx context.Context, keys ...string) (map[string]string, error)
+		// SetNX sets the key only when it doesn't exist yet, reporting whether it
+		// was set.
+		SetNX(ctx context.Context, key string, value any, ttl time.Duration) (bool, error)
+		// Incr increments the counter in key, returning its new value. The ttl is
+		// set when the counter is created and kept by later increments.
+		Incr(ctx context.Context, key string, ttl time.Duration) (int64, error)
+		Delete(ctx context.Context, key string) error
+	}
+
+	// ExpiringStore is implemented by the stores that can tell how long their
+	// keys have left, so a copy of them isn't kept after they expire.
+	ExpiringStore interface {
+		// MGetWithTTL works like MGet, along with the time each key has left. A
+		// key that doesn't expire has a ttl of zero.
+		MGetWithTTL(ctx context.Context, keys ...string) (map[string]ExpiringValue, error)
+	}
+
+	ExpiringValue struct {
+		Value string
+		TTL   time.Duration
 	}
 )
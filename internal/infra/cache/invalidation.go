@@ -0,0 +1,95 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+type (
+	// InvalidationBus broadcasts the keys changed by a replica, so the others
+	// drop their local copies.
+	InvalidationBus interface {
+		Publish(ctx context.Context, keys ...string) error
+		// Subscribe calls onInvalidate with the keys changed by other replicas
+		// until ctx is done. It returns once the subscription is active.
+		Subscribe(ctx context.Context, onInvalidate func(keys []string)) error
+	}
+
+	// RedisInvalidationBus is an InvalidationBus over a Redis pub/sub channel.
+	// Pub/sub doesn't keep messages, so a replica that is reconnecting misses
+	// them; the L1 ttl bounds how long it serves those keys.
+	RedisInvalidationBus struct {
+		client  *redis.Client
+		channel string
+		// nodeID identifies the messages of this replica, which already
+		// updated its own L1.
+		nodeID string
+	}
+
+	invalidationMessage struct {
+		Origin string   `json:"origin"`
+		Keys   []string `json:"keys"`
+	}
+)
+
+func NewRedisInvalidationBus(client *redis.Client, channel string) *RedisInvalidationBus {
+	return &RedisInvalidationBus{
+		client:  client,
+		channel: channel,
+		nodeID:  uuid.NewString(),
+	}
+}
+
+func (b *RedisInvalidationBus) Publish(ctx context.Context, keys ...string) error {
+	message, err := json.Marshal(invalidationMessage{Origin: b.nodeID, Keys: keys})
+	if err != nil {
+		return err
+	}
+
+	return b.client.Publish(ctx, b.channel, message).Err()
+}
+
+func (b *RedisInvalidationBus) Subscribe(ctx context.Context, onInvalidate func(keys []string)) error {
+	pubsub := b.client.Subscribe(ctx, b.channel)
+
+	if _, err := pubsub.Receive(ctx); err != nil {
+		_ = pubsub.Close()
+
+		return err
+	}
+
+	go func() {
+		defer pubsub.Close()
+
+		messages := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case message, ok := <-messages:
+				if !ok {
+					return
+				}
+
+				var invalidation invalidationMessage
+				if err := json.Unmarshal([]byte(message.Payload), &invalidation); err != nil {
+					log.Errorf("[CACHE] failed to decode invalidation %q: %v", message.Payload, err)
+
+					continue
+				}
+
+				if invalidation.Origin == b.nodeID {
+					continue
+				}
+
+				onInvalidate(invalidation.Keys)
+			}
+		}
+	}()
+
+	return nil
+}
@@ -0,0 +1,33 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// SetJSON stores value encoded as JSON, for structs that don't fit the string
+// values of CacheStore.
+func SetJSON[T any](ctx context.Context, store CacheStore, key string, value T, ttl time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	return store.Set(ctx, key, data, ttl)
+}
+
+// GetJSON decodes a value stored by SetJSON. It returns ErrCacheMiss when the
+// key doesn't exist.
+func GetJSON[T any](ctx context.Context, store CacheStore, key string) (T, error) {
+	var value T
+
+	data, err := store.Get(ctx, key)
+	if err != nil {
+		return value, err
+	}
+
+	err = json.Unmarshal([]byte(data), &value)
+
+	return value, err
+}
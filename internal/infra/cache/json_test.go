@@ -0,0 +1,41 @@
+package cache_test
+
+import (
+	"main-api/internal/infra/cache"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSON(t *testing.T) {
+	t.Parallel()
+
+	type policy struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+
+	testRedis := miniredis.RunT(t)
+	redisStorage := cache.NewRedisCacheAdapter(redis.NewClient(&redis.Options{
+		Addr: testRedis.Addr(),
+	}))
+
+	t.Run("Should get a struct stored as json", func(t *testing.T) {
+		err := cache.SetJSON(t.Context(), redisStorage, "policy-01", policy{ID: "01", Name: "test"}, 10*time.Second)
+		assert.NoError(t, err)
+
+		cached, err := cache.GetJSON[policy](t.Context(), redisStorage, "policy-01")
+
+		assert.NoError(t, err)
+		assert.Equal(t, policy{ID: "01", Name: "test"}, cached)
+	})
+
+	t.Run("Should return cache miss when key not exists", func(t *testing.T) {
+		_, err := cache.GetJSON[policy](t.Context(), redisStorage, "policy-02")
+
+		assert.Equal(t, cache.ErrCacheMiss, err)
+	})
+}
@@ -0,0 +1,217 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"encoding"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type (
+	// MemoryCacheAdapter keeps the values in the process memory, for tests and
+	// single replica runs, and as the local tier of TieredCacheAdapter. Once it
+	// holds maxEntries keys, the least recently used one is evicted to make
+	// room for a new key.
+	MemoryCacheAdapter struct {
+		mu         sync.Mutex
+		maxEntries int
+		entries    map[string]*list.Element
+		// recency orders the entries from the most to the least recently used.
+		recency *list.List
+	}
+
+	memoryEntry struct {
+		key       string
+		value     string
+		expiresAt time.Time
+	}
+)
+
+func NewMemoryCacheAdapter(maxEntries int) *MemoryCacheAdapter {
+	return &MemoryCacheAdapter{
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		recency:    list.New(),
+	}
+}
+
+func (m *MemoryCacheAdapter) Set(_ context.Context, key string, value any, ttl time.Duration) error {
+	encoded, err := encodeValue(value)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.set(key, encoded, ttl)
+
+	return nil
+}
+
+func (m *MemoryCacheAdapter) Get(_ context.Context, key string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, ok := m.get(key)
+	if !ok {
+		return "", ErrCacheMiss
+	}
+
+	return entry.value, nil
+}
+
+func (m *MemoryCacheAdapter) MGet(_ context.Context, keys ...string) (map[string]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	values := make(map[string]string, len(keys))
+	for _, key := range keys {
+		if entry, ok := m.get(key); ok {
+			values[key] = entry.value
+		}
+	}
+
+	return values, nil
+}
+
+func (m *MemoryCacheAdapter) SetNX(_ context.Context, key string, value any, ttl time.Duration) (bool, error) {
+	encoded, err := encodeValue(value)
+	if err != nil {
+		return false, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.get(key); ok {
+		return false, nil
+	}
+
+	m.set(key, encoded, ttl)
+
+	return true, nil
+}
+
+func (m *MemoryCacheAdapter) Incr(_ context.Context, key string, ttl time.Duration) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, ok := m.get(key)
+	if !ok {
+		m.set(key, "1", ttl)
+
+		return 1, nil
+	}
+
+	value, err := strconv.ParseInt(entry.value, 10, 64)
+	if err != nil {
+		return 0, ErrNotInteger
+	}
+
+	value++
+	entry.value = strconv.FormatInt(value, 10)
+
+	return value, nil
+}
+
+func (m *MemoryCacheAdapter) Delete(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if element, ok := m.entries[key]; ok {
+		m.remove(element)
+	}
+
+	return nil
+}
+
+// Len is the number of keys held, including expired keys not evicted yet.
+func (m *MemoryCacheAdapter) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.recency.Len()
+}
+
+// get returns the entry of a key that hasn't expired, marking it as the most
+// recently used. Expired keys are removed when they are found.
+func (m *MemoryCacheAdapter) get(key string) (*memoryEntry, bool) {
+	element, ok := m.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := element.Value.(*memoryEntry)
+	if !entry.expiresAt.IsZero() && !time.Now().Before(entry.expiresAt) {
+		m.remove(element)
+
+		return nil, false
+	}
+
+	m.recency.MoveToFront(element)
+
+	return entry, true
+}
+
+func (m *MemoryCacheAdapter) set(key, value string, ttl time.Duration) {
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = time.Now().Add(ttl)
+	}
+
+	if element, ok := m.entries[key]; ok {
+		entry := element.Value.(*memoryEntry)
+		entry.value = value
+		entry.expiresAt = expiresAt
+		m.recency.MoveToFront(element)
+
+		return
+	}
+
+	m.entries[key] = m.recency.PushFront(&memoryEntry{
+		key:       key,
+		value:     value,
+		expiresAt: expiresAt,
+	})
+
+	if m.maxEntries > 0 && m.recency.Len() > m.maxEntries {
+		m.remove(m.recency.Back())
+	}
+}
+
+func (m *MemoryCacheAdapter) remove(element *list.Element) {
+	m.recency.Remove(element)
+	delete(m.entries, element.Value.(*memoryEntry).key)
+}
+
+// encodeValue turns a value into the string Redis would store for it, so both
+// adapters return the same values.
+func encodeValue(value any) (string, error) {
+	switch value := value.(type) {
+	case string:
+		return value, nil
+	case []byte:
+		return string(value), nil
+	case nil:
+		return "", nil
+	case bool:
+		if value {
+			return "1", nil
+		}
+
+		return "0", nil
+	case encoding.BinaryMarshaler:
+		data, err := value.MarshalBinary()
+		if err != nil {
+			return "", err
+		}
+
+		return string(data), nil
+	default:
+		return fmt.Sprint(value), nil
+	}
+}
@@ -0,0 +1,139 @@
+package cache_test
+
+import (
+	"fmt"
+	"main-api/internal/infra/cache"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStorage(t *testing.T) {
+	t.Parallel()
+
+	memoryStorage := cache.NewMemoryCacheAdapter(100)
+
+	t.Run("Get value from cache and should not return error", func(t *testing.T) {
+		_ = memoryStorage.Set(t.Context(), "test-01", "fake-value", 10*time.Second)
+
+		value, err := memoryStorage.Get(t.Context(), "test-01")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "fake-value", value)
+	})
+
+	t.Run("Should store values the same way redis does", func(t *testing.T) {
+		_ = memoryStorage.Set(t.Context(), "test-02", []byte("bytes"), 0)
+		_ = memoryStorage.Set(t.Context(), "test-03", 42, 0)
+		_ = memoryStorage.Set(t.Context(), "test-04", true, 0)
+
+		values, err := memoryStorage.MGet(t.Context(), "test-02", "test-03", "test-04")
+
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"test-02": "bytes", "test-03": "42", "test-04": "1"}, values)
+	})
+
+	t.Run("Get value from cache and should return error when not found key", func(t *testing.T) {
+		_, err := memoryStorage.Get(t.Context(), "test-05")
+
+		assert.Equal(t, cache.ErrCacheMiss, err)
+	})
+
+	t.Run("Get value from cache and should return error when key expired", func(t *testing.T) {
+		_ = memoryStorage.Set(t.Context(), "test-06", "fake-value", 10*time.Millisecond)
+
+		time.Sleep(20 * time.Millisecond)
+
+		_, err := memoryStorage.Get(t.Context(), "test-06")
+
+		assert.Equal(t, cache.ErrCacheMiss, err)
+	})
+
+	t.Run("SetNX should set only keys that not exists", func(t *testing.T) {
+		ok, err := memoryStorage.SetNX(t.Context(), "test-07", "first", 10*time.Second)
+
+		assert.NoError(t, err)
+		assert.True(t, ok)
+
+		ok, err = memoryStorage.SetNX(t.Context(), "test-07", "second", 10*time.Second)
+
+		assert.NoError(t, err)
+		assert.False(t, ok)
+
+		value, _ := memoryStorage.Get(t.Context(), "test-07")
+		assert.Equal(t, "first", value)
+	})
+
+	t.Run("Incr should count and return error when value is not an integer", func(t *testing.T) {
+		value, err := memoryStorage.Incr(t.Context(), "test-08", 10*time.Second)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), value)
+
+		value, err = memoryStorage.Incr(t.Context(), "test-08", 10*time.Second)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), value)
+
+		_ = memoryStorage.Set(t.Context(), "test-09", "fake-value", 0)
+
+		_, err = memoryStorage.Incr(t.Context(), "test-09", 0)
+
+		assert.Equal(t, cache.ErrNotInteger, err)
+	})
+
+	t.Run("Delete should remove the key", func(t *testing.T) {
+		_ = memoryStorage.Set(t.Context(), "test-10", "fake-value", 10*time.Second)
+
+		err := memoryStorage.Delete(t.Context(), "test-10")
+		assert.NoError(t, err)
+
+		_, err = memoryStorage.Get(t.Context(), "test-10")
+		assert.Equal(t, cache.ErrCacheMiss, err)
+	})
+}
+
+func TestMemoryStorageEviction(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Should evict the least recently used key when full", func(t *testing.T) {
+		memoryStorage := cache.NewMemoryCacheAdapter(2)
+
+		_ = memoryStorage.Set(t.Context(), "first", "1", 0)
+		_ = memoryStorage.Set(t.Context(), "second", "2", 0)
+		_, _ = memoryStorage.Get(t.Context(), "first")
+		_ = memoryStorage.Set(t.Context(), "third", "3", 0)
+
+		values, _ := memoryStorage.MGet(t.Context(), "first", "second", "third")
+
+		assert.Equal(t, map[string]string{"first": "1", "third": "3"}, values)
+		assert.Equal(t, 2, memoryStorage.Len())
+	})
+
+	t.Run("Should be safe for concurrent use", func(t *testing.T) {
+		memoryStorage := cache.NewMemoryCacheAdapter(10)
+
+		var wg sync.WaitGroup
+		for i := range 50 {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				key := fmt.Sprintf("key-%d", i%20)
+				_ = memoryStorage.Set(t.Context(), key, i, 0)
+				_, _ = memoryStorage.Get(t.Context(), key)
+				_, _ = memoryStorage.Incr(t.Context(), "counter", 0)
+			}()
+		}
+
+		wg.Wait()
+
+		counter, _ := memoryStorage.Get(t.Context(), "counter")
+
+		assert.Equal(t, "50", counter)
+		assert.LessOrEqual(t, memoryStorage.Len(), 10)
+	})
+}
@@ -2,6 +2,7 @@ package cache
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -13,6 +14,18 @@ type (
 	}
 )
 
+var (
+	// incrScript increments the counter and sets its ttl in one round trip, so
+	// a counter is never left without expiration.
+	incrScript = redis.NewScript(`
+local value = redis.call("INCR", KEYS[1])
+if value == 1 and tonumber(ARGV[1]) > 0 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return value
+`)
+)
+
 func NewRedisCacheAdapter(redisClient *redis.Client) *RedisCacheAdapter {
 	return &RedisCacheAdapter{
 		redisClient: redisClient,
@@ -25,24 +38,6 @@ func (r *RedisCacheAdapter) Set(ctx context.Context, key string, value any, ttl
 	return err
 }
 
-// func (r *RedisCacheAdapter) GetAsStruct(ctx context.Context, key string, dest interface{}) error {
-// 	val, err := r.redisClient.Get(ctx, key).Result()
-// 	if err == redis.Nil {
-// 		return ErrCacheMiss
-// 	}
-
-// 	if err != nil {
-// 		return err
-// 	}
-
-// 	err = json.Unmarshal([]byte(val), dest)
-// 	if err != nil {
-// 		return err
-// 	}
-
-// 	return nil
-// }
-
 func (r *RedisCacheAdapter) Get(ctx context.Context, key string) (string, error) {
 	val, err := r.redisClient.Get(ctx, key).Result()
 	if err == redis.Nil {
@@ -55,3 +50,75 @@ func (r *RedisCacheAdapter) Get(ctx context.Context, key string) (string, error)
 
 	return val, nil
 }
+
+func (r *RedisCacheAdapter) MGet(ctx context.Context, keys ...string) (map[string]string, error) {
+	values := make(map[string]string, len(keys))
+	if len(keys) == 0 {
+		return values, nil
+	}
+
+	result, err := r.redisClient.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	for i, value := range result {
+		if value, ok := value.(string); ok {
+			values[keys[i]] = value
+		}
+	}
+
+	return values, nil
+}
+
+// MGetWithTTL reads each value along with its ttl in a transaction, so a key
+// set again in between doesn't mix both.
+func (r *RedisCacheAdapter) MGetWithTTL(ctx context.Context, keys ...string) (map[string]ExpiringValue, error) {
+	values := make(map[string]ExpiringValue, len(keys))
+	if len(keys) == 0 {
+		return values, nil
+	}
+
+	gets := make([]*redis.StringCmd, len(keys))
+	ttls := make([]*redis.DurationCmd, len(keys))
+
+	_, err := r.redisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, key := range keys {
+			gets[i] = pipe.Get(ctx, key)
+			ttls[i] = pipe.PTTL(ctx, key)
+		}
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+
+	for i, key := range keys {
+		value, err := gets[i].Result()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		// PTTL is negative for the keys without expiration.
+		values[key] = ExpiringValue{Value: value, TTL: max(ttls[i].Val(), 0)}
+	}
+
+	return values, nil
+}
+
+func (r *RedisCacheAdapter) SetNX(ctx context.Context, key string, value any, ttl time.Duration) (bool, error) {
+	return r.redisClient.SetNX(ctx, key, value, ttl).Result()
+}
+
+func (r *RedisCacheAdapter) Incr(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	return incrScript.Run(ctx, r.redisClient, []string{key}, ttl.Milliseconds()).Int64()
+}
+
+func (r *RedisCacheAdapter) Delete(ctx context.Context, key string) error {
+	return r.redisClient.Del(ctx, key).Err()
+}
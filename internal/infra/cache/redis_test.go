@@ -41,4 +41,81 @@ func TestRedisStorage(t *testing.T) {
 		assert.Error(t, err)
 		assert.Equal(t, cache.ErrCacheMiss, err)
 	})
+
+	t.Run("SetNX should set only keys that not exists", func(t *testing.T) {
+		ok, err := redisStorage.SetNX(t.Context(), "test-04", "first", 10*time.Second)
+
+		assert.NoError(t, err)
+		assert.True(t, ok)
+
+		ok, err = redisStorage.SetNX(t.Context(), "test-04", "second", 10*time.Second)
+
+		assert.NoError(t, err)
+		assert.False(t, ok)
+
+		value, _ := redisStorage.Get(t.Context(), "test-04")
+		assert.Equal(t, "first", value)
+	})
+
+	t.Run("Delete should remove the key", func(t *testing.T) {
+		_ = redisStorage.Set(t.Context(), "test-05", "fake-value", 10*time.Second)
+
+		err := redisStorage.Delete(t.Context(), "test-05")
+		assert.NoError(t, err)
+
+		_, err = redisStorage.Get(t.Context(), "test-05")
+		assert.Equal(t, cache.ErrCacheMiss, err)
+	})
+
+	t.Run("Incr should count and keep the ttl of the first increment", func(t *testing.T) {
+		value, err := redisStorage.Incr(t.Context(), "test-06", 10*time.Second)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), value)
+
+		testRedis.FastForward(5 * time.Second)
+
+		value, err = redisStorage.Incr(t.Context(), "test-06", 10*time.Second)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), value)
+		assert.Equal(t, 5*time.Second, testRedis.TTL("test-06"))
+	})
+
+	t.Run("Incr should not set a ttl when it is zero", func(t *testing.T) {
+		_, err := redisStorage.Incr(t.Context(), "test-07", 0)
+
+		assert.NoError(t, err)
+		assert.Zero(t, testRedis.TTL("test-07"))
+	})
+
+	t.Run("MGet should return only the keys that exists", func(t *testing.T) {
+		_ = redisStorage.Set(t.Context(), "test-08", "first", 10*time.Second)
+		_ = redisStorage.Set(t.Context(), "test-09", "second", 10*time.Second)
+
+		values, err := redisStorage.MGet(t.Context(), "test-08", "test-09", "test-10")
+
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"test-08": "first", "test-09": "second"}, values)
+	})
+
+	t.Run("MGet should return an empty map when no keys are given", func(t *testing.T) {
+		values, err := redisStorage.MGet(t.Context())
+
+		assert.NoError(t, err)
+		assert.Empty(t, values)
+	})
+
+	t.Run("MGetWithTTL should return the time each key has left", func(t *testing.T) {
+		_ = redisStorage.Set(t.Context(), "test-11", "first", 10*time.Second)
+		_ = redisStorage.Set(t.Context(), "test-12", "second", 0)
+
+		values, err := redisStorage.MGetWithTTL(t.Context(), "test-11", "test-12", "test-13")
+
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]cache.ExpiringValue{
+			"test-11": {Value: "first", TTL: 10 * time.Second},
+			"test-12": {Value: "second"},
+		}, values)
+	})
 }
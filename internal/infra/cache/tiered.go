@@ -0,0 +1,257 @@
+package cache
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/gofiber/fiber/v2/log"
+)
+
+type (
+	// TieredCacheAdapter keeps hot keys in a local L1 in front of a shared L2,
+	// usually Redis. Keys changed through the adapter are broadcast on the
+	// invalidation bus, so other replicas drop them from their L1. Values are
+	// kept in L1 for at most localTTL, which bounds how long a replica serves a
+	// value whose invalidation it missed, and never past their expiration in
+	// L2 when it is an ExpiringStore.
+	TieredCacheAdapter struct {
+		local    CacheStore
+		remote   CacheStore
+		localTTL time.Duration
+		bus      InvalidationBus
+
+		localHits     atomic.Int64
+		localMisses   atomic.Int64
+		remoteHits    atomic.Int64
+		remoteMisses  atomic.Int64
+		invalidations atomic.Int64
+	}
+
+	// TieredCacheStats counts the lookups served by each tier. The remote
+	// counters only include lookups that missed L1.
+	TieredCacheStats struct {
+		LocalHits     int64   `json:"local_hits"`
+		LocalMisses   int64   `json:"local_misses"`
+		LocalHitRate  float64 `json:"local_hit_rate"`
+		RemoteHits    int64   `json:"remote_hits"`
+		RemoteMisses  int64   `json:"remote_misses"`
+		RemoteHitRate float64 `json:"remote_hit_rate"`
+		Invalidations int64   `json:"invalidations"`
+	}
+)
+
+// NewTieredCacheAdapter builds the adapter. The bus may be nil when there is a
+// single replica; Listen must be called to receive invalidations from others.
+func NewTieredCacheAdapter(local, remote CacheStore, localTTL time.Duration, bus InvalidationBus) *TieredCacheAdapter {
+	return &TieredCacheAdapter{
+		local:    local,
+		remote:   remote,
+		localTTL: localTTL,
+		bus:      bus,
+	}
+}
+
+// Listen drops from L1 the keys changed by other replicas until ctx is done.
+func (t *TieredCacheAdapter) Listen(ctx context.Context) error {
+	if t.bus == nil {
+		return nil
+	}
+
+	return t.bus.Subscribe(ctx, func(keys []string) {
+		t.invalidations.Add(int64(len(keys)))
+
+		for _, key := range keys {
+			t.deleteLocal(ctx, key)
+		}
+	})
+}
+
+// Stats returns the hit counters of each tier, published by expvar.
+func (t *TieredCacheAdapter) Stats() TieredCacheStats {
+	stats := TieredCacheStats{
+		LocalHits:     t.localHits.Load(),
+		LocalMisses:   t.localMisses.Load(),
+		RemoteHits:    t.remoteHits.Load(),
+		RemoteMisses:  t.remoteMisses.Load(),
+		Invalidations: t.invalidations.Load(),
+	}
+
+	stats.LocalHitRate = hitRate(stats.LocalHits, stats.LocalMisses)
+	stats.RemoteHitRate = hitRate(stats.RemoteHits, stats.RemoteMisses)
+
+	return stats
+}
+
+func (t *TieredCacheAdapter) Set(ctx context.Context, key string, value any, ttl time.Duration) error {
+	if err := t.remote.Set(ctx, key, value, ttl); err != nil {
+		return err
+	}
+
+	t.setLocal(ctx, key, value, ttl)
+	t.publish(ctx, key)
+
+	return nil
+}
+
+func (t *TieredCacheAdapter) Get(ctx context.Context, key string) (string, error) {
+	if value, err := t.local.Get(ctx, key); err == nil {
+		t.localHits.Add(1)
+
+		return value, nil
+	}
+
+	t.localMisses.Add(1)
+
+	values, err := t.getRemote(ctx, key)
+	if err != nil {
+		return "", err
+	}
+
+	value, ok := values[key]
+	if !ok {
+		t.remoteMisses.Add(1)
+
+		return "", ErrCacheMiss
+	}
+
+	t.remoteHits.Add(1)
+	t.setLocal(ctx, key, value.Value, value.TTL)
+
+	return value.Value, nil
+}
+
+func (t *TieredCacheAdapter) MGet(ctx context.Context, keys ...string) (map[string]string, error) {
+	values, err := t.local.MGet(ctx, keys...)
+	if err != nil {
+		values = make(map[string]string, len(keys))
+	}
+
+	missing := make([]string, 0, len(keys)-len(values))
+	for _, key := range keys {
+		if _, ok := values[key]; !ok {
+			missing = append(missing, key)
+		}
+	}
+
+	t.localHits.Add(int64(len(values)))
+	t.localMisses.Add(int64(len(missing)))
+
+	if len(missing) == 0 {
+		return values, nil
+	}
+
+	remoteValues, err := t.getRemote(ctx, missing...)
+	if err != nil {
+		return nil, err
+	}
+
+	t.remoteHits.Add(int64(len(remoteValues)))
+	t.remoteMisses.Add(int64(len(missing) - len(remoteValues)))
+
+	for key, value := range remoteValues {
+		values[key] = value.Value
+		t.setLocal(ctx, key, value.Value, value.TTL)
+	}
+
+	return values, nil
+}
+
+// getRemote reads the keys from L2 along with their ttl when it can tell it.
+// Otherwise their ttl is zero and their L1 copy is kept for localTTL.
+func (t *TieredCacheAdapter) getRemote(ctx context.Context, keys ...string) (map[string]ExpiringValue, error) {
+	if remote, ok := t.remote.(ExpiringStore); ok {
+		return remote.MGetWithTTL(ctx, keys...)
+	}
+
+	values, err := t.remote.MGet(ctx, keys...)
+	if err != nil {
+		return nil, err
+	}
+
+	expiringValues := make(map[string]ExpiringValue, len(values))
+	for key, value := range values {
+		expiringValues[key] = ExpiringValue{Value: value}
+	}
+
+	return expiringValues, nil
+}
+
+// SetNX and Incr only run on the remote store, which is shared by every
+// replica, and drop the local copies of the key.
+func (t *TieredCacheAdapter) SetNX(ctx context.Context, key string, value any, ttl time.Duration) (bool, error) {
+	ok, err := t.remote.SetNX(ctx, key, value, ttl)
+	if err != nil {
+		return false, err
+	}
+
+	if ok {
+		t.deleteLocal(ctx, key)
+		t.publish(ctx, key)
+	}
+
+	return ok, nil
+}
+
+func (t *TieredCacheAdapter) Incr(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	value, err := t.remote.Incr(ctx, key, ttl)
+	if err != nil {
+		return 0, err
+	}
+
+	t.deleteLocal(ctx, key)
+	t.publish(ctx, key)
+
+	return value, nil
+}
+
+func (t *TieredCacheAdapter) Delete(ctx context.Context, key string) error {
+	t.deleteLocal(ctx, key)
+
+	if err := t.remote.Delete(ctx, key); err != nil {
+		return err
+	}
+
+	t.publish(ctx, key)
+
+	return nil
+}
+
+// setLocal keeps a copy of the key in L1 for localTTL, or for ttl when the key
+// expires sooner. A ttl of zero means the key doesn't expire.
+func (t *TieredCacheAdapter) setLocal(ctx context.Context, key string, value any, ttl time.Duration) {
+	localTTL := t.localTTL
+	if ttl > 0 {
+		localTTL = min(localTTL, ttl)
+	}
+
+	if err := t.local.Set(ctx, key, value, localTTL); err != nil {
+		log.Errorf("[CACHE] failed to set local key %q: %v", key, err)
+	}
+}
+
+func (t *TieredCacheAdapter) deleteLocal(ctx context.Context, key string) {
+	if err := t.local.Delete(ctx, key); err != nil {
+		log.Errorf("[CACHE] failed to delete local key %q: %v", key, err)
+	}
+}
+
+// publish broadcasts a changed key. A failure is only logged, as the write
+// itself succeeded and other replicas drop the key when their L1 ttl expires.
+func (t *TieredCacheAdapter) publish(ctx context.Context, key string) {
+	if t.bus == nil {
+		return
+	}
+
+	if err := t.bus.Publish(ctx, key); err != nil {
+		log.Errorf("[CACHE] failed to publish the invalidation of key %q: %v", key, err)
+	}
+}
+
+func hitRate(hits, misses int64) float64 {
+	if hits+misses == 0 {
+		return 0
+	}
+
+	return float64(hits) / float64(hits+misses)
+}
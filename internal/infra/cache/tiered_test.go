@@ -0,0 +1,189 @@
+package cache_test
+
+import (
+	"main-api/internal/infra/cache"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTieredStorage(t *testing.T) {
+	t.Parallel()
+
+	testRedis := miniredis.RunT(t)
+	redisStorage := cache.NewRedisCacheAdapter(redis.NewClient(&redis.Options{
+		Addr: testRedis.Addr(),
+	}))
+	localStorage := cache.NewMemoryCacheAdapter(100)
+	tieredStorage := cache.NewTieredCacheAdapter(localStorage, redisStorage, time.Minute, nil)
+
+	t.Run("Set should write to both tiers", func(t *testing.T) {
+		err := tieredStorage.Set(t.Context(), "test-01", "fake-value", time.Hour)
+		assert.NoError(t, err)
+
+		local, _ := localStorage.Get(t.Context(), "test-01")
+		remote, _ := redisStorage.Get(t.Context(), "test-01")
+
+		assert.Equal(t, "fake-value", local)
+		assert.Equal(t, "fake-value", remote)
+	})
+
+	t.Run("Get should serve from the local tier while it is fresh", func(t *testing.T) {
+		_ = tieredStorage.Set(t.Context(), "test-02", "first", time.Hour)
+		testRedis.Set("test-02", "second")
+
+		value, err := tieredStorage.Get(t.Context(), "test-02")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "first", value)
+	})
+
+	t.Run("Get should fill the local tier from redis", func(t *testing.T) {
+		testRedis.Set("test-03", "fake-value")
+
+		value, err := tieredStorage.Get(t.Context(), "test-03")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "fake-value", value)
+
+		local, _ := localStorage.Get(t.Context(), "test-03")
+		assert.Equal(t, "fake-value", local)
+	})
+
+	t.Run("Get should not keep a local copy past its expiration in redis", func(t *testing.T) {
+		testRedis.Set("test-09", "fake-value")
+		testRedis.SetTTL("test-09", 50*time.Millisecond)
+
+		_, err := tieredStorage.Get(t.Context(), "test-09")
+		assert.NoError(t, err)
+
+		local, _ := localStorage.Get(t.Context(), "test-09")
+		assert.Equal(t, "fake-value", local)
+
+		assert.Eventually(t, func() bool {
+			_, err := localStorage.Get(t.Context(), "test-09")
+
+			return err == cache.ErrCacheMiss
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("MGet should combine both tiers", func(t *testing.T) {
+		_ = localStorage.Set(t.Context(), "test-04", "local", time.Minute)
+		testRedis.Set("test-05", "remote")
+
+		values, err := tieredStorage.MGet(t.Context(), "test-04", "test-05", "test-06")
+
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"test-04": "local", "test-05": "remote"}, values)
+	})
+
+	t.Run("Incr should count on redis", func(t *testing.T) {
+		_, _ = tieredStorage.Incr(t.Context(), "test-07", time.Minute)
+		value, err := tieredStorage.Incr(t.Context(), "test-07", time.Minute)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), value)
+
+		_, err = localStorage.Get(t.Context(), "test-07")
+		assert.Equal(t, cache.ErrCacheMiss, err)
+	})
+
+	t.Run("Delete should remove the key from both tiers", func(t *testing.T) {
+		_ = tieredStorage.Set(t.Context(), "test-08", "fake-value", time.Hour)
+
+		err := tieredStorage.Delete(t.Context(), "test-08")
+		assert.NoError(t, err)
+
+		_, err = tieredStorage.Get(t.Context(), "test-08")
+		assert.Equal(t, cache.ErrCacheMiss, err)
+	})
+}
+
+func TestTieredStorageInvalidation(t *testing.T) {
+	t.Parallel()
+
+	testRedis := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{
+		Addr: testRedis.Addr(),
+	})
+	redisStorage := cache.NewRedisCacheAdapter(rdb)
+
+	newReplica := func() (*cache.TieredCacheAdapter, *cache.MemoryCacheAdapter) {
+		localStorage := cache.NewMemoryCacheAdapter(100)
+		bus := cache.NewRedisInvalidationBus(rdb, "cache:invalidation")
+		replica := cache.NewTieredCacheAdapter(localStorage, redisStorage, time.Minute, bus)
+
+		err := replica.Listen(t.Context())
+		assert.NoError(t, err)
+
+		return replica, localStorage
+	}
+
+	first, firstLocal := newReplica()
+	second, secondLocal := newReplica()
+
+	t.Run("Should drop the local copy of other replicas when a key changes", func(t *testing.T) {
+		_ = first.Set(t.Context(), "partner-01", "v1", time.Hour)
+		_, _ = second.Get(t.Context(), "partner-01")
+
+		err := first.Set(t.Context(), "partner-01", "v2", time.Hour)
+		assert.NoError(t, err)
+
+		assert.Eventually(t, func() bool {
+			_, err := secondLocal.Get(t.Context(), "partner-01")
+
+			return err == cache.ErrCacheMiss
+		}, time.Second, 5*time.Millisecond)
+
+		value, _ := second.Get(t.Context(), "partner-01")
+		assert.Equal(t, "v2", value)
+
+		local, _ := firstLocal.Get(t.Context(), "partner-01")
+		assert.Equal(t, "v2", local)
+	})
+
+	t.Run("Should drop the local copy of other replicas when a key is deleted", func(t *testing.T) {
+		_ = first.Set(t.Context(), "partner-02", "v1", time.Hour)
+		_, _ = second.Get(t.Context(), "partner-02")
+
+		err := first.Delete(t.Context(), "partner-02")
+		assert.NoError(t, err)
+
+		assert.Eventually(t, func() bool {
+			_, err := second.Get(t.Context(), "partner-02")
+
+			return err == cache.ErrCacheMiss
+		}, time.Second, 5*time.Millisecond)
+	})
+}
+
+func TestTieredStorageStats(t *testing.T) {
+	t.Parallel()
+
+	testRedis := miniredis.RunT(t)
+	redisStorage := cache.NewRedisCacheAdapter(redis.NewClient(&redis.Options{
+		Addr: testRedis.Addr(),
+	}))
+	tieredStorage := cache.NewTieredCacheAdapter(cache.NewMemoryCacheAdapter(100), redisStorage, time.Minute, nil)
+
+	t.Run("Should count the hits and misses of each tier", func(t *testing.T) {
+		testRedis.Set("test-01", "fake-value")
+
+		_, _ = tieredStorage.Get(t.Context(), "test-01")
+		_, _ = tieredStorage.Get(t.Context(), "test-01")
+		_, _ = tieredStorage.Get(t.Context(), "test-01")
+		_, _ = tieredStorage.Get(t.Context(), "test-02")
+
+		stats := tieredStorage.Stats()
+
+		assert.Equal(t, int64(2), stats.LocalHits)
+		assert.Equal(t, int64(2), stats.LocalMisses)
+		assert.Equal(t, 0.5, stats.LocalHitRate)
+		assert.Equal(t, int64(1), stats.RemoteHits)
+		assert.Equal(t, int64(1), stats.RemoteMisses)
+		assert.Equal(t, 0.5, stats.RemoteHitRate)
+	})
+}
@@ -0,0 +1,483 @@
+package encryption
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+type (
+	// DataKey encrypts the personal data of a partner. It is stored wrapped,
+	// encrypted by the master key with MasterKeyID.
+	DataKey struct {
+		ID          string
+		PartnerID   string
+		Version     int
+		WrappedKey  []byte
+		MasterKeyID string
+		CreatedAt   time.Time
+	}
+
+	// DataKeyStore keeps the data keys. The active key of a partner is the one
+	// with the highest version; the others are kept to decrypt the data
+	// encrypted before a rotation. A partner's first key is created while its
+	// first document is encrypted, usually in a transaction, so the store must
+	// read and write outside of any transaction in the context: the key has to
+	// outlive the transaction being aborted, and a key created by another
+	// replica has to be visible to it.
+	DataKeyStore interface {
+		// Active returns the partner's active key, or nil when the partner has
+		// none yet.
+		Active(ctx context.Context, partnerID string) (*DataKey, error)
+		GetByID(ctx context.Context, id string) (*DataKey, error)
+		// Create returns ErrDataKeyExists when the partner already has a key
+		// with the version, such as one created by another replica.
+		Create(ctx context.Context, key *DataKey) error
+		List(ctx context.Context) ([]DataKey, error)
+		// UpdateWrapping saves the key wrapped by another master key.
+		UpdateWrapping(ctx context.Context, key *DataKey) error
+	}
+
+	// Cipher encrypts fields of the documents with envelope encryption: each
+	// partner has its own data keys, wrapped by a master key from the
+	// configuration. It also hashes the fields searched by exact match, with a
+	// key of its own, so they can be found without being decrypted.
+	Cipher struct {
+		store       DataKeyStore
+		masterKeys  map[string][]byte
+		masterKeyID string
+		hashKey     []byte
+		// keys caches the unwrapped data keys by ID. Data keys never change,
+		// only their wrapping, so they are cached for good.
+		keys sync.Map
+	}
+
+	// Encrypter encrypts the fields of a document with the active data key of
+	// its partner. The first error is kept and returned by Err, so a document
+	// can be built without checking every field.
+	Encrypter struct {
+		keyID string
+		aead  cipher.AEAD
+		err   error
+	}
+
+	// Decrypter decrypts the fields of documents. Like Encrypter, it keeps the
+	// first error for Err.
+	Decrypter struct {
+		ctx    context.Context
+		cipher *Cipher
+		err    error
+	}
+)
+
+const (
+	// KeySize is the size of the master, hash and data keys, for AES-256.
+	KeySize = 32
+
+	// ciphertextPrefix starts the encrypted values, followed by the data key
+	// ID and the base64 of the nonce and the sealed value. Values without it
+	// were stored before encryption and are read as they are.
+	ciphertextPrefix = "enc:v1:"
+)
+
+var (
+	ErrDataKeyExists      = errors.New("data key already exists")
+	ErrDataKeyNotFound    = errors.New("data key not found")
+	ErrUnknownMasterKey   = errors.New("unknown master key")
+	ErrInvalidCiphertext  = errors.New("invalid ciphertext")
+	ErrInvalidKeySize     = fmt.Errorf("keys must have %d bytes", KeySize)
+	ErrMissingMasterKeyID = errors.New("the current master key isn't among the master keys")
+)
+
+// ParseKey decodes a base64 key, as the keys are given in the configuration.
+func ParseKey(value string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(key) != KeySize {
+		return nil, ErrInvalidKeySize
+	}
+
+	return key, nil
+}
+
+// NewCipher wraps the new data keys with the master key with masterKeyID. The
+// other master keys are only used to unwrap the data keys wrapped before the
+// master key was rotated.
+func NewCipher(store DataKeyStore, masterKeys map[string][]byte, masterKeyID string, hashKey []byte) (*Cipher, error) {
+	if _, ok := masterKeys[masterKeyID]; !ok {
+		return nil, ErrMissingMasterKeyID
+	}
+
+	for _, key := range append([][]byte{hashKey}, mapValues(masterKeys)...) {
+		if len(key) != KeySize {
+			return nil, ErrInvalidKeySize
+		}
+	}
+
+	return &Cipher{
+		store:       store,
+		masterKeys:  masterKeys,
+		masterKeyID: masterKeyID,
+		hashKey:     hashKey,
+	}, nil
+}
+
+// Hash is the keyed hash of a value, stored along with its encrypted field to
+// search it by exact match. Empty values hash to empty.
+func (c *Cipher) Hash(value string) string {
+	if value == "" {
+		return ""
+	}
+
+	mac := hmac.New(sha256.New, c.hashKey)
+	mac.Write([]byte(value))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Encrypter returns an Encrypter with the partner's active data key, creating
+// the partner's first key when it has none.
+func (c *Cipher) Encrypter(ctx context.Context, partnerID string) *Encrypter {
+	key, err := c.activeKey(ctx, partnerID)
+	if err != nil {
+		return &Encrypter{err: err}
+	}
+
+	aead, err := c.dataKey(key)
+	if err != nil {
+		return &Encrypter{err: err}
+	}
+
+	return &Encrypter{keyID: key.ID, aead: aead}
+}
+
+func (c *Cipher) Decrypter(ctx context.Context) *Decrypter {
+	return &Decrypter{ctx: ctx, cipher: c}
+}
+
+// RotateDataKey creates a new version of the partner's data key, which
+// encrypts the partner's data from then on.
+func (c *Cipher) RotateDataKey(ctx context.Context, partnerID string) (*DataKey, error) {
+	current, err := c.store.Active(ctx, partnerID)
+	if err != nil {
+		return nil, err
+	}
+
+	version := 1
+	if current != nil {
+		version = current.Version + 1
+	}
+
+	return c.createKey(ctx, partnerID, version)
+}
+
+// RewrapDataKeys wraps the data keys still wrapped by an older master key with
+// the current one, returning how many were rewrapped. Afterwards the older
+// master keys can be removed from the configuration.
+func (c *Cipher) RewrapDataKeys(ctx context.Context) (int64, error) {
+	keys, err := c.store.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var rewrapped int64
+	for index := range keys {
+		key := &keys[index]
+		if key.MasterKeyID == c.masterKeyID {
+			continue
+		}
+
+		plainKey, err := c.unwrap(key)
+		if err != nil {
+			return rewrapped, err
+		}
+
+		key.WrappedKey, err = c.wrap(key.PartnerID, plainKey)
+		if err != nil {
+			return rewrapped, err
+		}
+
+		key.MasterKeyID = c.masterKeyID
+
+		if err := c.store.UpdateWrapping(ctx, key); err != nil {
+			return rewrapped, err
+		}
+
+		rewrapped++
+	}
+
+	return rewrapped, nil
+}
+
+// RotateDataKeys creates a new version of the data key of every partner that
+// has one, returning how many were rotated.
+func (c *Cipher) RotateDataKeys(ctx context.Context) (int64, error) {
+	keys, err := c.store.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	partnerIDs := make(map[string]bool)
+	for _, key := range keys {
+		partnerIDs[key.PartnerID] = true
+	}
+
+	var rotated int64
+	for partnerID := range partnerIDs {
+		if _, err := c.RotateDataKey(ctx, partnerID); err != nil {
+			return rotated, err
+		}
+
+		rotated++
+	}
+
+	return rotated, nil
+}
+
+func (c *Cipher) activeKey(ctx context.Context, partnerID string) (*DataKey, error) {
+	key, err := c.store.Active(ctx, partnerID)
+	if err != nil || key != nil {
+		return key, err
+	}
+
+	key, err = c.createKey(ctx, partnerID, 1)
+	if !errors.Is(err, ErrDataKeyExists) {
+		return key, err
+	}
+
+	// Another replica created the first key since it was looked up.
+	key, err = c.store.Active(ctx, partnerID)
+	if err == nil && key == nil {
+		return nil, fmt.Errorf("%w: first key of partner %q", ErrDataKeyNotFound, partnerID)
+	}
+
+	return key, err
+}
+
+func (c *Cipher) createKey(ctx context.Context, partnerID string, version int) (*DataKey, error) {
+	plainKey := make([]byte, KeySize)
+	if _, err := rand.Read(plainKey); err != nil {
+		return nil, err
+	}
+
+	wrappedKey, err := c.wrap(partnerID, plainKey)
+	if err != nil {
+		return nil, err
+	}
+
+	key := &DataKey{
+		PartnerID:   partnerID,
+		Version:     version,
+		WrappedKey:  wrappedKey,
+		MasterKeyID: c.masterKeyID,
+		CreatedAt:   time.Now(),
+	}
+
+	if err := c.store.Create(ctx, key); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// dataKey returns the AEAD of the data key, unwrapping it the first time.
+func (c *Cipher) dataKey(key *DataKey) (cipher.AEAD, error) {
+	if aead, ok := c.keys.Load(key.ID); ok {
+		return aead.(cipher.AEAD), nil
+	}
+
+	plainKey, err := c.unwrap(key)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := newAEAD(plainKey)
+	if err != nil {
+		return nil, err
+	}
+
+	c.keys.Store(key.ID, aead)
+
+	return aead, nil
+}
+
+func (c *Cipher) dataKeyByID(ctx context.Context, keyID string) (cipher.AEAD, error) {
+	if aead, ok := c.keys.Load(keyID); ok {
+		return aead.(cipher.AEAD), nil
+	}
+
+	key, err := c.store.GetByID(ctx, keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	if key == nil {
+		return nil, ErrDataKeyNotFound
+	}
+
+	return c.dataKey(key)
+}
+
+// wrap encrypts a data key with the current master key. The partner is bound
+// to it, so a key can't be moved to another partner.
+func (c *Cipher) wrap(partnerID string, plainKey []byte) ([]byte, error) {
+	aead, err := newAEAD(c.masterKeys[c.masterKeyID])
+	if err != nil {
+		return nil, err
+	}
+
+	return seal(aead, plainKey, []byte(partnerID))
+}
+
+func (c *Cipher) unwrap(key *DataKey) ([]byte, error) {
+	masterKey, ok := c.masterKeys[key.MasterKeyID]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownMasterKey, key.MasterKeyID)
+	}
+
+	aead, err := newAEAD(masterKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return open(aead, key.WrappedKey, []byte(key.PartnerID))
+}
+
+// KeyID is the ID of the data key the values are encrypted with.
+func (e *Encrypter) KeyID() string {
+	return e.keyID
+}
+
+// Encrypt seals the value of the field, which is bound to it. Empty values are
+// kept empty.
+func (e *Encrypter) Encrypt(field, value string) string {
+	if e.err != nil || value == "" {
+		return ""
+	}
+
+	sealed, err := seal(e.aead, []byte(value), []byte(field))
+	if err != nil {
+		e.err = err
+		return ""
+	}
+
+	return ciphertextPrefix + e.keyID + ":" + base64.RawStdEncoding.EncodeToString(sealed)
+}
+
+func (e *Encrypter) Err() error {
+	return e.err
+}
+
+// Decrypt opens the stored value of the field. Values stored before the
+// encryption are returned as they are.
+func (d *Decrypter) Decrypt(field, value string) string {
+	if d.err != nil {
+		return ""
+	}
+
+	keyID, sealed, ok := parseCiphertext(value)
+	if !ok {
+		return value
+	}
+
+	aead, err := d.cipher.dataKeyByID(d.ctx, keyID)
+	if err != nil {
+		d.err = err
+		return ""
+	}
+
+	plaintext, err := open(aead, sealed, []byte(field))
+	if err != nil {
+		d.err = err
+		return ""
+	}
+
+	return string(plaintext)
+}
+
+func (d *Decrypter) Err() error {
+	return d.err
+}
+
+// KeyID returns the ID of the data key the stored value was encrypted with, or
+// an empty string when the value is plaintext.
+func KeyID(value string) string {
+	keyID, _, _ := parseCiphertext(value)
+
+	return keyID
+}
+
+func parseCiphertext(value string) (keyID string, sealed []byte, ok bool) {
+	rest, found := strings.CutPrefix(value, ciphertextPrefix)
+	if !found {
+		return "", nil, false
+	}
+
+	keyID, encoded, found := strings.Cut(rest, ":")
+	if !found {
+		return "", nil, false
+	}
+
+	sealed, err := base64.RawStdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", nil, false
+	}
+
+	return keyID, sealed, true
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts with a random nonce, which goes before the ciphertext.
+func seal(aead cipher.AEAD, plaintext, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+func open(aead cipher.AEAD, sealed, additionalData []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, ErrInvalidCiphertext
+	}
+
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+
+	plaintext, err := aead.Open(nil, nonce, ciphertext, additionalData)
+	if err != nil {
+		return nil, ErrInvalidCiphertext
+	}
+
+	return plaintext, nil
+}
+
+func mapValues(values map[string][]byte) [][]byte {
+	result := make([][]byte, 0, len(values))
+	for _, value := range values {
+		result = append(result, value)
+	}
+
+	return result
+}
@@ -0,0 +1,282 @@
+package encryption_test
+
+import (
+	"bytes"
+	"context"
+	"main-api/internal/infra/encryption"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryStore struct {
+	mu   sync.Mutex
+	keys []encryption.DataKey
+}
+
+func (s *memoryStore) Active(_ context.Context, partnerID string) (*encryption.DataKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var active *encryption.DataKey
+	for index := range s.keys {
+		key := s.keys[index]
+		if key.PartnerID == partnerID && (active == nil || key.Version > active.Version) {
+			active = &key
+		}
+	}
+
+	return active, nil
+}
+
+func (s *memoryStore) GetByID(_ context.Context, id string) (*encryption.DataKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, key := range s.keys {
+		if key.ID == id {
+			return &key, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (s *memoryStore) Create(_ context.Context, key *encryption.DataKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, stored := range s.keys {
+		if stored.PartnerID == key.PartnerID && stored.Version == key.Version {
+			return encryption.ErrDataKeyExists
+		}
+	}
+
+	key.ID = "key-" + strconv.Itoa(len(s.keys)+1)
+	s.keys = append(s.keys, *key)
+
+	return nil
+}
+
+func (s *memoryStore) List(_ context.Context) ([]encryption.DataKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]encryption.DataKey(nil), s.keys...), nil
+}
+
+func (s *memoryStore) UpdateWrapping(_ context.Context, key *encryption.DataKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for index := range s.keys {
+		if s.keys[index].ID == key.ID {
+			s.keys[index] = *key
+			return nil
+		}
+	}
+
+	return encryption.ErrDataKeyNotFound
+}
+
+// staleStore misses the active keys of its first lookups, like a store read
+// before another replica created the key.
+type staleStore struct {
+	*memoryStore
+	misses int
+}
+
+func (s *staleStore) Active(ctx context.Context, partnerID string) (*encryption.DataKey, error) {
+	if s.misses > 0 {
+		s.misses--
+		return nil, nil
+	}
+
+	return s.memoryStore.Active(ctx, partnerID)
+}
+
+func newKey(b byte) []byte {
+	return bytes.Repeat([]byte{b}, encryption.KeySize)
+}
+
+func TestCipher(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Should decrypt the values it encrypted", func(t *testing.T) {
+		cipher, err := encryption.NewCipher(&memoryStore{}, map[string][]byte{"master-1": newKey(1)}, "master-1", newKey(2))
+		assert.NoError(t, err)
+
+		encrypter := cipher.Encrypter(t.Context(), "partner-01")
+		name := encrypter.Encrypt("name", "customer-test")
+		empty := encrypter.Encrypt("email", "")
+		assert.NoError(t, encrypter.Err())
+
+		assert.True(t, strings.HasPrefix(name, "enc:v1:"+encrypter.KeyID()+":"))
+		assert.NotContains(t, name, "customer-test")
+		assert.Empty(t, empty)
+		assert.Equal(t, encrypter.KeyID(), encryption.KeyID(name))
+
+		decrypter := cipher.Decrypter(t.Context())
+		assert.Equal(t, "customer-test", decrypter.Decrypt("name", name))
+		assert.Empty(t, decrypter.Decrypt("email", empty))
+		assert.NoError(t, decrypter.Err())
+	})
+
+	t.Run("Should read the values stored before the encryption as they are", func(t *testing.T) {
+		cipher, err := encryption.NewCipher(&memoryStore{}, map[string][]byte{"master-1": newKey(1)}, "master-1", newKey(2))
+		assert.NoError(t, err)
+
+		decrypter := cipher.Decrypter(t.Context())
+
+		assert.Equal(t, "customer-test", decrypter.Decrypt("name", "customer-test"))
+		assert.NoError(t, decrypter.Err())
+		assert.Empty(t, encryption.KeyID("customer-test"))
+	})
+
+	t.Run("Not should decrypt a value moved to another field", func(t *testing.T) {
+		cipher, err := encryption.NewCipher(&memoryStore{}, map[string][]byte{"master-1": newKey(1)}, "master-1", newKey(2))
+		assert.NoError(t, err)
+
+		encrypter := cipher.Encrypter(t.Context(), "partner-01")
+		name := encrypter.Encrypt("name", "customer-test")
+
+		decrypter := cipher.Decrypter(t.Context())
+		decrypter.Decrypt("cpf", name)
+
+		assert.Equal(t, encryption.ErrInvalidCiphertext, decrypter.Err())
+	})
+
+	t.Run("Should give each partner its own key", func(t *testing.T) {
+		cipher, err := encryption.NewCipher(&memoryStore{}, map[string][]byte{"master-1": newKey(1)}, "master-1", newKey(2))
+		assert.NoError(t, err)
+
+		first := cipher.Encrypter(t.Context(), "partner-01")
+		second := cipher.Encrypter(t.Context(), "partner-02")
+
+		assert.NotEqual(t, first.KeyID(), second.KeyID())
+		assert.Equal(t, first.KeyID(), cipher.Encrypter(t.Context(), "partner-01").KeyID())
+	})
+
+	t.Run("Should use the first key another replica created in the meantime", func(t *testing.T) {
+		store := &memoryStore{}
+
+		replica, err := encryption.NewCipher(store, map[string][]byte{"master-1": newKey(1)}, "master-1", newKey(2))
+		assert.NoError(t, err)
+
+		cipher, err := encryption.NewCipher(&staleStore{memoryStore: store, misses: 1}, map[string][]byte{"master-1": newKey(1)}, "master-1", newKey(2))
+		assert.NoError(t, err)
+
+		created := replica.Encrypter(t.Context(), "partner-01")
+		encrypter := cipher.Encrypter(t.Context(), "partner-01")
+
+		assert.NoError(t, encrypter.Err())
+		assert.Equal(t, created.KeyID(), encrypter.KeyID())
+
+		keys, err := store.List(t.Context())
+		assert.NoError(t, err)
+		assert.Len(t, keys, 1)
+	})
+
+	t.Run("Not should encrypt when the first key exists but can't be found", func(t *testing.T) {
+		store := &memoryStore{}
+
+		replica, err := encryption.NewCipher(store, map[string][]byte{"master-1": newKey(1)}, "master-1", newKey(2))
+		assert.NoError(t, err)
+
+		cipher, err := encryption.NewCipher(&staleStore{memoryStore: store, misses: 2}, map[string][]byte{"master-1": newKey(1)}, "master-1", newKey(2))
+		assert.NoError(t, err)
+
+		replica.Encrypter(t.Context(), "partner-01")
+		encrypter := cipher.Encrypter(t.Context(), "partner-01")
+
+		assert.ErrorIs(t, encrypter.Err(), encryption.ErrDataKeyNotFound)
+		assert.Empty(t, encrypter.Encrypt("name", "customer-test"))
+	})
+
+	t.Run("Should hash equal values equally", func(t *testing.T) {
+		cipher, err := encryption.NewCipher(&memoryStore{}, map[string][]byte{"master-1": newKey(1)}, "master-1", newKey(2))
+		assert.NoError(t, err)
+
+		other, err := encryption.NewCipher(&memoryStore{}, map[string][]byte{"master-1": newKey(1)}, "master-1", newKey(3))
+		assert.NoError(t, err)
+
+		assert.Equal(t, cipher.Hash("12345678909"), cipher.Hash("12345678909"))
+		assert.NotEqual(t, cipher.Hash("12345678909"), cipher.Hash("98765432100"))
+		assert.NotEqual(t, cipher.Hash("12345678909"), other.Hash("12345678909"))
+		assert.Empty(t, cipher.Hash(""))
+	})
+
+	t.Run("Should encrypt with the new key after a rotation and still decrypt with the old one", func(t *testing.T) {
+		cipher, err := encryption.NewCipher(&memoryStore{}, map[string][]byte{"master-1": newKey(1)}, "master-1", newKey(2))
+		assert.NoError(t, err)
+
+		old := cipher.Encrypter(t.Context(), "partner-01")
+		name := old.Encrypt("name", "customer-test")
+
+		rotated, err := cipher.RotateDataKeys(t.Context())
+		assert.NoError(t, err)
+
+		current := cipher.Encrypter(t.Context(), "partner-01")
+
+		assert.Equal(t, int64(1), rotated)
+		assert.NotEqual(t, old.KeyID(), current.KeyID())
+
+		decrypter := cipher.Decrypter(t.Context())
+		assert.Equal(t, "customer-test", decrypter.Decrypt("name", name))
+		assert.NoError(t, decrypter.Err())
+	})
+
+	t.Run("Should rewrap the data keys with the current master key", func(t *testing.T) {
+		store := &memoryStore{}
+
+		previous, err := encryption.NewCipher(store, map[string][]byte{"master-1": newKey(1)}, "master-1", newKey(2))
+		assert.NoError(t, err)
+
+		encrypter := previous.Encrypter(t.Context(), "partner-01")
+		name := encrypter.Encrypt("name", "customer-test")
+
+		masterKeys := map[string][]byte{"master-1": newKey(1), "master-2": newKey(4)}
+		cipher, err := encryption.NewCipher(store, masterKeys, "master-2", newKey(2))
+		assert.NoError(t, err)
+
+		rewrapped, err := cipher.RewrapDataKeys(t.Context())
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), rewrapped)
+
+		// Once rewrapped, the old master key is no longer needed.
+		current, err := encryption.NewCipher(store, map[string][]byte{"master-2": newKey(4)}, "master-2", newKey(2))
+		assert.NoError(t, err)
+
+		decrypter := current.Decrypter(t.Context())
+		assert.Equal(t, "customer-test", decrypter.Decrypt("name", name))
+		assert.NoError(t, decrypter.Err())
+	})
+
+	t.Run("Not should decrypt the data keys of an unknown master key", func(t *testing.T) {
+		store := &memoryStore{}
+
+		previous, err := encryption.NewCipher(store, map[string][]byte{"master-1": newKey(1)}, "master-1", newKey(2))
+		assert.NoError(t, err)
+
+		name := previous.Encrypter(t.Context(), "partner-01").Encrypt("name", "customer-test")
+
+		cipher, err := encryption.NewCipher(store, map[string][]byte{"master-2": newKey(4)}, "master-2", newKey(2))
+		assert.NoError(t, err)
+
+		decrypter := cipher.Decrypter(t.Context())
+		decrypter.Decrypt("name", name)
+
+		assert.ErrorIs(t, decrypter.Err(), encryption.ErrUnknownMasterKey)
+	})
+
+	t.Run("Not should create a cipher with invalid keys", func(t *testing.T) {
+		_, err := encryption.NewCipher(&memoryStore{}, map[string][]byte{"master-1": newKey(1)}, "master-2", newKey(2))
+		assert.Equal(t, encryption.ErrMissingMasterKeyID, err)
+
+		_, err = encryption.NewCipher(&memoryStore{}, map[string][]byte{"master-1": newKey(1)[:16]}, "master-1", newKey(2))
+		assert.Equal(t, encryption.ErrInvalidKeySize, err)
+	})
+}
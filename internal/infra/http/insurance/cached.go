@@ -0,0 +1,116 @@
+package insurance
+
+import (
+	"context"
+	"errors"
+	"main-api/internal/domain/partners"
+	"main-api/internal/infra/cache"
+	"time"
+
+	"github.com/gofiber/fiber/v2/log"
+)
+
+type (
+	// CachedProvider is a read-through cache for the policies of the insurance
+	// provider. Cached policies are served while fresh; after that the provider
+	// is asked again, and the cached copy is kept as a fallback for when the
+	// provider is down, until staleTTL.
+	CachedProvider struct {
+		partners.InsuranceProvider
+		store    cache.CacheStore
+		ttl      time.Duration
+		staleTTL time.Duration
+	}
+
+	cachedPolicy struct {
+		Policy   partners.InsuranceProviderCreatePolicyResponse `json:"policy"`
+		CachedAt time.Time                                      `json:"cached_at"`
+	}
+)
+
+const policyCacheKeyPrefix = "insurance-provider-policy:"
+
+func NewCachedProvider(provider partners.InsuranceProvider, store cache.CacheStore, ttl, staleTTL time.Duration) *CachedProvider {
+	return &CachedProvider{
+		InsuranceProvider: provider,
+		store:             store,
+		ttl:               ttl,
+		staleTTL:          max(staleTTL, ttl),
+	}
+}
+
+func (p *CachedProvider) GetPolicy(ctx context.Context, policyID string) (*partners.InsuranceProviderCreatePolicyResponse, error) {
+	key := policyCacheKeyPrefix + policyID
+
+	cached, err := cache.GetJSON[cachedPolicy](ctx, p.store, key)
+	if err != nil && !errors.Is(err, cache.ErrCacheMiss) {
+		log.Errorf("[POLICY CACHE] failed to read policy %q: %v", policyID, err)
+	}
+
+	hit := err == nil
+	if hit && time.Since(cached.CachedAt) < p.ttl {
+		return &cached.Policy, nil
+	}
+
+	policy, err := p.InsuranceProvider.GetPolicy(ctx, policyID)
+	if err != nil {
+		if hit && !isRejectedByProvider(err) {
+			log.Warnf("[POLICY CACHE] serving stale policy %q: %v", policyID, err)
+
+			cached.Policy.Stale = true
+
+			return &cached.Policy, nil
+		}
+
+		return nil, err
+	}
+
+	p.storePolicy(ctx, policyID, policy)
+
+	return policy, nil
+}
+
+// GetFreshPolicy asks the provider for the policy without looking at the
+// cached copy, which it replaces.
+func (p *CachedProvider) GetFreshPolicy(ctx context.Context, policyID string) (*partners.InsuranceProviderCreatePolicyResponse, error) {
+	policy, err := p.InsuranceProvider.GetPolicy(ctx, policyID)
+	if err != nil {
+		return nil, err
+	}
+
+	p.storePolicy(ctx, policyID, policy)
+
+	return policy, nil
+}
+
+// CancelPolicy cancels the policy at the provider and drops its cached copy.
+func (p *CachedProvider) CancelPolicy(ctx context.Context, policyID string) error {
+	if err := p.InsuranceProvider.CancelPolicy(ctx, policyID); err != nil {
+		return err
+	}
+
+	if err := p.InvalidatePolicy(ctx, policyID); err != nil {
+		log.Errorf("[POLICY CACHE] failed to invalidate policy %q: %v", policyID, err)
+	}
+
+	return nil
+}
+
+// InvalidatePolicy drops the cached copy of a policy, for changes such as
+// endorsements and cancellations that must be visible right away.
+func (p *CachedProvider) InvalidatePolicy(ctx context.Context, policyID string) error {
+	return p.store.Delete(ctx, policyCacheKeyPrefix+policyID)
+}
+
+func (p *CachedProvider) storePolicy(ctx context.Context, policyID string, policy *partners.InsuranceProviderCreatePolicyResponse) {
+	err := cache.SetJSON(ctx, p.store, policyCacheKeyPrefix+policyID, cachedPolicy{Policy: *policy, CachedAt: time.Now()}, p.staleTTL)
+	if err != nil {
+		log.Errorf("[POLICY CACHE] failed to store policy %q: %v", policyID, err)
+	}
+}
+
+// isRejectedByProvider reports whether the provider answered the request with
+// a client error, in which case the cached copy can't stand in for it.
+func isRejectedByProvider(err error) bool {
+	return errors.Is(err, partners.ErrProviderRejected)
+}
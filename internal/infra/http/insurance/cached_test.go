@@ -0,0 +1,129 @@
+package insurance_test
+
+import (
+	"errors"
+	"fmt"
+	"main-api/internal/domain/partners"
+	"main-api/internal/infra/cache"
+	"main-api/internal/infra/http/insurance"
+	mocks "main-api/internal/infra/repository/mocks"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCachedProvider(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	testRedis := miniredis.RunT(t)
+	store := cache.NewRedisCacheAdapter(redis.NewClient(&redis.Options{Addr: testRedis.Addr()}))
+	provider := mocks.NewMockInsuranceProvider(ctrl)
+
+	fakePolicy := &partners.InsuranceProviderCreatePolicyResponse{
+		ID:          uuid.New(),
+		QuotationID: uuid.New(),
+		Name:        "test-policy",
+		Sex:         "F",
+		DateOfBirth: "1998-09-28",
+	}
+
+	t.Run("Should call the provider once while the policy is fresh", func(t *testing.T) {
+		cachedProvider := insurance.NewCachedProvider(provider, store, time.Hour, 24*time.Hour)
+		policyID := uuid.NewString()
+
+		provider.EXPECT().GetPolicy(gomock.Any(), policyID).Return(fakePolicy, nil).Times(1)
+
+		first, err := cachedProvider.GetPolicy(t.Context(), policyID)
+		assert.NoError(t, err)
+
+		second, err := cachedProvider.GetPolicy(t.Context(), policyID)
+		assert.NoError(t, err)
+
+		assert.Equal(t, fakePolicy, first)
+		assert.Equal(t, fakePolicy, second)
+		assert.False(t, second.Stale)
+	})
+
+	t.Run("Should serve the stale policy when the provider is down", func(t *testing.T) {
+		cachedProvider := insurance.NewCachedProvider(provider, store, 0, 24*time.Hour)
+		policyID := uuid.NewString()
+
+		provider.EXPECT().GetPolicy(gomock.Any(), policyID).Return(fakePolicy, nil)
+		provider.EXPECT().GetPolicy(gomock.Any(), policyID).Return(nil, insurance.ErrProviderUnavailable)
+
+		_, err := cachedProvider.GetPolicy(t.Context(), policyID)
+		assert.NoError(t, err)
+
+		policy, err := cachedProvider.GetPolicy(t.Context(), policyID)
+
+		assert.NoError(t, err)
+		assert.True(t, policy.Stale)
+		assert.Equal(t, fakePolicy.Name, policy.Name)
+	})
+
+	t.Run("Not should serve the stale policy when the provider rejects the request", func(t *testing.T) {
+		cachedProvider := insurance.NewCachedProvider(provider, store, 0, 24*time.Hour)
+		policyID := uuid.NewString()
+
+		provider.EXPECT().GetPolicy(gomock.Any(), policyID).Return(fakePolicy, nil)
+		provider.EXPECT().GetPolicy(gomock.Any(), policyID).Return(nil, fmt.Errorf("%w: %w", partners.ErrProviderRejected, fiber.NewError(fiber.StatusBadRequest, "policy not found")))
+
+		_, _ = cachedProvider.GetPolicy(t.Context(), policyID)
+		policy, err := cachedProvider.GetPolicy(t.Context(), policyID)
+
+		assert.Nil(t, policy)
+		assert.Error(t, err)
+	})
+
+	t.Run("Should return the error when the provider is down and nothing is cached", func(t *testing.T) {
+		cachedProvider := insurance.NewCachedProvider(provider, store, time.Hour, 24*time.Hour)
+
+		provider.EXPECT().GetPolicy(gomock.Any(), gomock.Any()).Return(nil, insurance.ErrProviderUnavailable)
+
+		policy, err := cachedProvider.GetPolicy(t.Context(), uuid.NewString())
+
+		assert.Nil(t, policy)
+		assert.True(t, errors.Is(err, insurance.ErrProviderUnavailable))
+	})
+
+	t.Run("Should call the provider again after the policy is invalidated", func(t *testing.T) {
+		cachedProvider := insurance.NewCachedProvider(provider, store, time.Hour, 24*time.Hour)
+		policyID := uuid.NewString()
+
+		provider.EXPECT().GetPolicy(gomock.Any(), policyID).Return(fakePolicy, nil).Times(2)
+
+		_, _ = cachedProvider.GetPolicy(t.Context(), policyID)
+		assert.NoError(t, cachedProvider.InvalidatePolicy(t.Context(), policyID))
+		_, err := cachedProvider.GetPolicy(t.Context(), policyID)
+
+		assert.NoError(t, err)
+	})
+	t.Run("Should skip the cached policy when asked for a fresh one and cache the answer", func(t *testing.T) {
+		cachedProvider := insurance.NewCachedProvider(provider, store, time.Hour, 24*time.Hour)
+		policyID := uuid.NewString()
+
+		changed := *fakePolicy
+		changed.Name = "changed-name"
+
+		provider.EXPECT().GetPolicy(gomock.Any(), policyID).Return(fakePolicy, nil)
+		provider.EXPECT().GetPolicy(gomock.Any(), policyID).Return(&changed, nil)
+
+		_, _ = cachedProvider.GetPolicy(t.Context(), policyID)
+
+		fresh, err := cachedProvider.GetFreshPolicy(t.Context(), policyID)
+		assert.NoError(t, err)
+		assert.Equal(t, "changed-name", fresh.Name)
+
+		cached, err := cachedProvider.GetPolicy(t.Context(), policyID)
+		assert.NoError(t, err)
+		assert.Equal(t, "changed-name", cached.Name)
+	})
+}
@@ -9,7 +9,9 @@ import (
 	"io"
 	"main-api/internal/domain/partners"
 	"main-api/internal/infra/cache"
+	"main-api/internal/infra/ratelimit"
 	"net/http"
+	"net/url"
 	"time"
 
 	"github.com/google/uuid"
@@ -21,6 +23,9 @@ type (
 		apiKey       string
 		cacheStorage cache.CacheStore
 		Client       *http.Client
+		limiter      *ratelimit.TokenBucket
+		budgets      map[string]ratelimit.Budget
+		reusePolicy  partners.QuoteReusePolicy
 	}
 
 	authenticateResponse struct {
@@ -36,14 +41,28 @@ type (
 	}
 
 	policyResponse struct {
-		ID          uuid.UUID `json:"id"`
-		QuotationID uuid.UUID `json:"quotation_id"`
-		DateBirth   string    `json:"date_of_birth"`
-		Name        string    `json:"name"`
-		Sex         string    `json:"sex"`
+		ID            uuid.UUID                               `json:"id"`
+		QuotationID   uuid.UUID                               `json:"quotation_id"`
+		DateBirth     string                                  `json:"date_of_birth"`
+		Name          string                                  `json:"name"`
+		Sex           string                                  `json:"sex"`
+		Beneficiaries []partners.InsuranceProviderBeneficiary `json:"beneficiaries"`
+		Dependents    []partners.InsuranceProviderDependent   `json:"dependents"`
 	}
 )
 
+const (
+	EndpointCreateQuotation = "create_quotation"
+	EndpointCreatePolicy    = "create_policy"
+	EndpointGetPolicy       = "get_policy"
+	EndpointCancelPolicy    = "cancel_policy"
+)
+
+// DefaultTimeout bounds the requests to the provider unless SetTimeout is
+// called. Without it a hung provider would hold the request, and its
+// idempotency key, forever.
+const DefaultTimeout = 10 * time.Second
+
 var (
 	jwtKey = "insurance-provider-jwt-token"
 )
@@ -54,20 +73,42 @@ func NewInsuranceProviderClient(cache cache.CacheStore, baseURL, apiKey string)
 		baseURL:      baseURL,
 		cacheStorage: cache,
 		apiKey:       apiKey,
-		Client:       &http.Client{},
+		Client:       &http.Client{Timeout: DefaultTimeout},
 	}
 }
 
-func (i *InsuranceProviderClient) Authenticate() (*authenticateResponse, error) {
-	req, err := http.NewRequest("POST", i.baseURL+"/auth", nil)
+// SetTimeout bounds each request to the provider, including reading its
+// response.
+func (i *InsuranceProviderClient) SetTimeout(timeout time.Duration) {
+	i.Client.Timeout = timeout
+}
+
+// SetRateLimiter keeps the requests to each endpoint within its budget across
+// every replica. Endpoints without a budget aren't limited.
+func (i *InsuranceProviderClient) SetRateLimiter(limiter *ratelimit.TokenBucket, budgets map[string]ratelimit.Budget) {
+	i.limiter = limiter
+	i.budgets = budgets
+}
+
+// SetQuoteReusePolicy sets what the provider agreed on reusing its quotations.
+// By default a partner only reuses its own quotations.
+func (i *InsuranceProviderClient) SetQuoteReusePolicy(policy partners.QuoteReusePolicy) {
+	i.reusePolicy = policy
+}
+
+func (i *InsuranceProviderClient) QuoteReusePolicy() partners.QuoteReusePolicy {
+	return i.reusePolicy
+}
+
+func (i *InsuranceProviderClient) Authenticate(ctx context.Context) (*authenticateResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", i.baseURL+"/auth", nil)
 	if err != nil {
 		return nil, err
 	}
 
 	req.Header.Set("x-api-key", i.apiKey)
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := i.Client.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -96,7 +137,7 @@ func (i *InsuranceProviderClient) CreateQuotation(
 		return nil, err
 	}
 
-	response, err := i.doRequestWithAuth(ctx, "POST", "quotations", jsonData)
+	response, err := i.doRequestWithAuth(ctx, EndpointCreateQuotation, "POST", "quotations", jsonData)
 	if err != nil {
 		return nil, err
 	}
@@ -125,7 +166,7 @@ func (i *InsuranceProviderClient) CreatePolicy(
 		return nil, err
 	}
 
-	body, err := i.doRequestWithAuth(ctx, "POST", "policies", jsonData)
+	body, err := i.doRequestWithAuth(ctx, EndpointCreatePolicy, "POST", "policies", jsonData)
 	if err != nil {
 		return nil, err
 	}
@@ -137,16 +178,22 @@ func (i *InsuranceProviderClient) CreatePolicy(
 	}
 
 	return &partners.InsuranceProviderCreatePolicyResponse{
-		ID:          createPolicyResponse.ID,
-		QuotationID: createPolicyResponse.QuotationID,
-		Name:        createPolicyResponse.Name,
-		Sex:         createPolicyResponse.Sex,
-		DateOfBirth: createPolicyResponse.DateBirth,
+		ID:            createPolicyResponse.ID,
+		QuotationID:   createPolicyResponse.QuotationID,
+		Name:          createPolicyResponse.Name,
+		Sex:           createPolicyResponse.Sex,
+		DateOfBirth:   createPolicyResponse.DateBirth,
+		Beneficiaries: createPolicyResponse.Beneficiaries,
+		Dependents:    createPolicyResponse.Dependents,
 	}, nil
 }
 
 func (i *InsuranceProviderClient) GetPolicy(ctx context.Context, policyID string) (*partners.InsuranceProviderCreatePolicyResponse, error) {
-	body, err := i.doRequestWithAuth(ctx, "GET", "policies/"+policyID, nil)
+	body, err := i.doRequestWithAuth(ctx, EndpointGetPolicy, "GET", "policies/"+policyID, nil)
+	if errors.Is(err, errNotFound) {
+		return nil, fmt.Errorf("%w: %w", partners.ErrProviderPolicyNotFound, err)
+	}
+
 	if err != nil {
 		return nil, err
 	}
@@ -158,14 +205,54 @@ func (i *InsuranceProviderClient) GetPolicy(ctx context.Context, policyID string
 	}
 
 	return &partners.InsuranceProviderCreatePolicyResponse{
-		ID:          response.ID,
-		QuotationID: response.QuotationID,
-		Name:        response.Name,
-		DateOfBirth: response.DateBirth,
-		Sex:         response.Sex,
+		ID:            response.ID,
+		QuotationID:   response.QuotationID,
+		Name:          response.Name,
+		DateOfBirth:   response.DateBirth,
+		Sex:           response.Sex,
+		Beneficiaries: response.Beneficiaries,
+		Dependents:    response.Dependents,
+	}, nil
+}
+
+// FindPolicyByReference lists the provider's policies created with the client
+// reference, which is unique to an issuance.
+func (i *InsuranceProviderClient) FindPolicyByReference(ctx context.Context, reference string) (*partners.InsuranceProviderCreatePolicyResponse, error) {
+	body, err := i.doRequestWithAuth(ctx, EndpointGetPolicy, "GET", "policies?client_reference="+url.QueryEscape(reference), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var response []policyResponse
+	err = json.Unmarshal(body, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(response) == 0 {
+		return nil, fmt.Errorf("%w: no policy with client reference %q", partners.ErrProviderPolicyNotFound, reference)
+	}
+
+	return &partners.InsuranceProviderCreatePolicyResponse{
+		ID:            response[0].ID,
+		QuotationID:   response[0].QuotationID,
+		Name:          response[0].Name,
+		DateOfBirth:   response[0].DateBirth,
+		Sex:           response[0].Sex,
+		Beneficiaries: response[0].Beneficiaries,
+		Dependents:    response[0].Dependents,
 	}, nil
 }
 
+func (i *InsuranceProviderClient) CancelPolicy(ctx context.Context, policyID string) error {
+	_, err := i.doRequestWithAuth(ctx, EndpointCancelPolicy, "POST", "policies/"+policyID+"/cancel", nil)
+	if errors.Is(err, errNotFound) {
+		return fmt.Errorf("%w: %w", partners.ErrProviderPolicyNotFound, err)
+	}
+
+	return err
+}
+
 func (i *InsuranceProviderClient) getToken(ctx context.Context) (string, error) {
 	tokenInCache, err := i.cacheStorage.Get(ctx, jwtKey)
 	if err != nil && !errors.Is(err, cache.ErrCacheMiss) {
@@ -176,7 +263,7 @@ func (i *InsuranceProviderClient) getToken(ctx context.Context) (string, error)
 		return tokenInCache, nil
 	}
 
-	authResponse, err := i.Authenticate()
+	authResponse, err := i.Authenticate(ctx)
 	if err != nil {
 		return "", err
 	}
@@ -189,13 +276,20 @@ func (i *InsuranceProviderClient) getToken(ctx context.Context) (string, error)
 	return authResponse.AcessToken, nil
 }
 
-func (i *InsuranceProviderClient) doRequestWithAuth(ctx context.Context, method, url string, payload []byte) ([]byte, error) {
+func (i *InsuranceProviderClient) doRequestWithAuth(ctx context.Context, endpoint, method, url string, payload []byte) ([]byte, error) {
+	if budget, ok := i.budgets[endpoint]; ok && i.limiter != nil {
+		if err := i.limiter.Wait(ctx, endpoint, budget); err != nil {
+			return nil, err
+		}
+	}
+
 	token, err := i.getToken(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	req, err := http.NewRequest(
+	req, err := http.NewRequestWithContext(
+		ctx,
 		method,
 		fmt.Sprintf("%s/%s", i.baseURL, url),
 		bytes.NewReader(payload),
@@ -207,8 +301,11 @@ func (i *InsuranceProviderClient) doRequestWithAuth(ctx context.Context, method,
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
 	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	if method != http.MethodGet {
+		partners.MarkProviderCalled(ctx)
+	}
+
+	resp, err := i.Client.Do(req)
 	if err != nil {
 		return nil, err
 	}
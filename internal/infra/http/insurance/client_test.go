@@ -1,16 +1,21 @@
 package insurance_test
 
 import (
+	"context"
 	"errors"
 	"main-api/internal/domain/partners"
 	"main-api/internal/infra/cache"
 	"main-api/internal/infra/http/insurance"
+	"main-api/internal/infra/ratelimit"
 	"net/http"
 	"testing"
 
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gofiber/fiber/v2"
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
 	"github.com/h2non/gock"
+	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -42,7 +47,7 @@ func TestAuthenticate(t *testing.T) {
 				"access_token": "fake-token",
 			})
 
-		token, err := insuranceProviderClient.Authenticate()
+		token, err := insuranceProviderClient.Authenticate(t.Context())
 
 		assert.NoError(t, err)
 		assert.Equal(t, "fake-token", token.AcessToken)
@@ -56,11 +61,29 @@ func TestAuthenticate(t *testing.T) {
 			Post("/auth").
 			ReplyError(assert.AnError)
 
-		_, err := insuranceProviderClient.Authenticate()
+		_, err := insuranceProviderClient.Authenticate(t.Context())
 
 		assert.Error(t, err)
 		assert.True(t, mock.Done())
 	})
+
+	t.Run("Not should authenticate when the request was cancelled", func(t *testing.T) {
+		defer gock.Clean()
+
+		gock.New(baseURL).
+			Post("/auth").
+			Reply(200).
+			JSON(map[string]interface{}{
+				"access_token": "fake-token",
+			})
+
+		ctx, cancel := context.WithCancel(t.Context())
+		cancel()
+
+		_, err := insuranceProviderClient.Authenticate(ctx)
+
+		assert.ErrorIs(t, err, context.Canceled)
+	})
 }
 
 func TestCreateQuotation(t *testing.T) {
@@ -299,4 +322,219 @@ func TestGetPolicy(t *testing.T) {
 		assert.Nil(t, response)
 		assert.Error(t, errors.New("policy not found"), err)
 	})
+
+	t.Run("Should return provider policy not found error when the provider answers 404", func(t *testing.T) {
+		defer gock.Clean()
+
+		policyID := "8ed8fe32-a8ce-46c1-aef4-64019eb5a860"
+
+		gock.New(baseURL).
+			Post("/auth").
+			Reply(200).
+			JSON(map[string]interface{}{
+				"access_token": "fake-token",
+			})
+		gock.New(baseURL).
+			Get("/policies/" + policyID).
+			Reply(http.StatusNotFound).
+			JSON(map[string]interface{}{
+				"message": "policy not found",
+			})
+
+		response, err := insuranceProviderClient.GetPolicy(t.Context(), policyID)
+
+		assert.Nil(t, response)
+		assert.ErrorIs(t, err, partners.ErrProviderPolicyNotFound)
+
+		var fiberErr *fiber.Error
+		if assert.ErrorAs(t, err, &fiberErr) {
+			assert.Equal(t, fiber.StatusBadRequest, fiberErr.Code)
+		}
+	})
+}
+
+func TestFindPolicyByReference(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+	defer gock.Off()
+
+	cacheStorage := cache.NewMockCacheStore(ctrl)
+	insuranceProviderClient := insurance.NewInsuranceProviderClient(cacheStorage, baseURL, apiKey)
+
+	gock.InterceptClient(insuranceProviderClient.Client)
+
+	cacheStorage.EXPECT().Get(gomock.Any(), gomock.Any()).Return("fake-token", nil).AnyTimes()
+
+	t.Run("Should return the policy created with the reference", func(t *testing.T) {
+		defer gock.Clean()
+
+		gock.New(baseURL).
+			Get("/policies").
+			MatchParam("client_reference", "67e1ae8c7e7a8b2b0f1e4b30").
+			Reply(200).
+			JSON([]map[string]interface{}{{
+				"id":            "8ed8fe32-a8ce-46c1-aef4-64019eb5a859",
+				"quotation_id":  "8ed8fe32-a8ce-46c1-aef4-64019eb5a858",
+				"name":          "quotation-test",
+				"sex":           "F",
+				"date_of_birth": "1998-09-28",
+			}})
+
+		response, err := insuranceProviderClient.FindPolicyByReference(t.Context(), "67e1ae8c7e7a8b2b0f1e4b30")
+
+		assert.NoError(t, err)
+		assert.Equal(t, uuid.MustParse("8ed8fe32-a8ce-46c1-aef4-64019eb5a859"), response.ID)
+	})
+
+	t.Run("Should return provider policy not found error when no policy has the reference", func(t *testing.T) {
+		defer gock.Clean()
+
+		gock.New(baseURL).
+			Get("/policies").
+			MatchParam("client_reference", "67e1ae8c7e7a8b2b0f1e4b31").
+			Reply(200).
+			JSON([]map[string]interface{}{})
+
+		response, err := insuranceProviderClient.FindPolicyByReference(t.Context(), "67e1ae8c7e7a8b2b0f1e4b31")
+
+		assert.Nil(t, response)
+		assert.ErrorIs(t, err, partners.ErrProviderPolicyNotFound)
+	})
+}
+
+func TestCancelPolicy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+	defer gock.Off()
+
+	cacheStorage := cache.NewMockCacheStore(ctrl)
+	insuranceProviderClient := insurance.NewInsuranceProviderClient(cacheStorage, baseURL, apiKey)
+
+	gock.InterceptClient(insuranceProviderClient.Client)
+
+	cacheStorage.EXPECT().Set(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	cacheStorage.EXPECT().Get(gomock.Any(), gomock.Any()).Return("", nil).AnyTimes()
+
+	t.Run("Should cancel the policy at the provider", func(t *testing.T) {
+		defer gock.Clean()
+
+		policyID := "8ed8fe32-a8ce-46c1-aef4-64019eb5a861"
+
+		gock.New(baseURL).
+			Post("/auth").
+			Reply(200).
+			JSON(map[string]interface{}{
+				"access_token": "fake-token",
+			})
+		gock.New(baseURL).
+			Post("/policies/" + policyID + "/cancel").
+			Reply(200).
+			JSON(map[string]interface{}{
+				"id":     policyID,
+				"status": "cancelled",
+			})
+
+		err := insuranceProviderClient.CancelPolicy(t.Context(), policyID)
+
+		assert.NoError(t, err)
+		assert.True(t, gock.IsDone())
+	})
+
+	t.Run("Should return provider policy not found error when the provider answers 404", func(t *testing.T) {
+		defer gock.Clean()
+
+		policyID := "8ed8fe32-a8ce-46c1-aef4-64019eb5a862"
+
+		gock.New(baseURL).
+			Post("/auth").
+			Reply(200).
+			JSON(map[string]interface{}{
+				"access_token": "fake-token",
+			})
+		gock.New(baseURL).
+			Post("/policies/" + policyID + "/cancel").
+			Reply(http.StatusNotFound).
+			JSON(map[string]interface{}{
+				"message": "policy not found",
+			})
+
+		err := insuranceProviderClient.CancelPolicy(t.Context(), policyID)
+
+		assert.ErrorIs(t, err, partners.ErrProviderPolicyNotFound)
+	})
+}
+
+func TestRateLimiter(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+	defer gock.Off()
+
+	cacheStorage := cache.NewMockCacheStore(ctrl)
+	insuranceProviderClient := insurance.NewInsuranceProviderClient(cacheStorage, baseURL, apiKey)
+
+	testRedis := miniredis.RunT(t)
+	insuranceProviderClient.SetRateLimiter(
+		ratelimit.NewTokenBucket(redis.NewClient(&redis.Options{Addr: testRedis.Addr()}), 0),
+		map[string]ratelimit.Budget{
+			insurance.EndpointGetPolicy: {Rate: 1, Burst: 1},
+		},
+	)
+
+	gock.InterceptClient(insuranceProviderClient.Client)
+
+	cacheStorage.EXPECT().Get(gomock.Any(), gomock.Any()).Return("fake-token", nil).AnyTimes()
+
+	t.Run("Should shed requests over the endpoint budget without calling the provider", func(t *testing.T) {
+		defer gock.Clean()
+
+		policyID := uuid.New()
+		mock := gock.New(baseURL).
+			Get("/policies/" + policyID.String()).
+			Times(1).
+			Reply(200).
+			JSON(map[string]interface{}{
+				"id": policyID,
+			})
+
+		_, err := insuranceProviderClient.GetPolicy(t.Context(), policyID.String())
+		assert.NoError(t, err)
+
+		_, err = insuranceProviderClient.GetPolicy(t.Context(), policyID.String())
+
+		var throttled *ratelimit.ThrottledError
+		assert.ErrorAs(t, err, &throttled)
+		assert.Equal(t, insurance.EndpointGetPolicy, throttled.Key)
+		assert.True(t, mock.Done())
+	})
+}
+
+func TestProviderUnavailable(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+	defer gock.Off()
+
+	cacheStorage := cache.NewMockCacheStore(ctrl)
+	insuranceProviderClient := insurance.NewInsuranceProviderClient(cacheStorage, baseURL, apiKey)
+
+	gock.InterceptClient(insuranceProviderClient.Client)
+
+	cacheStorage.EXPECT().Get(gomock.Any(), gomock.Any()).Return("fake-token", nil).AnyTimes()
+
+	t.Run("Should return provider unavailable when the provider fails", func(t *testing.T) {
+		defer gock.Clean()
+
+		policyID := uuid.NewString()
+		gock.New(baseURL).
+			Get("/policies/" + policyID).
+			Reply(503)
+
+		policy, err := insuranceProviderClient.GetPolicy(t.Context(), policyID)
+
+		assert.Nil(t, policy)
+		assert.ErrorIs(t, err, insurance.ErrProviderUnavailable)
+	})
 }
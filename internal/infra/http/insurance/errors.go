@@ -2,7 +2,9 @@ package insurance
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"main-api/internal/domain/partners"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -13,16 +15,30 @@ type (
 	}
 )
 
+var (
+	ErrProviderUnavailable = errors.New("insurance provider unavailable")
+	// errNotFound marks the client errors answered with a 404.
+	errNotFound = errors.New("not found at the insurance provider")
+)
+
 func handlerErrors(statusCode int, responseBody []byte) error {
 	var apiError apiError
 
 	if statusCode >= 400 && statusCode < 500 {
 		err := json.Unmarshal(responseBody, &apiError)
 		if err != nil {
-			return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("unexpected client error: %s", string(responseBody)))
+			return fmt.Errorf("%w: %w", partners.ErrProviderRejected, fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("unexpected client error: %s", string(responseBody))))
+		}
+
+		if statusCode == fiber.StatusNotFound {
+			return fmt.Errorf("%w: %w: %w", partners.ErrProviderRejected, errNotFound, fiber.NewError(fiber.StatusBadRequest, apiError.Message))
 		}
 
-		return fiber.NewError(fiber.StatusBadRequest, apiError.Message)
+		return fmt.Errorf("%w: %w", partners.ErrProviderRejected, fiber.NewError(fiber.StatusBadRequest, apiError.Message))
+	}
+
+	if statusCode >= 500 {
+		return fmt.Errorf("%w: status %d", ErrProviderUnavailable, statusCode)
 	}
 
 	return nil
@@ -0,0 +1,171 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"main-api/internal/domain/partners"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+type (
+	// Sender posts the webhook deliveries. Each request carries the signature
+	// of its timestamp and body, so partners can check it came from us and
+	// reject replays of old deliveries.
+	//
+	// Partners choose the URLs, so the sender refuses to connect to private,
+	// loopback and link-local addresses, the cloud metadata endpoint included.
+	// The check is made on the address being dialed, so it also covers hosts
+	// that resolve differently after the registration and redirects.
+	Sender struct {
+		Client *http.Client
+		// allowPrivateNetworks lifts the address check, for receivers that run
+		// in the local network in development and tests.
+		allowPrivateNetworks bool
+	}
+)
+
+const (
+	HeaderDeliveryID = "X-Webhook-Delivery"
+	HeaderEvent      = "X-Webhook-Event"
+	HeaderTimestamp  = "X-Webhook-Timestamp"
+	HeaderSignature  = "X-Webhook-Signature"
+
+	signaturePrefix = "sha256="
+)
+
+var (
+	ErrForbiddenAddress = errors.New("webhook address is not allowed")
+
+	// sharedAddressSpace is the carrier-grade NAT range, which isn't reported
+	// as private by netip but isn't reachable from the internet either.
+	sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+)
+
+func NewSender(timeout time.Duration, allowPrivateNetworks bool) *Sender {
+	sender := &Sender{allowPrivateNetworks: allowPrivateNetworks}
+
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+
+			addr, err := netip.ParseAddr(host)
+			if err != nil {
+				return err
+			}
+
+			return sender.checkAddr(addr)
+		},
+	}
+
+	sender.Client = &http.Client{
+		Timeout: timeout,
+		// No proxy, so the dialed address is the receiver's.
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+			MaxIdleConns:        100,
+			IdleConnTimeout:     90 * time.Second,
+		},
+	}
+
+	return sender
+}
+
+// Sign returns the signature sent in HeaderSignature: the hex encoded
+// HMAC-SHA256 of the timestamp, a dot and the body, keyed by the secret.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// CheckURL rejects the URLs whose host is, or resolves to, an address the
+// sender doesn't connect to. Hosts that don't resolve yet are accepted, since
+// every delivery is checked again when it is dialed.
+func (s *Sender) CheckURL(ctx context.Context, rawURL string) error {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+
+	host := parsedURL.Hostname()
+
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return s.checkAddr(addr)
+	}
+
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return nil
+	}
+
+	for _, addr := range addrs {
+		if err := s.checkAddr(addr); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Sender) Send(ctx context.Context, request partners.WebhookRequest) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, request.URL, bytes.NewReader(request.Body))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := time.Now().Unix()
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderDeliveryID, request.DeliveryID)
+	req.Header.Set(HeaderEvent, string(request.EventType))
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(request.Secret, timestamp, request.Body))
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+
+	defer resp.Body.Close()
+
+	// The body isn't kept: it is whatever the receiver answers and the error
+	// is stored with the delivery.
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
+
+func (s *Sender) checkAddr(addr netip.Addr) error {
+	if s.allowPrivateNetworks {
+		return nil
+	}
+
+	addr = addr.Unmap()
+
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() || sharedAddressSpace.Contains(addr) {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, addr)
+	}
+
+	return nil
+}
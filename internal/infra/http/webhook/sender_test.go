@@ -0,0 +1,125 @@
+package webhook_test
+
+import (
+	"context"
+	"io"
+	"main-api/internal/domain/partners"
+	"main-api/internal/infra/http/webhook"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSender(t *testing.T) {
+	t.Parallel()
+
+	sender := webhook.NewSender(time.Second, true)
+
+	t.Run("Should post the body signed with the partner secret", func(t *testing.T) {
+		var received *http.Request
+		var receivedBody []byte
+
+		receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			received = r
+			receivedBody, _ = io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer receiver.Close()
+
+		body := []byte(`{"id":"event-01"}`)
+
+		statusCode, err := sender.Send(context.Background(), partners.WebhookRequest{
+			URL:        receiver.URL,
+			DeliveryID: "delivery-01",
+			EventType:  partners.EventPolicyIssued,
+			Secret:     "whsec_test",
+			Body:       body,
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNoContent, statusCode)
+		assert.Equal(t, body, receivedBody)
+		assert.Equal(t, "delivery-01", received.Header.Get(webhook.HeaderDeliveryID))
+		assert.Equal(t, "policy.issued", received.Header.Get(webhook.HeaderEvent))
+
+		timestamp, err := strconv.ParseInt(received.Header.Get(webhook.HeaderTimestamp), 10, 64)
+		assert.NoError(t, err)
+		assert.Equal(t, webhook.Sign("whsec_test", timestamp, body), received.Header.Get(webhook.HeaderSignature))
+	})
+
+	t.Run("Not should sign the same way with another secret", func(t *testing.T) {
+		body := []byte(`{"id":"event-01"}`)
+
+		assert.NotEqual(t, webhook.Sign("whsec_test", 1, body), webhook.Sign("whsec_other", 1, body))
+		assert.NotEqual(t, webhook.Sign("whsec_test", 1, body), webhook.Sign("whsec_test", 2, body))
+	})
+
+	t.Run("Should return an error with the status of a failed response", func(t *testing.T) {
+		receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		}))
+		defer receiver.Close()
+
+		statusCode, err := sender.Send(context.Background(), partners.WebhookRequest{URL: receiver.URL})
+
+		assert.ErrorContains(t, err, "503")
+		assert.NotContains(t, err.Error(), "unavailable", "the response body isn't kept")
+		assert.Equal(t, http.StatusServiceUnavailable, statusCode)
+	})
+
+	t.Run("Should return an error without status when the receiver can't be reached", func(t *testing.T) {
+		receiver := httptest.NewServer(http.NotFoundHandler())
+		receiver.Close()
+
+		statusCode, err := sender.Send(context.Background(), partners.WebhookRequest{URL: receiver.URL})
+
+		assert.Error(t, err)
+		assert.Zero(t, statusCode)
+	})
+}
+
+func TestSenderAddresses(t *testing.T) {
+	t.Parallel()
+
+	sender := webhook.NewSender(time.Second, false)
+
+	t.Run("Not should accept the urls of private, loopback and link-local addresses", func(t *testing.T) {
+		for _, url := range []string{
+			"http://127.0.0.1/hooks",
+			"http://localhost:8080/hooks",
+			"http://10.0.0.1/hooks",
+			"http://192.168.0.10/hooks",
+			"http://169.254.169.254/latest/meta-data",
+			"http://[::1]/hooks",
+			"http://[::ffff:127.0.0.1]/hooks",
+			"http://0.0.0.0/hooks",
+		} {
+			assert.ErrorIs(t, sender.CheckURL(context.Background(), url), webhook.ErrForbiddenAddress, url)
+		}
+	})
+
+	t.Run("Should accept the urls of public addresses and of hosts that don't resolve yet", func(t *testing.T) {
+		assert.NoError(t, sender.CheckURL(context.Background(), "https://8.8.8.8/hooks"))
+		assert.NoError(t, sender.CheckURL(context.Background(), "https://partner.invalid/hooks"))
+	})
+
+	t.Run("Not should connect to a private address when sending", func(t *testing.T) {
+		var called atomic.Bool
+
+		receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			called.Store(true)
+		}))
+		defer receiver.Close()
+
+		statusCode, err := sender.Send(context.Background(), partners.WebhookRequest{URL: receiver.URL})
+
+		assert.ErrorIs(t, err, webhook.ErrForbiddenAddress)
+		assert.Zero(t, statusCode)
+		assert.False(t, called.Load())
+	})
+}
@@ -0,0 +1,37 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+type (
+	// Message is an event stored in the outbox, as it is published.
+	Message struct {
+		ID          string          `json:"id"`
+		Type        string          `json:"type"`
+		AggregateID string          `json:"aggregate_id"`
+		EntityID    string          `json:"entity_id"`
+		Payload     json.RawMessage `json:"payload"`
+		OccurredAt  time.Time       `json:"occurred_at"`
+		// Attempts counts the failed attempts to publish the message.
+		Attempts int `json:"-"`
+	}
+
+	// Store keeps the messages until they are published.
+	Store interface {
+		// ListPending returns the messages not published yet whose aggregate
+		// isn't waiting to be retried, in the order they occurred.
+		ListPending(ctx context.Context, now time.Time, limit int) ([]Message, error)
+		MarkPublished(ctx context.Context, id string, publishedAt time.Time) error
+		// MarkFailed records a failed attempt to publish the message and holds
+		// every pending message of its aggregate until retryAt, so they are
+		// still published in order.
+		MarkFailed(ctx context.Context, message Message, cause error, retryAt time.Time) error
+	}
+
+	Publisher interface {
+		Publish(ctx context.Context, message Message) error
+	}
+)
@@ -0,0 +1,46 @@
+package outbox
+
+import (
+	"context"
+	"main-api/internal/domain/partners"
+)
+
+type (
+	// MultiPublisher publishes each message to every publisher. A message is
+	// only published when all of them accept it, so a failing one makes the
+	// others receive the message again on the retry.
+	MultiPublisher []Publisher
+
+	// WebhookPublisher turns the messages into deliveries to the webhooks the
+	// partners registered for them.
+	WebhookPublisher struct {
+		service partners.Service
+	}
+)
+
+func NewWebhookPublisher(service partners.Service) *WebhookPublisher {
+	return &WebhookPublisher{
+		service: service,
+	}
+}
+
+func (p MultiPublisher) Publish(ctx context.Context, message Message) error {
+	for _, publisher := range p {
+		if err := publisher.Publish(ctx, message); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *WebhookPublisher) Publish(ctx context.Context, message Message) error {
+	return p.service.EnqueueWebhookDeliveries(ctx, partners.Event{
+		ID:          message.ID,
+		Type:        partners.EventType(message.Type),
+		AggregateID: message.AggregateID,
+		EntityID:    message.EntityID,
+		Payload:     message.Payload,
+		OccurredAt:  message.OccurredAt,
+	})
+}
@@ -0,0 +1,46 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type (
+	// RedisStreamPublisher appends the messages to a Redis stream, which the
+	// downstream systems read with their own consumer groups.
+	RedisStreamPublisher struct {
+		client *redis.Client
+		stream string
+		// maxLen approximately caps the stream, trimming the oldest messages.
+		maxLen int64
+	}
+)
+
+func NewRedisStreamPublisher(client *redis.Client, stream string, maxLen int64) *RedisStreamPublisher {
+	return &RedisStreamPublisher{
+		client: client,
+		stream: stream,
+		maxLen: maxLen,
+	}
+}
+
+func (p *RedisStreamPublisher) Publish(ctx context.Context, message Message) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	return p.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: p.stream,
+		MaxLen: p.maxLen,
+		Approx: true,
+		Values: map[string]any{
+			"id":           message.ID,
+			"type":         message.Type,
+			"aggregate_id": message.AggregateID,
+			"message":      data,
+		},
+	}).Err()
+}
@@ -0,0 +1,94 @@
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofiber/fiber/v2/log"
+)
+
+type (
+	// Relay publishes the messages stored in the outbox. A message is only
+	// marked as published after the publisher accepts it, so it may be
+	// published more than once and consumers must ignore the IDs they already
+	// handled. The messages of an aggregate are published in order: when one
+	// fails, the following ones wait for it to be retried.
+	Relay struct {
+		store     Store
+		publisher Publisher
+		batchSize int
+	}
+
+	// RelayResult counts what a run of the relay did.
+	RelayResult struct {
+		Published int64
+		Failed    int64
+	}
+)
+
+const (
+	retryBaseDelay = time.Second
+	retryMaxDelay  = 10 * time.Minute
+)
+
+func NewRelay(store Store, publisher Publisher, batchSize int) *Relay {
+	return &Relay{
+		store:     store,
+		publisher: publisher,
+		batchSize: batchSize,
+	}
+}
+
+// RelayPending publishes a batch of pending messages. Only one relay must run
+// at a time, or the messages of an aggregate could be published out of order.
+func (r *Relay) RelayPending(ctx context.Context) (RelayResult, error) {
+	var result RelayResult
+
+	messages, err := r.store.ListPending(ctx, time.Now(), r.batchSize)
+	if err != nil {
+		return result, err
+	}
+
+	blocked := make(map[string]bool)
+
+	for _, message := range messages {
+		if blocked[message.AggregateID] {
+			continue
+		}
+
+		if err := r.publisher.Publish(ctx, message); err != nil {
+			log.Errorf("[OUTBOX] failed to publish message %q: %v", message.ID, err)
+
+			blocked[message.AggregateID] = true
+			result.Failed++
+
+			retryAt := time.Now().Add(retryDelay(message.Attempts + 1))
+			if err := r.store.MarkFailed(ctx, message, err, retryAt); err != nil {
+				return result, err
+			}
+
+			continue
+		}
+
+		if err := r.store.MarkPublished(ctx, message.ID, time.Now()); err != nil {
+			return result, err
+		}
+
+		result.Published++
+	}
+
+	return result, nil
+}
+
+// retryDelay doubles the wait after each failed attempt, up to retryMaxDelay.
+func retryDelay(attempts int) time.Duration {
+	delay := retryBaseDelay
+	for range attempts - 1 {
+		delay *= 2
+		if delay >= retryMaxDelay {
+			return retryMaxDelay
+		}
+	}
+
+	return delay
+}
@@ -0,0 +1,277 @@
+package outbox_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"main-api/internal/infra/outbox"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+type (
+	fakeStoreMessage struct {
+		message       outbox.Message
+		nextAttemptAt time.Time
+		published     bool
+	}
+
+	fakeStore struct {
+		mu       sync.Mutex
+		messages []*fakeStoreMessage
+	}
+
+	fakePublisher struct {
+		published []string
+		// failing makes the publisher reject the messages with these IDs.
+		failing map[string]bool
+	}
+)
+
+func (s *fakeStore) add(messages ...outbox.Message) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, message := range messages {
+		s.messages = append(s.messages, &fakeStoreMessage{message: message})
+	}
+}
+
+func (s *fakeStore) ListPending(_ context.Context, now time.Time, limit int) ([]outbox.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pending := make([]*fakeStoreMessage, 0, len(s.messages))
+	for _, stored := range s.messages {
+		if !stored.published {
+			pending = append(pending, stored)
+		}
+	}
+
+	sort.SliceStable(pending, func(i, j int) bool {
+		return pending[i].message.OccurredAt.Before(pending[j].message.OccurredAt)
+	})
+
+	held := make(map[string]bool)
+
+	var messages []outbox.Message
+	for _, stored := range pending {
+		if stored.nextAttemptAt.After(now) {
+			held[stored.message.AggregateID] = true
+		}
+
+		if held[stored.message.AggregateID] {
+			continue
+		}
+
+		messages = append(messages, stored.message)
+	}
+
+	if len(messages) > limit {
+		messages = messages[:limit]
+	}
+
+	return messages, nil
+}
+
+func (s *fakeStore) MarkPublished(_ context.Context, id string, _ time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, stored := range s.messages {
+		if stored.message.ID == id {
+			stored.published = true
+		}
+	}
+
+	return nil
+}
+
+func (s *fakeStore) MarkFailed(_ context.Context, message outbox.Message, _ error, retryAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, stored := range s.messages {
+		if stored.message.ID == message.ID {
+			stored.message.Attempts++
+		}
+
+		if stored.message.AggregateID == message.AggregateID && !stored.published {
+			stored.nextAttemptAt = retryAt
+		}
+	}
+
+	return nil
+}
+
+func (s *fakeStore) retryNow() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, stored := range s.messages {
+		stored.nextAttemptAt = time.Time{}
+	}
+}
+
+func (p *fakePublisher) Publish(_ context.Context, message outbox.Message) error {
+	if p.failing[message.ID] {
+		return errors.New("publisher unavailable")
+	}
+
+	p.published = append(p.published, message.ID)
+
+	return nil
+}
+
+func newMessage(id, aggregateID string, occurredAt time.Time) outbox.Message {
+	return outbox.Message{
+		ID:          id,
+		Type:        "quote.created",
+		AggregateID: aggregateID,
+		EntityID:    id,
+		Payload:     json.RawMessage(`{"id":"` + id + `"}`),
+		OccurredAt:  occurredAt,
+	}
+}
+
+func TestRelay(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+
+	t.Run("Should publish the pending messages in the order they occurred", func(t *testing.T) {
+		store := &fakeStore{}
+		store.add(
+			newMessage("event-02", "partner-01", now.Add(-time.Second)),
+			newMessage("event-01", "partner-01", now.Add(-2*time.Second)),
+			newMessage("event-03", "partner-02", now),
+		)
+		publisher := &fakePublisher{}
+
+		result, err := outbox.NewRelay(store, publisher, 10).RelayPending(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, outbox.RelayResult{Published: 3}, result)
+		assert.Equal(t, []string{"event-01", "event-02", "event-03"}, publisher.published)
+	})
+
+	t.Run("Should hold the following messages of an aggregate when one fails", func(t *testing.T) {
+		store := &fakeStore{}
+		store.add(
+			newMessage("event-01", "partner-01", now.Add(-3*time.Second)),
+			newMessage("event-02", "partner-02", now.Add(-2*time.Second)),
+			newMessage("event-03", "partner-01", now.Add(-time.Second)),
+		)
+		publisher := &fakePublisher{failing: map[string]bool{"event-01": true}}
+		relay := outbox.NewRelay(store, publisher, 10)
+
+		result, err := relay.RelayPending(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, outbox.RelayResult{Published: 1, Failed: 1}, result)
+		assert.Equal(t, []string{"event-02"}, publisher.published)
+
+		result, err = relay.RelayPending(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, outbox.RelayResult{}, result, "the aggregate waits for the retry")
+
+		publisher.failing = nil
+		store.retryNow()
+
+		result, err = relay.RelayPending(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, outbox.RelayResult{Published: 2}, result)
+		assert.Equal(t, []string{"event-02", "event-01", "event-03"}, publisher.published)
+	})
+
+	t.Run("Should hold the messages added to an aggregate waiting to be retried", func(t *testing.T) {
+		store := &fakeStore{}
+		store.add(newMessage("event-01", "partner-01", now.Add(-2*time.Second)))
+		publisher := &fakePublisher{failing: map[string]bool{"event-01": true}}
+		relay := outbox.NewRelay(store, publisher, 10)
+
+		_, err := relay.RelayPending(context.Background())
+		assert.NoError(t, err)
+
+		store.add(newMessage("event-02", "partner-01", now.Add(-time.Second)))
+
+		result, err := relay.RelayPending(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, outbox.RelayResult{}, result)
+		assert.Empty(t, publisher.published)
+	})
+
+	t.Run("Should only publish up to the batch size", func(t *testing.T) {
+		store := &fakeStore{}
+		store.add(
+			newMessage("event-01", "partner-01", now.Add(-2*time.Second)),
+			newMessage("event-02", "partner-01", now.Add(-time.Second)),
+		)
+		publisher := &fakePublisher{}
+
+		result, err := outbox.NewRelay(store, publisher, 1).RelayPending(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), result.Published)
+		assert.Equal(t, []string{"event-01"}, publisher.published)
+	})
+}
+
+func TestRedisStreamPublisher(t *testing.T) {
+	t.Parallel()
+
+	testRedis := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: testRedis.Addr()})
+
+	publisher := outbox.NewRedisStreamPublisher(redisClient, "events", 1000)
+
+	t.Run("Should append the message to the stream", func(t *testing.T) {
+		message := newMessage("event-01", "partner-01", time.Now())
+
+		err := publisher.Publish(context.Background(), message)
+		assert.NoError(t, err)
+
+		entries, err := redisClient.XRange(context.Background(), "events", "-", "+").Result()
+		assert.NoError(t, err)
+		assert.Len(t, entries, 1)
+		assert.Equal(t, "event-01", entries[0].Values["id"])
+		assert.Equal(t, "partner-01", entries[0].Values["aggregate_id"])
+
+		var published outbox.Message
+		err = json.Unmarshal([]byte(entries[0].Values["message"].(string)), &published)
+		assert.NoError(t, err)
+		assert.Equal(t, message.ID, published.ID)
+		assert.JSONEq(t, `{"id":"event-01"}`, string(published.Payload))
+	})
+}
+
+func TestMultiPublisher(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Should publish the message to every publisher", func(t *testing.T) {
+		first, second := &fakePublisher{}, &fakePublisher{}
+
+		err := outbox.MultiPublisher{first, second}.Publish(context.Background(), newMessage("event-01", "partner-01", time.Now()))
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"event-01"}, first.published)
+		assert.Equal(t, []string{"event-01"}, second.published)
+	})
+
+	t.Run("Should fail when any publisher fails", func(t *testing.T) {
+		failing := &fakePublisher{failing: map[string]bool{"event-01": true}}
+
+		err := outbox.MultiPublisher{&fakePublisher{}, failing}.Publish(context.Background(), newMessage("event-01", "partner-01", time.Now()))
+
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,165 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+type (
+	// Result describes the state of a limit after a request was counted.
+	Result struct {
+		Allowed bool
+		Limit   int
+		// Remaining is how many requests can still be made before the limit is
+		// reached.
+		Remaining int
+		// ResetAfter is how long until a slot is freed, when not allowed, or the
+		// window is empty again, when allowed.
+		ResetAfter time.Duration
+	}
+
+	// SlidingWindow limits requests to a number per window, counting every
+	// request in a Redis sorted set so the window slides with the clock instead
+	// of resetting at fixed boundaries.
+	SlidingWindow struct {
+		client *redis.Client
+		window time.Duration
+	}
+
+	// DailyQuota limits requests to a number per calendar day.
+	DailyQuota struct {
+		client   *redis.Client
+		location *time.Location
+	}
+)
+
+var (
+	// Brazil has no daylight saving time since 2019, so a fixed zone keeps the
+	// quota days aligned with our partners' without depending on tzdata.
+	QuotaLocation = time.FixedZone("America/Sao_Paulo", -3*60*60)
+
+	slidingWindowScript = redis.NewScript(`
+local key = KEYS[1]
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+
+redis.call('ZREMRANGEBYSCORE', key, '-inf', now - window)
+
+local count = redis.call('ZCARD', key)
+local allowed = 0
+if count < limit then
+	redis.call('ZADD', key, now, ARGV[4])
+	redis.call('PEXPIRE', key, window)
+	count = count + 1
+	allowed = 1
+end
+
+local reset = window
+local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+if oldest[2] then
+	reset = tonumber(oldest[2]) + window - now
+end
+
+return {allowed, count, reset}
+`)
+
+	dailyQuotaScript = redis.NewScript(`
+local count = redis.call('INCR', KEYS[1])
+if count == 1 then
+	redis.call('PEXPIREAT', KEYS[1], ARGV[2])
+end
+
+if count > tonumber(ARGV[1]) then
+	redis.call('DECR', KEYS[1])
+	return {0, count - 1}
+end
+
+return {1, count}
+`)
+)
+
+func NewSlidingWindow(client *redis.Client, window time.Duration) *SlidingWindow {
+	return &SlidingWindow{
+		client: client,
+		window: window,
+	}
+}
+
+func (s *SlidingWindow) Window() time.Duration {
+	return s.window
+}
+
+// Allow counts a request under key, unless limit requests were already counted
+// in the current window.
+func (s *SlidingWindow) Allow(ctx context.Context, key string, limit int) (Result, error) {
+	values, err := slidingWindowScript.Run(
+		ctx,
+		s.client,
+		[]string{key},
+		time.Now().UnixMilli(),
+		s.window.Milliseconds(),
+		limit,
+		uuid.NewString(),
+	).Int64Slice()
+	if err != nil {
+		return Result{}, err
+	}
+
+	return Result{
+		Allowed:    values[0] == 1,
+		Limit:      limit,
+		Remaining:  max(limit-int(values[1]), 0),
+		ResetAfter: time.Duration(values[2]) * time.Millisecond,
+	}, nil
+}
+
+func NewDailyQuota(client *redis.Client, location *time.Location) *DailyQuota {
+	return &DailyQuota{
+		client:   client,
+		location: location,
+	}
+}
+
+// Consume counts a request under key for the day of now, unless quota requests
+// were already counted that day.
+func (q *DailyQuota) Consume(ctx context.Context, key string, quota int, now time.Time) (Result, error) {
+	endOfDay := q.endOfDay(now)
+
+	values, err := dailyQuotaScript.Run(
+		ctx,
+		q.client,
+		[]string{q.dayKey(key, now)},
+		quota,
+		endOfDay.UnixMilli(),
+	).Int64Slice()
+	if err != nil {
+		return Result{}, err
+	}
+
+	return Result{
+		Allowed:    values[0] == 1,
+		Limit:      quota,
+		Remaining:  max(quota-int(values[1]), 0),
+		ResetAfter: endOfDay.Sub(now),
+	}, nil
+}
+
+// Refund gives back a request counted by Consume, for requests that failed
+// before using the quota.
+func (q *DailyQuota) Refund(ctx context.Context, key string, now time.Time) error {
+	return q.client.Decr(ctx, q.dayKey(key, now)).Err()
+}
+
+func (q *DailyQuota) dayKey(key string, now time.Time) string {
+	return key + ":" + now.In(q.location).Format(time.DateOnly)
+}
+
+func (q *DailyQuota) endOfDay(now time.Time) time.Time {
+	year, month, day := now.In(q.location).Date()
+
+	return time.Date(year, month, day+1, 0, 0, 0, 0, q.location)
+}
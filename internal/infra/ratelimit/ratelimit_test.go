@@ -0,0 +1,84 @@
+package ratelimit_test
+
+import (
+	"main-api/internal/infra/ratelimit"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlidingWindow(t *testing.T) {
+	t.Parallel()
+
+	testRedis := miniredis.RunT(t)
+	limiter := ratelimit.NewSlidingWindow(redis.NewClient(&redis.Options{Addr: testRedis.Addr()}), time.Minute)
+
+	t.Run("Should allow requests until the limit is reached", func(t *testing.T) {
+		for attempt := 1; attempt <= 3; attempt++ {
+			result, err := limiter.Allow(t.Context(), "partner-01", 3)
+
+			assert.NoError(t, err)
+			assert.True(t, result.Allowed)
+			assert.Equal(t, 3-attempt, result.Remaining)
+		}
+
+		result, err := limiter.Allow(t.Context(), "partner-01", 3)
+
+		assert.NoError(t, err)
+		assert.False(t, result.Allowed)
+		assert.Equal(t, 0, result.Remaining)
+		assert.Greater(t, result.ResetAfter, time.Duration(0))
+		assert.LessOrEqual(t, result.ResetAfter, time.Minute)
+	})
+
+	t.Run("Should count each key apart", func(t *testing.T) {
+		_, _ = limiter.Allow(t.Context(), "partner-02", 1)
+
+		result, err := limiter.Allow(t.Context(), "partner-03", 1)
+
+		assert.NoError(t, err)
+		assert.True(t, result.Allowed)
+	})
+}
+
+func TestDailyQuota(t *testing.T) {
+	t.Parallel()
+
+	testRedis := miniredis.RunT(t)
+	quota := ratelimit.NewDailyQuota(redis.NewClient(&redis.Options{Addr: testRedis.Addr()}), ratelimit.QuotaLocation)
+
+	now := time.Now()
+
+	t.Run("Should deny requests over the quota until the next day", func(t *testing.T) {
+		first, err := quota.Consume(t.Context(), "quotes:partner-01", 2, now)
+		assert.NoError(t, err)
+		assert.True(t, first.Allowed)
+		assert.Equal(t, 1, first.Remaining)
+
+		_, _ = quota.Consume(t.Context(), "quotes:partner-01", 2, now)
+		denied, err := quota.Consume(t.Context(), "quotes:partner-01", 2, now)
+
+		assert.NoError(t, err)
+		assert.False(t, denied.Allowed)
+		assert.Greater(t, denied.ResetAfter, time.Duration(0))
+		assert.LessOrEqual(t, denied.ResetAfter, 24*time.Hour)
+
+		nextDay, err := quota.Consume(t.Context(), "quotes:partner-01", 2, now.Add(24*time.Hour))
+
+		assert.NoError(t, err)
+		assert.True(t, nextDay.Allowed)
+	})
+
+	t.Run("Should give back refunded requests", func(t *testing.T) {
+		_, _ = quota.Consume(t.Context(), "quotes:partner-02", 1, now)
+		assert.NoError(t, quota.Refund(t.Context(), "quotes:partner-02", now))
+
+		result, err := quota.Consume(t.Context(), "quotes:partner-02", 1, now)
+
+		assert.NoError(t, err)
+		assert.True(t, result.Allowed)
+	})
+}
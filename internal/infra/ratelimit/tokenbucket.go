@@ -0,0 +1,156 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type (
+	// Budget is the rate allowed for a key: Rate requests per second on
+	// average, with bursts of up to Burst requests.
+	Budget struct {
+		Rate  float64
+		Burst int
+	}
+
+	// TokenBucket shares a token bucket per key across every replica through
+	// Redis. Callers reserve a token and wait until it is due, so the wait
+	// queue is the debt of the bucket; it is bounded by maxWait and by the
+	// deadline of the caller's context.
+	TokenBucket struct {
+		client  *redis.Client
+		maxWait time.Duration
+	}
+
+	// ThrottledError is returned when the wait for a token would exceed the
+	// wait queue bound or the caller's deadline. No token is taken.
+	ThrottledError struct {
+		Key        string
+		RetryAfter time.Duration
+	}
+)
+
+var (
+	// ErrInvalidRate is returned for budgets whose rate isn't positive, since
+	// the wait for a token is divided by it.
+	ErrInvalidRate = errors.New("rate must be positive")
+
+	tokenBucketScript = redis.NewScript(`
+local key = KEYS[1]
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local max_wait = tonumber(ARGV[4])
+
+local state = redis.call('HMGET', key, 'tokens', 'ts')
+local tokens = tonumber(state[1]) or burst
+local ts = tonumber(state[2]) or now
+
+tokens = math.min(burst, tokens + math.max(0, now - ts) * rate)
+
+local wait = 0
+if tokens < 1 then
+	wait = math.ceil((1 - tokens) / rate)
+end
+
+if wait > max_wait then
+	return {0, wait}
+end
+
+tokens = tokens - 1
+redis.call('HSET', key, 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', key, math.ceil(burst / rate) + wait)
+
+return {1, wait}
+`)
+)
+
+// BudgetsFromRates builds budgets from requests per second, allowing bursts of
+// one second worth of requests.
+func BudgetsFromRates(rates map[string]float64) (map[string]Budget, error) {
+	budgets := make(map[string]Budget, len(rates))
+	for key, rate := range rates {
+		if !(rate > 0) {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidRate, key, rate)
+		}
+
+		budgets[key] = Budget{Rate: rate, Burst: int(math.Ceil(rate))}
+	}
+
+	return budgets, nil
+}
+
+func NewTokenBucket(client *redis.Client, maxWait time.Duration) *TokenBucket {
+	return &TokenBucket{
+		client:  client,
+		maxWait: maxWait,
+	}
+}
+
+// Wait blocks until a token of key is available. It fails right away with a
+// *ThrottledError when the token wouldn't be available in time.
+func (b *TokenBucket) Wait(ctx context.Context, key string, budget Budget) error {
+	wait, err := b.reserve(ctx, key, budget, time.Now())
+	if err != nil || wait <= 0 {
+		return err
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func (b *TokenBucket) reserve(ctx context.Context, key string, budget Budget, now time.Time) (time.Duration, error) {
+	if !(budget.Rate > 0) {
+		return 0, fmt.Errorf("%w: %s: %v", ErrInvalidRate, key, budget.Rate)
+	}
+
+	maxWait := b.maxWait
+	if deadline, ok := ctx.Deadline(); ok {
+		maxWait = min(maxWait, deadline.Sub(now))
+	}
+
+	burst := max(budget.Burst, 1)
+
+	values, err := tokenBucketScript.Run(
+		ctx,
+		b.client,
+		[]string{"tokenbucket:" + key},
+		// The script works in milliseconds.
+		budget.Rate/1000,
+		burst,
+		now.UnixMilli(),
+		max(maxWait.Milliseconds(), 0),
+	).Int64Slice()
+	if err != nil {
+		return 0, err
+	}
+
+	wait := time.Duration(values[1]) * time.Millisecond
+	if values[0] == 0 {
+		return 0, &ThrottledError{Key: key, RetryAfter: wait}
+	}
+
+	return wait, nil
+}
+
+func (e *ThrottledError) Error() string {
+	return fmt.Sprintf("rate limit of %s exceeded, retry after %s", e.Key, e.RetryAfter)
+}
+
+// RetryAfterSeconds rounds RetryAfter up to whole seconds, as used by the
+// Retry-After header.
+func (e *ThrottledError) RetryAfterSeconds() int {
+	return int(math.Ceil(e.RetryAfter.Seconds()))
+}
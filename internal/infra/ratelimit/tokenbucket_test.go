@@ -0,0 +1,112 @@
+package ratelimit_test
+
+import (
+	"context"
+	"main-api/internal/infra/ratelimit"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenBucket(t *testing.T) {
+	t.Parallel()
+
+	testRedis := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: testRedis.Addr()})
+
+	t.Run("Should shed requests over the burst when waiting is not allowed", func(t *testing.T) {
+		bucket := ratelimit.NewTokenBucket(redisClient, 0)
+		budget := ratelimit.Budget{Rate: 1, Burst: 2}
+
+		assert.NoError(t, bucket.Wait(t.Context(), "quotations", budget))
+		assert.NoError(t, bucket.Wait(t.Context(), "quotations", budget))
+
+		err := bucket.Wait(t.Context(), "quotations", budget)
+
+		var throttled *ratelimit.ThrottledError
+		assert.ErrorAs(t, err, &throttled)
+		assert.Equal(t, "quotations", throttled.Key)
+		assert.Equal(t, 1, throttled.RetryAfterSeconds())
+	})
+
+	t.Run("Should wait for the next token within the queue bound", func(t *testing.T) {
+		bucket := ratelimit.NewTokenBucket(redisClient, 250*time.Millisecond)
+		budget := ratelimit.Budget{Rate: 10, Burst: 1}
+
+		assert.NoError(t, bucket.Wait(t.Context(), "policies", budget))
+
+		start := time.Now()
+		assert.NoError(t, bucket.Wait(t.Context(), "policies", budget))
+		assert.GreaterOrEqual(t, time.Since(start), 80*time.Millisecond)
+
+		// Concurrent callers queue up: they wait 100ms and 200ms, and the third
+		// one, due in 300ms, goes over the bound.
+		errs := make(chan error, 3)
+		for range 3 {
+			go func() {
+				errs <- bucket.Wait(t.Context(), "policies", budget)
+			}()
+		}
+
+		throttledCount := 0
+		for range 3 {
+			var throttled *ratelimit.ThrottledError
+			if err := <-errs; err != nil {
+				assert.ErrorAs(t, err, &throttled)
+				throttledCount++
+			}
+		}
+
+		assert.Equal(t, 1, throttledCount)
+	})
+
+	t.Run("Not should wait past the deadline of the request", func(t *testing.T) {
+		bucket := ratelimit.NewTokenBucket(redisClient, time.Minute)
+		budget := ratelimit.Budget{Rate: 1, Burst: 1}
+
+		assert.NoError(t, bucket.Wait(t.Context(), "get-policy", budget))
+
+		ctx, cancel := context.WithTimeout(t.Context(), 100*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		err := bucket.Wait(ctx, "get-policy", budget)
+
+		var throttled *ratelimit.ThrottledError
+		assert.ErrorAs(t, err, &throttled)
+		assert.Less(t, time.Since(start), 50*time.Millisecond)
+	})
+	t.Run("Not should take a token of a budget without rate", func(t *testing.T) {
+		bucket := ratelimit.NewTokenBucket(redisClient, time.Second)
+
+		err := bucket.Wait(t.Context(), "cancel-policy", ratelimit.Budget{Rate: 0, Burst: 1})
+
+		assert.ErrorIs(t, err, ratelimit.ErrInvalidRate)
+		assert.False(t, testRedis.Exists("tokenbucket:cancel-policy"))
+	})
+}
+
+func TestBudgetsFromRates(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Should allow bursts of one second of requests", func(t *testing.T) {
+		budgets, err := ratelimit.BudgetsFromRates(map[string]float64{"create_policy": 5, "get_policy": 0.5})
+
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]ratelimit.Budget{
+			"create_policy": {Rate: 5, Burst: 5},
+			"get_policy":    {Rate: 0.5, Burst: 1},
+		}, budgets)
+	})
+
+	t.Run("Not should build budgets with a rate that isn't positive", func(t *testing.T) {
+		for _, rate := range []float64{0, -1} {
+			_, err := ratelimit.BudgetsFromRates(map[string]float64{"create_policy": rate})
+
+			assert.ErrorIs(t, err, ratelimit.ErrInvalidRate)
+		}
+	})
+}
@@ -0,0 +1,386 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"main-api/internal/domain/partners"
+	"main-api/internal/infra/encryption"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type (
+	// Repo stores the changes of the events about a partner's records
+	// encrypted with the partner's key. The chain covers their digest, so it
+	// verifies whatever key they are encrypted with.
+	Repo struct {
+		DatabaseName string
+		DB           *mongo.Client
+		Cipher       *encryption.Cipher
+	}
+
+	eventResultDB struct {
+		ID           bson.ObjectID `bson:"_id"`
+		Sequence     int64         `bson:"sequence"`
+		ActorType    string        `bson:"actor_type"`
+		ActorID      string        `bson:"actor_id"`
+		Action       string        `bson:"action"`
+		EntityType   string        `bson:"entity_type"`
+		EntityID     string        `bson:"entity_id"`
+		PartnerID    string        `bson:"partner_id"`
+		Changes      *string       `bson:"changes"`
+		ChangesHash  string        `bson:"changes_hash"`
+		RequestID    string        `bson:"request_id"`
+		SourceIP     string        `bson:"source_ip"`
+		OccurredAt   time.Time     `bson:"occurred_at"`
+		PreviousHash string        `bson:"previous_hash"`
+		Hash         string        `bson:"hash"`
+	}
+
+	headResultDB struct {
+		Sequence int64  `bson:"sequence"`
+		Hash     string `bson:"hash"`
+	}
+)
+
+const (
+	CollectionName      = "audit_events"
+	ChainCollectionName = "audit_chain"
+
+	// headID is the document of ChainCollectionName with the last entry of
+	// the chain.
+	headID = "head"
+)
+
+func NewRepo(db *mongo.Client, dbName string, cipher *encryption.Cipher) *Repo {
+	return &Repo{
+		DatabaseName: dbName,
+		DB:           db,
+		Cipher:       cipher,
+	}
+}
+
+// CreateIndexes ensures each sequence is used once and the filters of the
+// admin queries are indexed. The audit log is kept forever, so there's no
+// retention index.
+func (r *Repo) CreateIndexes(ctx context.Context) error {
+	collection := r.DB.Database(r.DatabaseName).Collection(CollectionName)
+
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "sequence", Value: 1}},
+			Options: options.Index().SetName("sequence").SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "partner_id", Value: 1}, {Key: "sequence", Value: 1}},
+			Options: options.Index().SetName("partner_id_sequence"),
+		},
+		{
+			Keys:    bson.D{{Key: "entity_id", Value: 1}, {Key: "sequence", Value: 1}},
+			Options: options.Index().SetName("entity_id_sequence"),
+		},
+	})
+
+	return err
+}
+
+// Append reserves the next sequences on the head of the chain and links the
+// events after it. Updating the head makes concurrent appends conflict, and
+// the transaction retried, so the chain is written in a single line. Outside
+// of a transaction, Append runs in one of its own.
+//
+// The single head is the cost of one global order: every audited change, of
+// any partner, waits for the transactions holding the head before it, and a
+// conflict retries the whole transaction, the change included. That caps the
+// audited writes at roughly one transaction per round trip to the primary,
+// which is enough for the API's write rate but rules out bulk changes.
+func (r *Repo) Append(ctx context.Context, events ...partners.AuditEventEntity) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	if mongo.SessionFromContext(ctx) != nil {
+		return r.append(ctx, events)
+	}
+
+	session, err := r.DB.StartSession()
+	if err != nil {
+		return err
+	}
+
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(ctx context.Context) (any, error) {
+		return nil, r.append(ctx, events)
+	})
+
+	return err
+}
+
+func (r *Repo) append(ctx context.Context, events []partners.AuditEventEntity) error {
+	database := r.DB.Database(r.DatabaseName)
+
+	var head headResultDB
+	err := database.Collection(ChainCollectionName).FindOneAndUpdate(
+		ctx,
+		bson.M{"_id": headID},
+		bson.M{"$inc": bson.M{"sequence": int64(len(events))}},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.Before),
+	).Decode(&head)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return err
+	}
+
+	documents := make([]interface{}, len(events))
+	for index := range events {
+		event := &events[index]
+		event.Seal(head.Sequence+1, head.Hash)
+
+		changes, err := r.encryptChanges(ctx, event.PartnerID, string(event.Changes))
+		if err != nil {
+			return err
+		}
+
+		head = headResultDB{Sequence: event.Sequence, Hash: event.Hash}
+		documents[index] = map[string]interface{}{
+			"sequence":      event.Sequence,
+			"actor_type":    event.Actor.Type,
+			"actor_id":      event.Actor.ID,
+			"action":        event.Action,
+			"entity_type":   event.EntityType,
+			"entity_id":     event.EntityID,
+			"partner_id":    event.PartnerID,
+			"changes":       changes,
+			"changes_hash":  event.ChangesHash,
+			"request_id":    event.RequestID,
+			"source_ip":     event.SourceIP,
+			"occurred_at":   event.OccurredAt,
+			"previous_hash": event.PreviousHash,
+			"hash":          event.Hash,
+		}
+	}
+
+	_, err = database.Collection(ChainCollectionName).UpdateOne(
+		ctx,
+		bson.M{"_id": headID},
+		bson.M{"$set": bson.M{"hash": head.Hash}},
+	)
+	if err != nil {
+		return err
+	}
+
+	result, err := database.Collection(CollectionName).InsertMany(ctx, documents)
+	if err != nil {
+		return err
+	}
+
+	for index, insertedID := range result.InsertedIDs {
+		if objectID, ok := insertedID.(bson.ObjectID); ok {
+			events[index].ID = objectID.Hex()
+		}
+	}
+
+	return nil
+}
+
+func (r *Repo) List(ctx context.Context, filter partners.AuditEventFilter) ([]partners.AuditEventEntity, error) {
+	collection := r.DB.Database(r.DatabaseName).Collection(CollectionName)
+
+	query := bson.M{"sequence": bson.M{"$gt": filter.AfterSequence}}
+
+	for field, value := range map[string]string{
+		"partner_id":  filter.PartnerID,
+		"entity_type": filter.EntityType,
+		"entity_id":   filter.EntityID,
+		"action":      string(filter.Action),
+		"actor_id":    filter.ActorID,
+	} {
+		if value != "" {
+			query[field] = value
+		}
+	}
+
+	occurredAt := bson.M{}
+	if !filter.From.IsZero() {
+		occurredAt["$gte"] = filter.From
+	}
+
+	if !filter.To.IsZero() {
+		occurredAt["$lt"] = filter.To
+	}
+
+	if len(occurredAt) > 0 {
+		query["occurred_at"] = occurredAt
+	}
+
+	cursor, err := collection.Find(
+		ctx,
+		query,
+		options.Find().SetSort(bson.D{{Key: "sequence", Value: 1}}).SetLimit(int64(filter.Limit)),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []eventResultDB
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	decrypter := r.Cipher.Decrypter(ctx)
+
+	events := make([]partners.AuditEventEntity, len(results))
+	for index, result := range results {
+		if result.Changes != nil {
+			changes := decrypter.Decrypt("changes", *result.Changes)
+			result.Changes = &changes
+		}
+
+		events[index] = toEntity(result)
+	}
+
+	if err := decrypter.Err(); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+func (r *Repo) Head(ctx context.Context) (partners.AuditChainHead, error) {
+	collection := r.DB.Database(r.DatabaseName).Collection(ChainCollectionName)
+
+	var head headResultDB
+	err := collection.FindOne(ctx, bson.M{"_id": headID}).Decode(&head)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return partners.AuditChainHead{}, nil
+	}
+
+	if err != nil {
+		return partners.AuditChainHead{}, err
+	}
+
+	return partners.AuditChainHead{Sequence: head.Sequence, Hash: head.Hash}, nil
+}
+
+// EraseChanges sets the changes of the records' events to null. The digest
+// stays, so the chain still verifies.
+func (r *Repo) EraseChanges(ctx context.Context, entityIDs ...string) error {
+	if len(entityIDs) == 0 {
+		return nil
+	}
+
+	collection := r.DB.Database(r.DatabaseName).Collection(CollectionName)
+
+	_, err := collection.UpdateMany(
+		ctx,
+		bson.M{"entity_id": bson.M{"$in": entityIDs}},
+		bson.M{"$set": bson.M{"changes": nil}},
+	)
+
+	return err
+}
+
+// Reencrypt encrypts the changes of the events with the active key of their
+// partner, returning how many events were updated. Like the other
+// repositories, an event is only updated if its changes are still the ones
+// read.
+func (r *Repo) Reencrypt(ctx context.Context) (int64, error) {
+	collection := r.DB.Database(r.DatabaseName).Collection(CollectionName)
+
+	cursor, err := collection.Find(ctx, bson.M{"partner_id": bson.M{"$ne": ""}, "changes": bson.M{"$ne": nil}})
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	activeKeys := make(map[string]string)
+
+	var updated int64
+	for cursor.Next(ctx) {
+		var result eventResultDB
+		if err := cursor.Decode(&result); err != nil {
+			return updated, err
+		}
+
+		if _, ok := activeKeys[result.PartnerID]; !ok {
+			encrypter := r.Cipher.Encrypter(ctx, result.PartnerID)
+			if err := encrypter.Err(); err != nil {
+				return updated, err
+			}
+
+			activeKeys[result.PartnerID] = encrypter.KeyID()
+		}
+
+		if encryption.KeyID(*result.Changes) == activeKeys[result.PartnerID] {
+			continue
+		}
+
+		decrypter := r.Cipher.Decrypter(ctx)
+		plaintext := decrypter.Decrypt("changes", *result.Changes)
+		if err := decrypter.Err(); err != nil {
+			return updated, err
+		}
+
+		changes, err := r.encryptChanges(ctx, result.PartnerID, plaintext)
+		if err != nil {
+			return updated, err
+		}
+
+		updateResult, err := collection.UpdateOne(
+			ctx,
+			bson.M{"_id": result.ID, "changes": *result.Changes},
+			bson.M{"$set": bson.M{"changes": changes}},
+		)
+		if err != nil {
+			return updated, err
+		}
+
+		updated += updateResult.ModifiedCount
+	}
+
+	return updated, cursor.Err()
+}
+
+// encryptChanges encrypts the changes of the events about a partner's
+// records. The others don't carry personal data and are kept as they are.
+func (r *Repo) encryptChanges(ctx context.Context, partnerID, changes string) (string, error) {
+	if partnerID == "" {
+		return changes, nil
+	}
+
+	encrypter := r.Cipher.Encrypter(ctx, partnerID)
+	encrypted := encrypter.Encrypt("changes", changes)
+
+	return encrypted, encrypter.Err()
+}
+
+func toEntity(result eventResultDB) partners.AuditEventEntity {
+	var changes []byte
+	if result.Changes != nil {
+		changes = []byte(*result.Changes)
+	}
+
+	return partners.AuditEventEntity{
+		ID:       result.ID.Hex(),
+		Sequence: result.Sequence,
+		Actor: partners.AuditActor{
+			Type: partners.AuditActorTypeEnum(result.ActorType),
+			ID:   result.ActorID,
+		},
+		Action:       partners.AuditActionEnum(result.Action),
+		EntityType:   result.EntityType,
+		EntityID:     result.EntityID,
+		PartnerID:    result.PartnerID,
+		Changes:      changes,
+		ChangesHash:  result.ChangesHash,
+		RequestID:    result.RequestID,
+		SourceIP:     result.SourceIP,
+		OccurredAt:   result.OccurredAt,
+		PreviousHash: result.PreviousHash,
+		Hash:         result.Hash,
+	}
+}
@@ -0,0 +1,416 @@
+package customers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"main-api/internal/domain/partners"
+	"main-api/internal/infra/encryption"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type (
+	// Repo stores the personal data of the customers encrypted with the key of
+	// their partner, and the CPF's keyed hash to look them up by it.
+	Repo struct {
+		DatabaseName string
+		DB           *mongo.Client
+		Cipher       *encryption.Cipher
+	}
+
+	customerResultDB struct {
+		ID          bson.ObjectID `bson:"_id"`
+		PartnerID   string        `bson:"partner_id"`
+		Name        string        `bson:"name"`
+		Sex         string        `bson:"sex"`
+		DateOfBirth string        `bson:"date_of_birth"`
+		Cpf         string        `bson:"cpf"`
+		CpfHash     string        `bson:"cpf_hash,omitempty"`
+		Email       string        `bson:"email,omitempty"`
+		Phone       string        `bson:"phone,omitempty"`
+		CreatedAt   time.Time     `bson:"created_at"`
+		UpdatedAt   time.Time     `bson:"updated_at"`
+		DeletedAt   time.Time     `bson:"deleted_at,omitempty"`
+		ErasedAt    time.Time     `bson:"erased_at,omitempty"`
+	}
+)
+
+var (
+	CollectionName = "customers"
+)
+
+const (
+	// legacyCpfIndexName is the unique CPF index from before customers were
+	// soft deleted, which would keep a deleted customer's CPF taken.
+	legacyCpfIndexName = "partner_id_cpf"
+
+	errCodeIndexNotFound     = 27
+	errCodeNamespaceNotFound = 26
+)
+
+func NewRepo(db *mongo.Client, dbName string, cipher *encryption.Cipher) *Repo {
+	return &Repo{
+		DatabaseName: dbName,
+		DB:           db,
+		Cipher:       cipher,
+	}
+}
+
+func (r *Repo) Create(ctx context.Context, customer *partners.CustomerEntity) error {
+	collection := r.DB.Database(r.DatabaseName).Collection(CollectionName)
+
+	document, err := r.personalData(ctx, customer)
+	if err != nil {
+		return err
+	}
+
+	document["partner_id"] = customer.PartnerID
+	document["created_at"] = customer.CreatedAt
+	document["updated_at"] = customer.UpdatedAt
+
+	result, err := collection.InsertOne(ctx, document)
+	if mongo.IsDuplicateKeyError(err) {
+		return partners.ErrCustomerAlreadyExists
+	}
+
+	if err != nil {
+		return err
+	}
+
+	objectID, ok := result.InsertedID.(bson.ObjectID)
+	if !ok {
+		return fmt.Errorf("error on convert inserted id to ObjectID")
+	}
+
+	customer.ID = objectID.Hex()
+
+	return nil
+}
+
+func (r *Repo) GetByIDAndPartnerID(ctx context.Context, customerID, partnerID string) (*partners.CustomerEntity, error) {
+	id, err := bson.ObjectIDFromHex(customerID)
+	if err != nil {
+		return nil, nil
+	}
+
+	return r.getByFilter(ctx, bson.M{
+		"_id":        id,
+		"partner_id": partnerID,
+		"deleted_at": nil,
+	})
+}
+
+func (r *Repo) GetByCpfAndPartnerID(ctx context.Context, cpf, partnerID string) (*partners.CustomerEntity, error) {
+	return r.getByFilter(ctx, bson.M{
+		"$or":        r.cpfFilter(cpf),
+		"partner_id": partnerID,
+		"deleted_at": nil,
+	})
+}
+
+func (r *Repo) ListByPartnerID(ctx context.Context, partnerID string) ([]partners.CustomerEntity, error) {
+	return r.listByFilter(ctx, bson.M{"partner_id": partnerID, "deleted_at": nil})
+}
+
+func (r *Repo) ListByCpf(ctx context.Context, cpf string) ([]partners.CustomerEntity, error) {
+	return r.listByFilter(ctx, bson.M{"$or": r.cpfFilter(cpf)})
+}
+
+func (r *Repo) Update(ctx context.Context, customer *partners.CustomerEntity) error {
+	collection := r.DB.Database(r.DatabaseName).Collection(CollectionName)
+
+	id, err := bson.ObjectIDFromHex(customer.ID)
+	if err != nil {
+		return partners.ErrCustomerNotFound
+	}
+
+	fields, err := r.personalData(ctx, customer)
+	if err != nil {
+		return err
+	}
+
+	fields["updated_at"] = customer.UpdatedAt
+
+	result, err := collection.UpdateOne(
+		ctx,
+		bson.M{"_id": id, "partner_id": customer.PartnerID, "deleted_at": nil},
+		bson.M{"$set": fields},
+	)
+	if mongo.IsDuplicateKeyError(err) {
+		return partners.ErrCustomerAlreadyExists
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return partners.ErrCustomerNotFound
+	}
+
+	return nil
+}
+
+func (r *Repo) Delete(ctx context.Context, customerID, partnerID string) error {
+	collection := r.DB.Database(r.DatabaseName).Collection(CollectionName)
+
+	id, err := bson.ObjectIDFromHex(customerID)
+	if err != nil {
+		return partners.ErrCustomerNotFound
+	}
+
+	result, err := collection.UpdateOne(
+		ctx,
+		bson.M{"_id": id, "partner_id": partnerID, "deleted_at": nil},
+		bson.M{"$set": bson.M{"deleted_at": time.Now()}},
+	)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return partners.ErrCustomerNotFound
+	}
+
+	return nil
+}
+
+func (r *Repo) Erase(ctx context.Context, customer *partners.CustomerEntity) error {
+	collection := r.DB.Database(r.DatabaseName).Collection(CollectionName)
+
+	id, err := bson.ObjectIDFromHex(customer.ID)
+	if err != nil {
+		return partners.ErrCustomerNotFound
+	}
+
+	fields, err := r.personalData(ctx, customer)
+	if err != nil {
+		return err
+	}
+
+	fields["updated_at"] = customer.UpdatedAt
+	fields["deleted_at"] = customer.DeletedAt
+	fields["erased_at"] = customer.ErasedAt
+
+	result, err := collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": fields})
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return partners.ErrCustomerNotFound
+	}
+
+	return nil
+}
+
+// CreateIndexes ensures a CPF is registered only once per partner among the
+// customers not deleted, whose deleted_at is null in the index, and that the
+// customers of a CPF are found across partners. The CPF is unique by its hash;
+// the indexes on cpf serve the customers stored before it was encrypted, until
+// the rotate-keys command encrypts them.
+func (r *Repo) CreateIndexes(ctx context.Context) error {
+	collection := r.DB.Database(r.DatabaseName).Collection(CollectionName)
+
+	err := collection.Indexes().DropOne(ctx, legacyCpfIndexName)
+
+	var commandErr mongo.CommandError
+	if errors.As(err, &commandErr) && (commandErr.Code == errCodeIndexNotFound || commandErr.Code == errCodeNamespaceNotFound) {
+		err = nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	_, err = collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "partner_id", Value: 1},
+				{Key: "cpf", Value: 1},
+				{Key: "deleted_at", Value: 1},
+			},
+			Options: options.Index().SetName("partner_id_cpf_deleted_at").SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "cpf", Value: 1}},
+			Options: options.Index().SetName("cpf"),
+		},
+		{
+			Keys: bson.D{
+				{Key: "partner_id", Value: 1},
+				{Key: "cpf_hash", Value: 1},
+				{Key: "deleted_at", Value: 1},
+			},
+			Options: options.Index().
+				SetName("partner_id_cpf_hash_deleted_at").
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{"cpf_hash": bson.M{"$exists": true}}),
+		},
+		{
+			Keys:    bson.D{{Key: "cpf_hash", Value: 1}},
+			Options: options.Index().SetName("cpf_hash"),
+		},
+	})
+
+	return err
+}
+
+func (r *Repo) listByFilter(ctx context.Context, filter bson.M) ([]partners.CustomerEntity, error) {
+	collection := r.DB.Database(r.DatabaseName).Collection(CollectionName)
+
+	cursor, err := collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []customerResultDB
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	customers := make([]partners.CustomerEntity, len(results))
+	for index, result := range results {
+		customer, err := r.toEntity(ctx, result)
+		if err != nil {
+			return nil, err
+		}
+
+		customers[index] = *customer
+	}
+
+	return customers, nil
+}
+
+func (r *Repo) getByFilter(ctx context.Context, filter bson.M) (*partners.CustomerEntity, error) {
+	collection := r.DB.Database(r.DatabaseName).Collection(CollectionName)
+
+	var result customerResultDB
+	err := collection.FindOne(ctx, filter).Decode(&result)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return r.toEntity(ctx, result)
+}
+
+// Reencrypt encrypts the customers with the active key of their partner,
+// returning how many were updated. The ones already encrypted with it are
+// skipped. Each customer is only updated if it didn't change since it was
+// read, otherwise it was already saved with the active key.
+func (r *Repo) Reencrypt(ctx context.Context) (int64, error) {
+	collection := r.DB.Database(r.DatabaseName).Collection(CollectionName)
+
+	cursor, err := collection.Find(ctx, bson.M{})
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	activeKeys := make(map[string]string)
+
+	var updated int64
+	for cursor.Next(ctx) {
+		var result customerResultDB
+		if err := cursor.Decode(&result); err != nil {
+			return updated, err
+		}
+
+		if _, ok := activeKeys[result.PartnerID]; !ok {
+			encrypter := r.Cipher.Encrypter(ctx, result.PartnerID)
+			if err := encrypter.Err(); err != nil {
+				return updated, err
+			}
+
+			activeKeys[result.PartnerID] = encrypter.KeyID()
+		}
+
+		if encryption.KeyID(result.Cpf) == activeKeys[result.PartnerID] {
+			continue
+		}
+
+		customer, err := r.toEntity(ctx, result)
+		if err != nil {
+			return updated, err
+		}
+
+		fields, err := r.personalData(ctx, customer)
+		if err != nil {
+			return updated, err
+		}
+
+		updateResult, err := collection.UpdateOne(
+			ctx,
+			bson.M{"_id": result.ID, "cpf": result.Cpf},
+			bson.M{"$set": fields},
+		)
+		if err != nil {
+			return updated, err
+		}
+
+		updated += updateResult.ModifiedCount
+	}
+
+	return updated, cursor.Err()
+}
+
+// personalData returns the customer's personal data encrypted, along with the
+// CPF's hash.
+func (r *Repo) personalData(ctx context.Context, customer *partners.CustomerEntity) (bson.M, error) {
+	encrypter := r.Cipher.Encrypter(ctx, customer.PartnerID)
+
+	fields := bson.M{
+		"name":          encrypter.Encrypt("name", customer.Name),
+		"sex":           customer.Sex,
+		"date_of_birth": encrypter.Encrypt("date_of_birth", customer.DateOfBirth),
+		"cpf":           encrypter.Encrypt("cpf", customer.Cpf),
+		"cpf_hash":      r.Cipher.Hash(customer.Cpf),
+		"email":         encrypter.Encrypt("email", customer.Email),
+		"phone":         encrypter.Encrypt("phone", customer.Phone),
+	}
+
+	return fields, encrypter.Err()
+}
+
+// cpfFilter matches the CPF by its hash or, on the customers stored before
+// the CPF was encrypted, by its value.
+func (r *Repo) cpfFilter(cpf string) bson.A {
+	return bson.A{
+		bson.M{"cpf_hash": r.Cipher.Hash(cpf)},
+		bson.M{"cpf": cpf},
+	}
+}
+
+func (r *Repo) toEntity(ctx context.Context, result customerResultDB) (*partners.CustomerEntity, error) {
+	decrypter := r.Cipher.Decrypter(ctx)
+
+	customer := &partners.CustomerEntity{
+		ID:          result.ID.Hex(),
+		PartnerID:   result.PartnerID,
+		Name:        decrypter.Decrypt("name", result.Name),
+		Sex:         partners.SexEnum(result.Sex),
+		DateOfBirth: decrypter.Decrypt("date_of_birth", result.DateOfBirth),
+		Cpf:         decrypter.Decrypt("cpf", result.Cpf),
+		Email:       decrypter.Decrypt("email", result.Email),
+		Phone:       decrypter.Decrypt("phone", result.Phone),
+		CreatedAt:   result.CreatedAt,
+		UpdatedAt:   result.UpdatedAt,
+		DeletedAt:   result.DeletedAt,
+		ErasedAt:    result.ErasedAt,
+	}
+
+	if err := decrypter.Err(); err != nil {
+		return nil, err
+	}
+
+	return customer, nil
+}
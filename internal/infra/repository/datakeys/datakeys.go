@@ -0,0 +1,183 @@
+package datakeys
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"main-api/internal/infra/encryption"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type (
+	// Repo keeps the data keys outside of the transaction of the context: a
+	// key created while encrypting a document must outlive the transaction
+	// being retried or aborted, since the cipher caches it.
+	Repo struct {
+		DatabaseName string
+		DB           *mongo.Client
+	}
+
+	dataKeyResultDB struct {
+		ID          bson.ObjectID `bson:"_id"`
+		PartnerID   string        `bson:"partner_id"`
+		Version     int           `bson:"version"`
+		WrappedKey  []byte        `bson:"wrapped_key"`
+		MasterKeyID string        `bson:"master_key_id"`
+		CreatedAt   time.Time     `bson:"created_at"`
+	}
+)
+
+var (
+	CollectionName = "data_keys"
+)
+
+func NewRepo(db *mongo.Client, dbName string) *Repo {
+	return &Repo{
+		DatabaseName: dbName,
+		DB:           db,
+	}
+}
+
+// CreateIndexes ensures each version of a partner's key is created once.
+func (r *Repo) CreateIndexes(ctx context.Context) error {
+	collection := r.DB.Database(r.DatabaseName).Collection(CollectionName)
+
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "partner_id", Value: 1}, {Key: "version", Value: 1}},
+		Options: options.Index().SetName("partner_id_version").SetUnique(true),
+	})
+
+	return err
+}
+
+func (r *Repo) Active(ctx context.Context, partnerID string) (*encryption.DataKey, error) {
+	return r.findOne(withoutTransaction(ctx), bson.M{"partner_id": partnerID},
+		options.FindOne().SetSort(bson.D{{Key: "version", Value: -1}}))
+}
+
+func (r *Repo) GetByID(ctx context.Context, id string) (*encryption.DataKey, error) {
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, nil
+	}
+
+	return r.findOne(withoutTransaction(ctx), bson.M{"_id": objectID})
+}
+
+func (r *Repo) Create(ctx context.Context, key *encryption.DataKey) error {
+	collection := r.DB.Database(r.DatabaseName).Collection(CollectionName)
+
+	result, err := collection.InsertOne(withoutTransaction(ctx), map[string]interface{}{
+		"partner_id":    key.PartnerID,
+		"version":       key.Version,
+		"wrapped_key":   key.WrappedKey,
+		"master_key_id": key.MasterKeyID,
+		"created_at":    key.CreatedAt,
+	})
+	if mongo.IsDuplicateKeyError(err) {
+		return encryption.ErrDataKeyExists
+	}
+
+	if err != nil {
+		return err
+	}
+
+	objectID, ok := result.InsertedID.(bson.ObjectID)
+	if !ok {
+		return fmt.Errorf("error on convert inserted id to ObjectID")
+	}
+
+	key.ID = objectID.Hex()
+
+	return nil
+}
+
+func (r *Repo) List(ctx context.Context) ([]encryption.DataKey, error) {
+	collection := r.DB.Database(r.DatabaseName).Collection(CollectionName)
+
+	cursor, err := collection.Find(withoutTransaction(ctx), bson.M{}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []dataKeyResultDB
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	keys := make([]encryption.DataKey, len(results))
+	for index, result := range results {
+		keys[index] = *toEntity(result)
+	}
+
+	return keys, nil
+}
+
+func (r *Repo) UpdateWrapping(ctx context.Context, key *encryption.DataKey) error {
+	collection := r.DB.Database(r.DatabaseName).Collection(CollectionName)
+
+	id, err := bson.ObjectIDFromHex(key.ID)
+	if err != nil {
+		return encryption.ErrDataKeyNotFound
+	}
+
+	result, err := collection.UpdateOne(
+		withoutTransaction(ctx),
+		bson.M{"_id": id},
+		bson.M{"$set": bson.M{
+			"wrapped_key":   key.WrappedKey,
+			"master_key_id": key.MasterKeyID,
+		}},
+	)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return encryption.ErrDataKeyNotFound
+	}
+
+	return nil
+}
+
+func (r *Repo) findOne(
+	ctx context.Context,
+	filter bson.M,
+	opts ...options.Lister[options.FindOneOptions],
+) (*encryption.DataKey, error) {
+	collection := r.DB.Database(r.DatabaseName).Collection(CollectionName)
+
+	var result dataKeyResultDB
+	err := collection.FindOne(ctx, filter, opts...).Decode(&result)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return toEntity(result), nil
+}
+
+// withoutTransaction drops the session from the context, so the operations
+// don't join its transaction.
+func withoutTransaction(ctx context.Context) context.Context {
+	return mongo.NewSessionContext(ctx, nil)
+}
+
+func toEntity(result dataKeyResultDB) *encryption.DataKey {
+	return &encryption.DataKey{
+		ID:          result.ID.Hex(),
+		PartnerID:   result.PartnerID,
+		Version:     result.Version,
+		WrappedKey:  result.WrappedKey,
+		MasterKeyID: result.MasterKeyID,
+		CreatedAt:   result.CreatedAt,
+	}
+}
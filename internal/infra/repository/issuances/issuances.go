@@ -0,0 +1,170 @@
+package issuances
+
+import (
+	"context"
+	"fmt"
+	"main-api/internal/domain/partners"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type (
+	Repo struct {
+		DatabaseName string
+		DB           *mongo.Client
+	}
+
+	issuanceResultDB struct {
+		ID          bson.ObjectID `bson:"_id"`
+		PartnerID   string        `bson:"partner_id"`
+		QuotationID string        `bson:"quotation_id"`
+		ProviderID  string        `bson:"provider_id,omitempty"`
+		PolicyID    string        `bson:"policy_id,omitempty"`
+		Status      string        `bson:"status"`
+		LastError   string        `bson:"last_error,omitempty"`
+		CreatedAt   time.Time     `bson:"created_at"`
+		ResolvedAt  time.Time     `bson:"resolved_at,omitempty"`
+	}
+)
+
+var (
+	CollectionName = "policy_issuances"
+)
+
+func NewRepo(db *mongo.Client, dbName string) *Repo {
+	return &Repo{
+		DatabaseName: dbName,
+		DB:           db,
+	}
+}
+
+func (r *Repo) Create(ctx context.Context, issuance *partners.PolicyIssuanceEntity) error {
+	collection := r.DB.Database(r.DatabaseName).Collection(CollectionName)
+
+	result, err := collection.InsertOne(ctx, map[string]interface{}{
+		"partner_id":   issuance.PartnerID,
+		"quotation_id": issuance.QuotationID.String(),
+		"status":       issuance.Status,
+		"created_at":   issuance.CreatedAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	objectID, ok := result.InsertedID.(bson.ObjectID)
+	if !ok {
+		return fmt.Errorf("error on convert inserted id to ObjectID")
+	}
+
+	issuance.ID = objectID.Hex()
+
+	return nil
+}
+
+func (r *Repo) Update(ctx context.Context, issuance *partners.PolicyIssuanceEntity) error {
+	collection := r.DB.Database(r.DatabaseName).Collection(CollectionName)
+
+	id, err := bson.ObjectIDFromHex(issuance.ID)
+	if err != nil {
+		return err
+	}
+
+	set := bson.M{
+		"status":     issuance.Status,
+		"last_error": issuance.LastError,
+		"policy_id":  issuance.PolicyID,
+	}
+	unset := bson.M{}
+
+	if issuance.ProviderID != uuid.Nil {
+		set["provider_id"] = issuance.ProviderID.String()
+	}
+
+	// Only the resolved issuances have resolved_at, which the retention index
+	// expires them by.
+	if issuance.ResolvedAt.IsZero() {
+		unset["resolved_at"] = ""
+	} else {
+		set["resolved_at"] = issuance.ResolvedAt
+	}
+
+	update := bson.M{"$set": set}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+
+	_, err = collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+
+	return err
+}
+
+func (r *Repo) ListPending(ctx context.Context, createdBefore time.Time, limit int) ([]partners.PolicyIssuanceEntity, error) {
+	collection := r.DB.Database(r.DatabaseName).Collection(CollectionName)
+
+	cursor, err := collection.Find(
+		ctx,
+		bson.M{
+			"status":     partners.PolicyIssuancePending,
+			"created_at": bson.M{"$lt": createdBefore},
+		},
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}).SetLimit(int64(limit)),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []issuanceResultDB
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	issuances := make([]partners.PolicyIssuanceEntity, len(results))
+	for index, result := range results {
+		issuances[index] = toEntity(result)
+	}
+
+	return issuances, nil
+}
+
+// CreateIndexes ensures the pending issuances can be found by age and makes
+// MongoDB delete the resolved ones after retention.
+func (r *Repo) CreateIndexes(ctx context.Context, retention time.Duration) error {
+	collection := r.DB.Database(r.DatabaseName).Collection(CollectionName)
+
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "status", Value: 1}, {Key: "created_at", Value: 1}},
+			Options: options.Index().SetName("status_created_at"),
+		},
+		{
+			Keys: bson.D{{Key: "resolved_at", Value: 1}},
+			Options: options.Index().
+				SetName("resolved_at_retention").
+				SetExpireAfterSeconds(int32(retention.Seconds())),
+		},
+	})
+
+	return err
+}
+
+func toEntity(result issuanceResultDB) partners.PolicyIssuanceEntity {
+	quotationID, _ := uuid.Parse(result.QuotationID)
+	providerID, _ := uuid.Parse(result.ProviderID)
+
+	return partners.PolicyIssuanceEntity{
+		ID:          result.ID.Hex(),
+		PartnerID:   result.PartnerID,
+		QuotationID: quotationID,
+		ProviderID:  providerID,
+		PolicyID:    result.PolicyID,
+		Status:      partners.PolicyIssuanceStatusEnum(result.Status),
+		LastError:   result.LastError,
+		CreatedAt:   result.CreatedAt,
+		ResolvedAt:  result.ResolvedAt,
+	}
+}
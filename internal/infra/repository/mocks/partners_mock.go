@@ -8,8 +8,10 @@ import (
 	context "context"
 	partners "main-api/internal/domain/partners"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
 )
 
 // MockPartnerRepository is a mock of PartnerRepository interface.
@@ -79,6 +81,20 @@ func (mr *MockPartnerRepositoryMockRecorder) GetByID(ctx, id interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockPartnerRepository)(nil).GetByID), ctx, id)
 }
 
+// Update mocks base method.
+func (m *MockPartnerRepository) Update(ctx context.Context, partner *partners.PartnerEntity) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, partner)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockPartnerRepositoryMockRecorder) Update(ctx, partner interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockPartnerRepository)(nil).Update), ctx, partner)
+}
+
 // MockQuotesRepository is a mock of QuotesRepository interface.
 type MockQuotesRepository struct {
 	ctrl     *gomock.Controller
@@ -116,6 +132,51 @@ func (mr *MockQuotesRepositoryMockRecorder) Create(ctx, quote interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockQuotesRepository)(nil).Create), ctx, quote)
 }
 
+// GetByProviderIDAndPartnerID mocks base method.
+func (m *MockQuotesRepository) GetByProviderIDAndPartnerID(ctx context.Context, providerID uuid.UUID, partnerID string) (*partners.QuoteEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByProviderIDAndPartnerID", ctx, providerID, partnerID)
+	ret0, _ := ret[0].(*partners.QuoteEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByProviderIDAndPartnerID indicates an expected call of GetByProviderIDAndPartnerID.
+func (mr *MockQuotesRepositoryMockRecorder) GetByProviderIDAndPartnerID(ctx, providerID, partnerID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByProviderIDAndPartnerID", reflect.TypeOf((*MockQuotesRepository)(nil).GetByProviderIDAndPartnerID), ctx, providerID, partnerID)
+}
+
+// GetReusable mocks base method.
+func (m *MockQuotesRepository) GetReusable(ctx context.Context, age uint, sex partners.SexEnum, partnerID string, validAt time.Time) (*partners.QuoteEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReusable", ctx, age, sex, partnerID, validAt)
+	ret0, _ := ret[0].(*partners.QuoteEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReusable indicates an expected call of GetReusable.
+func (mr *MockQuotesRepositoryMockRecorder) GetReusable(ctx, age, sex, partnerID, validAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReusable", reflect.TypeOf((*MockQuotesRepository)(nil).GetReusable), ctx, age, sex, partnerID, validAt)
+}
+
+// MarkExpired mocks base method.
+func (m *MockQuotesRepository) MarkExpired(ctx context.Context, now time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkExpired", ctx, now)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkExpired indicates an expected call of MarkExpired.
+func (mr *MockQuotesRepositoryMockRecorder) MarkExpired(ctx, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkExpired", reflect.TypeOf((*MockQuotesRepository)(nil).MarkExpired), ctx, now)
+}
+
 // MockPoliciesRepository is a mock of PoliciesRepository interface.
 type MockPoliciesRepository struct {
 	ctrl     *gomock.Controller
@@ -153,6 +214,20 @@ func (mr *MockPoliciesRepositoryMockRecorder) Create(ctx, policy interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockPoliciesRepository)(nil).Create), ctx, policy)
 }
 
+// Erase mocks base method.
+func (m *MockPoliciesRepository) Erase(ctx context.Context, policy *partners.PolicyEntity) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Erase", ctx, policy)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Erase indicates an expected call of Erase.
+func (mr *MockPoliciesRepositoryMockRecorder) Erase(ctx, policy interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Erase", reflect.TypeOf((*MockPoliciesRepository)(nil).Erase), ctx, policy)
+}
+
 // GetByIdAndPartnerID mocks base method.
 func (m *MockPoliciesRepository) GetByIdAndPartnerID(ctx context.Context, policyID, partnerID string) (*partners.PolicyEntity, error) {
 	m.ctrl.T.Helper()
@@ -168,70 +243,1014 @@ func (mr *MockPoliciesRepositoryMockRecorder) GetByIdAndPartnerID(ctx, policyID,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByIdAndPartnerID", reflect.TypeOf((*MockPoliciesRepository)(nil).GetByIdAndPartnerID), ctx, policyID, partnerID)
 }
 
-// MockInsuranceProvider is a mock of InsuranceProvider interface.
-type MockInsuranceProvider struct {
+// GetByProviderID mocks base method.
+func (m *MockPoliciesRepository) GetByProviderID(ctx context.Context, providerID uuid.UUID) (*partners.PolicyEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByProviderID", ctx, providerID)
+	ret0, _ := ret[0].(*partners.PolicyEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByProviderID indicates an expected call of GetByProviderID.
+func (mr *MockPoliciesRepositoryMockRecorder) GetByProviderID(ctx, providerID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByProviderID", reflect.TypeOf((*MockPoliciesRepository)(nil).GetByProviderID), ctx, providerID)
+}
+
+// ListAfter mocks base method.
+func (m *MockPoliciesRepository) ListAfter(ctx context.Context, afterID string, limit int) ([]partners.PolicyEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAfter", ctx, afterID, limit)
+	ret0, _ := ret[0].([]partners.PolicyEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAfter indicates an expected call of ListAfter.
+func (mr *MockPoliciesRepositoryMockRecorder) ListAfter(ctx, afterID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAfter", reflect.TypeOf((*MockPoliciesRepository)(nil).ListAfter), ctx, afterID, limit)
+}
+
+// ListByHolder mocks base method.
+func (m *MockPoliciesRepository) ListByHolder(ctx context.Context, customerIDs []string, cpf string) ([]partners.PolicyEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByHolder", ctx, customerIDs, cpf)
+	ret0, _ := ret[0].([]partners.PolicyEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByHolder indicates an expected call of ListByHolder.
+func (mr *MockPoliciesRepositoryMockRecorder) ListByHolder(ctx, customerIDs, cpf interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByHolder", reflect.TypeOf((*MockPoliciesRepository)(nil).ListByHolder), ctx, customerIDs, cpf)
+}
+
+// ListByPartnerIDAndCpf mocks base method.
+func (m *MockPoliciesRepository) ListByPartnerIDAndCpf(ctx context.Context, partnerID, cpf string) ([]partners.PolicyEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByPartnerIDAndCpf", ctx, partnerID, cpf)
+	ret0, _ := ret[0].([]partners.PolicyEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByPartnerIDAndCpf indicates an expected call of ListByPartnerIDAndCpf.
+func (mr *MockPoliciesRepositoryMockRecorder) ListByPartnerIDAndCpf(ctx, partnerID, cpf interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByPartnerIDAndCpf", reflect.TypeOf((*MockPoliciesRepository)(nil).ListByPartnerIDAndCpf), ctx, partnerID, cpf)
+}
+
+// ListByPartnerIDAndHolder mocks base method.
+func (m *MockPoliciesRepository) ListByPartnerIDAndHolder(ctx context.Context, partnerID, customerID, cpf string) ([]partners.PolicyEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByPartnerIDAndHolder", ctx, partnerID, customerID, cpf)
+	ret0, _ := ret[0].([]partners.PolicyEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByPartnerIDAndHolder indicates an expected call of ListByPartnerIDAndHolder.
+func (mr *MockPoliciesRepositoryMockRecorder) ListByPartnerIDAndHolder(ctx, partnerID, customerID, cpf interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByPartnerIDAndHolder", reflect.TypeOf((*MockPoliciesRepository)(nil).ListByPartnerIDAndHolder), ctx, partnerID, customerID, cpf)
+}
+
+// UpdateProviderData mocks base method.
+func (m *MockPoliciesRepository) UpdateProviderData(ctx context.Context, policy *partners.PolicyEntity) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProviderData", ctx, policy)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateProviderData indicates an expected call of UpdateProviderData.
+func (mr *MockPoliciesRepositoryMockRecorder) UpdateProviderData(ctx, policy interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProviderData", reflect.TypeOf((*MockPoliciesRepository)(nil).UpdateProviderData), ctx, policy)
+}
+
+// UpdateStatus mocks base method.
+func (m *MockPoliciesRepository) UpdateStatus(ctx context.Context, policy *partners.PolicyEntity) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateStatus", ctx, policy)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateStatus indicates an expected call of UpdateStatus.
+func (mr *MockPoliciesRepositoryMockRecorder) UpdateStatus(ctx, policy interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatus", reflect.TypeOf((*MockPoliciesRepository)(nil).UpdateStatus), ctx, policy)
+}
+
+// MockPolicyIssuancesRepository is a mock of PolicyIssuancesRepository interface.
+type MockPolicyIssuancesRepository struct {
 	ctrl     *gomock.Controller
-	recorder *MockInsuranceProviderMockRecorder
+	recorder *MockPolicyIssuancesRepositoryMockRecorder
 }
 
-// MockInsuranceProviderMockRecorder is the mock recorder for MockInsuranceProvider.
-type MockInsuranceProviderMockRecorder struct {
-	mock *MockInsuranceProvider
+// MockPolicyIssuancesRepositoryMockRecorder is the mock recorder for MockPolicyIssuancesRepository.
+type MockPolicyIssuancesRepositoryMockRecorder struct {
+	mock *MockPolicyIssuancesRepository
 }
 
-// NewMockInsuranceProvider creates a new mock instance.
-func NewMockInsuranceProvider(ctrl *gomock.Controller) *MockInsuranceProvider {
-	mock := &MockInsuranceProvider{ctrl: ctrl}
-	mock.recorder = &MockInsuranceProviderMockRecorder{mock}
+// NewMockPolicyIssuancesRepository creates a new mock instance.
+func NewMockPolicyIssuancesRepository(ctrl *gomock.Controller) *MockPolicyIssuancesRepository {
+	mock := &MockPolicyIssuancesRepository{ctrl: ctrl}
+	mock.recorder = &MockPolicyIssuancesRepositoryMockRecorder{mock}
 	return mock
 }
 
 // EXPECT returns an object that allows the caller to indicate expected use.
-func (m *MockInsuranceProvider) EXPECT() *MockInsuranceProviderMockRecorder {
+func (m *MockPolicyIssuancesRepository) EXPECT() *MockPolicyIssuancesRepositoryMockRecorder {
 	return m.recorder
 }
 
-// CreatePolicy mocks base method.
-func (m *MockInsuranceProvider) CreatePolicy(ctx context.Context, data partners.InsuranceProviderCreatePolicyRequest) (*partners.InsuranceProviderCreatePolicyResponse, error) {
+// Create mocks base method.
+func (m *MockPolicyIssuancesRepository) Create(ctx context.Context, issuance *partners.PolicyIssuanceEntity) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreatePolicy", ctx, data)
-	ret0, _ := ret[0].(*partners.InsuranceProviderCreatePolicyResponse)
+	ret := m.ctrl.Call(m, "Create", ctx, issuance)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockPolicyIssuancesRepositoryMockRecorder) Create(ctx, issuance interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockPolicyIssuancesRepository)(nil).Create), ctx, issuance)
+}
+
+// ListPending mocks base method.
+func (m *MockPolicyIssuancesRepository) ListPending(ctx context.Context, createdBefore time.Time, limit int) ([]partners.PolicyIssuanceEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPending", ctx, createdBefore, limit)
+	ret0, _ := ret[0].([]partners.PolicyIssuanceEntity)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreatePolicy indicates an expected call of CreatePolicy.
-func (mr *MockInsuranceProviderMockRecorder) CreatePolicy(ctx, data interface{}) *gomock.Call {
+// ListPending indicates an expected call of ListPending.
+func (mr *MockPolicyIssuancesRepositoryMockRecorder) ListPending(ctx, createdBefore, limit interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePolicy", reflect.TypeOf((*MockInsuranceProvider)(nil).CreatePolicy), ctx, data)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPending", reflect.TypeOf((*MockPolicyIssuancesRepository)(nil).ListPending), ctx, createdBefore, limit)
 }
 
-// CreateQuotation mocks base method.
-func (m *MockInsuranceProvider) CreateQuotation(ctx context.Context, data partners.InsuranceProviderCreateQuotationRequest) (*partners.InsuranceProviderCreateQuotationResponse, error) {
+// Update mocks base method.
+func (m *MockPolicyIssuancesRepository) Update(ctx context.Context, issuance *partners.PolicyIssuanceEntity) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateQuotation", ctx, data)
-	ret0, _ := ret[0].(*partners.InsuranceProviderCreateQuotationResponse)
+	ret := m.ctrl.Call(m, "Update", ctx, issuance)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockPolicyIssuancesRepositoryMockRecorder) Update(ctx, issuance interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockPolicyIssuancesRepository)(nil).Update), ctx, issuance)
+}
+
+// MockPolicyMismatchesRepository is a mock of PolicyMismatchesRepository interface.
+type MockPolicyMismatchesRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockPolicyMismatchesRepositoryMockRecorder
+}
+
+// MockPolicyMismatchesRepositoryMockRecorder is the mock recorder for MockPolicyMismatchesRepository.
+type MockPolicyMismatchesRepositoryMockRecorder struct {
+	mock *MockPolicyMismatchesRepository
+}
+
+// NewMockPolicyMismatchesRepository creates a new mock instance.
+func NewMockPolicyMismatchesRepository(ctrl *gomock.Controller) *MockPolicyMismatchesRepository {
+	mock := &MockPolicyMismatchesRepository{ctrl: ctrl}
+	mock.recorder = &MockPolicyMismatchesRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPolicyMismatchesRepository) EXPECT() *MockPolicyMismatchesRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockPolicyMismatchesRepository) Create(ctx context.Context, mismatches ...partners.PolicyMismatchEntity) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx}
+	for _, a := range mismatches {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Create", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockPolicyMismatchesRepositoryMockRecorder) Create(ctx interface{}, mismatches ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx}, mismatches...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockPolicyMismatchesRepository)(nil).Create), varargs...)
+}
+
+// MockProviderEventsRepository is a mock of ProviderEventsRepository interface.
+type MockProviderEventsRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockProviderEventsRepositoryMockRecorder
+}
+
+// MockProviderEventsRepositoryMockRecorder is the mock recorder for MockProviderEventsRepository.
+type MockProviderEventsRepositoryMockRecorder struct {
+	mock *MockProviderEventsRepository
+}
+
+// NewMockProviderEventsRepository creates a new mock instance.
+func NewMockProviderEventsRepository(ctrl *gomock.Controller) *MockProviderEventsRepository {
+	mock := &MockProviderEventsRepository{ctrl: ctrl}
+	mock.recorder = &MockProviderEventsRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockProviderEventsRepository) EXPECT() *MockProviderEventsRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Add mocks base method.
+func (m *MockProviderEventsRepository) Add(ctx context.Context, eventID string, receivedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Add", ctx, eventID, receivedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Add indicates an expected call of Add.
+func (mr *MockProviderEventsRepositoryMockRecorder) Add(ctx, eventID, receivedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*MockProviderEventsRepository)(nil).Add), ctx, eventID, receivedAt)
+}
+
+// MockCustomersRepository is a mock of CustomersRepository interface.
+type MockCustomersRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockCustomersRepositoryMockRecorder
+}
+
+// MockCustomersRepositoryMockRecorder is the mock recorder for MockCustomersRepository.
+type MockCustomersRepositoryMockRecorder struct {
+	mock *MockCustomersRepository
+}
+
+// NewMockCustomersRepository creates a new mock instance.
+func NewMockCustomersRepository(ctrl *gomock.Controller) *MockCustomersRepository {
+	mock := &MockCustomersRepository{ctrl: ctrl}
+	mock.recorder = &MockCustomersRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCustomersRepository) EXPECT() *MockCustomersRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockCustomersRepository) Create(ctx context.Context, customer *partners.CustomerEntity) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, customer)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockCustomersRepositoryMockRecorder) Create(ctx, customer interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockCustomersRepository)(nil).Create), ctx, customer)
+}
+
+// Delete mocks base method.
+func (m *MockCustomersRepository) Delete(ctx context.Context, customerID, partnerID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, customerID, partnerID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockCustomersRepositoryMockRecorder) Delete(ctx, customerID, partnerID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockCustomersRepository)(nil).Delete), ctx, customerID, partnerID)
+}
+
+// Erase mocks base method.
+func (m *MockCustomersRepository) Erase(ctx context.Context, customer *partners.CustomerEntity) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Erase", ctx, customer)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Erase indicates an expected call of Erase.
+func (mr *MockCustomersRepositoryMockRecorder) Erase(ctx, customer interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Erase", reflect.TypeOf((*MockCustomersRepository)(nil).Erase), ctx, customer)
+}
+
+// GetByCpfAndPartnerID mocks base method.
+func (m *MockCustomersRepository) GetByCpfAndPartnerID(ctx context.Context, cpf, partnerID string) (*partners.CustomerEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByCpfAndPartnerID", ctx, cpf, partnerID)
+	ret0, _ := ret[0].(*partners.CustomerEntity)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreateQuotation indicates an expected call of CreateQuotation.
-func (mr *MockInsuranceProviderMockRecorder) CreateQuotation(ctx, data interface{}) *gomock.Call {
+// GetByCpfAndPartnerID indicates an expected call of GetByCpfAndPartnerID.
+func (mr *MockCustomersRepositoryMockRecorder) GetByCpfAndPartnerID(ctx, cpf, partnerID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateQuotation", reflect.TypeOf((*MockInsuranceProvider)(nil).CreateQuotation), ctx, data)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByCpfAndPartnerID", reflect.TypeOf((*MockCustomersRepository)(nil).GetByCpfAndPartnerID), ctx, cpf, partnerID)
 }
 
-// GetPolicy mocks base method.
-func (m *MockInsuranceProvider) GetPolicy(ctx context.Context, policyID string) (*partners.InsuranceProviderCreatePolicyResponse, error) {
+// GetByIDAndPartnerID mocks base method.
+func (m *MockCustomersRepository) GetByIDAndPartnerID(ctx context.Context, customerID, partnerID string) (*partners.CustomerEntity, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetPolicy", ctx, policyID)
-	ret0, _ := ret[0].(*partners.InsuranceProviderCreatePolicyResponse)
+	ret := m.ctrl.Call(m, "GetByIDAndPartnerID", ctx, customerID, partnerID)
+	ret0, _ := ret[0].(*partners.CustomerEntity)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetPolicy indicates an expected call of GetPolicy.
-func (mr *MockInsuranceProviderMockRecorder) GetPolicy(ctx, policyID interface{}) *gomock.Call {
+// GetByIDAndPartnerID indicates an expected call of GetByIDAndPartnerID.
+func (mr *MockCustomersRepositoryMockRecorder) GetByIDAndPartnerID(ctx, customerID, partnerID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPolicy", reflect.TypeOf((*MockInsuranceProvider)(nil).GetPolicy), ctx, policyID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByIDAndPartnerID", reflect.TypeOf((*MockCustomersRepository)(nil).GetByIDAndPartnerID), ctx, customerID, partnerID)
+}
+
+// ListByCpf mocks base method.
+func (m *MockCustomersRepository) ListByCpf(ctx context.Context, cpf string) ([]partners.CustomerEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByCpf", ctx, cpf)
+	ret0, _ := ret[0].([]partners.CustomerEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByCpf indicates an expected call of ListByCpf.
+func (mr *MockCustomersRepositoryMockRecorder) ListByCpf(ctx, cpf interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByCpf", reflect.TypeOf((*MockCustomersRepository)(nil).ListByCpf), ctx, cpf)
+}
+
+// ListByPartnerID mocks base method.
+func (m *MockCustomersRepository) ListByPartnerID(ctx context.Context, partnerID string) ([]partners.CustomerEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByPartnerID", ctx, partnerID)
+	ret0, _ := ret[0].([]partners.CustomerEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByPartnerID indicates an expected call of ListByPartnerID.
+func (mr *MockCustomersRepositoryMockRecorder) ListByPartnerID(ctx, partnerID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByPartnerID", reflect.TypeOf((*MockCustomersRepository)(nil).ListByPartnerID), ctx, partnerID)
+}
+
+// Update mocks base method.
+func (m *MockCustomersRepository) Update(ctx context.Context, customer *partners.CustomerEntity) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, customer)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockCustomersRepositoryMockRecorder) Update(ctx, customer interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockCustomersRepository)(nil).Update), ctx, customer)
+}
+
+// MockWebhooksRepository is a mock of WebhooksRepository interface.
+type MockWebhooksRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebhooksRepositoryMockRecorder
+}
+
+// MockWebhooksRepositoryMockRecorder is the mock recorder for MockWebhooksRepository.
+type MockWebhooksRepositoryMockRecorder struct {
+	mock *MockWebhooksRepository
+}
+
+// NewMockWebhooksRepository creates a new mock instance.
+func NewMockWebhooksRepository(ctrl *gomock.Controller) *MockWebhooksRepository {
+	mock := &MockWebhooksRepository{ctrl: ctrl}
+	mock.recorder = &MockWebhooksRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWebhooksRepository) EXPECT() *MockWebhooksRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockWebhooksRepository) Create(ctx context.Context, webhook *partners.WebhookEntity) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, webhook)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockWebhooksRepositoryMockRecorder) Create(ctx, webhook interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockWebhooksRepository)(nil).Create), ctx, webhook)
+}
+
+// Delete mocks base method.
+func (m *MockWebhooksRepository) Delete(ctx context.Context, partnerID, webhookID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, partnerID, webhookID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockWebhooksRepositoryMockRecorder) Delete(ctx, partnerID, webhookID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockWebhooksRepository)(nil).Delete), ctx, partnerID, webhookID)
+}
+
+// GetSecret mocks base method.
+func (m *MockWebhooksRepository) GetSecret(ctx context.Context, partnerID string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSecret", ctx, partnerID)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSecret indicates an expected call of GetSecret.
+func (mr *MockWebhooksRepositoryMockRecorder) GetSecret(ctx, partnerID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSecret", reflect.TypeOf((*MockWebhooksRepository)(nil).GetSecret), ctx, partnerID)
+}
+
+// ListByPartnerID mocks base method.
+func (m *MockWebhooksRepository) ListByPartnerID(ctx context.Context, partnerID string) ([]partners.WebhookEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByPartnerID", ctx, partnerID)
+	ret0, _ := ret[0].([]partners.WebhookEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByPartnerID indicates an expected call of ListByPartnerID.
+func (mr *MockWebhooksRepositoryMockRecorder) ListByPartnerID(ctx, partnerID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByPartnerID", reflect.TypeOf((*MockWebhooksRepository)(nil).ListByPartnerID), ctx, partnerID)
+}
+
+// SetSecret mocks base method.
+func (m *MockWebhooksRepository) SetSecret(ctx context.Context, partnerID, secret string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetSecret", ctx, partnerID, secret)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetSecret indicates an expected call of SetSecret.
+func (mr *MockWebhooksRepositoryMockRecorder) SetSecret(ctx, partnerID, secret interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSecret", reflect.TypeOf((*MockWebhooksRepository)(nil).SetSecret), ctx, partnerID, secret)
+}
+
+// MockWebhookDeliveriesRepository is a mock of WebhookDeliveriesRepository interface.
+type MockWebhookDeliveriesRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebhookDeliveriesRepositoryMockRecorder
+}
+
+// MockWebhookDeliveriesRepositoryMockRecorder is the mock recorder for MockWebhookDeliveriesRepository.
+type MockWebhookDeliveriesRepositoryMockRecorder struct {
+	mock *MockWebhookDeliveriesRepository
+}
+
+// NewMockWebhookDeliveriesRepository creates a new mock instance.
+func NewMockWebhookDeliveriesRepository(ctrl *gomock.Controller) *MockWebhookDeliveriesRepository {
+	mock := &MockWebhookDeliveriesRepository{ctrl: ctrl}
+	mock.recorder = &MockWebhookDeliveriesRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWebhookDeliveriesRepository) EXPECT() *MockWebhookDeliveriesRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockWebhookDeliveriesRepository) Create(ctx context.Context, deliveries ...partners.WebhookDeliveryEntity) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx}
+	for _, a := range deliveries {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Create", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockWebhookDeliveriesRepositoryMockRecorder) Create(ctx interface{}, deliveries ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx}, deliveries...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockWebhookDeliveriesRepository)(nil).Create), varargs...)
+}
+
+// DeleteByEntityIDs mocks base method.
+func (m *MockWebhookDeliveriesRepository) DeleteByEntityIDs(ctx context.Context, entityIDs ...string) (int64, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx}
+	for _, a := range entityIDs {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DeleteByEntityIDs", varargs...)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteByEntityIDs indicates an expected call of DeleteByEntityIDs.
+func (mr *MockWebhookDeliveriesRepositoryMockRecorder) DeleteByEntityIDs(ctx interface{}, entityIDs ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx}, entityIDs...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByEntityIDs", reflect.TypeOf((*MockWebhookDeliveriesRepository)(nil).DeleteByEntityIDs), varargs...)
+}
+
+// GetByIDAndPartnerID mocks base method.
+func (m *MockWebhookDeliveriesRepository) GetByIDAndPartnerID(ctx context.Context, deliveryID, partnerID string) (*partners.WebhookDeliveryEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByIDAndPartnerID", ctx, deliveryID, partnerID)
+	ret0, _ := ret[0].(*partners.WebhookDeliveryEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByIDAndPartnerID indicates an expected call of GetByIDAndPartnerID.
+func (mr *MockWebhookDeliveriesRepositoryMockRecorder) GetByIDAndPartnerID(ctx, deliveryID, partnerID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByIDAndPartnerID", reflect.TypeOf((*MockWebhookDeliveriesRepository)(nil).GetByIDAndPartnerID), ctx, deliveryID, partnerID)
+}
+
+// ListByPartnerID mocks base method.
+func (m *MockWebhookDeliveriesRepository) ListByPartnerID(ctx context.Context, partnerID string, status partners.WebhookDeliveryStatusEnum, limit int) ([]partners.WebhookDeliveryEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByPartnerID", ctx, partnerID, status, limit)
+	ret0, _ := ret[0].([]partners.WebhookDeliveryEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByPartnerID indicates an expected call of ListByPartnerID.
+func (mr *MockWebhookDeliveriesRepositoryMockRecorder) ListByPartnerID(ctx, partnerID, status, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByPartnerID", reflect.TypeOf((*MockWebhookDeliveriesRepository)(nil).ListByPartnerID), ctx, partnerID, status, limit)
+}
+
+// ListDue mocks base method.
+func (m *MockWebhookDeliveriesRepository) ListDue(ctx context.Context, now time.Time, limit int) ([]partners.WebhookDeliveryEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDue", ctx, now, limit)
+	ret0, _ := ret[0].([]partners.WebhookDeliveryEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDue indicates an expected call of ListDue.
+func (mr *MockWebhookDeliveriesRepositoryMockRecorder) ListDue(ctx, now, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDue", reflect.TypeOf((*MockWebhookDeliveriesRepository)(nil).ListDue), ctx, now, limit)
+}
+
+// Update mocks base method.
+func (m *MockWebhookDeliveriesRepository) Update(ctx context.Context, delivery *partners.WebhookDeliveryEntity) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, delivery)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockWebhookDeliveriesRepositoryMockRecorder) Update(ctx, delivery interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockWebhookDeliveriesRepository)(nil).Update), ctx, delivery)
+}
+
+// MockWebhookSender is a mock of WebhookSender interface.
+type MockWebhookSender struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebhookSenderMockRecorder
+}
+
+// MockWebhookSenderMockRecorder is the mock recorder for MockWebhookSender.
+type MockWebhookSenderMockRecorder struct {
+	mock *MockWebhookSender
+}
+
+// NewMockWebhookSender creates a new mock instance.
+func NewMockWebhookSender(ctrl *gomock.Controller) *MockWebhookSender {
+	mock := &MockWebhookSender{ctrl: ctrl}
+	mock.recorder = &MockWebhookSenderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWebhookSender) EXPECT() *MockWebhookSenderMockRecorder {
+	return m.recorder
+}
+
+// CheckURL mocks base method.
+func (m *MockWebhookSender) CheckURL(ctx context.Context, url string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckURL", ctx, url)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckURL indicates an expected call of CheckURL.
+func (mr *MockWebhookSenderMockRecorder) CheckURL(ctx, url interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckURL", reflect.TypeOf((*MockWebhookSender)(nil).CheckURL), ctx, url)
+}
+
+// Send mocks base method.
+func (m *MockWebhookSender) Send(ctx context.Context, request partners.WebhookRequest) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Send", ctx, request)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Send indicates an expected call of Send.
+func (mr *MockWebhookSenderMockRecorder) Send(ctx, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockWebhookSender)(nil).Send), ctx, request)
+}
+
+// MockOutboxRepository is a mock of OutboxRepository interface.
+type MockOutboxRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockOutboxRepositoryMockRecorder
+}
+
+// MockOutboxRepositoryMockRecorder is the mock recorder for MockOutboxRepository.
+type MockOutboxRepositoryMockRecorder struct {
+	mock *MockOutboxRepository
+}
+
+// NewMockOutboxRepository creates a new mock instance.
+func NewMockOutboxRepository(ctrl *gomock.Controller) *MockOutboxRepository {
+	mock := &MockOutboxRepository{ctrl: ctrl}
+	mock.recorder = &MockOutboxRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOutboxRepository) EXPECT() *MockOutboxRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Add mocks base method.
+func (m *MockOutboxRepository) Add(ctx context.Context, events ...partners.Event) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx}
+	for _, a := range events {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Add", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Add indicates an expected call of Add.
+func (mr *MockOutboxRepositoryMockRecorder) Add(ctx interface{}, events ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx}, events...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*MockOutboxRepository)(nil).Add), varargs...)
+}
+
+// MockAuditLogRepository is a mock of AuditLogRepository interface.
+type MockAuditLogRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditLogRepositoryMockRecorder
+}
+
+// MockAuditLogRepositoryMockRecorder is the mock recorder for MockAuditLogRepository.
+type MockAuditLogRepositoryMockRecorder struct {
+	mock *MockAuditLogRepository
+}
+
+// NewMockAuditLogRepository creates a new mock instance.
+func NewMockAuditLogRepository(ctrl *gomock.Controller) *MockAuditLogRepository {
+	mock := &MockAuditLogRepository{ctrl: ctrl}
+	mock.recorder = &MockAuditLogRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuditLogRepository) EXPECT() *MockAuditLogRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Append mocks base method.
+func (m *MockAuditLogRepository) Append(ctx context.Context, events ...partners.AuditEventEntity) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx}
+	for _, a := range events {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Append", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Append indicates an expected call of Append.
+func (mr *MockAuditLogRepositoryMockRecorder) Append(ctx interface{}, events ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx}, events...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Append", reflect.TypeOf((*MockAuditLogRepository)(nil).Append), varargs...)
+}
+
+// EraseChanges mocks base method.
+func (m *MockAuditLogRepository) EraseChanges(ctx context.Context, entityIDs ...string) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx}
+	for _, a := range entityIDs {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "EraseChanges", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EraseChanges indicates an expected call of EraseChanges.
+func (mr *MockAuditLogRepositoryMockRecorder) EraseChanges(ctx interface{}, entityIDs ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx}, entityIDs...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EraseChanges", reflect.TypeOf((*MockAuditLogRepository)(nil).EraseChanges), varargs...)
+}
+
+// Head mocks base method.
+func (m *MockAuditLogRepository) Head(ctx context.Context) (partners.AuditChainHead, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Head", ctx)
+	ret0, _ := ret[0].(partners.AuditChainHead)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Head indicates an expected call of Head.
+func (mr *MockAuditLogRepositoryMockRecorder) Head(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Head", reflect.TypeOf((*MockAuditLogRepository)(nil).Head), ctx)
+}
+
+// List mocks base method.
+func (m *MockAuditLogRepository) List(ctx context.Context, filter partners.AuditEventFilter) ([]partners.AuditEventEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, filter)
+	ret0, _ := ret[0].([]partners.AuditEventEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockAuditLogRepositoryMockRecorder) List(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockAuditLogRepository)(nil).List), ctx, filter)
+}
+
+// MockTransactor is a mock of Transactor interface.
+type MockTransactor struct {
+	ctrl     *gomock.Controller
+	recorder *MockTransactorMockRecorder
+}
+
+// MockTransactorMockRecorder is the mock recorder for MockTransactor.
+type MockTransactorMockRecorder struct {
+	mock *MockTransactor
+}
+
+// NewMockTransactor creates a new mock instance.
+func NewMockTransactor(ctrl *gomock.Controller) *MockTransactor {
+	mock := &MockTransactor{ctrl: ctrl}
+	mock.recorder = &MockTransactorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTransactor) EXPECT() *MockTransactorMockRecorder {
+	return m.recorder
+}
+
+// WithTransaction mocks base method.
+func (m *MockTransactor) WithTransaction(ctx context.Context, fn func(context.Context) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithTransaction", ctx, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WithTransaction indicates an expected call of WithTransaction.
+func (mr *MockTransactorMockRecorder) WithTransaction(ctx, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithTransaction", reflect.TypeOf((*MockTransactor)(nil).WithTransaction), ctx, fn)
+}
+
+// MockInsuranceProvider is a mock of InsuranceProvider interface.
+type MockInsuranceProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockInsuranceProviderMockRecorder
+}
+
+// MockInsuranceProviderMockRecorder is the mock recorder for MockInsuranceProvider.
+type MockInsuranceProviderMockRecorder struct {
+	mock *MockInsuranceProvider
+}
+
+// NewMockInsuranceProvider creates a new mock instance.
+func NewMockInsuranceProvider(ctrl *gomock.Controller) *MockInsuranceProvider {
+	mock := &MockInsuranceProvider{ctrl: ctrl}
+	mock.recorder = &MockInsuranceProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockInsuranceProvider) EXPECT() *MockInsuranceProviderMockRecorder {
+	return m.recorder
+}
+
+// CancelPolicy mocks base method.
+func (m *MockInsuranceProvider) CancelPolicy(ctx context.Context, policyID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelPolicy", ctx, policyID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelPolicy indicates an expected call of CancelPolicy.
+func (mr *MockInsuranceProviderMockRecorder) CancelPolicy(ctx, policyID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelPolicy", reflect.TypeOf((*MockInsuranceProvider)(nil).CancelPolicy), ctx, policyID)
+}
+
+// CreatePolicy mocks base method.
+func (m *MockInsuranceProvider) CreatePolicy(ctx context.Context, data partners.InsuranceProviderCreatePolicyRequest) (*partners.InsuranceProviderCreatePolicyResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePolicy", ctx, data)
+	ret0, _ := ret[0].(*partners.InsuranceProviderCreatePolicyResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePolicy indicates an expected call of CreatePolicy.
+func (mr *MockInsuranceProviderMockRecorder) CreatePolicy(ctx, data interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePolicy", reflect.TypeOf((*MockInsuranceProvider)(nil).CreatePolicy), ctx, data)
+}
+
+// CreateQuotation mocks base method.
+func (m *MockInsuranceProvider) CreateQuotation(ctx context.Context, data partners.InsuranceProviderCreateQuotationRequest) (*partners.InsuranceProviderCreateQuotationResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateQuotation", ctx, data)
+	ret0, _ := ret[0].(*partners.InsuranceProviderCreateQuotationResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateQuotation indicates an expected call of CreateQuotation.
+func (mr *MockInsuranceProviderMockRecorder) CreateQuotation(ctx, data interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateQuotation", reflect.TypeOf((*MockInsuranceProvider)(nil).CreateQuotation), ctx, data)
+}
+
+// FindPolicyByReference mocks base method.
+func (m *MockInsuranceProvider) FindPolicyByReference(ctx context.Context, reference string) (*partners.InsuranceProviderCreatePolicyResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindPolicyByReference", ctx, reference)
+	ret0, _ := ret[0].(*partners.InsuranceProviderCreatePolicyResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindPolicyByReference indicates an expected call of FindPolicyByReference.
+func (mr *MockInsuranceProviderMockRecorder) FindPolicyByReference(ctx, reference interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPolicyByReference", reflect.TypeOf((*MockInsuranceProvider)(nil).FindPolicyByReference), ctx, reference)
+}
+
+// GetPolicy mocks base method.
+func (m *MockInsuranceProvider) GetPolicy(ctx context.Context, policyID string) (*partners.InsuranceProviderCreatePolicyResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPolicy", ctx, policyID)
+	ret0, _ := ret[0].(*partners.InsuranceProviderCreatePolicyResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPolicy indicates an expected call of GetPolicy.
+func (mr *MockInsuranceProviderMockRecorder) GetPolicy(ctx, policyID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPolicy", reflect.TypeOf((*MockInsuranceProvider)(nil).GetPolicy), ctx, policyID)
+}
+
+// QuoteReusePolicy mocks base method.
+func (m *MockInsuranceProvider) QuoteReusePolicy() partners.QuoteReusePolicy {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QuoteReusePolicy")
+	ret0, _ := ret[0].(partners.QuoteReusePolicy)
+	return ret0
+}
+
+// QuoteReusePolicy indicates an expected call of QuoteReusePolicy.
+func (mr *MockInsuranceProviderMockRecorder) QuoteReusePolicy() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QuoteReusePolicy", reflect.TypeOf((*MockInsuranceProvider)(nil).QuoteReusePolicy))
+}
+
+// MockPolicyCacheInvalidator is a mock of PolicyCacheInvalidator interface.
+type MockPolicyCacheInvalidator struct {
+	ctrl     *gomock.Controller
+	recorder *MockPolicyCacheInvalidatorMockRecorder
+}
+
+// MockPolicyCacheInvalidatorMockRecorder is the mock recorder for MockPolicyCacheInvalidator.
+type MockPolicyCacheInvalidatorMockRecorder struct {
+	mock *MockPolicyCacheInvalidator
+}
+
+// NewMockPolicyCacheInvalidator creates a new mock instance.
+func NewMockPolicyCacheInvalidator(ctrl *gomock.Controller) *MockPolicyCacheInvalidator {
+	mock := &MockPolicyCacheInvalidator{ctrl: ctrl}
+	mock.recorder = &MockPolicyCacheInvalidatorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPolicyCacheInvalidator) EXPECT() *MockPolicyCacheInvalidatorMockRecorder {
+	return m.recorder
+}
+
+// InvalidatePolicy mocks base method.
+func (m *MockPolicyCacheInvalidator) InvalidatePolicy(ctx context.Context, policyID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InvalidatePolicy", ctx, policyID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InvalidatePolicy indicates an expected call of InvalidatePolicy.
+func (mr *MockPolicyCacheInvalidatorMockRecorder) InvalidatePolicy(ctx, policyID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InvalidatePolicy", reflect.TypeOf((*MockPolicyCacheInvalidator)(nil).InvalidatePolicy), ctx, policyID)
+}
+
+// MockFreshPolicyGetter is a mock of FreshPolicyGetter interface.
+type MockFreshPolicyGetter struct {
+	ctrl     *gomock.Controller
+	recorder *MockFreshPolicyGetterMockRecorder
+}
+
+// MockFreshPolicyGetterMockRecorder is the mock recorder for MockFreshPolicyGetter.
+type MockFreshPolicyGetterMockRecorder struct {
+	mock *MockFreshPolicyGetter
+}
+
+// NewMockFreshPolicyGetter creates a new mock instance.
+func NewMockFreshPolicyGetter(ctrl *gomock.Controller) *MockFreshPolicyGetter {
+	mock := &MockFreshPolicyGetter{ctrl: ctrl}
+	mock.recorder = &MockFreshPolicyGetterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFreshPolicyGetter) EXPECT() *MockFreshPolicyGetterMockRecorder {
+	return m.recorder
+}
+
+// GetFreshPolicy mocks base method.
+func (m *MockFreshPolicyGetter) GetFreshPolicy(ctx context.Context, policyID string) (*partners.InsuranceProviderCreatePolicyResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFreshPolicy", ctx, policyID)
+	ret0, _ := ret[0].(*partners.InsuranceProviderCreatePolicyResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFreshPolicy indicates an expected call of GetFreshPolicy.
+func (mr *MockFreshPolicyGetterMockRecorder) GetFreshPolicy(ctx, policyID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFreshPolicy", reflect.TypeOf((*MockFreshPolicyGetter)(nil).GetFreshPolicy), ctx, policyID)
 }
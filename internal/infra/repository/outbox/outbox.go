@@ -0,0 +1,221 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"main-api/internal/domain/partners"
+	"main-api/internal/infra/outbox"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type (
+	Repo struct {
+		DatabaseName string
+		DB           *mongo.Client
+	}
+
+	// The _id is generated by the driver and breaks ties between events that
+	// occurred in the same millisecond, the precision of Mongo dates.
+	messageResultDB struct {
+		ID            bson.ObjectID `bson:"_id"`
+		EventID       string        `bson:"event_id"`
+		Type          string        `bson:"type"`
+		AggregateID   string        `bson:"aggregate_id"`
+		EntityID      string        `bson:"entity_id"`
+		Payload       string        `bson:"payload"`
+		OccurredAt    time.Time     `bson:"occurred_at"`
+		Attempts      int           `bson:"attempts"`
+		NextAttemptAt time.Time     `bson:"next_attempt_at"`
+		LastError     string        `bson:"last_error,omitempty"`
+		PublishedAt   *time.Time    `bson:"published_at"`
+	}
+)
+
+const (
+	CollectionName     = "outbox"
+	retentionIndexName = "published_at_retention"
+)
+
+func NewRepo(db *mongo.Client, dbName string) *Repo {
+	return &Repo{
+		DatabaseName: dbName,
+		DB:           db,
+	}
+}
+
+// CreateIndexes ensures the indexes used to list the pending messages exist,
+// and makes MongoDB delete the published ones after retention.
+func (r *Repo) CreateIndexes(ctx context.Context, retention time.Duration) error {
+	collection := r.DB.Database(r.DatabaseName).Collection(CollectionName)
+
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "event_id", Value: 1}},
+			Options: options.Index().SetName("event_id").SetUnique(true),
+		},
+		{
+			Keys: bson.D{
+				{Key: "published_at", Value: 1},
+				{Key: "next_attempt_at", Value: 1},
+				{Key: "occurred_at", Value: 1},
+				{Key: "_id", Value: 1},
+			},
+			Options: options.Index().SetName("pending"),
+		},
+		{
+			Keys:    bson.D{{Key: "aggregate_id", Value: 1}, {Key: "published_at", Value: 1}},
+			Options: options.Index().SetName("aggregate_id_published_at"),
+		},
+		{
+			Keys: bson.D{{Key: "published_at", Value: 1}},
+			Options: options.Index().
+				SetName(retentionIndexName).
+				SetExpireAfterSeconds(int32(retention.Seconds())),
+		},
+	})
+
+	return err
+}
+
+func (r *Repo) Add(ctx context.Context, events ...partners.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	collection := r.DB.Database(r.DatabaseName).Collection(CollectionName)
+
+	documents := make([]interface{}, 0, len(events))
+	for _, event := range events {
+		payload, err := json.Marshal(event.Payload)
+		if err != nil {
+			return err
+		}
+
+		documents = append(documents, map[string]interface{}{
+			"event_id":        event.ID,
+			"type":            event.Type,
+			"aggregate_id":    event.AggregateID,
+			"entity_id":       event.EntityID,
+			"payload":         string(payload),
+			"occurred_at":     event.OccurredAt,
+			"attempts":        0,
+			"next_attempt_at": event.OccurredAt,
+			"published_at":    nil,
+		})
+	}
+
+	_, err := collection.InsertMany(ctx, documents)
+
+	return err
+}
+
+// ListPending leaves out the messages of an aggregate with an earlier message
+// waiting to be retried. MarkFailed holds the messages pending when it runs,
+// but the ones added afterwards are due as soon as they occur.
+func (r *Repo) ListPending(ctx context.Context, now time.Time, limit int) ([]outbox.Message, error) {
+	collection := r.DB.Database(r.DatabaseName).Collection(CollectionName)
+
+	cursor, err := collection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"published_at":    nil,
+			"next_attempt_at": bson.M{"$lte": now},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "occurred_at", Value: 1}, {Key: "_id", Value: 1}}}},
+		{{Key: "$lookup", Value: bson.M{
+			"from": CollectionName,
+			"let": bson.M{
+				"aggregate_id": "$aggregate_id",
+				"occurred_at":  "$occurred_at",
+				"id":           "$_id",
+			},
+			"pipeline": mongo.Pipeline{
+				{{Key: "$match", Value: bson.M{
+					"published_at":    nil,
+					"next_attempt_at": bson.M{"$gt": now},
+					"$expr": bson.M{"$and": bson.A{
+						bson.M{"$eq": bson.A{"$aggregate_id", "$$aggregate_id"}},
+						bson.M{"$or": bson.A{
+							bson.M{"$lt": bson.A{"$occurred_at", "$$occurred_at"}},
+							bson.M{"$and": bson.A{
+								bson.M{"$eq": bson.A{"$occurred_at", "$$occurred_at"}},
+								bson.M{"$lt": bson.A{"$_id", "$$id"}},
+							}},
+						}},
+					}},
+				}}},
+				{{Key: "$limit", Value: 1}},
+				{{Key: "$project", Value: bson.M{"_id": 1}}},
+			},
+			"as": "held_by",
+		}}},
+		{{Key: "$match", Value: bson.M{"held_by": bson.M{"$size": 0}}}},
+		{{Key: "$limit", Value: int64(limit)}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var results []messageResultDB
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	messages := make([]outbox.Message, 0, len(results))
+	for _, result := range results {
+		messages = append(messages, toMessage(result))
+	}
+
+	return messages, nil
+}
+
+func (r *Repo) MarkPublished(ctx context.Context, id string, publishedAt time.Time) error {
+	collection := r.DB.Database(r.DatabaseName).Collection(CollectionName)
+
+	_, err := collection.UpdateOne(
+		ctx,
+		bson.M{"event_id": id},
+		bson.M{"$set": bson.M{"published_at": publishedAt}},
+	)
+
+	return err
+}
+
+func (r *Repo) MarkFailed(ctx context.Context, message outbox.Message, cause error, retryAt time.Time) error {
+	collection := r.DB.Database(r.DatabaseName).Collection(CollectionName)
+
+	_, err := collection.UpdateOne(
+		ctx,
+		bson.M{"event_id": message.ID},
+		bson.M{
+			"$inc": bson.M{"attempts": 1},
+			"$set": bson.M{"last_error": cause.Error()},
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = collection.UpdateMany(
+		ctx,
+		bson.M{"aggregate_id": message.AggregateID, "published_at": nil},
+		bson.M{"$set": bson.M{"next_attempt_at": retryAt}},
+	)
+
+	return err
+}
+
+func toMessage(result messageResultDB) outbox.Message {
+	return outbox.Message{
+		ID:          result.EventID,
+		Type:        result.Type,
+		AggregateID: result.AggregateID,
+		EntityID:    result.EntityID,
+		Payload:     json.RawMessage(result.Payload),
+		OccurredAt:  result.OccurredAt,
+		Attempts:    result.Attempts,
+	}
+}
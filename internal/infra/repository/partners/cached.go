@@ -0,0 +1,79 @@
+package partners
+
+import (
+	"context"
+	"errors"
+	"main-api/internal/domain/partners"
+	"main-api/internal/infra/cache"
+	"time"
+
+	"github.com/gofiber/fiber/v2/log"
+)
+
+type (
+	// CachedRepo caches the partners looked up by ID, which happens on nearly
+	// every request. IDs that don't exist are cached too, for a shorter time, so
+	// requests with an unknown partner don't reach the database either.
+	CachedRepo struct {
+		partners.PartnerRepository
+		store       cache.CacheStore
+		ttl         time.Duration
+		negativeTTL time.Duration
+	}
+
+	// cachedPartner is the cached lookup; a nil Partner is an unknown ID.
+	cachedPartner struct {
+		Partner *partners.PartnerEntity `json:"partner"`
+	}
+)
+
+const partnerCacheKeyPrefix = "partner:"
+
+func NewCachedRepo(repo partners.PartnerRepository, store cache.CacheStore, ttl, negativeTTL time.Duration) *CachedRepo {
+	return &CachedRepo{
+		PartnerRepository: repo,
+		store:             store,
+		ttl:               ttl,
+		negativeTTL:       negativeTTL,
+	}
+}
+
+func (r *CachedRepo) GetByID(ctx context.Context, id string) (*partners.PartnerEntity, error) {
+	key := partnerCacheKeyPrefix + id
+
+	cached, err := cache.GetJSON[cachedPartner](ctx, r.store, key)
+	if err == nil {
+		return cached.Partner, nil
+	}
+
+	if !errors.Is(err, cache.ErrCacheMiss) {
+		log.Errorf("[PARTNER CACHE] failed to read partner %q: %v", id, err)
+	}
+
+	partner, err := r.PartnerRepository.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := r.ttl
+	if partner == nil {
+		ttl = r.negativeTTL
+	}
+
+	err = cache.SetJSON(ctx, r.store, key, cachedPartner{Partner: partner}, ttl)
+	if err != nil {
+		log.Errorf("[PARTNER CACHE] failed to store partner %q: %v", id, err)
+	}
+
+	return partner, nil
+}
+
+// InvalidatePartner drops the cached lookup of a partner that changed, or was
+// created after its ID was looked up. The service calls it once the change is
+// committed, since Create and Update run in its transactions. When it fails
+// the old partner is served until the cache ttl expires.
+func (r *CachedRepo) InvalidatePartner(ctx context.Context, id string) {
+	if err := r.store.Delete(ctx, partnerCacheKeyPrefix+id); err != nil {
+		log.Errorf("[PARTNER CACHE] failed to invalidate partner %q: %v", id, err)
+	}
+}
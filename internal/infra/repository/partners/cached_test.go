@@ -0,0 +1,170 @@
+package partners_test
+
+import (
+	"context"
+	"errors"
+	"main-api/internal/domain/partners"
+	"main-api/internal/infra/cache"
+	mocks "main-api/internal/infra/repository/mocks"
+	partnersRepository "main-api/internal/infra/repository/partners"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+type (
+	// slowRepo answers GetByID after a delay close to a MongoDB round trip.
+	slowRepo struct {
+		partners.PartnerRepository
+		latency time.Duration
+		partner *partners.PartnerEntity
+	}
+)
+
+func (r *slowRepo) GetByID(_ context.Context, _ string) (*partners.PartnerEntity, error) {
+	time.Sleep(r.latency)
+
+	return r.partner, nil
+}
+
+func TestCachedRepo(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	testRedis := miniredis.RunT(t)
+	store := cache.NewRedisCacheAdapter(redis.NewClient(&redis.Options{Addr: testRedis.Addr()}))
+	repo := mocks.NewMockPartnerRepository(ctrl)
+	cachedRepo := partnersRepository.NewCachedRepo(repo, store, time.Hour, time.Minute)
+
+	fakePartner := &partners.PartnerEntity{
+		ID:       "67e1ae8c7e7a8b2b0f1e4b10",
+		Name:     "test-partner",
+		Cnpj:     "12345678901234",
+		Language: "pt-BR",
+		Limits: partners.PartnerLimitsEntity{
+			RequestsPerWindow: 10,
+		},
+		CreatedAt: time.Date(2025, 3, 24, 10, 0, 0, 0, time.UTC),
+		UpdatedAt: time.Date(2025, 3, 24, 10, 0, 0, 0, time.UTC),
+	}
+
+	t.Run("Should query the database once while the partner is cached", func(t *testing.T) {
+		repo.EXPECT().GetByID(gomock.Any(), fakePartner.ID).Return(fakePartner, nil).Times(1)
+
+		first, err := cachedRepo.GetByID(t.Context(), fakePartner.ID)
+		assert.NoError(t, err)
+
+		second, err := cachedRepo.GetByID(t.Context(), fakePartner.ID)
+		assert.NoError(t, err)
+
+		assert.Equal(t, fakePartner, first)
+		assert.Equal(t, fakePartner, second)
+	})
+
+	t.Run("Should cache unknown ids for the negative ttl", func(t *testing.T) {
+		repo.EXPECT().GetByID(gomock.Any(), "unknown-01").Return(nil, nil).Times(1)
+
+		first, err := cachedRepo.GetByID(t.Context(), "unknown-01")
+		assert.NoError(t, err)
+		assert.Nil(t, first)
+
+		second, err := cachedRepo.GetByID(t.Context(), "unknown-01")
+		assert.NoError(t, err)
+		assert.Nil(t, second)
+
+		assert.Equal(t, time.Minute, testRedis.TTL("partner:unknown-01"))
+	})
+
+	t.Run("Should not cache database errors", func(t *testing.T) {
+		errDatabase := errors.New("database unavailable")
+
+		repo.EXPECT().GetByID(gomock.Any(), "unknown-02").Return(nil, errDatabase).Times(1)
+		repo.EXPECT().GetByID(gomock.Any(), "unknown-02").Return(nil, nil).Times(1)
+
+		_, err := cachedRepo.GetByID(t.Context(), "unknown-02")
+		assert.Equal(t, errDatabase, err)
+
+		partner, err := cachedRepo.GetByID(t.Context(), "unknown-02")
+		assert.NoError(t, err)
+		assert.Nil(t, partner)
+	})
+
+	t.Run("Should keep the cached partner until the update is invalidated", func(t *testing.T) {
+		suspended := *fakePartner
+		suspended.Suspended = true
+
+		repo.EXPECT().Update(gomock.Any(), &suspended).Return(nil).Times(1)
+		repo.EXPECT().GetByID(gomock.Any(), fakePartner.ID).Return(&suspended, nil).Times(1)
+
+		// The update may still be rolled back, so it doesn't touch the cache.
+		err := cachedRepo.Update(t.Context(), &suspended)
+		assert.NoError(t, err)
+
+		partner, err := cachedRepo.GetByID(t.Context(), fakePartner.ID)
+		assert.NoError(t, err)
+		assert.False(t, partner.Suspended)
+
+		cachedRepo.InvalidatePartner(t.Context(), fakePartner.ID)
+
+		partner, err = cachedRepo.GetByID(t.Context(), fakePartner.ID)
+		assert.NoError(t, err)
+		assert.True(t, partner.Suspended)
+	})
+
+	t.Run("Should drop a cached unknown id when the partner is created", func(t *testing.T) {
+		created := &partners.PartnerEntity{ID: "unknown-01", Name: "test-partner"}
+
+		repo.EXPECT().Create(gomock.Any(), created).Return(nil).Times(1)
+		repo.EXPECT().GetByID(gomock.Any(), "unknown-01").Return(created, nil).Times(1)
+
+		err := cachedRepo.Create(t.Context(), created)
+		assert.NoError(t, err)
+
+		cachedRepo.InvalidatePartner(t.Context(), created.ID)
+
+		partner, err := cachedRepo.GetByID(t.Context(), "unknown-01")
+		assert.NoError(t, err)
+		assert.Equal(t, created, partner)
+	})
+}
+
+// BenchmarkGetByID compares a lookup that always reaches the database, with a
+// simulated round trip of 500µs, against the cached lookups.
+func BenchmarkGetByID(b *testing.B) {
+	partner := &partners.PartnerEntity{
+		ID:       "67e1ae8c7e7a8b2b0f1e4b10",
+		Name:     "test-partner",
+		Cnpj:     "12345678901234",
+		Language: "pt-BR",
+	}
+	repo := &slowRepo{latency: 500 * time.Microsecond, partner: partner}
+
+	testRedis := miniredis.RunT(b)
+	redisStore := cache.NewRedisCacheAdapter(redis.NewClient(&redis.Options{Addr: testRedis.Addr()}))
+
+	benchmarks := []struct {
+		name string
+		repo partners.PartnerRepository
+	}{
+		{name: "database", repo: repo},
+		{name: "redis", repo: partnersRepository.NewCachedRepo(repo, redisStore, time.Hour, time.Minute)},
+		{name: "memory", repo: partnersRepository.NewCachedRepo(repo, cache.NewMemoryCacheAdapter(100), time.Hour, time.Minute)},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			ctx := context.Background()
+
+			for b.Loop() {
+				if _, err := bm.repo.GetByID(ctx, partner.ID); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -48,9 +48,11 @@ func (r *Repo) GetByID(ctx context.Context, id string) (*partners.PartnerEntity,
 	collection := r.DB.Database(r.DatabaseName).Collection(CollectionName)
 	partner := new(partners.PartnerEntity)
 
+	// An id that isn't an ObjectID can't match any partner, so it is reported as
+	// not found instead of failing the request.
 	objectID, err := bson.ObjectIDFromHex(id)
 	if err != nil {
-		return nil, err
+		return nil, nil
 	}
 
 	err = collection.FindOne(ctx, map[string]interface{}{"_id": objectID}).Decode(partner)
@@ -73,7 +75,11 @@ func (r *Repo) Create(ctx context.Context, partner *partners.PartnerEntity) erro
 	result, err := collection.InsertOne(ctx, map[string]interface{}{
 		"name":       partner.Name,
 		"cnpj":       partner.Cnpj,
+		"language":   partner.Language,
+		"limits":     partner.Limits,
+		"suspended":  partner.Suspended,
 		"created_at": partner.CreatedAt,
+		"updated_at": partner.UpdatedAt,
 	})
 	if err != nil {
 		return err
@@ -88,3 +94,33 @@ func (r *Repo) Create(ctx context.Context, partner *partners.PartnerEntity) erro
 
 	return nil
 }
+
+func (r *Repo) Update(ctx context.Context, partner *partners.PartnerEntity) error {
+	collection := r.DB.Database(r.DatabaseName).Collection(CollectionName)
+
+	objectID, err := bson.ObjectIDFromHex(partner.ID)
+	if err != nil {
+		return partners.ErrPartnerNotFound
+	}
+
+	result, err := collection.UpdateOne(
+		ctx,
+		bson.M{"_id": objectID},
+		bson.M{"$set": bson.M{
+			"name":       partner.Name,
+			"language":   partner.Language,
+			"limits":     partner.Limits,
+			"suspended":  partner.Suspended,
+			"updated_at": partner.UpdatedAt,
+		}},
+	)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return partners.ErrPartnerNotFound
+	}
+
+	return nil
+}
@@ -2,28 +2,64 @@ package policies
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"main-api/internal/domain/partners"
+	"main-api/internal/infra/encryption"
+	"time"
 
 	"github.com/google/uuid"
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
 )
 
 type (
+	// Repo stores the personal data of the holders, beneficiaries and
+	// dependents encrypted with the key of the policy's partner, and the CPF's
+	// keyed hash to look the policies up by it.
 	Repo struct {
 		DatabaseName string
 		DB           *mongo.Client
+		Cipher       *encryption.Cipher
 	}
 
 	policyResultDB struct {
-		ID          bson.ObjectID `bson:"_id"`
-		ProviderID  string        `bson:"provider_id"`
-		QuotationID string        `bson:"quotation_id"`
-		PartnerID   string        `bson:"partner_id"`
-		Name        string        `bson:"name"`
-		Sex         string        `bson:"sex"`
-		DateOfBirth string        `bson:"date_of_birth"`
+		ID            bson.ObjectID   `bson:"_id"`
+		ProviderID    string          `bson:"provider_id"`
+		QuotationID   string          `bson:"quotation_id"`
+		PartnerID     string          `bson:"partner_id"`
+		CustomerID    string          `bson:"customer_id,omitempty"`
+		Name          string          `bson:"name"`
+		Sex           string          `bson:"sex"`
+		DateOfBirth   string          `bson:"date_of_birth"`
+		Cpf           string          `bson:"cpf"`
+		CpfHash       string          `bson:"cpf_hash,omitempty"`
+		Email         string          `bson:"email,omitempty"`
+		Phone         string          `bson:"phone,omitempty"`
+		Beneficiaries []beneficiaryDB `bson:"beneficiaries,omitempty"`
+		Dependents    []dependentDB   `bson:"dependents,omitempty"`
+		// Status is missing on the policies issued before it was kept.
+		Status          string    `bson:"status,omitempty"`
+		StatusUpdatedAt time.Time `bson:"status_updated_at,omitempty"`
+		ErasedAt        time.Time `bson:"erased_at,omitempty"`
+		// PersonalDataVersion is bumped by every write of the personal data,
+		// so Reencrypt can tell a policy changed since it was read. It is
+		// missing, read as 0, on the policies stored before it was kept.
+		PersonalDataVersion int64 `bson:"personal_data_version,omitempty"`
+	}
+
+	beneficiaryDB struct {
+		Name         string  `bson:"name"`
+		Relationship string  `bson:"relationship,omitempty"`
+		Percentage   float64 `bson:"percentage"`
+	}
+
+	dependentDB struct {
+		Name         string `bson:"name"`
+		Sex          string `bson:"sex"`
+		DateOfBirth  string `bson:"date_of_birth"`
+		Relationship string `bson:"relationship"`
 	}
 )
 
@@ -31,24 +67,33 @@ var (
 	CollectionName = "policies"
 )
 
-func NewRepo(db *mongo.Client, dbName string) *Repo {
+const personalDataVersionField = "personal_data_version"
+
+func NewRepo(db *mongo.Client, dbName string, cipher *encryption.Cipher) *Repo {
 	return &Repo{
 		DatabaseName: dbName,
 		DB:           db,
+		Cipher:       cipher,
 	}
 }
 
 func (r *Repo) Create(ctx context.Context, policy *partners.PolicyEntity) error {
 	collection := r.DB.Database(r.DatabaseName).Collection(CollectionName)
 
-	result, err := collection.InsertOne(ctx, map[string]interface{}{
-		"provider_id":   policy.ProviderID.String(),
-		"quotation_id":  policy.QuotationID.String(),
-		"partner_id":    policy.PartnerID,
-		"name":          policy.Name,
-		"sex":           policy.Sex,
-		"date_of_birth": policy.DateOfBirth,
-	})
+	document, err := r.personalData(ctx, policy)
+	if err != nil {
+		return err
+	}
+
+	document["provider_id"] = policy.ProviderID.String()
+	document["quotation_id"] = policy.QuotationID.String()
+	document["partner_id"] = policy.PartnerID
+	document["customer_id"] = policy.CustomerID
+	document["status"] = policy.Status
+	document["status_updated_at"] = policy.StatusUpdatedAt
+	document[personalDataVersionField] = 1
+
+	result, err := collection.InsertOne(ctx, document)
 	if err != nil {
 		return err
 	}
@@ -67,7 +112,7 @@ func (r *Repo) GetByIdAndPartnerID(ctx context.Context, policyID, partnerID stri
 	collection := r.DB.Database(r.DatabaseName).Collection(CollectionName)
 	id, err := bson.ObjectIDFromHex(policyID)
 	if err != nil {
-		return nil, err
+		return nil, nil
 	}
 
 	filter := bson.M{
@@ -84,13 +129,428 @@ func (r *Repo) GetByIdAndPartnerID(ctx context.Context, policyID, partnerID stri
 		return nil, err
 	}
 
-	return &partners.PolicyEntity{
-		ID:          result.ID.Hex(),
-		PartnerID:   result.PartnerID,
-		Name:        result.Name,
-		DateOfBirth: result.DateOfBirth,
-		QuotationID: uuid.MustParse(result.QuotationID),
-		ProviderID:  uuid.MustParse(result.ProviderID),
-		Sex:         partners.SexEnum(result.Sex),
-	}, nil
+	return r.toEntity(ctx, result)
+}
+
+func (r *Repo) ListByPartnerIDAndCpf(ctx context.Context, partnerID, cpf string) ([]partners.PolicyEntity, error) {
+	return r.listByFilter(ctx, bson.M{
+		"partner_id": partnerID,
+		"$or":        r.cpfFilter(cpf),
+	})
+}
+
+func (r *Repo) ListByPartnerIDAndHolder(ctx context.Context, partnerID, customerID, cpf string) ([]partners.PolicyEntity, error) {
+	return r.listByFilter(ctx, bson.M{
+		"partner_id": partnerID,
+		"$or":        append(r.cpfFilter(cpf), bson.M{"customer_id": customerID}),
+	})
+}
+
+func (r *Repo) ListByHolder(ctx context.Context, customerIDs []string, cpf string) ([]partners.PolicyEntity, error) {
+	return r.listByFilter(ctx, bson.M{
+		"$or": append(r.cpfFilter(cpf), bson.M{"customer_id": bson.M{"$in": customerIDs}}),
+	})
+}
+
+func (r *Repo) GetByProviderID(ctx context.Context, providerID uuid.UUID) (*partners.PolicyEntity, error) {
+	collection := r.DB.Database(r.DatabaseName).Collection(CollectionName)
+
+	var result policyResultDB
+	err := collection.FindOne(ctx, bson.M{"provider_id": providerID.String()}).Decode(&result)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return r.toEntity(ctx, result)
+}
+
+func (r *Repo) UpdateStatus(ctx context.Context, policy *partners.PolicyEntity) error {
+	collection := r.DB.Database(r.DatabaseName).Collection(CollectionName)
+
+	id, err := bson.ObjectIDFromHex(policy.ID)
+	if err != nil {
+		return partners.ErrPolicyNotFound
+	}
+
+	result, err := collection.UpdateOne(
+		ctx,
+		bson.M{"_id": id},
+		bson.M{"$set": bson.M{
+			"status":            policy.Status,
+			"status_updated_at": policy.StatusUpdatedAt,
+		}},
+	)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return partners.ErrPolicyNotFound
+	}
+
+	return nil
+}
+
+func (r *Repo) ListAfter(ctx context.Context, afterID string, limit int) ([]partners.PolicyEntity, error) {
+	filter := bson.M{}
+	if afterID != "" {
+		id, err := bson.ObjectIDFromHex(afterID)
+		if err != nil {
+			return nil, err
+		}
+
+		filter["_id"] = bson.M{"$gt": id}
+	}
+
+	return r.listByFilter(ctx, filter, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetLimit(int64(limit)))
+}
+
+// UpdateProviderData leaves out the erased policies in the filter, since the
+// holder may have been erased after the policy was read.
+func (r *Repo) UpdateProviderData(ctx context.Context, policy *partners.PolicyEntity) (bool, error) {
+	collection := r.DB.Database(r.DatabaseName).Collection(CollectionName)
+
+	id, err := bson.ObjectIDFromHex(policy.ID)
+	if err != nil {
+		return false, partners.ErrPolicyNotFound
+	}
+
+	encrypter := r.Cipher.Encrypter(ctx, policy.PartnerID)
+
+	fields := bson.M{
+		"quotation_id":  policy.QuotationID.String(),
+		"name":          encrypter.Encrypt("name", policy.Name),
+		"sex":           policy.Sex,
+		"date_of_birth": encrypter.Encrypt("date_of_birth", policy.DateOfBirth),
+		"beneficiaries": toBeneficiariesDB(encrypter, policy.Beneficiaries),
+		"dependents":    toDependentsDB(encrypter, policy.Dependents),
+	}
+
+	if err := encrypter.Err(); err != nil {
+		return false, err
+	}
+
+	filter := bson.M{"_id": id, "erased_at": bson.M{"$exists": false}}
+
+	result, err := collection.UpdateOne(ctx, filter, personalDataUpdate(fields))
+	if err != nil {
+		return false, err
+	}
+
+	return result.MatchedCount > 0, nil
+}
+
+func (r *Repo) Erase(ctx context.Context, policy *partners.PolicyEntity) error {
+	collection := r.DB.Database(r.DatabaseName).Collection(CollectionName)
+
+	id, err := bson.ObjectIDFromHex(policy.ID)
+	if err != nil {
+		return partners.ErrPolicyNotFound
+	}
+
+	fields, err := r.personalData(ctx, policy)
+	if err != nil {
+		return err
+	}
+
+	fields["erased_at"] = policy.ErasedAt
+
+	result, err := collection.UpdateOne(ctx, bson.M{"_id": id}, personalDataUpdate(fields))
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return partners.ErrPolicyNotFound
+	}
+
+	return nil
+}
+
+func (r *Repo) listByFilter(
+	ctx context.Context,
+	filter bson.M,
+	opts ...options.Lister[options.FindOptions],
+) ([]partners.PolicyEntity, error) {
+	collection := r.DB.Database(r.DatabaseName).Collection(CollectionName)
+
+	cursor, err := collection.Find(ctx, filter, opts...)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []policyResultDB
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	policies := make([]partners.PolicyEntity, len(results))
+	for index, result := range results {
+		policy, err := r.toEntity(ctx, result)
+		if err != nil {
+			return nil, err
+		}
+
+		policies[index] = *policy
+	}
+
+	return policies, nil
+}
+
+// CreateIndexes ensures the indexes used by the partner scoped lookups, by
+// the provider events and by the data subject requests exist. The indexes on
+// cpf serve the policies stored before it was encrypted, until the
+// rotate-keys command encrypts them.
+func (r *Repo) CreateIndexes(ctx context.Context) error {
+	collection := r.DB.Database(r.DatabaseName).Collection(CollectionName)
+
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "partner_id", Value: 1}, {Key: "cpf", Value: 1}},
+			Options: options.Index().SetName("partner_id_cpf"),
+		},
+		{
+			Keys:    bson.D{{Key: "provider_id", Value: 1}},
+			Options: options.Index().SetName("provider_id"),
+		},
+		{
+			Keys:    bson.D{{Key: "cpf", Value: 1}},
+			Options: options.Index().SetName("cpf"),
+		},
+		{
+			Keys:    bson.D{{Key: "customer_id", Value: 1}},
+			Options: options.Index().SetName("customer_id"),
+		},
+		{
+			Keys:    bson.D{{Key: "partner_id", Value: 1}, {Key: "cpf_hash", Value: 1}},
+			Options: options.Index().SetName("partner_id_cpf_hash"),
+		},
+		{
+			Keys:    bson.D{{Key: "cpf_hash", Value: 1}},
+			Options: options.Index().SetName("cpf_hash"),
+		},
+	})
+
+	return err
+}
+
+// Reencrypt encrypts the policies with the active key of their partner,
+// returning how many were updated. The ones already encrypted with it are
+// skipped. Each policy is only updated if its personal data wasn't written
+// since it was read, otherwise it was already saved with the active key.
+func (r *Repo) Reencrypt(ctx context.Context) (int64, error) {
+	collection := r.DB.Database(r.DatabaseName).Collection(CollectionName)
+
+	cursor, err := collection.Find(ctx, bson.M{})
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	activeKeys := make(map[string]string)
+
+	var updated int64
+	for cursor.Next(ctx) {
+		var result policyResultDB
+		if err := cursor.Decode(&result); err != nil {
+			return updated, err
+		}
+
+		if _, ok := activeKeys[result.PartnerID]; !ok {
+			encrypter := r.Cipher.Encrypter(ctx, result.PartnerID)
+			if err := encrypter.Err(); err != nil {
+				return updated, err
+			}
+
+			activeKeys[result.PartnerID] = encrypter.KeyID()
+		}
+
+		// The provider data is updated on its own, so the key is told by the
+		// CPF, which is only written with the rest of the personal data. The
+		// policies issued for a customer may have no CPF.
+		value := result.Cpf
+		if value == "" {
+			value = result.Name
+		}
+
+		if encryption.KeyID(value) == activeKeys[result.PartnerID] {
+			continue
+		}
+
+		policy, err := r.toEntity(ctx, result)
+		if err != nil {
+			return updated, err
+		}
+
+		fields, err := r.personalData(ctx, policy)
+		if err != nil {
+			return updated, err
+		}
+
+		updateResult, err := collection.UpdateOne(
+			ctx,
+			bson.M{"_id": result.ID, personalDataVersionField: versionFilter(result.PersonalDataVersion)},
+			personalDataUpdate(fields),
+		)
+		if err != nil {
+			return updated, err
+		}
+
+		updated += updateResult.ModifiedCount
+	}
+
+	return updated, cursor.Err()
+}
+
+// personalData returns the personal data of the policy encrypted, along with
+// the CPF's hash.
+func (r *Repo) personalData(ctx context.Context, policy *partners.PolicyEntity) (bson.M, error) {
+	encrypter := r.Cipher.Encrypter(ctx, policy.PartnerID)
+
+	fields := bson.M{
+		"name":          encrypter.Encrypt("name", policy.Name),
+		"sex":           policy.Sex,
+		"date_of_birth": encrypter.Encrypt("date_of_birth", policy.DateOfBirth),
+		"cpf":           encrypter.Encrypt("cpf", policy.Cpf),
+		"cpf_hash":      r.Cipher.Hash(policy.Cpf),
+		"email":         encrypter.Encrypt("email", policy.Email),
+		"phone":         encrypter.Encrypt("phone", policy.Phone),
+		"beneficiaries": toBeneficiariesDB(encrypter, policy.Beneficiaries),
+		"dependents":    toDependentsDB(encrypter, policy.Dependents),
+	}
+
+	return fields, encrypter.Err()
+}
+
+// personalDataUpdate sets the personal data fields and bumps their version.
+func personalDataUpdate(fields bson.M) bson.M {
+	return bson.M{
+		"$set": fields,
+		"$inc": bson.M{personalDataVersionField: 1},
+	}
+}
+
+// versionFilter matches the personal data version read from a policy, which
+// is missing on the policies stored before it was kept.
+func versionFilter(version int64) any {
+	if version == 0 {
+		return bson.M{"$exists": false}
+	}
+
+	return version
+}
+
+// cpfFilter matches the CPF by its hash or, on the policies stored before the
+// CPF was encrypted, by its value. Policies issued for a customer may have no
+// CPF, whose empty hash must not match.
+func (r *Repo) cpfFilter(cpf string) bson.A {
+	return bson.A{
+		bson.M{"cpf_hash": bson.M{"$eq": r.Cipher.Hash(cpf), "$ne": ""}},
+		bson.M{"cpf": cpf},
+	}
+}
+
+func (r *Repo) toEntity(ctx context.Context, result policyResultDB) (*partners.PolicyEntity, error) {
+	// The quotation is left empty when missing, for the reconciliation with
+	// the provider to fill it in.
+	quotationID, _ := uuid.Parse(result.QuotationID)
+
+	status := partners.PolicyStatusEnum(result.Status)
+	if status == "" {
+		status = partners.PolicyStatusActive
+	}
+
+	decrypter := r.Cipher.Decrypter(ctx)
+
+	policy := &partners.PolicyEntity{
+		ID:              result.ID.Hex(),
+		PartnerID:       result.PartnerID,
+		CustomerID:      result.CustomerID,
+		Name:            decrypter.Decrypt("name", result.Name),
+		DateOfBirth:     decrypter.Decrypt("date_of_birth", result.DateOfBirth),
+		Cpf:             decrypter.Decrypt("cpf", result.Cpf),
+		Email:           decrypter.Decrypt("email", result.Email),
+		Phone:           decrypter.Decrypt("phone", result.Phone),
+		QuotationID:     quotationID,
+		ProviderID:      uuid.MustParse(result.ProviderID),
+		Sex:             partners.SexEnum(result.Sex),
+		Beneficiaries:   toBeneficiariesEntity(decrypter, result.Beneficiaries),
+		Dependents:      toDependentsEntity(decrypter, result.Dependents),
+		Status:          status,
+		StatusUpdatedAt: result.StatusUpdatedAt,
+		ErasedAt:        result.ErasedAt,
+	}
+
+	if err := decrypter.Err(); err != nil {
+		return nil, err
+	}
+
+	return policy, nil
+}
+
+func toBeneficiariesDB(encrypter *encryption.Encrypter, beneficiaries []partners.BeneficiaryEntity) []beneficiaryDB {
+	result := make([]beneficiaryDB, len(beneficiaries))
+	for index, beneficiary := range beneficiaries {
+		result[index] = beneficiaryDB{
+			Name:         encrypter.Encrypt("beneficiaries.name", beneficiary.Name),
+			Relationship: string(beneficiary.Relationship),
+			Percentage:   beneficiary.Percentage,
+		}
+	}
+
+	return result
+}
+
+func toDependentsDB(encrypter *encryption.Encrypter, dependents []partners.DependentEntity) []dependentDB {
+	result := make([]dependentDB, len(dependents))
+	for index, dependent := range dependents {
+		result[index] = dependentDB{
+			Name:         encrypter.Encrypt("dependents.name", dependent.Name),
+			Sex:          string(dependent.Sex),
+			DateOfBirth:  encrypter.Encrypt("dependents.date_of_birth", dependent.DateOfBirth),
+			Relationship: string(dependent.Relationship),
+		}
+	}
+
+	return result
+}
+
+func toBeneficiariesEntity(decrypter *encryption.Decrypter, beneficiaries []beneficiaryDB) []partners.BeneficiaryEntity {
+	if len(beneficiaries) == 0 {
+		return nil
+	}
+
+	result := make([]partners.BeneficiaryEntity, len(beneficiaries))
+	for index, beneficiary := range beneficiaries {
+		result[index] = partners.BeneficiaryEntity{
+			Name:         decrypter.Decrypt("beneficiaries.name", beneficiary.Name),
+			Relationship: partners.RelationshipEnum(beneficiary.Relationship),
+			Percentage:   beneficiary.Percentage,
+		}
+	}
+
+	return result
+}
+
+func toDependentsEntity(decrypter *encryption.Decrypter, dependents []dependentDB) []partners.DependentEntity {
+	if len(dependents) == 0 {
+		return nil
+	}
+
+	result := make([]partners.DependentEntity, len(dependents))
+	for index, dependent := range dependents {
+		result[index] = partners.DependentEntity{
+			Name:         decrypter.Decrypt("dependents.name", dependent.Name),
+			Sex:          partners.SexEnum(dependent.Sex),
+			DateOfBirth:  decrypter.Decrypt("dependents.date_of_birth", dependent.DateOfBirth),
+			Relationship: partners.RelationshipEnum(dependent.Relationship),
+		}
+	}
+
+	return result
 }
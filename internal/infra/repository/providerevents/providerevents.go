@@ -0,0 +1,60 @@
+package providerevents
+
+import (
+	"context"
+	"main-api/internal/domain/partners"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type (
+	Repo struct {
+		DatabaseName string
+		DB           *mongo.Client
+	}
+)
+
+var (
+	CollectionName = "provider_events"
+)
+
+func NewRepo(db *mongo.Client, dbName string) *Repo {
+	return &Repo{
+		DatabaseName: dbName,
+		DB:           db,
+	}
+}
+
+// Add records the event under its ID, so the insert of a redelivered event
+// fails on the _id index.
+func (r *Repo) Add(ctx context.Context, eventID string, receivedAt time.Time) error {
+	collection := r.DB.Database(r.DatabaseName).Collection(CollectionName)
+
+	_, err := collection.InsertOne(ctx, bson.M{
+		"_id":         eventID,
+		"received_at": receivedAt,
+	})
+	if mongo.IsDuplicateKeyError(err) {
+		return partners.ErrDuplicateProviderEvent
+	}
+
+	return err
+}
+
+// CreateIndexes makes MongoDB forget the events after retention, which must
+// be longer than the provider keeps redelivering them.
+func (r *Repo) CreateIndexes(ctx context.Context, retention time.Duration) error {
+	collection := r.DB.Database(r.DatabaseName).Collection(CollectionName)
+
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "received_at", Value: 1}},
+		Options: options.Index().
+			SetName("received_at_retention").
+			SetExpireAfterSeconds(int32(retention.Seconds())),
+	})
+
+	return err
+}
@@ -2,11 +2,15 @@ package quotes
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"main-api/internal/domain/partners"
+	"time"
 
+	"github.com/google/uuid"
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
 )
 
 type (
@@ -14,6 +18,28 @@ type (
 		DatabaseName string
 		DB           *mongo.Client
 	}
+
+	quoteResultDB struct {
+		ID         bson.ObjectID `bson:"_id"`
+		ProviderID string        `bson:"provider_id"`
+		PartnerID  string        `bson:"partner_id"`
+		Age        uint          `bson:"age"`
+		Sex        string        `bson:"sex"`
+		Price      float64       `bson:"price"`
+		ExpiresAt  time.Time     `bson:"expires_at"`
+		CreatedAt  time.Time     `bson:"created_at"`
+		// ReusedFromID is empty for quotes with their own provider quotation.
+		ReusedFromID string    `bson:"reused_from_id,omitempty"`
+		Status       string    `bson:"status,omitempty"`
+		ExpiredAt    time.Time `bson:"expired_at,omitempty"`
+	}
+)
+
+const (
+	retentionIndexName = "expired_at_retention"
+	// errCodeIndexOptionsConflict is returned when an index exists with other
+	// options, such as a retention changed since it was created.
+	errCodeIndexOptionsConflict = 85
 )
 
 var (
@@ -30,15 +56,21 @@ func NewRepo(db *mongo.Client, dbName string) *Repo {
 func (r *Repo) Create(ctx context.Context, quote *partners.QuoteEntity) error {
 	collection := r.DB.Database(r.DatabaseName).Collection(collectionName)
 
-	result, err := collection.InsertOne(ctx, map[string]interface{}{
+	document := map[string]interface{}{
 		"provider_id": quote.ProviderID.String(),
 		"partner_id":  quote.PartnerID,
 		"age":         quote.Age,
 		"sex":         quote.Sex,
 		"price":       quote.Price,
 		"expires_at":  quote.ExpiresAt,
+		"status":      quote.Status,
 		"created_at":  quote.CreatedAt,
-	})
+	}
+	if quote.ReusedFromID != "" {
+		document["reused_from_id"] = quote.ReusedFromID
+	}
+
+	result, err := collection.InsertOne(ctx, document)
 	if err != nil {
 		return err
 	}
@@ -52,3 +84,145 @@ func (r *Repo) Create(ctx context.Context, quote *partners.QuoteEntity) error {
 
 	return nil
 }
+
+func (r *Repo) GetByProviderIDAndPartnerID(
+	ctx context.Context,
+	providerID uuid.UUID,
+	partnerID string,
+) (*partners.QuoteEntity, error) {
+	collection := r.DB.Database(r.DatabaseName).Collection(collectionName)
+
+	var result quoteResultDB
+	err := collection.FindOne(ctx, bson.M{
+		"provider_id": providerID.String(),
+		"partner_id":  partnerID,
+	}).Decode(&result)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return toEntity(result), nil
+}
+
+func (r *Repo) GetReusable(
+	ctx context.Context,
+	age uint,
+	sex partners.SexEnum,
+	partnerID string,
+	validAt time.Time,
+) (*partners.QuoteEntity, error) {
+	collection := r.DB.Database(r.DatabaseName).Collection(collectionName)
+
+	filter := bson.M{
+		"age":        age,
+		"sex":        sex,
+		"expires_at": bson.M{"$gte": validAt},
+	}
+	if partnerID != "" {
+		filter["partner_id"] = partnerID
+	}
+
+	var result quoteResultDB
+	err := collection.FindOne(
+		ctx,
+		filter,
+		options.FindOne().SetSort(bson.D{{Key: "expires_at", Value: -1}}),
+	).Decode(&result)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return toEntity(result), nil
+}
+
+func (r *Repo) MarkExpired(ctx context.Context, now time.Time) (int64, error) {
+	collection := r.DB.Database(r.DatabaseName).Collection(collectionName)
+
+	result, err := collection.UpdateMany(
+		ctx,
+		bson.M{
+			"expires_at": bson.M{"$lt": now},
+			"status":     bson.M{"$ne": partners.QuoteStatusExpired},
+		},
+		bson.M{"$set": bson.M{
+			"status":     partners.QuoteStatusExpired,
+			"expired_at": now,
+		}},
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.ModifiedCount, nil
+}
+
+// CreateIndexes ensures the indexes used to find reusable quotes exist.
+func (r *Repo) CreateIndexes(ctx context.Context) error {
+	collection := r.DB.Database(r.DatabaseName).Collection(collectionName)
+
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "age", Value: 1}, {Key: "sex", Value: 1}, {Key: "expires_at", Value: -1}},
+		Options: options.Index().SetName("age_sex_expires_at"),
+	})
+
+	return err
+}
+
+// CreateRetentionIndex makes MongoDB delete the quotes once they have been
+// expired for longer than retention. A retention changed since the index was
+// created is updated in place.
+func (r *Repo) CreateRetentionIndex(ctx context.Context, retention time.Duration) error {
+	database := r.DB.Database(r.DatabaseName)
+	expireAfterSeconds := int32(retention.Seconds())
+
+	_, err := database.Collection(collectionName).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "expired_at", Value: 1}},
+		Options: options.Index().
+			SetName(retentionIndexName).
+			SetExpireAfterSeconds(expireAfterSeconds),
+	})
+
+	var commandErr mongo.CommandError
+	if errors.As(err, &commandErr) && commandErr.Code == errCodeIndexOptionsConflict {
+		return database.RunCommand(ctx, bson.D{
+			{Key: "collMod", Value: collectionName},
+			{Key: "index", Value: bson.D{
+				{Key: "name", Value: retentionIndexName},
+				{Key: "expireAfterSeconds", Value: expireAfterSeconds},
+			}},
+		}).Err()
+	}
+
+	return err
+}
+
+func toEntity(result quoteResultDB) *partners.QuoteEntity {
+	// Quotes created before the status existed are active until the expiration
+	// job marks them.
+	status := partners.QuoteStatusEnum(result.Status)
+	if status == "" {
+		status = partners.QuoteStatusActive
+	}
+
+	return &partners.QuoteEntity{
+		ID:           result.ID.Hex(),
+		ProviderID:   uuid.MustParse(result.ProviderID),
+		PartnerID:    result.PartnerID,
+		Age:          result.Age,
+		Sex:          partners.SexEnum(result.Sex),
+		Price:        result.Price,
+		ExpiresAt:    result.ExpiresAt,
+		CreatedAt:    result.CreatedAt,
+		ReusedFromID: result.ReusedFromID,
+		Status:       status,
+		ExpiredAt:    result.ExpiredAt,
+	}
+}
@@ -0,0 +1,74 @@
+package reconciliation
+
+import (
+	"context"
+	"main-api/internal/domain/partners"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type (
+	Repo struct {
+		DatabaseName string
+		DB           *mongo.Client
+	}
+)
+
+var (
+	CollectionName = "reconciliation_reports"
+)
+
+func NewRepo(db *mongo.Client, dbName string) *Repo {
+	return &Repo{
+		DatabaseName: dbName,
+		DB:           db,
+	}
+}
+
+func (r *Repo) Create(ctx context.Context, mismatches ...partners.PolicyMismatchEntity) error {
+	collection := r.DB.Database(r.DatabaseName).Collection(CollectionName)
+
+	documents := make([]interface{}, len(mismatches))
+	for index, mismatch := range mismatches {
+		documents[index] = map[string]interface{}{
+			"run_id":      mismatch.RunID,
+			"policy_id":   mismatch.PolicyID,
+			"provider_id": mismatch.ProviderID.String(),
+			"partner_id":  mismatch.PartnerID,
+			"kind":        mismatch.Kind,
+			"field":       mismatch.Field,
+			"local":       mismatch.Local,
+			"provider":    mismatch.Provider,
+			"healed":      mismatch.Healed,
+			"detected_at": mismatch.DetectedAt,
+		}
+	}
+
+	_, err := collection.InsertMany(ctx, documents)
+
+	return err
+}
+
+// CreateIndexes ensures the reports can be read by run and makes MongoDB
+// delete them after retention.
+func (r *Repo) CreateIndexes(ctx context.Context, retention time.Duration) error {
+	collection := r.DB.Database(r.DatabaseName).Collection(CollectionName)
+
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "run_id", Value: 1}, {Key: "kind", Value: 1}},
+			Options: options.Index().SetName("run_id_kind"),
+		},
+		{
+			Keys: bson.D{{Key: "detected_at", Value: 1}},
+			Options: options.Index().
+				SetName("detected_at_retention").
+				SetExpireAfterSeconds(int32(retention.Seconds())),
+		},
+	})
+
+	return err
+}
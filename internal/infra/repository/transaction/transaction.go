@@ -0,0 +1,37 @@
+package transaction
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+type (
+	// MongoTransactor runs writes in a MongoDB transaction. The repositories
+	// join it by using the context passed to fn. Transactions require MongoDB
+	// to run as a replica set.
+	MongoTransactor struct {
+		DB *mongo.Client
+	}
+)
+
+func NewMongoTransactor(db *mongo.Client) *MongoTransactor {
+	return &MongoTransactor{
+		DB: db,
+	}
+}
+
+func (t *MongoTransactor) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	session, err := t.DB.StartSession()
+	if err != nil {
+		return err
+	}
+
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(ctx context.Context) (any, error) {
+		return nil, fn(ctx)
+	})
+
+	return err
+}
@@ -0,0 +1,282 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"main-api/internal/domain/partners"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type (
+	DeliveriesRepo struct {
+		DatabaseName string
+		DB           *mongo.Client
+	}
+
+	deliveryResultDB struct {
+		ID             bson.ObjectID `bson:"_id"`
+		WebhookID      string        `bson:"webhook_id"`
+		PartnerID      string        `bson:"partner_id"`
+		EventID        string        `bson:"event_id"`
+		EntityID       string        `bson:"entity_id,omitempty"`
+		EventType      string        `bson:"event_type"`
+		URL            string        `bson:"url"`
+		Body           string        `bson:"body"`
+		Status         string        `bson:"status"`
+		Attempts       int           `bson:"attempts"`
+		LastStatusCode int           `bson:"last_status_code"`
+		LastError      string        `bson:"last_error,omitempty"`
+		NextAttemptAt  time.Time     `bson:"next_attempt_at"`
+		RetryUntil     time.Time     `bson:"retry_until"`
+		CreatedAt      time.Time     `bson:"created_at"`
+		DeliveredAt    time.Time     `bson:"delivered_at,omitempty"`
+	}
+)
+
+const (
+	errCodeDuplicateKey = 11000
+)
+
+var (
+	DeliveriesCollectionName = "webhook_deliveries"
+)
+
+func NewDeliveriesRepo(db *mongo.Client, dbName string) *DeliveriesRepo {
+	return &DeliveriesRepo{
+		DatabaseName: dbName,
+		DB:           db,
+	}
+}
+
+func (r *DeliveriesRepo) Create(ctx context.Context, deliveries ...partners.WebhookDeliveryEntity) error {
+	collection := r.DB.Database(r.DatabaseName).Collection(DeliveriesCollectionName)
+
+	documents := make([]interface{}, len(deliveries))
+	for index, delivery := range deliveries {
+		documents[index] = map[string]interface{}{
+			"webhook_id":       delivery.WebhookID,
+			"partner_id":       delivery.PartnerID,
+			"event_id":         delivery.EventID,
+			"entity_id":        delivery.EntityID,
+			"event_type":       delivery.EventType,
+			"url":              delivery.URL,
+			"body":             string(delivery.Body),
+			"status":           delivery.Status,
+			"attempts":         delivery.Attempts,
+			"last_status_code": delivery.LastStatusCode,
+			"next_attempt_at":  delivery.NextAttemptAt,
+			"retry_until":      delivery.RetryUntil,
+			"created_at":       delivery.CreatedAt,
+		}
+	}
+
+	// Unordered, so the deliveries already created for a republished event
+	// don't stop the others from being inserted.
+	_, err := collection.InsertMany(ctx, documents, options.InsertMany().SetOrdered(false))
+	if onlyDuplicates(err) {
+		return nil
+	}
+
+	return err
+}
+
+func (r *DeliveriesRepo) GetByIDAndPartnerID(
+	ctx context.Context,
+	deliveryID, partnerID string,
+) (*partners.WebhookDeliveryEntity, error) {
+	collection := r.DB.Database(r.DatabaseName).Collection(DeliveriesCollectionName)
+
+	id, err := bson.ObjectIDFromHex(deliveryID)
+	if err != nil {
+		return nil, nil
+	}
+
+	var result deliveryResultDB
+	err = collection.FindOne(ctx, bson.M{"_id": id, "partner_id": partnerID}).Decode(&result)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return toDeliveryEntity(result), nil
+}
+
+func (r *DeliveriesRepo) ListByPartnerID(
+	ctx context.Context,
+	partnerID string,
+	status partners.WebhookDeliveryStatusEnum,
+	limit int,
+) ([]partners.WebhookDeliveryEntity, error) {
+	filter := bson.M{"partner_id": partnerID}
+	if status != "" {
+		filter["status"] = status
+	}
+
+	return r.list(ctx, filter, options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetLimit(int64(limit)),
+	)
+}
+
+func (r *DeliveriesRepo) ListDue(ctx context.Context, now time.Time, limit int) ([]partners.WebhookDeliveryEntity, error) {
+	return r.list(
+		ctx,
+		bson.M{
+			"status":          partners.WebhookDeliveryPending,
+			"next_attempt_at": bson.M{"$lte": now},
+		},
+		options.Find().
+			SetSort(bson.D{{Key: "next_attempt_at", Value: 1}}).
+			SetLimit(int64(limit)),
+	)
+}
+
+func (r *DeliveriesRepo) Update(ctx context.Context, delivery *partners.WebhookDeliveryEntity) error {
+	collection := r.DB.Database(r.DatabaseName).Collection(DeliveriesCollectionName)
+
+	id, err := bson.ObjectIDFromHex(delivery.ID)
+	if err != nil {
+		return partners.ErrWebhookDeliveryNotFound
+	}
+
+	result, err := collection.UpdateOne(
+		ctx,
+		bson.M{"_id": id},
+		bson.M{"$set": bson.M{
+			"status":           delivery.Status,
+			"attempts":         delivery.Attempts,
+			"last_status_code": delivery.LastStatusCode,
+			"last_error":       delivery.LastError,
+			"next_attempt_at":  delivery.NextAttemptAt,
+			"retry_until":      delivery.RetryUntil,
+			"delivered_at":     delivery.DeliveredAt,
+		}},
+	)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return partners.ErrWebhookDeliveryNotFound
+	}
+
+	return nil
+}
+
+// CreateIndexes ensures an event is delivered only once to each webhook, and
+// the indexes used to find the due deliveries, list a partner's and erase a
+// record's exist.
+func (r *DeliveriesRepo) CreateIndexes(ctx context.Context) error {
+	collection := r.DB.Database(r.DatabaseName).Collection(DeliveriesCollectionName)
+
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "webhook_id", Value: 1}, {Key: "event_id", Value: 1}},
+			Options: options.Index().SetName("webhook_id_event_id").SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "status", Value: 1}, {Key: "next_attempt_at", Value: 1}},
+			Options: options.Index().SetName("status_next_attempt_at"),
+		},
+		{
+			Keys: bson.D{
+				{Key: "partner_id", Value: 1},
+				{Key: "status", Value: 1},
+				{Key: "created_at", Value: -1},
+			},
+			Options: options.Index().SetName("partner_id_status_created_at"),
+		},
+		{
+			Keys:    bson.D{{Key: "entity_id", Value: 1}},
+			Options: options.Index().SetName("entity_id"),
+		},
+	})
+
+	return err
+}
+
+func (r *DeliveriesRepo) DeleteByEntityIDs(ctx context.Context, entityIDs ...string) (int64, error) {
+	if len(entityIDs) == 0 {
+		return 0, nil
+	}
+
+	collection := r.DB.Database(r.DatabaseName).Collection(DeliveriesCollectionName)
+
+	result, err := collection.DeleteMany(ctx, bson.M{"entity_id": bson.M{"$in": entityIDs}})
+	if err != nil {
+		return 0, err
+	}
+
+	return result.DeletedCount, nil
+}
+
+func (r *DeliveriesRepo) list(
+	ctx context.Context,
+	filter bson.M,
+	opts *options.FindOptionsBuilder,
+) ([]partners.WebhookDeliveryEntity, error) {
+	collection := r.DB.Database(r.DatabaseName).Collection(DeliveriesCollectionName)
+
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []deliveryResultDB
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	deliveries := make([]partners.WebhookDeliveryEntity, len(results))
+	for index, result := range results {
+		deliveries[index] = *toDeliveryEntity(result)
+	}
+
+	return deliveries, nil
+}
+
+// onlyDuplicates tells whether every write of an insert failed because the
+// document already exists.
+func onlyDuplicates(err error) bool {
+	var bulkErr mongo.BulkWriteException
+	if !errors.As(err, &bulkErr) || bulkErr.WriteConcernError != nil {
+		return false
+	}
+
+	for _, writeErr := range bulkErr.WriteErrors {
+		if writeErr.Code != errCodeDuplicateKey {
+			return false
+		}
+	}
+
+	return true
+}
+
+func toDeliveryEntity(result deliveryResultDB) *partners.WebhookDeliveryEntity {
+	return &partners.WebhookDeliveryEntity{
+		ID:             result.ID.Hex(),
+		WebhookID:      result.WebhookID,
+		PartnerID:      result.PartnerID,
+		EventID:        result.EventID,
+		EntityID:       result.EntityID,
+		EventType:      partners.EventType(result.EventType),
+		URL:            result.URL,
+		Body:           []byte(result.Body),
+		Status:         partners.WebhookDeliveryStatusEnum(result.Status),
+		Attempts:       result.Attempts,
+		LastStatusCode: result.LastStatusCode,
+		LastError:      result.LastError,
+		NextAttemptAt:  result.NextAttemptAt,
+		RetryUntil:     result.RetryUntil,
+		CreatedAt:      result.CreatedAt,
+		DeliveredAt:    result.DeliveredAt,
+	}
+}
@@ -0,0 +1,172 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"main-api/internal/domain/partners"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type (
+	Repo struct {
+		DatabaseName string
+		DB           *mongo.Client
+	}
+
+	webhookResultDB struct {
+		ID         bson.ObjectID `bson:"_id"`
+		PartnerID  string        `bson:"partner_id"`
+		URL        string        `bson:"url"`
+		EventTypes []string      `bson:"event_types"`
+		CreatedAt  time.Time     `bson:"created_at"`
+	}
+
+	secretResultDB struct {
+		Secret string `bson:"secret"`
+	}
+)
+
+var (
+	CollectionName = "webhooks"
+	// SecretsCollectionName keeps the partners' secrets apart from the
+	// partners, so they aren't cached with them.
+	SecretsCollectionName = "webhook_secrets"
+)
+
+func NewRepo(db *mongo.Client, dbName string) *Repo {
+	return &Repo{
+		DatabaseName: dbName,
+		DB:           db,
+	}
+}
+
+func (r *Repo) Create(ctx context.Context, webhook *partners.WebhookEntity) error {
+	collection := r.DB.Database(r.DatabaseName).Collection(CollectionName)
+
+	result, err := collection.InsertOne(ctx, map[string]interface{}{
+		"partner_id":  webhook.PartnerID,
+		"url":         webhook.URL,
+		"event_types": webhook.EventTypes,
+		"created_at":  webhook.CreatedAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	objectID, ok := result.InsertedID.(bson.ObjectID)
+	if !ok {
+		return fmt.Errorf("error on convert inserted id to ObjectID")
+	}
+
+	webhook.ID = objectID.Hex()
+
+	return nil
+}
+
+func (r *Repo) ListByPartnerID(ctx context.Context, partnerID string) ([]partners.WebhookEntity, error) {
+	collection := r.DB.Database(r.DatabaseName).Collection(CollectionName)
+
+	cursor, err := collection.Find(
+		ctx,
+		bson.M{"partner_id": partnerID},
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []webhookResultDB
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	webhooks := make([]partners.WebhookEntity, len(results))
+	for index, result := range results {
+		webhooks[index] = *toEntity(result)
+	}
+
+	return webhooks, nil
+}
+
+func (r *Repo) Delete(ctx context.Context, partnerID, webhookID string) error {
+	collection := r.DB.Database(r.DatabaseName).Collection(CollectionName)
+
+	id, err := bson.ObjectIDFromHex(webhookID)
+	if err != nil {
+		return partners.ErrWebhookNotFound
+	}
+
+	result, err := collection.DeleteOne(ctx, bson.M{"_id": id, "partner_id": partnerID})
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return partners.ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+func (r *Repo) GetSecret(ctx context.Context, partnerID string) (string, error) {
+	collection := r.DB.Database(r.DatabaseName).Collection(SecretsCollectionName)
+
+	var result secretResultDB
+	err := collection.FindOne(ctx, bson.M{"_id": partnerID}).Decode(&result)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return "", nil
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	return result.Secret, nil
+}
+
+func (r *Repo) SetSecret(ctx context.Context, partnerID, secret string) error {
+	collection := r.DB.Database(r.DatabaseName).Collection(SecretsCollectionName)
+
+	_, err := collection.UpdateOne(
+		ctx,
+		bson.M{"_id": partnerID},
+		bson.M{"$set": bson.M{"secret": secret, "updated_at": time.Now()}},
+		options.UpdateOne().SetUpsert(true),
+	)
+
+	return err
+}
+
+// CreateIndexes ensures the indexes used to find the webhooks of a partner
+// exist.
+func (r *Repo) CreateIndexes(ctx context.Context) error {
+	collection := r.DB.Database(r.DatabaseName).Collection(CollectionName)
+
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "partner_id", Value: 1}, {Key: "created_at", Value: 1}},
+		Options: options.Index().SetName("partner_id_created_at"),
+	})
+
+	return err
+}
+
+func toEntity(result webhookResultDB) *partners.WebhookEntity {
+	eventTypes := make([]partners.EventType, len(result.EventTypes))
+	for index, eventType := range result.EventTypes {
+		eventTypes[index] = partners.EventType(eventType)
+	}
+
+	return &partners.WebhookEntity{
+		ID:         result.ID.Hex(),
+		PartnerID:  result.PartnerID,
+		URL:        result.URL,
+		EventTypes: eventTypes,
+		CreatedAt:  result.CreatedAt,
+	}
+}
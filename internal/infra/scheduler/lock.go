@@ -0,0 +1,72 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+type (
+	// RedisLocker elects a single replica to run each job. The lock of a job is
+	// held by the replica that took it while the run lasts, renewed by it, and
+	// released after the run.
+	RedisLocker struct {
+		client *redis.Client
+		// owner identifies this replica as the holder of its locks.
+		owner string
+	}
+)
+
+var (
+	// acquireScript takes the lock when it is free and renews it when this
+	// owner already holds it.
+	acquireScript = redis.NewScript(`
+local owner = redis.call("GET", KEYS[1])
+if owner == ARGV[1] then
+	redis.call("PEXPIRE", KEYS[1], ARGV[2])
+	return 1
+end
+if owner then
+	return 0
+end
+redis.call("SET", KEYS[1], ARGV[1], "PX", ARGV[2])
+return 1
+`)
+
+	releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+)
+
+func NewRedisLocker(client *redis.Client) *RedisLocker {
+	return &RedisLocker{
+		client: client,
+		owner:  uuid.NewString(),
+	}
+}
+
+// Owner identifies this replica in the locks and job statuses.
+func (l *RedisLocker) Owner() string {
+	return l.owner
+}
+
+// Acquire reports whether this replica holds the lock for the next ttl.
+func (l *RedisLocker) Acquire(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	acquired, err := acquireScript.Run(ctx, l.client, []string{key}, l.owner, ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, err
+	}
+
+	return acquired == 1, nil
+}
+
+// Release frees a lock held by this replica, so another one can take over
+// without waiting for it to expire.
+func (l *RedisLocker) Release(ctx context.Context, key string) error {
+	return releaseScript.Run(ctx, l.client, []string{key}, l.owner).Err()
+}
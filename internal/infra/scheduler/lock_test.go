@@ -0,0 +1,66 @@
+package scheduler_test
+
+import (
+	"main-api/internal/infra/scheduler"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedisLocker(t *testing.T) {
+	t.Parallel()
+
+	testRedis := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: testRedis.Addr()})
+
+	first := scheduler.NewRedisLocker(redisClient)
+	second := scheduler.NewRedisLocker(redisClient)
+
+	t.Run("Should let only one replica hold the lock", func(t *testing.T) {
+		acquired, err := first.Acquire(t.Context(), "lock-01", time.Minute)
+
+		assert.NoError(t, err)
+		assert.True(t, acquired)
+
+		acquired, err = second.Acquire(t.Context(), "lock-01", time.Minute)
+
+		assert.NoError(t, err)
+		assert.False(t, acquired)
+	})
+
+	t.Run("Should renew the lock of the holder", func(t *testing.T) {
+		_, _ = first.Acquire(t.Context(), "lock-02", time.Minute)
+		testRedis.FastForward(50 * time.Second)
+
+		acquired, err := first.Acquire(t.Context(), "lock-02", time.Minute)
+
+		assert.NoError(t, err)
+		assert.True(t, acquired)
+		assert.Equal(t, time.Minute, testRedis.TTL("lock-02"))
+	})
+
+	t.Run("Should let another replica take over when the lock expires", func(t *testing.T) {
+		_, _ = first.Acquire(t.Context(), "lock-03", time.Minute)
+		testRedis.FastForward(time.Minute)
+
+		acquired, err := second.Acquire(t.Context(), "lock-03", time.Minute)
+
+		assert.NoError(t, err)
+		assert.True(t, acquired)
+	})
+
+	t.Run("Should only release the lock of the holder", func(t *testing.T) {
+		_, _ = first.Acquire(t.Context(), "lock-04", time.Minute)
+
+		err := second.Release(t.Context(), "lock-04")
+		assert.NoError(t, err)
+		assert.True(t, testRedis.Exists("lock-04"))
+
+		err = first.Release(t.Context(), "lock-04")
+		assert.NoError(t, err)
+		assert.False(t, testRedis.Exists("lock-04"))
+	})
+}
@@ -0,0 +1,328 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"main-api/internal/infra/cache"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2/log"
+)
+
+type (
+	// Counts are the numbers a job reports about a run, such as how many
+	// records it changed.
+	Counts map[string]int64
+
+	Job struct {
+		Name     string
+		Interval time.Duration
+		// At, when set, makes the job run every day at that time instead of
+		// right away and then on its interval, which is then a day.
+		At  *TimeOfDay
+		Run func(ctx context.Context) (Counts, error)
+	}
+
+	// TimeOfDay is the time a daily job runs at.
+	TimeOfDay struct {
+		Hour     int
+		Minute   int
+		Location *time.Location
+	}
+
+	// JobStatus is the outcome of the last run of a job by any replica.
+	JobStatus struct {
+		Name         string        `json:"name"`
+		Interval     time.Duration `json:"interval"`
+		At           string        `json:"at,omitempty"`
+		LastRunAt    time.Time     `json:"last_run_at,omitempty"`
+		LastDuration time.Duration `json:"last_duration,omitempty"`
+		LastError    string        `json:"last_error,omitempty"`
+		Counts       Counts        `json:"counts,omitempty"`
+		// RanBy identifies the replica that ran the job last.
+		RanBy string `json:"ran_by,omitempty"`
+		Runs  int64  `json:"runs"`
+	}
+
+	// Scheduler runs the registered jobs on their interval in one replica at a
+	// time, elected through the locker, and keeps their status in the store so
+	// any replica can report it.
+	Scheduler struct {
+		locker *RedisLocker
+		store  cache.CacheStore
+		mu     sync.Mutex
+		jobs   []Job
+	}
+)
+
+const (
+	lockKeyPrefix   = "scheduler:lock:"
+	statusKeyPrefix = "scheduler:status:"
+	// lockTTL bounds how long the lock of a replica that stopped in the middle
+	// of a run blocks the others. The leader renews it while the run lasts.
+	lockTTL = 30 * time.Second
+)
+
+// ParseTimeOfDay reads a time of day in the 15:04 layout.
+func ParseTimeOfDay(value string, location *time.Location) (TimeOfDay, error) {
+	parsed, err := time.Parse("15:04", value)
+	if err != nil {
+		return TimeOfDay{}, fmt.Errorf("invalid time of day %q: %w", value, err)
+	}
+
+	return TimeOfDay{Hour: parsed.Hour(), Minute: parsed.Minute(), Location: location}, nil
+}
+
+// Daily returns a job that runs every day at the given time.
+func Daily(name string, at TimeOfDay, run func(ctx context.Context) (Counts, error)) Job {
+	return Job{
+		Name:     name,
+		Interval: 24 * time.Hour,
+		At:       &at,
+		Run:      run,
+	}
+}
+
+// Next returns the first time after now at this time of day.
+func (t TimeOfDay) Next(now time.Time) time.Time {
+	now = now.In(t.Location)
+
+	next := time.Date(now.Year(), now.Month(), now.Day(), t.Hour, t.Minute, 0, 0, t.Location)
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+
+	return next
+}
+
+func (t TimeOfDay) String() string {
+	return fmt.Sprintf("%02d:%02d %s", t.Hour, t.Minute, t.Location)
+}
+
+func New(locker *RedisLocker, store cache.CacheStore) *Scheduler {
+	return &Scheduler{
+		locker: locker,
+		store:  store,
+	}
+}
+
+// Register adds a job. Jobs must be registered before Run is called.
+func (s *Scheduler) Register(job Job) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.jobs = append(s.jobs, job)
+}
+
+// Run runs every job right away and then on its interval, or daily at its
+// time, until ctx is done.
+func (s *Scheduler) Run(ctx context.Context) {
+	s.mu.Lock()
+	jobs := append([]Job(nil), s.jobs...)
+	s.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, job := range jobs {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			s.loop(ctx, job)
+		}()
+	}
+
+	wg.Wait()
+}
+
+// Status returns the status of every registered job. Jobs that never ran only
+// have their name and interval.
+func (s *Scheduler) Status(ctx context.Context) ([]JobStatus, error) {
+	s.mu.Lock()
+	jobs := append([]Job(nil), s.jobs...)
+	s.mu.Unlock()
+
+	keys := make([]string, len(jobs))
+	for i, job := range jobs {
+		keys[i] = statusKeyPrefix + job.Name
+	}
+
+	values, err := s.store.MGet(ctx, keys...)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]JobStatus, len(jobs))
+	for i, job := range jobs {
+		statuses[i] = JobStatus{Name: job.Name, Interval: job.Interval, At: job.at()}
+
+		value, ok := values[keys[i]]
+		if !ok {
+			continue
+		}
+
+		if err := json.Unmarshal([]byte(value), &statuses[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return statuses, nil
+}
+
+func (s *Scheduler) loop(ctx context.Context, job Job) {
+	lockKey := lockKeyPrefix + job.Name
+
+	scheduledAt := time.Now()
+	if job.At == nil {
+		s.tick(ctx, job, lockKey)
+	}
+
+	for {
+		scheduledAt = job.nextRun(scheduledAt, time.Now())
+		timer := time.NewTimer(time.Until(scheduledAt))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return
+		case <-timer.C:
+		}
+
+		s.tick(ctx, job, lockKey)
+	}
+}
+
+// nextRun returns when the job runs after the run scheduled at previous. Like
+// a ticker, it skips the runs missed while the previous one was running.
+func (j Job) nextRun(previous, now time.Time) time.Time {
+	if j.At != nil {
+		return j.At.Next(now)
+	}
+
+	next := previous.Add(j.Interval)
+	for !next.After(now) {
+		next = next.Add(j.Interval)
+	}
+
+	return next
+}
+
+func (j Job) at() string {
+	if j.At == nil {
+		return ""
+	}
+
+	return j.At.String()
+}
+
+// tick runs the job when this replica is the leader and the job is due. The
+// lock is only held while the run lasts, so the replicas, whose ticks aren't
+// aligned, tell whether the job is due by the last run of any of them.
+func (s *Scheduler) tick(ctx context.Context, job Job, lockKey string) {
+	leader, err := s.locker.Acquire(ctx, lockKey, lockTTL)
+	if err != nil {
+		log.Errorf("[SCHEDULER] failed to acquire the lock of job %q: %v", job.Name, err)
+
+		return
+	}
+
+	if !leader {
+		return
+	}
+
+	defer func() {
+		if err := s.locker.Release(context.WithoutCancel(ctx), lockKey); err != nil {
+			log.Errorf("[SCHEDULER] failed to release the lock of job %q: %v", job.Name, err)
+		}
+	}()
+
+	if !s.due(ctx, job, time.Now()) {
+		return
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, job.Interval)
+	defer cancel()
+
+	go s.renewLock(runCtx, cancel, job, lockKey)
+
+	startedAt := time.Now()
+	counts, err := job.Run(runCtx)
+
+	status := JobStatus{
+		Name:         job.Name,
+		Interval:     job.Interval,
+		At:           job.at(),
+		LastRunAt:    startedAt,
+		LastDuration: time.Since(startedAt),
+		Counts:       counts,
+		RanBy:        s.locker.Owner(),
+	}
+
+	if err != nil {
+		log.Errorf("[SCHEDULER] job %q failed: %v", job.Name, err)
+
+		status.LastError = err.Error()
+	}
+
+	// The status of a run that ends while the replica shuts down is saved too.
+	s.saveStatus(context.WithoutCancel(ctx), status)
+}
+
+// due reports whether the last run of the job, by any replica, started more
+// than half an interval ago. Half an interval tolerates the drift of the ticks
+// of the leader while another replica ticking in between doesn't run it again.
+func (s *Scheduler) due(ctx context.Context, job Job, now time.Time) bool {
+	previous, err := cache.GetJSON[JobStatus](ctx, s.store, statusKeyPrefix+job.Name)
+	if err != nil {
+		return true
+	}
+
+	return now.Sub(previous.LastRunAt) >= job.Interval/2
+}
+
+// renewLock keeps the lock of the job while the run lasts, and stops the run
+// when another replica took the lock.
+func (s *Scheduler) renewLock(ctx context.Context, cancel context.CancelFunc, job Job, lockKey string) {
+	ticker := time.NewTicker(lockTTL / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		leader, err := s.locker.Acquire(ctx, lockKey, lockTTL)
+		if err != nil {
+			log.Errorf("[SCHEDULER] failed to renew the lock of job %q: %v", job.Name, err)
+
+			continue
+		}
+
+		if !leader {
+			log.Errorf("[SCHEDULER] job %q stopped: its lock was taken by another replica", job.Name)
+			cancel()
+
+			return
+		}
+	}
+}
+
+func (s *Scheduler) saveStatus(ctx context.Context, status JobStatus) {
+	key := statusKeyPrefix + status.Name
+
+	previous, err := cache.GetJSON[JobStatus](ctx, s.store, key)
+	if err == nil {
+		status.Runs = previous.Runs
+	}
+
+	status.Runs++
+
+	if err := cache.SetJSON(ctx, s.store, key, status, 0); err != nil {
+		log.Errorf("[SCHEDULER] failed to save the status of job %q: %v", status.Name, err)
+	}
+}
@@ -0,0 +1,184 @@
+package scheduler_test
+
+import (
+	"context"
+	"errors"
+	"main-api/internal/infra/cache"
+	"main-api/internal/infra/scheduler"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScheduler(t *testing.T) {
+	t.Parallel()
+
+	testRedis := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: testRedis.Addr()})
+	store := cache.NewRedisCacheAdapter(redisClient)
+
+	t.Run("Should run a job in a single replica and save its status", func(t *testing.T) {
+		var runs atomic.Int64
+
+		job := scheduler.Job{
+			Name:     "job-01",
+			Interval: 20 * time.Millisecond,
+			Run: func(ctx context.Context) (scheduler.Counts, error) {
+				runs.Add(1)
+
+				return scheduler.Counts{"expired": 3}, nil
+			},
+		}
+
+		leader := scheduler.New(scheduler.NewRedisLocker(redisClient), store)
+		follower := scheduler.New(scheduler.NewRedisLocker(redisClient), store)
+		leader.Register(job)
+		follower.Register(job)
+
+		ctx, cancel := context.WithTimeout(t.Context(), 110*time.Millisecond)
+		defer cancel()
+
+		var wg sync.WaitGroup
+		for _, replica := range []*scheduler.Scheduler{leader, follower} {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				replica.Run(ctx)
+			}()
+		}
+
+		wg.Wait()
+
+		statuses, err := follower.Status(t.Context())
+
+		assert.NoError(t, err)
+		assert.Len(t, statuses, 1)
+		assert.Equal(t, "job-01", statuses[0].Name)
+		// Both replicas tick about 6 times; only the leader runs the job.
+		assert.LessOrEqual(t, runs.Load(), int64(7))
+		assert.Equal(t, runs.Load(), statuses[0].Runs)
+		assert.Equal(t, scheduler.Counts{"expired": 3}, statuses[0].Counts)
+		assert.NotZero(t, statuses[0].LastRunAt)
+		assert.Empty(t, statuses[0].LastError)
+		assert.False(t, testRedis.Exists("scheduler:lock:job-01"))
+	})
+
+	t.Run("Should save the error of a failed run", func(t *testing.T) {
+		jobs := scheduler.New(scheduler.NewRedisLocker(redisClient), store)
+		jobs.Register(scheduler.Job{
+			Name:     "job-02",
+			Interval: time.Hour,
+			Run: func(ctx context.Context) (scheduler.Counts, error) {
+				return nil, errors.New("database unavailable")
+			},
+		})
+
+		ctx, cancel := context.WithTimeout(t.Context(), 20*time.Millisecond)
+		defer cancel()
+
+		jobs.Run(ctx)
+
+		statuses, err := jobs.Status(t.Context())
+
+		assert.NoError(t, err)
+		assert.Equal(t, "database unavailable", statuses[0].LastError)
+		assert.Equal(t, int64(1), statuses[0].Runs)
+	})
+
+	t.Run("Not should run a job again in another replica before it is due", func(t *testing.T) {
+		var runs atomic.Int64
+
+		job := scheduler.Job{
+			Name:     "job-05",
+			Interval: time.Hour,
+			Run: func(ctx context.Context) (scheduler.Counts, error) {
+				runs.Add(1)
+
+				return nil, nil
+			},
+		}
+
+		for range 2 {
+			replica := scheduler.New(scheduler.NewRedisLocker(redisClient), store)
+			replica.Register(job)
+
+			ctx, cancel := context.WithTimeout(t.Context(), 20*time.Millisecond)
+			replica.Run(ctx)
+			cancel()
+		}
+
+		assert.Equal(t, int64(1), runs.Load())
+		assert.False(t, testRedis.Exists("scheduler:lock:job-05"))
+	})
+
+	t.Run("Should report the jobs that never ran", func(t *testing.T) {
+		jobs := scheduler.New(scheduler.NewRedisLocker(redisClient), store)
+		jobs.Register(scheduler.Job{Name: "job-03", Interval: time.Minute})
+
+		statuses, err := jobs.Status(t.Context())
+
+		assert.NoError(t, err)
+		assert.Equal(t, []scheduler.JobStatus{{Name: "job-03", Interval: time.Minute}}, statuses)
+	})
+	t.Run("Not should run a daily job before its time", func(t *testing.T) {
+		var runs atomic.Int64
+
+		at := scheduler.TimeOfDay{Hour: time.Now().Add(2 * time.Hour).Hour(), Location: time.Local}
+
+		jobs := scheduler.New(scheduler.NewRedisLocker(redisClient), store)
+		jobs.Register(scheduler.Daily("job-04", at, func(ctx context.Context) (scheduler.Counts, error) {
+			runs.Add(1)
+
+			return nil, nil
+		}))
+
+		ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+		defer cancel()
+
+		jobs.Run(ctx)
+
+		statuses, err := jobs.Status(t.Context())
+
+		assert.NoError(t, err)
+		assert.Zero(t, runs.Load())
+		assert.Equal(t, 24*time.Hour, statuses[0].Interval)
+		assert.Equal(t, at.String(), statuses[0].At)
+	})
+}
+
+func TestTimeOfDay(t *testing.T) {
+	t.Parallel()
+
+	location := time.FixedZone("UTC-3", -3*60*60)
+
+	t.Run("Should parse a time of day", func(t *testing.T) {
+		at, err := scheduler.ParseTimeOfDay("03:30", location)
+
+		assert.NoError(t, err)
+		assert.Equal(t, scheduler.TimeOfDay{Hour: 3, Minute: 30, Location: location}, at)
+	})
+
+	t.Run("Not should parse an invalid time of day", func(t *testing.T) {
+		_, err := scheduler.ParseTimeOfDay("25:00", location)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("Should return the next time of day in its location", func(t *testing.T) {
+		at := scheduler.TimeOfDay{Hour: 3, Location: location}
+
+		// 05:00 UTC is 02:00 in UTC-3.
+		before := time.Date(2025, 3, 10, 5, 0, 0, 0, time.UTC)
+		assert.Equal(t, time.Date(2025, 3, 10, 3, 0, 0, 0, location), at.Next(before))
+
+		after := time.Date(2025, 3, 10, 6, 0, 0, 0, time.UTC)
+		assert.Equal(t, time.Date(2025, 3, 11, 3, 0, 0, 0, location), at.Next(after))
+	})
+}
@@ -0,0 +1,27 @@
+package jobs
+
+import (
+	"context"
+	"main-api/internal/domain/partners"
+	"main-api/internal/infra/scheduler"
+	"time"
+)
+
+const RecoverPolicyIssuancesJobName = "recover-policy-issuances"
+
+// NewRecoverPolicyIssuances resolves the policy issuances left pending,
+// cancelling at the provider the policies we failed to store.
+func NewRecoverPolicyIssuances(service partners.Service, interval time.Duration) scheduler.Job {
+	return scheduler.Job{
+		Name:     RecoverPolicyIssuancesJobName,
+		Interval: interval,
+		Run: func(ctx context.Context) (scheduler.Counts, error) {
+			result, err := service.RecoverPolicyIssuances(ctx)
+
+			return scheduler.Counts{
+				"compensated": result.Compensated,
+				"failed":      result.Failed,
+			}, err
+		},
+	}
+}
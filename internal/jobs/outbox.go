@@ -0,0 +1,28 @@
+package jobs
+
+import (
+	"context"
+	"main-api/internal/infra/outbox"
+	"main-api/internal/infra/scheduler"
+	"time"
+)
+
+const RelayOutboxJobName = "relay-outbox"
+
+// NewRelayOutbox publishes the events stored in the outbox. Running it as a
+// scheduler job keeps a single relay across the replicas, which the ordering
+// of the events of each partner depends on.
+func NewRelayOutbox(relay *outbox.Relay, interval time.Duration) scheduler.Job {
+	return scheduler.Job{
+		Name:     RelayOutboxJobName,
+		Interval: interval,
+		Run: func(ctx context.Context) (scheduler.Counts, error) {
+			result, err := relay.RelayPending(ctx)
+
+			return scheduler.Counts{
+				"published": result.Published,
+				"failed":    result.Failed,
+			}, err
+		},
+	}
+}
@@ -0,0 +1,27 @@
+package jobs
+
+import (
+	"context"
+	"main-api/internal/domain/partners"
+	"main-api/internal/infra/scheduler"
+	"time"
+)
+
+const ExpireQuotesJobName = "expire-quotes"
+
+// NewExpireQuotes marks the quotes past their expiration date as expired. The
+// expired quotes are deleted by MongoDB once their retention ends.
+func NewExpireQuotes(service partners.Service, interval time.Duration) scheduler.Job {
+	return scheduler.Job{
+		Name:     ExpireQuotesJobName,
+		Interval: interval,
+		Run: func(ctx context.Context) (scheduler.Counts, error) {
+			expired, err := service.ExpireQuotes(ctx)
+			if err != nil {
+				return nil, err
+			}
+
+			return scheduler.Counts{"expired": expired}, nil
+		},
+	}
+}
@@ -0,0 +1,30 @@
+package jobs
+
+import (
+	"context"
+	"main-api/internal/domain/partners"
+	"main-api/internal/infra/scheduler"
+
+	"github.com/gofiber/fiber/v2/log"
+)
+
+const ReconcilePoliciesJobName = "reconcile-policies"
+
+// NewReconcilePolicies compares our policies with the provider's every day at
+// the given time, recording the mismatches and filling in the fields we lack.
+func NewReconcilePolicies(service partners.Service, at scheduler.TimeOfDay) scheduler.Job {
+	return scheduler.Daily(ReconcilePoliciesJobName, at, func(ctx context.Context) (scheduler.Counts, error) {
+		result, err := service.ReconcilePolicies(ctx)
+
+		for _, policyErr := range result.Errors {
+			log.Errorf("[RECONCILIATION] failed to reconcile: %v", policyErr)
+		}
+
+		return scheduler.Counts{
+			"checked":    result.Checked,
+			"mismatches": result.Mismatches,
+			"healed":     result.Healed,
+			"failed":     result.Failed,
+		}, err
+	})
+}
@@ -0,0 +1,28 @@
+package jobs
+
+import (
+	"context"
+	"main-api/internal/domain/partners"
+	"main-api/internal/infra/scheduler"
+	"time"
+)
+
+const DeliverWebhooksJobName = "deliver-webhooks"
+
+// NewDeliverWebhooks sends the webhook deliveries due, retrying the failed
+// ones until they go to the dead letter list.
+func NewDeliverWebhooks(service partners.Service, interval time.Duration) scheduler.Job {
+	return scheduler.Job{
+		Name:     DeliverWebhooksJobName,
+		Interval: interval,
+		Run: func(ctx context.Context) (scheduler.Counts, error) {
+			result, err := service.DeliverWebhooks(ctx)
+
+			return scheduler.Counts{
+				"delivered": result.Delivered,
+				"retried":   result.Retried,
+				"dead":      result.Dead,
+			}, err
+		},
+	}
+}
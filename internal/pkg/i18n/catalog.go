@@ -0,0 +1,225 @@
+package i18n
+
+import "strings"
+
+type (
+	// Error is an error identified by a catalog code, so its message can be
+	// rendered in the caller's language. Message is the English text used in
+	// logs and when the catalog has no entry for the code.
+	Error struct {
+		Status  int
+		Code    string
+		Message string
+	}
+)
+
+// catalog holds the messages by code. "{0}" is replaced by the name of the
+// field the message refers to, the same placeholder the validator
+// translations use.
+var catalog = map[string]map[Language]string{
+	// Problem titles, keyed by problem kind.
+	"validation-error": {
+		PortugueseBR: "Os parâmetros da requisição são inválidos.",
+		English:      "Your request parameters didn't validate.",
+	},
+	"bad-request": {
+		PortugueseBR: "Requisição inválida",
+		English:      "Bad Request",
+	},
+	"unauthorized": {
+		PortugueseBR: "Não autorizado",
+		English:      "Unauthorized",
+	},
+	"forbidden": {
+		PortugueseBR: "Acesso negado",
+		English:      "Forbidden",
+	},
+	"not-found": {
+		PortugueseBR: "Não encontrado",
+		English:      "Not Found",
+	},
+	"conflict": {
+		PortugueseBR: "Conflito",
+		English:      "Conflict",
+	},
+	"unprocessable-entity": {
+		PortugueseBR: "Entidade não processável",
+		English:      "Unprocessable Entity",
+	},
+	"too-many-requests": {
+		PortugueseBR: "Muitas requisições",
+		English:      "Too Many Requests",
+	},
+	"internal-error": {
+		PortugueseBR: "Erro interno do servidor",
+		English:      "Internal Server Error",
+	},
+	"bad-gateway": {
+		PortugueseBR: "Gateway inválido",
+		English:      "Bad Gateway",
+	},
+	"service-unavailable": {
+		PortugueseBR: "Serviço indisponível",
+		English:      "Service Unavailable",
+	},
+	"gateway-timeout": {
+		PortugueseBR: "Tempo de resposta do gateway esgotado",
+		English:      "Gateway Timeout",
+	},
+	"payload-too-large": {
+		PortugueseBR: "Corpo da requisição muito grande",
+		English:      "Request Entity Too Large",
+	},
+
+	// Domain errors.
+	"internal_error": {
+		PortugueseBR: "erro interno do servidor",
+		English:      "internal server error",
+	},
+	"partner_already_exists": {
+		PortugueseBR: "parceiro já cadastrado",
+		English:      "partner already exists",
+	},
+	"partner_not_found": {
+		PortugueseBR: "parceiro não encontrado",
+		English:      "partner not found",
+	},
+	"partner_suspended": {
+		PortugueseBR: "o parceiro está suspenso",
+		English:      "partner is suspended",
+	},
+	"policy_not_found": {
+		PortugueseBR: "apólice não encontrada",
+		English:      "policy not found",
+	},
+	"quote_not_found": {
+		PortugueseBR: "cotação não encontrada",
+		English:      "quote not found",
+	},
+	"customer_already_exists": {
+		PortugueseBR: "cliente já cadastrado",
+		English:      "customer already exists",
+	},
+	"customer_not_found": {
+		PortugueseBR: "cliente não encontrado",
+		English:      "customer not found",
+	},
+	"data_subject_not_found": {
+		PortugueseBR: "nenhum dado pessoal encontrado para o cpf",
+		English:      "no personal data found for the cpf",
+	},
+	"webhook_not_found": {
+		PortugueseBR: "webhook não encontrado",
+		English:      "webhook not found",
+	},
+	"webhook_delivery_not_found": {
+		PortugueseBR: "entrega de webhook não encontrada",
+		English:      "webhook delivery not found",
+	},
+	"invalid_webhook_event_type": {
+		PortugueseBR: "tipo de evento de webhook inválido",
+		English:      "invalid webhook event type",
+	},
+	"invalid_webhook_url": {
+		PortugueseBR: "a url do webhook deve ser http ou https",
+		English:      "webhook url must be an http or https url",
+	},
+	"beneficiaries_percentage_sum": {
+		PortugueseBR: "a soma dos percentuais dos beneficiários deve ser 100",
+		English:      "beneficiaries percentages must add up to 100",
+	},
+	"invalid_beneficiary_percentage": {
+		PortugueseBR: "o percentual do beneficiário deve ser maior que 0",
+		English:      "beneficiary percentage must be greater than 0",
+	},
+	"invalid_relationship": {
+		PortugueseBR: "tipo de parentesco inválido",
+		English:      "invalid relationship type",
+	},
+	"invalid_dependent_relationship": {
+		PortugueseBR: "o parentesco do dependente deve ser spouse, child ou parent",
+		English:      "dependent relationship must be spouse, child or parent",
+	},
+
+	// Idempotency errors.
+	"invalid_idempotency_key": {
+		PortugueseBR: "a chave de idempotência deve ter entre 1 e 255 caracteres",
+		English:      "idempotency key must have between 1 and 255 characters",
+	},
+	"idempotency_key_reused": {
+		PortugueseBR: "a chave de idempotência já foi usada em uma requisição diferente",
+		English:      "idempotency key was already used with a different request",
+	},
+	"idempotency_request_in_progress": {
+		PortugueseBR: "uma requisição com esta chave de idempotência ainda está em andamento",
+		English:      "a request with this idempotency key is still in progress",
+	},
+
+	// Rate limit errors.
+	"rate_limit_exceeded": {
+		PortugueseBR: "limite de requisições excedido, tente novamente mais tarde",
+		English:      "rate limit exceeded, try again later",
+	},
+	"daily_quota_exceeded": {
+		PortugueseBR: "cota diária excedida, tente novamente amanhã",
+		English:      "daily quota exceeded, try again tomorrow",
+	},
+	"provider_unavailable": {
+		PortugueseBR: "a seguradora está indisponível, tente novamente mais tarde",
+		English:      "the insurance provider is unavailable, try again later",
+	},
+	"provider_rate_limited": {
+		PortugueseBR: "a seguradora está sobrecarregada, tente novamente mais tarde",
+		English:      "the insurance provider is busy, try again later",
+	},
+
+	// Field errors raised by domain rules.
+	"datetime": {
+		PortugueseBR: "{0} deve ser uma data válida no formato AAAA-MM-DD",
+		English:      "{0} must be a valid date in the format YYYY-MM-DD",
+	},
+	"past_date": {
+		PortugueseBR: "{0} não pode estar no futuro",
+		English:      "{0} can't be in the future",
+	},
+	"age_range": {
+		PortugueseBR: "a idade do titular deve estar entre 0 e 120 anos",
+		English:      "holder age must be between 0 and 120 years",
+	},
+	"quotation_age": {
+		PortugueseBR: "{0} não corresponde à idade da cotação",
+		English:      "{0} doesn't match the quotation age",
+	},
+}
+
+func NewError(status int, code, message string) *Error {
+	return &Error{
+		Status:  status,
+		Code:    code,
+		Message: message,
+	}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Message renders the message of a code in the given language, falling back to
+// English. The second return is false when the catalog doesn't know the code.
+func Message(language Language, code string, field ...string) (string, bool) {
+	messages, ok := catalog[code]
+	if !ok {
+		return "", false
+	}
+
+	message, ok := messages[language]
+	if !ok {
+		message = messages[English]
+	}
+
+	if len(field) > 0 {
+		message = strings.ReplaceAll(message, "{0}", field[0])
+	}
+
+	return message, true
+}
@@ -0,0 +1,109 @@
+package i18n
+
+import (
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type (
+	Language string
+)
+
+const (
+	PortugueseBR Language = "pt-BR"
+	English      Language = "en"
+
+	// DefaultLanguage answers the requests that neither ask for a language nor
+	// belong to a partner that chose one, as the API did before it was
+	// localized.
+	DefaultLanguage = English
+	// PartnerDefaultLanguage is given to the partners registered without one.
+	PartnerDefaultLanguage = PortugueseBR
+
+	partnerLanguageLocal = "partner_language"
+)
+
+var Supported = []Language{PortugueseBR, English}
+
+// Parse matches a language tag ("pt", "pt-br", "en-US") to a supported
+// language.
+func Parse(tag string) (Language, bool) {
+	base := strings.ToLower(strings.SplitN(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-"), "-", 2)[0])
+
+	switch base {
+	case "pt":
+		return PortugueseBR, true
+	case "en":
+		return English, true
+	}
+
+	return "", false
+}
+
+// Negotiate picks the supported language with the highest weight in an
+// Accept-Language header.
+func Negotiate(acceptLanguage string) (Language, bool) {
+	type weighted struct {
+		tag    string
+		weight float64
+	}
+
+	candidates := []weighted{}
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		weight := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+
+			weight = parsed
+		}
+
+		if weight > 0 {
+			candidates = append(candidates, weighted{tag: tag, weight: weight})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].weight > candidates[j].weight
+	})
+
+	for _, candidate := range candidates {
+		if language, ok := Parse(candidate.tag); ok {
+			return language, true
+		}
+	}
+
+	return "", false
+}
+
+// SetPartnerLanguage records the language chosen by the partner the request
+// belongs to, once the request has loaded it.
+func SetPartnerLanguage(c *fiber.Ctx, language Language) {
+	c.Locals(partnerLanguageLocal, language)
+}
+
+// FromRequest resolves the language of a response: the Accept-Language header
+// first, then the language of the partner loaded by the request, then
+// DefaultLanguage.
+func FromRequest(c *fiber.Ctx) Language {
+	if language, ok := Negotiate(c.Get(fiber.HeaderAcceptLanguage)); ok {
+		return language
+	}
+
+	if language, ok := c.Locals(partnerLanguageLocal).(Language); ok && slices.Contains(Supported, language) {
+		return language
+	}
+
+	return DefaultLanguage
+}
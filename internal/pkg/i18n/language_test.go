@@ -0,0 +1,60 @@
+package i18n_test
+
+import (
+	"main-api/internal/pkg/i18n"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNegotiate(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []struct {
+		header   string
+		expected i18n.Language
+		ok       bool
+	}{
+		{header: "pt-BR", expected: i18n.PortugueseBR, ok: true},
+		{header: "pt", expected: i18n.PortugueseBR, ok: true},
+		{header: "en-US,en;q=0.9", expected: i18n.English, ok: true},
+		{header: "fr-FR,en;q=0.5,pt-BR;q=0.8", expected: i18n.PortugueseBR, ok: true},
+		{header: "en;q=0,pt;q=0.1", expected: i18n.PortugueseBR, ok: true},
+		{header: "fr-FR,de", ok: false},
+		{header: "*", ok: false},
+		{header: "", ok: false},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.header, func(t *testing.T) {
+			language, ok := i18n.Negotiate(scenario.header)
+
+			assert.Equal(t, scenario.ok, ok)
+			assert.Equal(t, scenario.expected, language)
+		})
+	}
+}
+
+func TestMessage(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Should render the message in the given language", func(t *testing.T) {
+		message, ok := i18n.Message(i18n.PortugueseBR, "partner_not_found")
+
+		assert.True(t, ok)
+		assert.Equal(t, "parceiro não encontrado", message)
+	})
+
+	t.Run("Should replace the field placeholder", func(t *testing.T) {
+		message, ok := i18n.Message(i18n.English, "past_date", "date_of_birth")
+
+		assert.True(t, ok)
+		assert.Equal(t, "date_of_birth can't be in the future", message)
+	})
+
+	t.Run("Not should render an unknown code", func(t *testing.T) {
+		_, ok := i18n.Message(i18n.English, "unknown_code")
+
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,57 @@
+package validator
+
+import (
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+const cpfLength = 11
+
+// NormalizeCPF strips the punctuation of a formatted CPF ("123.456.789-09"),
+// keeping only its digits.
+func NormalizeCPF(cpf string) string {
+	var builder strings.Builder
+
+	for _, char := range cpf {
+		if char >= '0' && char <= '9' {
+			builder.WriteRune(char)
+		}
+	}
+
+	return builder.String()
+}
+
+func IsValidCPF(cpf string) bool {
+	digits := NormalizeCPF(cpf)
+	if len(digits) != cpfLength {
+		return false
+	}
+
+	if strings.Count(digits, digits[:1]) == cpfLength {
+		return false
+	}
+
+	return cpfCheckDigit(digits[:9]) == digits[9] && cpfCheckDigit(digits[:10]) == digits[10]
+}
+
+func cpfCheckDigit(digits string) byte {
+	sum := 0
+	weight := len(digits) + 1
+
+	for _, char := range digits {
+		sum += int(char-'0') * weight
+		weight--
+	}
+
+	rest := (sum * 10) % 11
+	if rest == 10 {
+		rest = 0
+	}
+
+	return byte('0' + rest)
+}
+
+func validateCPF(fl validator.FieldLevel) bool {
+	return IsValidCPF(fl.Field().String())
+}
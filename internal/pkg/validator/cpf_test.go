@@ -0,0 +1,49 @@
+package validator_test
+
+import (
+	"main-api/internal/pkg/validator"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsValidCPF(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should accept valid cpfs with or without punctuation", func(t *testing.T) {
+		assert.True(t, validator.IsValidCPF("52998224725"))
+		assert.True(t, validator.IsValidCPF("529.982.247-25"))
+		assert.True(t, validator.IsValidCPF("11144477735"))
+	})
+
+	t.Run("should reject cpfs with wrong check digits", func(t *testing.T) {
+		assert.False(t, validator.IsValidCPF("52998224724"))
+		assert.False(t, validator.IsValidCPF("11144477730"))
+	})
+
+	t.Run("should reject cpfs with repeated digits or wrong length", func(t *testing.T) {
+		assert.False(t, validator.IsValidCPF("11111111111"))
+		assert.False(t, validator.IsValidCPF("5299822472"))
+		assert.False(t, validator.IsValidCPF(""))
+	})
+}
+
+func TestBodyDataCPFTag(t *testing.T) {
+	t.Parallel()
+
+	type payload struct {
+		Cpf string `validate:"required,cpf"`
+	}
+
+	t.Run("should not return error when cpf is valid", func(t *testing.T) {
+		assert.Nil(t, validator.BodyData(&payload{Cpf: "529.982.247-25"}))
+	})
+
+	t.Run("should return error when cpf is invalid", func(t *testing.T) {
+		err := validator.BodyData(&payload{Cpf: "12345678900"})
+
+		assert.NotNil(t, err)
+		assert.Equal(t, "Cpf", err.Errors[0].Field)
+		assert.Equal(t, "cpf", err.Errors[0].Code)
+	})
+}
@@ -0,0 +1,137 @@
+package validator
+
+import (
+	"errors"
+	"main-api/internal/pkg/i18n"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+type (
+	// ProblemDetails is the RFC 7807 body returned for every error response.
+	ProblemDetails struct {
+		Type     string       `json:"type"`
+		Title    string       `json:"title"`
+		Status   int          `json:"status"`
+		Detail   string       `json:"detail,omitempty"`
+		Instance string       `json:"instance,omitempty"`
+		Errors   []FieldError `json:"errors,omitempty"`
+	}
+)
+
+const (
+	ProblemContentType = "application/problem+json"
+
+	problemTypeBaseURI = "/problems/"
+
+	validationErrorKind = "validation-error"
+)
+
+var (
+	errInternal = i18n.NewError(fiber.StatusInternalServerError, "internal_error", "internal server error")
+
+	problemKinds = map[int]string{
+		fiber.StatusBadRequest:            "bad-request",
+		fiber.StatusUnauthorized:          "unauthorized",
+		fiber.StatusForbidden:             "forbidden",
+		fiber.StatusNotFound:              "not-found",
+		fiber.StatusConflict:              "conflict",
+		fiber.StatusUnprocessableEntity:   "unprocessable-entity",
+		fiber.StatusTooManyRequests:       "too-many-requests",
+		fiber.StatusInternalServerError:   "internal-error",
+		fiber.StatusBadGateway:            "bad-gateway",
+		fiber.StatusServiceUnavailable:    "service-unavailable",
+		fiber.StatusGatewayTimeout:        "gateway-timeout",
+		fiber.StatusRequestEntityTooLarge: "payload-too-large",
+	}
+)
+
+// ProblemType returns the type URI that identifies the kind of an error.
+func ProblemType(kind string) string {
+	return problemTypeBaseURI + kind
+}
+
+// ErrorHandler renders errors in the language asked by the Accept-Language
+// header, falling back to the language of the partner loaded by the request
+// and then to the default language.
+func ErrorHandler(c *fiber.Ctx, err error) error {
+	language := i18n.FromRequest(c)
+	c.Set(fiber.HeaderContentLanguage, string(language))
+
+	return SendProblem(c, NewProblem(err, c.OriginalURL(), language))
+}
+
+func NewProblem(err error, instance string, language i18n.Language) ProblemDetails {
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		return validationProblem(validationErr.Errors, instance, language)
+	}
+
+	var fieldErr *FieldError
+	if errors.As(err, &fieldErr) {
+		return validationProblem([]FieldError{*fieldErr}, instance, language)
+	}
+
+	var localizedErr *i18n.Error
+	if errors.As(err, &localizedErr) {
+		return statusProblem(localizedErr.Status, localizedMessage(language, localizedErr), instance, language)
+	}
+
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return statusProblem(fiberErr.Code, fiberErr.Message, instance, language)
+	}
+
+	return statusProblem(fiber.StatusInternalServerError, localizedMessage(language, errInternal), instance, language)
+}
+
+func SendProblem(c *fiber.Ctx, problem ProblemDetails) error {
+	return c.Status(problem.Status).JSON(problem, ProblemContentType)
+}
+
+func validationProblem(errs []FieldError, instance string, language i18n.Language) ProblemDetails {
+	localized := make([]FieldError, len(errs))
+	for index, err := range errs {
+		localized[index] = err.Localize(language)
+	}
+
+	title, _ := i18n.Message(language, validationErrorKind)
+
+	return ProblemDetails{
+		Type:     ProblemType(validationErrorKind),
+		Title:    title,
+		Status:   fiber.StatusBadRequest,
+		Instance: instance,
+		Errors:   localized,
+	}
+}
+
+func statusProblem(status int, detail, instance string, language i18n.Language) ProblemDetails {
+	problemType := "about:blank"
+	title := utils.StatusMessage(status)
+
+	if kind, ok := problemKinds[status]; ok {
+		problemType = ProblemType(kind)
+
+		if message, ok := i18n.Message(language, kind); ok {
+			title = message
+		}
+	}
+
+	return ProblemDetails{
+		Type:     problemType,
+		Title:    title,
+		Status:   status,
+		Detail:   detail,
+		Instance: instance,
+	}
+}
+
+func localizedMessage(language i18n.Language, err *i18n.Error) string {
+	if message, ok := i18n.Message(language, err.Code); ok {
+		return message
+	}
+
+	return err.Message
+}
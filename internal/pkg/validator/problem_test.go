@@ -0,0 +1,158 @@
+package validator_test
+
+import (
+	"encoding/json"
+	"errors"
+	"main-api/internal/pkg/i18n"
+	"main-api/internal/pkg/validator"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorHandler(t *testing.T) {
+	t.Parallel()
+
+	type payload struct {
+		DateOfBirth string  `json:"date_of_birth" validate:"required,datetime=2006-01-02"`
+		Percentage  float64 `json:"percentage" validate:"gt=0"`
+	}
+
+	type holderPayload struct {
+		PartnerCustomerID string `json:"partner_customer_id"`
+		Cpf               string `json:"cpf" validate:"excluded_with=PartnerCustomerID"`
+	}
+
+	app := fiber.New(fiber.Config{ErrorHandler: validator.ErrorHandler})
+	app.Get("/validation", func(c *fiber.Ctx) error {
+		return validator.BodyData(&payload{DateOfBirth: "28/09/1998", Percentage: -1})
+	})
+	app.Get("/excluded", func(c *fiber.Ctx) error {
+		return validator.BodyData(&holderPayload{PartnerCustomerID: "customer-01", Cpf: "12345678909"})
+	})
+	app.Get("/field", func(c *fiber.Ctx) error {
+		return validator.NewFieldError("date_of_birth", "quotation_age", "date of birth doesn't match the quotation age")
+	})
+	app.Get("/not-found", func(c *fiber.Ctx) error {
+		return fiber.NewError(fiber.StatusNotFound, "partner not found")
+	})
+	app.Get("/internal", func(c *fiber.Ctx) error {
+		return errors.New("database is down")
+	})
+
+	app.Get("/localized", func(c *fiber.Ctx) error {
+		return i18n.NewError(fiber.StatusNotFound, "partner_not_found", "partner not found")
+	})
+	app.Get("/partner-localized", func(c *fiber.Ctx) error {
+		i18n.SetPartnerLanguage(c, i18n.PortugueseBR)
+
+		return i18n.NewError(fiber.StatusNotFound, "partner_not_found", "partner not found")
+	})
+
+	doRequest := func(t *testing.T, path string, acceptLanguage ...string) (*http.Response, validator.ProblemDetails) {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if len(acceptLanguage) > 0 {
+			req.Header.Set(fiber.HeaderAcceptLanguage, acceptLanguage[0])
+		}
+
+		resp, err := app.Test(req, -1)
+		assert.NoError(t, err)
+
+		defer resp.Body.Close()
+
+		var problem validator.ProblemDetails
+		err = json.NewDecoder(resp.Body).Decode(&problem)
+		assert.NoError(t, err)
+
+		return resp, problem
+	}
+
+	t.Run("should return validation errors with json field names", func(t *testing.T) {
+		resp, problem := doRequest(t, "/validation")
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		assert.Equal(t, validator.ProblemContentType, resp.Header.Get(fiber.HeaderContentType))
+		assert.Equal(t, validator.ProblemType("validation-error"), problem.Type)
+		assert.Equal(t, "/validation", problem.Instance)
+		assert.Len(t, problem.Errors, 2)
+		assert.Equal(t, "date_of_birth", problem.Errors[0].Field)
+		assert.Equal(t, "datetime", problem.Errors[0].Code)
+		assert.Equal(t, "28/09/1998", problem.Errors[0].RejectedValue)
+		assert.Equal(t, "percentage", problem.Errors[1].Field)
+		assert.Equal(t, "gt", problem.Errors[1].Code)
+	})
+
+	t.Run("should return domain field errors in the same format", func(t *testing.T) {
+		resp, problem := doRequest(t, "/field")
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		assert.Equal(t, validator.ProblemType("validation-error"), problem.Type)
+		assert.Equal(t, "quotation_age", problem.Errors[0].Code)
+	})
+
+	t.Run("should return a problem typed after the status code", func(t *testing.T) {
+		resp, problem := doRequest(t, "/not-found")
+
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		assert.Equal(t, validator.ProblemType("not-found"), problem.Type)
+		assert.Equal(t, "partner not found", problem.Detail)
+		assert.Empty(t, problem.Errors)
+	})
+
+	t.Run("should hide unexpected errors", func(t *testing.T) {
+		resp, problem := doRequest(t, "/internal")
+
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+		assert.Equal(t, validator.ProblemType("internal-error"), problem.Type)
+		assert.Equal(t, "internal server error", problem.Detail)
+	})
+
+	t.Run("should default to the language of the partner loaded by the request", func(t *testing.T) {
+		resp, problem := doRequest(t, "/partner-localized")
+
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		assert.Equal(t, "pt-BR", resp.Header.Get(fiber.HeaderContentLanguage))
+		assert.Equal(t, "Não encontrado", problem.Title)
+		assert.Equal(t, "parceiro não encontrado", problem.Detail)
+
+		resp, problem = doRequest(t, "/partner-localized", "en")
+
+		assert.Equal(t, "en", resp.Header.Get(fiber.HeaderContentLanguage))
+		assert.Equal(t, "partner not found", problem.Detail)
+	})
+
+	t.Run("should translate messages to the language asked by the client", func(t *testing.T) {
+		_, problem := doRequest(t, "/localized", "en-US,en;q=0.9")
+
+		assert.Equal(t, "Not Found", problem.Title)
+		assert.Equal(t, "partner not found", problem.Detail)
+
+		_, problem = doRequest(t, "/validation", "en")
+
+		assert.Equal(t, "date_of_birth does not match the 2006-01-02 format", problem.Errors[0].Message)
+		assert.Equal(t, "percentage must be greater than 0", problem.Errors[1].Message)
+
+		_, problem = doRequest(t, "/validation", "pt-BR")
+
+		assert.Equal(t, "date_of_birth deve ser uma data no formato 2006-01-02", problem.Errors[0].Message)
+		assert.Equal(t, "percentage deve ser maior do que 0", problem.Errors[1].Message)
+
+		_, problem = doRequest(t, "/field", "pt-BR")
+
+		assert.Equal(t, "date_of_birth não corresponde à idade da cotação", problem.Errors[0].Message)
+	})
+
+	t.Run("should name the field a field must not be sent with", func(t *testing.T) {
+		_, problem := doRequest(t, "/excluded", "en")
+
+		assert.Equal(t, "excluded_with", problem.Errors[0].Code)
+		assert.Equal(t, "cpf must not be sent with partner_customer_id", problem.Errors[0].Message)
+
+		_, problem = doRequest(t, "/excluded", "pt-BR")
+
+		assert.Equal(t, "cpf não deve ser enviado junto com partner_customer_id", problem.Errors[0].Message)
+	})
+}
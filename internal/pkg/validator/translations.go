@@ -0,0 +1,134 @@
+package validator
+
+import (
+	"main-api/internal/pkg/i18n"
+	"strings"
+	"unicode"
+
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/pt_BR"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	enTranslations "github.com/go-playground/validator/v10/translations/en"
+	ptBRTranslations "github.com/go-playground/validator/v10/translations/pt_BR"
+)
+
+var (
+	translators = map[i18n.Language]ut.Translator{}
+
+	// customTranslations covers the tags the go-playground catalogs don't
+	// translate, either because they're ours or because the locale lacks them,
+	// and excluded_with, whose default message doesn't tell what to leave out.
+	customTranslations = map[i18n.Language]map[string]string{
+		i18n.English: {
+			"cpf":           "{0} must be a valid CPF",
+			"mongodb":       "{0} must be a valid id",
+			"excluded_with": "{0} must not be sent with {1}",
+		},
+		i18n.PortugueseBR: {
+			"cpf":              "{0} deve ser um CPF válido",
+			"mongodb":          "{0} deve ser um id válido",
+			"datetime":         "{0} deve ser uma data no formato {1}",
+			"e164":             "{0} deve ser um telefone no formato E.164",
+			"required_without": "{0} é um campo obrigatório",
+			"excluded_with":    "{0} não deve ser enviado junto com {1}",
+		},
+	}
+
+	// fieldParamTags are the tags whose param names other fields of the
+	// struct, by their Go name.
+	fieldParamTags = map[string]bool{
+		"excluded_with": true,
+	}
+)
+
+func registerTranslations(v *validator.Validate) {
+	english := en.New()
+	universal := ut.New(english, english, pt_BR.New())
+
+	enTranslator, _ := universal.GetTranslator(english.Locale())
+	ptBRTranslator, _ := universal.GetTranslator("pt_BR")
+
+	_ = enTranslations.RegisterDefaultTranslations(v, enTranslator)
+	_ = ptBRTranslations.RegisterDefaultTranslations(v, ptBRTranslator)
+
+	translators[i18n.English] = enTranslator
+	translators[i18n.PortugueseBR] = ptBRTranslator
+
+	for language, messages := range customTranslations {
+		for tag, message := range messages {
+			_ = v.RegisterTranslation(tag, translators[language], addTranslation(tag, message), translate)
+		}
+	}
+}
+
+func addTranslation(tag, message string) validator.RegisterTranslationsFunc {
+	return func(trans ut.Translator) error {
+		return trans.Add(tag, message, true)
+	}
+}
+
+func translate(trans ut.Translator, err validator.FieldError) string {
+	param := err.Param()
+	if fieldParamTags[err.Tag()] {
+		param = paramFieldNames(param)
+	}
+
+	message, translateErr := trans.T(err.Tag(), err.Field(), param)
+	if translateErr != nil {
+		return err.Error()
+	}
+
+	return message
+}
+
+// paramFieldNames names the fields of a param after the key the client sends,
+// as fieldName does, assuming the snake case keys of the payloads.
+func paramFieldNames(param string) string {
+	fields := strings.Fields(param)
+	for i, field := range fields {
+		fields[i] = snakeCase(field)
+	}
+
+	return strings.Join(fields, ", ")
+}
+
+// snakeCase turns "CustomerID" into "customer_id".
+func snakeCase(name string) string {
+	runes := []rune(name)
+
+	var builder strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			startsWord := i > 0 && (unicode.IsLower(runes[i-1]) || i+1 < len(runes) && unicode.IsLower(runes[i+1]))
+			if startsWord {
+				builder.WriteByte('_')
+			}
+
+			r = unicode.ToLower(r)
+		}
+
+		builder.WriteRune(r)
+	}
+
+	return builder.String()
+}
+
+// Localize renders the message of a field error in the given language. Struct
+// tag failures go through the validator translations, domain errors through
+// the i18n catalog, and anything else keeps its original message.
+func (e FieldError) Localize(language i18n.Language) FieldError {
+	if e.source != nil {
+		if trans, ok := translators[language]; ok {
+			e.Message = e.source.Translate(trans)
+		}
+
+		return e
+	}
+
+	if message, ok := i18n.Message(language, e.Code, e.Field); ok {
+		e.Message = message
+	}
+
+	return e
+}
@@ -1,81 +1,120 @@
 package validator
 
 import (
+	"errors"
 	"fmt"
+	"main-api/internal/pkg/i18n"
+	"reflect"
 	"strings"
 
 	"github.com/go-playground/validator/v10"
-	"github.com/gofiber/fiber/v2"
 )
 
 type (
-	ErrorResponse struct {
-		Error       bool
-		FailedField string
-		Tag         string
-		Value       interface{}
+	// FieldError reports a validation failure on a single payload field. Struct
+	// tag failures are converted to it, and domain rules that can't be expressed
+	// as tags build it directly.
+	FieldError struct {
+		Field         string      `json:"field"`
+		Code          string      `json:"code"`
+		Message       string      `json:"message"`
+		RejectedValue interface{} `json:"rejected_value"`
+
+		source validator.FieldError
 	}
 
-	GlobalErrorHandlerResp struct {
-		Success bool   `json:"success"`
-		Message string `json:"message"`
+	ValidationError struct {
+		Errors []FieldError
 	}
 )
 
-var validate = validator.New()
+var validate = newValidator()
 
-func BodyData(data interface{}) *fiber.Error {
-	validationErrors := []ErrorResponse{}
+func newValidator() *validator.Validate {
+	v := validator.New()
 
-	errs := validate.Struct(data)
-	if errs != nil {
-		for _, err := range errs.(validator.ValidationErrors) {
-			var elem ErrorResponse
+	v.RegisterTagNameFunc(fieldName)
+	_ = v.RegisterValidation("cpf", validateCPF)
+	registerTranslations(v)
+
+	return v
+}
 
-			elem.FailedField = err.Field()
-			elem.Tag = err.Tag()
-			elem.Value = err.Value()
-			elem.Error = true
+func BodyData(data interface{}) *ValidationError {
+	validationErrors := []FieldError{}
+
+	errs := validate.Struct(data)
 
-			validationErrors = append(validationErrors, elem)
+	var fieldErrs validator.ValidationErrors
+	if errors.As(errs, &fieldErrs) {
+		for _, err := range fieldErrs {
+			validationErrors = append(validationErrors, FieldError{
+				Field:         fieldPath(err),
+				Code:          err.Tag(),
+				Message:       err.Translate(translators[i18n.English]),
+				RejectedValue: err.Value(),
+				source:        err,
+			})
 		}
 	}
 
 	return FormatErrors(validationErrors)
 }
 
-func ErrorHandler(c *fiber.Ctx, err error) error {
-	if fiberErr, ok := err.(*fiber.Error); ok {
-		return c.Status(fiberErr.Code).JSON(GlobalErrorHandlerResp{
-			Success: false,
-			Message: fiberErr.Message,
-		})
+func NewFieldError(field, code, message string) *FieldError {
+	return &FieldError{
+		Field:   field,
+		Code:    code,
+		Message: message,
+	}
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("[%s]: %s", e.Field, e.Message)
+}
+
+func FormatErrors(errs []FieldError) *ValidationError {
+	if len(errs) == 0 {
+		return nil
 	}
 
-	return c.Status(fiber.StatusInternalServerError).JSON(GlobalErrorHandlerResp{
-		Success: false,
-		Message: "internal server error",
-	})
+	return &ValidationError{Errors: errs}
 }
 
-func FormatErrors(errs []ErrorResponse) *fiber.Error {
-	errMsgs := make([]string, len(errs))
+func (e *ValidationError) Error() string {
+	errMsgs := make([]string, len(e.Errors))
 
-	for index, err := range errs {
-		errMsgs[index] = fmt.Sprintf(
-			"[%s]: '%v' | Needs to implement '%s'",
-			err.FailedField,
-			err.Value,
-			err.Tag,
-		)
+	for index, err := range e.Errors {
+		errMsgs[index] = err.Error()
 	}
 
-	if len(errMsgs) > 0 {
-		return &fiber.Error{
-			Code:    fiber.ErrBadRequest.Code,
-			Message: strings.Join(errMsgs, ", "),
+	return strings.Join(errMsgs, ", ")
+}
+
+// fieldName names fields after the key the client sent, so errors point to
+// "date_of_birth" instead of "DateOfBirth".
+func fieldName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "query", "params"} {
+		name := strings.SplitN(field.Tag.Get(tag), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+
+		if name != "" {
+			return name
 		}
 	}
 
-	return nil
+	return field.Name
+}
+
+// fieldPath drops the root struct name from the namespace, keeping the path of
+// nested fields such as "beneficiaries[0].percentage".
+func fieldPath(err validator.FieldError) string {
+	namespace := err.Namespace()
+	if index := strings.Index(namespace, "."); index >= 0 {
+		return namespace[index+1:]
+	}
+
+	return err.Field()
 }